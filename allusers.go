@@ -0,0 +1,189 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/zerkz/gsca/steam"
+)
+
+// allUsers is --all-users: update every account on the Steam install
+var allUsers bool
+
+// maxParallelUsers bounds how many accounts' localconfig.vdf files are written at once
+const maxParallelUsers = 4
+
+// userUpdate is one account's part of an --all-users update
+type userUpdate struct {
+	user            steam.User
+	localConfigPath string
+	fingerprint     steam.Fingerprint
+	values          []steam.AppValue
+
+	result *steam.UpdateResult
+	err    error
+}
+
+// validateAllUsersFlags rejects flags that only make sense for a single account
+func validateAllUsersFlags() error {
+	if !allUsers {
+		return nil
+	}
+	switch {
+	case userID != "":
+		return fmt.Errorf("--all-users can't be used with --user-id")
+	case interactive:
+		return fmt.Errorf("--all-users can't be used with --interactive")
+	case planFile != "":
+		return fmt.Errorf("--all-users can't be used with --plan")
+	case showDiff:
+		return fmt.Errorf("--all-users can't be used with --diff")
+	case openConfig:
+		return fmt.Errorf("--all-users can't be used with --open")
+	}
+	return nil
+}
+
+// applyAllUsers is the rest of applyAppValue for --all-users. Games are selected for each
+// account in turn, since selection reads per-account files through the userID global, then
+// the accounts' files are written concurrently, and the results are reported per account
+func applyAllUsers(update appValueUpdate, updater *steam.Updater) error {
+	users, err := steam.ListUsers(steamPath)
+	if err != nil {
+		updater.Finish()
+		return fmt.Errorf("failed to list Steam accounts: %w", err)
+	}
+
+	// The accounts share one scan of the appmanifests
+	fmt.Println("Loading game library...")
+	session := steam.NewSession(steamPath, "")
+	library, err := session.Library()
+	if err != nil {
+		updater.Finish()
+		return fmt.Errorf("failed to load game library: %w", err)
+	}
+	fmt.Printf("Found %d games\n", len(library.All()))
+	warnOfflineLibraries()
+
+	var updates []*userUpdate
+	for _, user := range users {
+		userID = user.ID
+		u := &userUpdate{user: user, localConfigPath: steam.GetLocalConfigPath(steamPath, user.ID)}
+		updates = append(updates, u)
+		fmt.Printf("\n== %s ==\n", user.Label())
+
+		if u.fingerprint, err = steam.FingerprintFile(u.localConfigPath); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				// Accounts that never played a game on this machine have no localconfig.vdf
+				fmt.Println("No localconfig.vdf; skipping")
+				continue
+			}
+			u.err = fmt.Errorf("failed to read localconfig.vdf: %w", err)
+			fmt.Printf("ERROR: %v\n", u.err)
+			continue
+		}
+		if u.values, err = targetAppValues(update, session.ForAccount(u.localConfigPath)); err != nil {
+			u.err = err
+			fmt.Printf("ERROR: %v\n", err)
+			continue
+		}
+		if update.key == steam.KeyLaunchOptions {
+			if err := checkLaunchOptions(u.values); err != nil {
+				updater.Finish()
+				return err
+			}
+		}
+		fmt.Printf("Will update %s for %d games\n", update.label, len(u.values))
+		if dryRun {
+			for _, v := range u.values {
+				fmt.Printf("  - %s: %s\n", v.AppID, v.Value)
+			}
+		}
+	}
+	userID = ""
+
+	if dryRun {
+		fmt.Println("\n[DRY RUN] No files were changed")
+		if expiresAfter > 0 {
+			fmt.Printf("[DRY RUN] Would revert the changes after %s\n", time.Now().Add(expiresAfter).Format("2006-01-02 15:04"))
+		}
+		return allUsersError(updates)
+	}
+
+	writeAllUsers(updates, update.key, updater.Options)
+
+	names := libraryNames(library)
+	changed, accounts := 0, 0
+	for _, u := range updates {
+		if u.result == nil {
+			continue
+		}
+		accounts++
+		fmt.Printf("\n== %s ==\n", u.user.Label())
+		for _, app := range u.result.Apps {
+			if app.Status == steam.UpdateFailed {
+				fmt.Printf("ERROR: Failed to update app %s: %v\n", app.AppID, app.Err)
+			}
+		}
+		printUpdateSummary(u.result)
+		changed += u.result.Count(steam.UpdateChanged)
+
+		userID = u.user.ID
+		txID := recordTransaction(update.key, u.localConfigPath, u.result, names, "")
+		notifyWebhook("update", update.key, u.result, txID, "account "+u.user.ID)
+		tagUpdatedGames(u.result)
+	}
+	userID = ""
+
+	fmt.Printf("\nUpdated %d game(s) across %d account(s)\n", changed, accounts)
+	updater.Finish()
+	return allUsersError(updates)
+}
+
+// writeAllUsers sets the values of each account that has something to write, up to
+// maxParallelUsers at a time. Each account has its own localconfig.vdf, so the writes
+// don't depend on each other
+func writeAllUsers(updates []*userUpdate, key string, opts steam.UpdateOptions) {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, maxParallelUsers)
+	for _, u := range updates {
+		if u.err != nil || len(u.values) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(u *userUpdate) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			// The targets are stale if localconfig changed since it was read
+			if current, err := steam.FingerprintFile(u.localConfigPath); err == nil && !current.Equal(u.fingerprint) {
+				u.err = fmt.Errorf("%w - make sure Steam is closed and run the command again", steam.ErrConfigModified)
+				return
+			}
+			u.result, u.err = steam.SetAppValues(u.localConfigPath, key, u.values, opts)
+		}(u)
+	}
+	wg.Wait()
+}
+
+// allUsersError lists the accounts that failed, or returns nil if none did
+func allUsersError(updates []*userUpdate) error {
+	failed := 0
+	for _, u := range updates {
+		if u.err != nil {
+			if failed == 0 {
+				fmt.Println("\nAccounts that failed:")
+			}
+			failed++
+			fmt.Printf("  %s: %v\n", u.user.Label(), u.err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d account(s) failed", failed, len(updates))
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zerkz/gsca/anticheat"
+	"github.com/zerkz/gsca/argdb"
+	"github.com/zerkz/gsca/httpcache"
+	"github.com/zerkz/gsca/steam"
+)
+
+// antiCheatWarned holds the app/value pairs already warned about, so suggest's warning isn't
+// repeated when the suggestion is applied
+var antiCheatWarned = make(map[steam.AppValue]bool)
+
+// warnAntiCheat warns about games with kernel anti-cheat that are about to get launch options
+// known to trip it. The AreWeAntiCheatYet dataset is only fetched when some value has such a
+// token, and failing to fetch it is a warning rather than an error
+func warnAntiCheat(db *argdb.DB, values []steam.AppValue) {
+	risky := make(map[string][]string)
+	for _, v := range values {
+		if _, checked := risky[v.Value]; !checked {
+			risky[v.Value] = db.AntiCheatRisks(v.Value)
+		}
+	}
+	hasRisks := false
+	for _, tokens := range risky {
+		hasRisks = hasRisks || len(tokens) > 0
+	}
+	if !hasRisks {
+		return
+	}
+
+	cache, err := httpcache.NewDefault(refreshMetadata, offlineMode)
+	if err != nil {
+		fmt.Printf("Warning: %v; not checking for anti-cheat\n", err)
+		return
+	}
+	games, err := anticheat.New(cache).Games()
+	if err != nil {
+		fmt.Printf("Warning: Failed to get the AreWeAntiCheatYet list: %v; not checking for anti-cheat\n", err)
+		return
+	}
+	for _, warning := range antiCheatWarnings(values, risky, games) {
+		fmt.Printf("WARNING: %s\n", warning)
+	}
+}
+
+// antiCheatWarnings describes the values that give risky tokens (by value) to games with
+// kernel anti-cheat, once per app and value
+func antiCheatWarnings(values []steam.AppValue, risky map[string][]string, games map[string]anticheat.Game) []string {
+	var warnings []string
+	for _, v := range values {
+		tokens := risky[v.Value]
+		if len(tokens) == 0 || antiCheatWarned[v] {
+			continue
+		}
+		game, found := games[v.AppID]
+		kernel := game.KernelAntiCheats()
+		if !found || len(kernel) == 0 {
+			continue
+		}
+		antiCheatWarned[v] = true
+		warnings = append(warnings, fmt.Sprintf("app %s (%s) uses %s; %s may be flagged as cheating",
+			v.AppID, game.Name, strings.Join(kernel, ", "), strings.Join(tokens, " ")))
+	}
+	return warnings
+}
@@ -0,0 +1,92 @@
+// Package anticheat looks up which anti-cheat systems games use, from the AreWeAntiCheatYet
+// dataset, through the httpcache
+package anticheat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zerkz/gsca/httpcache"
+)
+
+// DefaultURL is the AreWeAntiCheatYet dataset
+const DefaultURL = "https://raw.githubusercontent.com/AreWeAntiCheatYet/AreWeAntiCheatYet/master/games.json"
+
+// cacheTTL is how long the dataset is reused; it changes a few times a week at most
+const cacheTTL = 24 * time.Hour
+
+// kernelAntiCheats are (parts of) the names of anti-cheat systems that run at kernel level
+// on Windows and watch for code injected into the game, which they may punish with a ban
+var kernelAntiCheats = []string{
+	"easy anti-cheat",
+	"battleye",
+	"vanguard",
+	"ricochet",
+	"gameguard",
+	"xigncode",
+	"denuvo anti-cheat",
+	"anti-cheat expert",
+	"equ8",
+	"faceit",
+	"javelin",
+	"mhyprot",
+}
+
+// Game is one entry of the dataset
+type Game struct {
+	Name string `json:"name"`
+	// Status is how the game runs on Linux: Supported, Running, Planned, Broken, or Denied
+	Status     string   `json:"status"`
+	AntiCheats []string `json:"anticheats"`
+	StoreIDs   struct {
+		Steam string `json:"steam"`
+	} `json:"storeIds"`
+}
+
+// KernelAntiCheats returns the game's anti-cheat systems that run at kernel level
+func (g Game) KernelAntiCheats() []string {
+	var kernel []string
+	for _, name := range g.AntiCheats {
+		lower := strings.ToLower(name)
+		for _, known := range kernelAntiCheats {
+			if strings.Contains(lower, known) {
+				kernel = append(kernel, name)
+				break
+			}
+		}
+	}
+	return kernel
+}
+
+// Client reads the dataset
+type Client struct {
+	HTTP *httpcache.Client
+	URL  string
+}
+
+// New creates a client using the default dataset URL
+func New(cache *httpcache.Client) *Client {
+	return &Client{HTTP: cache, URL: DefaultURL}
+}
+
+// Games returns the games of the dataset by Steam app ID; games not on Steam are left out
+func (c *Client) Games() (map[string]Game, error) {
+	body, err := c.HTTP.Get(c.URL, cacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	var games []Game
+	if err := json.Unmarshal(body, &games); err != nil {
+		return nil, fmt.Errorf("unexpected response from %s: %w", c.URL, err)
+	}
+
+	byAppID := make(map[string]Game)
+	for _, game := range games {
+		if game.StoreIDs.Steam != "" {
+			byAppID[game.StoreIDs.Steam] = game
+		}
+	}
+	return byAppID, nil
+}
@@ -0,0 +1,52 @@
+package anticheat
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/zerkz/gsca/httpcache"
+)
+
+func TestGames(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"name": "Apex Legends", "status": "Supported", "anticheats": ["Easy Anti-Cheat"], "storeIds": {"steam": "1172470"}},
+			{"name": "Rainbow Six Siege", "status": "Denied", "anticheats": ["BattlEye"], "storeIds": {"steam": "359550"}},
+			{"name": "Epic Only", "status": "Running", "anticheats": ["Easy Anti-Cheat"], "storeIds": {"epic": {"namespace": "x"}}}
+		]`))
+	}))
+	defer server.Close()
+
+	cache := httpcache.New(t.TempDir())
+	cache.MinInterval = 0
+	cache.MaxRetries = 0
+	client := &Client{HTTP: cache, URL: server.URL}
+
+	games, err := client.Games()
+	if err != nil {
+		t.Fatalf("Games() error = %v", err)
+	}
+	if len(games) != 2 || games["359550"].Status != "Denied" {
+		t.Errorf("Games() = %+v, want the two Steam games", games)
+	}
+}
+
+func TestKernelAntiCheats(t *testing.T) {
+	tests := []struct {
+		anticheats []string
+		want       []string
+	}{
+		{[]string{"Easy Anti-Cheat", "Valve Anti-Cheat"}, []string{"Easy Anti-Cheat"}},
+		{[]string{"BattlEye"}, []string{"BattlEye"}},
+		{[]string{"nProtect GameGuard", "Custom"}, []string{"nProtect GameGuard"}},
+		{[]string{"Valve Anti-Cheat", "PunkBuster"}, nil},
+	}
+
+	for _, tt := range tests {
+		if got := (Game{AntiCheats: tt.anticheats}).KernelAntiCheats(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("KernelAntiCheats(%v) = %v, want %v", tt.anticheats, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/steam"
+)
+
+var (
+	appConfigKey   string
+	appConfigValue string
+)
+
+var appConfigCmd = &cobra.Command{
+	Use:   "appconfig",
+	Short: "Read or write arbitrary per-game keys in localconfig.vdf",
+	Long: `Read or write any key under each game's entry in localconfig.vdf
+(e.g. DisableLaunchInVR). LaunchOptions is one such key; 'gsca update' is a shortcut for it.`,
+}
+
+var appConfigSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set a per-game key for selected games",
+	Long: `Set a key under each selected game's entry in localconfig.vdf.
+
+Games are selected with --all, --allow, or --deny, exactly like 'gsca update'.
+
+Example:
+  gsca appconfig set --key DisableLaunchInVR --value 1 --allow list.txt`,
+	RunE: runAppConfigSet,
+}
+
+var appConfigGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Show a per-game key for your games",
+	Long: `Show the value of a key for each game in localconfig.vdf.
+
+Without --allow or --deny, every game that has the key set is shown.`,
+	RunE: runAppConfigGet,
+}
+
+func init() {
+	appConfigSetCmd.Flags().StringVarP(&appConfigKey, "key", "k", "", "Key to set under each game's entry (required)")
+	appConfigSetCmd.Flags().StringVar(&appConfigValue, "value", "", "Value to set (required)")
+	addUpdateFlags(appConfigSetCmd)
+	_ = appConfigSetCmd.MarkFlagRequired("key")
+	_ = appConfigSetCmd.MarkFlagRequired("value")
+
+	appConfigGetCmd.Flags().StringVarP(&appConfigKey, "key", "k", "", "Key to show (required)")
+	appConfigGetCmd.Flags().StringVarP(&allowFile, "allow", "l", "", "Only show games in this allow list file")
+	appConfigGetCmd.Flags().StringVarP(&denyFile, "deny", "d", "", "Hide games in this deny list file")
+	_ = appConfigGetCmd.MarkFlagRequired("key")
+
+	appConfigCmd.AddCommand(appConfigSetCmd)
+	appConfigCmd.AddCommand(appConfigGetCmd)
+	rootCmd.AddCommand(appConfigCmd)
+}
+
+func runAppConfigSet(cmd *cobra.Command, args []string) error {
+	return applyAppValue(appValueUpdate{
+		key:        appConfigKey,
+		value:      appConfigValue,
+		label:      appConfigKey,
+		valueLabel: "Value",
+	})
+}
+
+func runAppConfigGet(cmd *cobra.Command, args []string) error {
+	if allowFile != "" && denyFile != "" {
+		return fmt.Errorf("cannot specify both --allow and --deny flags")
+	}
+
+	localConfigPath, err := detectLocalConfigPath()
+	if err != nil {
+		return err
+	}
+
+	library, err := steam.LoadLibrary(steamPath, nil)
+	if err != nil {
+		return fmt.Errorf("failed to load game library: %w", err)
+	}
+
+	values, err := steam.GetAppValues(localConfigPath, appConfigKey)
+	if err != nil {
+		return err
+	}
+
+	// Without a list, only games that have the key are interesting
+	var appIDs []string
+	if allowFile == "" && denyFile == "" {
+		for appID := range values {
+			appIDs = append(appIDs, appID)
+		}
+		sort.Strings(appIDs)
+	} else {
+		allGameIDs, idsErr := steam.GetAllGameIDs(localConfigPath)
+		if idsErr != nil {
+			return fmt.Errorf("failed to get game IDs: %w", idsErr)
+		}
+		allGameIDs = steam.AddInstalledGameIDs(allGameIDs, library)
+		if appIDs, err = selectTargetGameIDs(allGameIDs, library); err != nil {
+			return err
+		}
+	}
+
+	if len(appIDs) == 0 {
+		fmt.Printf("No games have %s set\n", appConfigKey)
+		return nil
+	}
+
+	for _, appID := range appIDs {
+		name := appID
+		if game, found := library.LookupByID(appID); found {
+			name = game.Name
+		}
+
+		value, set := values[appID]
+		if !set {
+			value = "(not set)"
+		}
+		fmt.Printf("%-10s %-40s %s\n", appID, name, value)
+	}
+
+	return nil
+}
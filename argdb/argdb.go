@@ -0,0 +1,222 @@
+// Package argdb describes common launch option tokens (game flags, environment variables,
+// and wrapper commands) from an embedded database that users can extend with their own file
+package argdb
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zerkz/gsca/steam"
+)
+
+// Kinds of launch option tokens
+const (
+	KindFlag    = "flag"    // game argument, e.g. -novid
+	KindEnv     = "env"     // environment variable, e.g. PROTON_LOG=1
+	KindWrapper = "wrapper" // command the game runs under, e.g. gamemoderun
+)
+
+//go:embed args.json
+var builtin []byte
+
+// Entry describes one token
+type Entry struct {
+	// Token is the flag, variable name, or wrapper program
+	Token       string `json:"token"`
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+	// Value describes the value that follows a flag, if it takes one (e.g. "width in pixels")
+	Value string `json:"value,omitempty"`
+	// Platforms limits where the token has an effect (linux, windows, darwin); empty means everywhere
+	Platforms []string `json:"platforms,omitempty"`
+	// Proton is set for tokens that only have an effect in games run through Proton
+	Proton bool `json:"proton,omitempty"`
+	// AntiCheatRisk is set for tokens that load code into the game process (overlays,
+	// injected libraries), which kernel-level anti-cheat may treat as cheating
+	AntiCheatRisk bool `json:"anticheat_risk,omitempty"`
+}
+
+// NeedsCommand reports whether the token only works before %command%
+func (e Entry) NeedsCommand() bool {
+	return e.Kind == KindEnv || e.Kind == KindWrapper
+}
+
+// DB is a set of entries, looked up by token
+type DB struct {
+	entries map[string]Entry
+}
+
+// Default returns the embedded database
+func Default() *DB {
+	db := &DB{entries: make(map[string]Entry)}
+	if err := db.add(builtin); err != nil {
+		panic(fmt.Sprintf("embedded args.json: %v", err))
+	}
+	return db
+}
+
+// DefaultUserPath returns the path of the user's own entries, args.json under the user's
+// config directory
+func DefaultUserPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gsca", "args.json"), nil
+}
+
+// Merge adds the entries of a user file, replacing built-in entries for the same token.
+// A missing file adds nothing
+func (db *DB) Merge(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := db.add(data); err != nil {
+		return fmt.Errorf("invalid args file %s: %w", path, err)
+	}
+	return nil
+}
+
+// add parses a {"args": [...]} file into db
+func (db *DB) add(data []byte) error {
+	var file struct {
+		Args []Entry `json:"args"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	for _, entry := range file.Args {
+		switch {
+		case entry.Token == "":
+			return fmt.Errorf("entry without a token")
+		case entry.Kind != KindFlag && entry.Kind != KindEnv && entry.Kind != KindWrapper:
+			return fmt.Errorf("%s: kind must be %s, %s, or %s", entry.Token, KindFlag, KindEnv, KindWrapper)
+		}
+		db.entries[lookupKey(entry.Kind, entry.Token)] = entry
+	}
+	return nil
+}
+
+// lookupKey indexes entries by kind, ignoring case (flags like -USEALLAVAILABLECORES are
+// matched case-insensitively by most engines)
+func lookupKey(kind, token string) string {
+	return kind + ":" + strings.ToLower(token)
+}
+
+// Lookup finds the entry of a kind for a token: a flag, a variable name, or a wrapper
+// program, which may be given as a path (e.g. /usr/bin/mangohud)
+func (db *DB) Lookup(kind, token string) (Entry, bool) {
+	if kind == KindWrapper {
+		token = filepath.Base(strings.ReplaceAll(token, `\`, "/"))
+	}
+	entry, ok := db.entries[lookupKey(kind, token)]
+	return entry, ok
+}
+
+// Explanation describes one token of a launch string
+type Explanation struct {
+	Token string
+	// Kind is one of the Kind constants, KindCommand, KindValue (the value of the preceding
+	// flag), or "" when the token isn't recognized
+	Kind string
+	// Entry is the database entry, if the token is known
+	Entry *Entry
+	// Misplaced is set for environment variables and wrappers that won't take effect where
+	// they are: after %command% (or without it) Steam passes them to the game as arguments,
+	// and an environment variable after a wrapper is run as a command
+	Misplaced bool
+}
+
+// Kinds of tokens that only appear in explanations
+const (
+	KindCommand    = "command"     // the %command% placeholder
+	KindValue      = "value"       // value of the preceding flag, e.g. 1920 in "-w 1920"
+	KindWrapperArg = "wrapper-arg" // argument of the preceding wrapper, e.g. -c 0-7 in "taskset -c 0-7"
+)
+
+// Explain describes each token of a launch string, in order
+func (db *DB) Explain(launchOptions string) []Explanation {
+	tokens := steam.SplitLaunchOptions(launchOptions)
+	commandIdx := -1
+	for i, token := range tokens {
+		if token == steam.CommandToken {
+			commandIdx = i
+			break
+		}
+	}
+
+	explanations := make([]Explanation, 0, len(tokens))
+	inWrapper, sawWrapper := false, false
+	for i, token := range tokens {
+		ex := Explanation{Token: token}
+		var prev *Explanation
+		if i > 0 {
+			prev = &explanations[i-1]
+		}
+		name, _, isEnv := steam.SplitEnvVar(token)
+		switch {
+		case token == steam.CommandToken:
+			ex.Kind = KindCommand
+		case isEnv:
+			ex.Kind = KindEnv
+			ex.Entry = db.find(KindEnv, name)
+			// After a wrapper, the assignment would be run as the wrapper's command
+			ex.Misplaced = i > commandIdx || sawWrapper
+		case inWrapper && i < commandIdx && db.find(KindWrapper, token) == nil:
+			ex.Kind = KindWrapperArg
+		case prev != nil && prev.Kind == KindFlag && prev.Entry != nil && prev.Entry.Value != "" && !isFlag(token):
+			ex.Kind = KindValue
+		case isFlag(token):
+			ex.Kind = KindFlag
+			ex.Entry = db.find(KindFlag, token)
+		default:
+			if ex.Entry = db.find(KindWrapper, token); ex.Entry != nil || i < commandIdx {
+				ex.Kind = KindWrapper
+				ex.Misplaced = i > commandIdx
+			}
+		}
+		inWrapper = ex.Kind == KindWrapper || ex.Kind == KindWrapperArg
+		sawWrapper = sawWrapper || ex.Kind == KindWrapper
+		explanations = append(explanations, ex)
+	}
+	return explanations
+}
+
+// AntiCheatRisks returns the tokens of a launch string that may trip anti-cheat. Variables
+// set to "" or 0 turn the feature off, so they don't count
+func (db *DB) AntiCheatRisks(launchOptions string) []string {
+	var risks []string
+	for _, ex := range db.Explain(launchOptions) {
+		if ex.Entry == nil || !ex.Entry.AntiCheatRisk {
+			continue
+		}
+		if ex.Kind == KindEnv {
+			_, value, _ := steam.SplitEnvVar(ex.Token)
+			if value = strings.Trim(value, `"'`); value == "" || value == "0" {
+				continue
+			}
+		}
+		risks = append(risks, ex.Token)
+	}
+	return risks
+}
+
+// find is Lookup returning nil for unknown tokens
+func (db *DB) find(kind, token string) *Entry {
+	if entry, ok := db.Lookup(kind, token); ok {
+		return &entry
+	}
+	return nil
+}
+
+func isFlag(token string) bool {
+	return strings.HasPrefix(token, "-") || strings.HasPrefix(token, "+")
+}
@@ -0,0 +1,121 @@
+package argdb
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	db := Default()
+
+	type token struct {
+		kind      string
+		known     bool
+		misplaced bool
+	}
+	tests := []struct {
+		name string
+		in   string
+		want []token
+	}{
+		{
+			name: "no command",
+			in:   "-novid PROTON_ENABLE_NVAPI=1 mangohud",
+			want: []token{{KindFlag, true, false}, {KindEnv, true, true}, {KindWrapper, true, true}},
+		},
+		{
+			name: "well formed",
+			in:   "PROTON_LOG=1 /usr/bin/gamemoderun %command% -w 1920 -NOVID",
+			want: []token{{KindEnv, true, false}, {KindWrapper, true, false}, {KindCommand, false, false}, {KindFlag, true, false}, {KindValue, false, false}, {KindFlag, true, false}},
+		},
+		{
+			name: "wrapper arguments",
+			in:   "taskset -c 0-7 gamemoderun %command%",
+			want: []token{{KindWrapper, true, false}, {KindWrapperArg, false, false}, {KindWrapperArg, false, false}, {KindWrapper, true, false}, {KindCommand, false, false}},
+		},
+		{
+			name: "env after wrapper",
+			in:   "gamemoderun PROTON_LOG=1 %command%",
+			want: []token{{KindWrapper, true, false}, {KindEnv, true, true}, {KindCommand, false, false}},
+		},
+		{
+			name: "unknown tokens",
+			in:   "FOO=1 mywrapper %command% -frobnicate stray",
+			want: []token{{KindEnv, false, false}, {KindWrapper, false, false}, {KindCommand, false, false}, {KindFlag, false, false}, {"", false, false}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := db.Explain(tt.in)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Explain(%q) returned %d tokens, want %d", tt.in, len(got), len(tt.want))
+			}
+			for i, want := range tt.want {
+				ex := got[i]
+				if ex.Kind != want.kind || (ex.Entry != nil) != want.known || ex.Misplaced != want.misplaced {
+					t.Errorf("token %q = kind %q, known %v, misplaced %v, want %q, %v, %v",
+						ex.Token, ex.Kind, ex.Entry != nil, ex.Misplaced, want.kind, want.known, want.misplaced)
+				}
+			}
+		})
+	}
+}
+
+func TestAntiCheatRisks(t *testing.T) {
+	db := Default()
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"gamemoderun %command% -novid", nil},
+		{"mangohud %command%", []string{"mangohud"}},
+		{"LD_PRELOAD=/usr/lib/libhook.so ENABLE_VKBASALT=1 %command%", []string{"LD_PRELOAD=/usr/lib/libhook.so", "ENABLE_VKBASALT=1"}},
+		{`LD_PRELOAD="" MANGOHUD=0 %command%`, nil},
+	}
+
+	for _, tt := range tests {
+		if got := db.AntiCheatRisks(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AntiCheatRisks(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	dir := t.TempDir()
+	db := Default()
+
+	if err := db.Merge(filepath.Join(dir, "missing.json")); err != nil {
+		t.Errorf("Merge() of a missing file error = %v, want nil", err)
+	}
+
+	path := filepath.Join(dir, "args.json")
+	user := `{"args": [
+		{"token": "-novid", "kind": "flag", "description": "My own description"},
+		{"token": "MY_VAR", "kind": "env", "description": "Something local"}
+	]}`
+	if err := os.WriteFile(path, []byte(user), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Merge(path); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if entry, ok := db.Lookup(KindFlag, "-novid"); !ok || entry.Description != "My own description" {
+		t.Errorf("Lookup(-novid) = %+v, want the user's entry", entry)
+	}
+	if _, ok := db.Lookup(KindEnv, "MY_VAR"); !ok {
+		t.Error("Lookup(MY_VAR) not found after Merge()")
+	}
+	if _, ok := db.Lookup(KindWrapper, "gamemoderun"); !ok {
+		t.Error("Merge() dropped built-in entries")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"args": [{"token": "x", "kind": "other"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Merge(path); err == nil {
+		t.Error("Merge() of an entry with an invalid kind error = nil, want error")
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/argdb"
+	"github.com/zerkz/gsca/steam"
+)
+
+var argsCmd = &cobra.Command{
+	Use:   "args",
+	Short: "Work with launch option strings",
+}
+
+var argsExplainCmd = &cobra.Command{
+	Use:   "explain <launch options>",
+	Short: "Describe each token of a launch string",
+	Long: `Describe what each token of a launch string does, which platform it applies to, and
+whether it needs to come before %command%. Tokens are looked up in a built-in database,
+extended by args.json in gsca's config directory, in the same format:
+
+  {"args": [{"token": "MY_VAR", "kind": "env", "description": "...", "platforms": ["linux"]}]}
+
+Example:
+  gsca args explain "-novid PROTON_ENABLE_NVAPI=1 mangohud"`,
+	Args: cobra.MinimumNArgs(1),
+	// Launch strings start with flags like -novid, which mustn't be parsed as gsca's own
+	DisableFlagParsing: true,
+	RunE:               runArgsExplain,
+}
+
+func init() {
+	argsCmd.AddCommand(argsExplainCmd)
+	rootCmd.AddCommand(argsCmd)
+}
+
+func runArgsExplain(cmd *cobra.Command, args []string) error {
+	db, err := loadArgDB()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 && (args[0] == "--help" || args[0] == "-h") {
+		return cmd.Help()
+	}
+
+	launchOptions := strings.Join(args, " ")
+	explanations := db.Explain(launchOptions)
+	if len(explanations) == 0 {
+		return fmt.Errorf("no launch options given")
+	}
+
+	risky := make(map[string]bool)
+	for _, token := range db.AntiCheatRisks(launchOptions) {
+		risky[token] = true
+	}
+
+	misplaced := false
+	for i, ex := range explanations {
+		fmt.Printf("%s\n  %s\n", ex.Token, describeToken(ex))
+		if ex.Misplaced {
+			misplaced = true
+			if ex.Kind == argdb.KindEnv && i < commandIndex(explanations) {
+				fmt.Println("  Warning: after a wrapper, so it is run as a command instead of being set")
+			} else {
+				fmt.Printf("  Warning: not before %s, so Steam passes it to the game as an argument\n", steam.CommandToken)
+			}
+		}
+		if risky[ex.Token] {
+			fmt.Println("  Warning: loads code into the game, which kernel anti-cheat may flag")
+		}
+	}
+
+	if misplaced {
+		fmt.Printf("\nDid you mean: %s\n", reorderLaunchOptions(explanations))
+	}
+	return nil
+}
+
+// loadArgDB returns the built-in token database with the user's own entries merged in
+func loadArgDB() (*argdb.DB, error) {
+	db := argdb.Default()
+	if path, err := argdb.DefaultUserPath(); err == nil {
+		if err := db.Merge(path); err != nil {
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// describeToken renders what a token is and does on one line
+func describeToken(ex argdb.Explanation) string {
+	switch ex.Kind {
+	case argdb.KindCommand:
+		return "Placeholder Steam replaces with the game's executable"
+	case argdb.KindValue:
+		return "Value of the previous flag"
+	case argdb.KindWrapperArg:
+		return "Argument of the previous wrapper"
+	}
+
+	kind := map[string]string{
+		argdb.KindFlag:    "Game flag",
+		argdb.KindEnv:     "Environment variable",
+		argdb.KindWrapper: "Wrapper command",
+	}[ex.Kind]
+	if ex.Entry == nil {
+		if kind == "" {
+			return "Unknown token, passed to the game as an argument"
+		}
+		return kind + " (unknown)"
+	}
+
+	details := []string{kind}
+	if len(ex.Entry.Platforms) > 0 {
+		details = append(details, strings.Join(ex.Entry.Platforms, "/")+" only")
+	}
+	if ex.Entry.Proton {
+		details = append(details, "Proton only")
+	}
+	if ex.Entry.NeedsCommand() {
+		details = append(details, "needs "+steam.CommandToken)
+	}
+	if ex.Entry.Value != "" {
+		details = append(details, "followed by "+ex.Entry.Value)
+	}
+	return fmt.Sprintf("%s [%s]", ex.Entry.Description, strings.Join(details, ", "))
+}
+
+// commandIndex returns the position of %command%, or -1
+func commandIndex(explanations []argdb.Explanation) int {
+	for i, ex := range explanations {
+		if ex.Kind == argdb.KindCommand {
+			return i
+		}
+	}
+	return -1
+}
+
+// reorderLaunchOptions moves misplaced environment variables and wrappers before %command%
+func reorderLaunchOptions(explanations []argdb.Explanation) string {
+	opts := &steam.LaunchOptions{}
+	inWrapper := false
+	for _, ex := range explanations {
+		switch {
+		case ex.Kind == argdb.KindCommand:
+			inWrapper = false
+		case ex.Kind == argdb.KindEnv:
+			name, value, _ := steam.SplitEnvVar(ex.Token)
+			opts.SetEnv(name, value)
+		case ex.Kind == argdb.KindWrapper:
+			opts.Wrappers = append(opts.Wrappers, ex.Token)
+			inWrapper = true
+		case ex.Kind == argdb.KindWrapperArg && inWrapper:
+			opts.Wrappers = append(opts.Wrappers, ex.Token)
+		default:
+			opts.Args = append(opts.Args, ex.Token)
+			inWrapper = false
+		}
+	}
+	return opts.String()
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/zerkz/gsca/audit"
+	"github.com/zerkz/gsca/steam"
+)
+
+// auditFiles collects the Steam files modified during this run
+var auditFiles []audit.File
+
+func init() {
+	steam.OnFileChange = func(change steam.FileChange) {
+		auditFiles = append(auditFiles, audit.File{Path: change.Path, Before: change.Before, After: change.After})
+	}
+}
+
+// writeAuditLog appends this run's modifications, if any, to the audit log
+// Failing to write the audit log only warns, since the changes are already written
+func writeAuditLog() {
+	if len(auditFiles) == 0 {
+		return
+	}
+
+	path, err := audit.DefaultPath()
+	if err == nil {
+		err = audit.Log{Path: path}.Append(&audit.Entry{
+			User:    audit.CurrentUser(),
+			Command: os.Args,
+			Files:   auditFiles,
+		})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to write the audit log: %v\n", err)
+	}
+}
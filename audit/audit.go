@@ -0,0 +1,84 @@
+// Package audit keeps an append-only log of the Steam files each gsca run modified, so
+// admins of shared machines can see who changed what and when
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+)
+
+// EnvPath overrides the log location, e.g. to share one log between the users of a machine
+const EnvPath = "GSCA_AUDIT_LOG"
+
+// File is a file a run modified, with the SHA-256 of its contents before and after
+// ("" if it didn't exist)
+type File struct {
+	Path   string `json:"path"`
+	Before string `json:"sha256_before"`
+	After  string `json:"sha256_after"`
+}
+
+// Entry is one run that modified files
+type Entry struct {
+	Time    time.Time `json:"time"`
+	User    string    `json:"user"`
+	Command []string  `json:"command"`
+	Files   []File    `json:"files"`
+}
+
+// Log is a file of entries, one JSON object per line. Entries are only ever appended
+type Log struct {
+	Path string
+}
+
+// DefaultPath returns $GSCA_AUDIT_LOG, or audit.log under the user's config directory
+func DefaultPath() (string, error) {
+	if path := os.Getenv(EnvPath); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gsca", "audit.log"), nil
+}
+
+// CurrentUser names the user running gsca
+func CurrentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
+
+// Append adds an entry to the end of the log, setting its time
+func (l Log) Append(entry *Entry) error {
+	entry.Time = time.Now().UTC()
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(l.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	_, err = f.Write(append(line, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAppend(t *testing.T) {
+	log := Log{Path: filepath.Join(t.TempDir(), "gsca", "audit.log")}
+
+	entries := []*Entry{
+		{User: "alice", Command: []string{"gsca", "update", "--all"}, Files: []File{{Path: "localconfig.vdf", Before: "aa", After: "bb"}}},
+		{User: "bob", Command: []string{"gsca", "undo"}, Files: []File{{Path: "localconfig.vdf", Before: "bb", After: "aa"}}},
+	}
+	for _, entry := range entries {
+		if err := log.Append(entry); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+		if entry.Time.IsZero() {
+			t.Error("Append() did not set the entry time")
+		}
+	}
+
+	f, err := os.Open(log.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	// Earlier entries are kept, one per line
+	var users []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("log line %q: %v", scanner.Text(), err)
+		}
+		users = append(users, entry.User)
+	}
+	if len(users) != 2 || users[0] != "alice" || users[1] != "bob" {
+		t.Errorf("log users = %v, want [alice bob]", users)
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv(EnvPath, "/var/log/gsca-audit.log")
+	if path, err := DefaultPath(); err != nil || path != "/var/log/gsca-audit.log" {
+		t.Errorf("DefaultPath() = %q, %v, want the %s path", path, err, EnvPath)
+	}
+}
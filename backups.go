@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/output"
+	"github.com/zerkz/gsca/steam"
+)
+
+var backupsCmd = &cobra.Command{
+	Use:   "backups",
+	Short: "Check the backups of localconfig.vdf",
+}
+
+var backupsVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Re-hash and re-parse backups to find corrupted or truncated ones",
+	Long: `Check every backup of localconfig.vdf before you need it for a restore.
+
+Each backup is compared to the checksum recorded when it was made and parsed again.
+Backups made before gsca recorded checksums are only parsed. Exits non-zero if any
+backup is corrupted or damaged.`,
+	Args:         cobra.NoArgs,
+	RunE:         runBackupsVerify,
+	SilenceUsage: true,
+}
+
+func init() {
+	addFormatFlag(backupsVerifyCmd)
+	backupsCmd.AddCommand(backupsVerifyCmd)
+	rootCmd.AddCommand(backupsCmd)
+}
+
+func runBackupsVerify(cmd *cobra.Command, args []string) error {
+	restoreStdout, err := startFormat()
+	if err != nil {
+		return err
+	}
+	defer restoreStdout()
+
+	localConfigPath, err := detectLocalConfigPath()
+	if err != nil {
+		return err
+	}
+	checks, err := steam.VerifyBackups(localConfigPath)
+	if err != nil {
+		return err
+	}
+	if outputFormat != "" {
+		return writeBackupChecks(checks)
+	}
+	if len(checks) == 0 {
+		fmt.Printf("No backups of %s\n", localConfigPath)
+		return nil
+	}
+
+	bad := 0
+	for _, check := range checks {
+		fmt.Printf("%-10s %s  %s", check.Status, check.Backup.ModTime.Format("2006-01-02 15:04"), check.Backup.Name)
+		if check.Err != nil {
+			bad++
+			fmt.Printf(": %v", check.Err)
+		}
+		fmt.Println()
+	}
+
+	if bad > 0 {
+		return fmt.Errorf("%d of %d backup(s) can't be trusted for a restore", bad, len(checks))
+	}
+	fmt.Printf("\n%d backup(s) OK\n", len(checks))
+	return nil
+}
+
+// writeBackupChecks prints backup checks in the --format format, failing like the text
+// output if any backup can't be trusted
+func writeBackupChecks(checks []steam.BackupCheck) error {
+	table := output.Table{Columns: []string{"status", "time", "name", "path", "error"}}
+	bad := 0
+	for _, check := range checks {
+		var checkErr any
+		if check.Err != nil {
+			bad++
+			checkErr = check.Err.Error()
+		}
+		table.Add(check.Status, check.Backup.ModTime.Format(time.RFC3339), check.Backup.Name, check.Backup.Path, checkErr)
+	}
+	if err := writeFormat(table); err != nil {
+		return err
+	}
+	if bad > 0 {
+		return fmt.Errorf("%d of %d backup(s) can't be trusted for a restore", bad, len(checks))
+	}
+	return nil
+}
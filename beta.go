@@ -0,0 +1,59 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/steam"
+)
+
+var betaBranch string
+
+var betaCmd = &cobra.Command{
+	Use:   "beta",
+	Short: "Manage beta branches of installed games",
+}
+
+var betaSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Opt selected games into or out of a beta branch",
+	Long: `Select a beta branch for installed games, like the Betas tab in each game's Steam properties.
+Use --branch public to leave a beta. Steam downloads the selected branch the next time it starts.
+
+Games are selected with --all, --allow, or --deny, exactly like 'gsca update'.
+Each changed appmanifest is backed up next to itself unless --no-backup is given.
+
+Example:
+  gsca beta set --branch public --allow list.txt`,
+	RunE: runBetaSet,
+}
+
+func init() {
+	betaSetCmd.Flags().StringVarP(&betaBranch, "branch", "b", "", "Beta branch key, or 'public' for the default branch (required)")
+	addSelectionFlags(betaSetCmd)
+	_ = betaSetCmd.MarkFlagRequired("branch")
+
+	betaCmd.AddCommand(betaSetCmd)
+	rootCmd.AddCommand(betaCmd)
+}
+
+func runBetaSet(cmd *cobra.Command, args []string) error {
+	return applyManifestValue("the beta branch", "Branch", betaBranch, func(appIDs []string) (*steam.UpdateResult, error) {
+		return steam.SetBetaBranch(steamPath, appIDs, betaBranch, steam.UpdateOptions{SkipBackup: noBackup})
+	})
+}
+
+// applyManifestValue runs the update pipeline for settings stored in appmanifest files,
+// calling set with the targeted installed games
+func applyManifestValue(label, valueLabel, value string, set func(appIDs []string) (*steam.UpdateResult, error)) error {
+	return applyAppValue(appValueUpdate{
+		value:      value,
+		label:      label,
+		valueLabel: valueLabel,
+		write: func(values []steam.AppValue) (*steam.UpdateResult, error) {
+			appIDs := make([]string, len(values))
+			for i, v := range values {
+				appIDs[i] = v.AppID
+			}
+			return set(appIDs)
+		},
+	})
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/steam"
+)
+
+var (
+	compareUsers []string
+	compareCopy  bool
+	compareApps  []string
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare --user-id A --user-id B",
+	Short: "Compare the launch options of two Steam users",
+	Long: `Show the games whose launch options differ between two Steam accounts on this machine.
+
+With --copy, the first user's launch options are copied to the second user,
+for the games the second user has (and only those in --apps, if given). A game
+the first user has no launch options for is only cleared for the second user
+when it's named in --apps.
+
+Example:
+  gsca compare -u 11111111 -u 22222222
+  gsca compare -u 11111111 -u 22222222 --copy --apps 570,730`,
+	Args: cobra.NoArgs,
+	RunE: runCompare,
+}
+
+func init() {
+	// Shadows the global --user-id, which only takes one user
+	compareCmd.Flags().StringArrayVarP(&compareUsers, "user-id", "u", nil, "Steam user ID to compare (give exactly two)")
+	compareCmd.Flags().BoolVar(&compareCopy, "copy", false, "Copy the first user's launch options to the second user")
+	compareCmd.Flags().StringSliceVar(&compareApps, "apps", nil, "Only compare (and copy) these app IDs")
+	compareCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what --copy would change without modifying files")
+	compareCmd.Flags().BoolVarP(&autoCloseSteam, "force", "f", false, "Automatically close Steam if running (no prompt)")
+	compareCmd.Flags().BoolVar(&noBackup, "no-backup", false, "Skip creating backup file")
+	addRestartFlags(compareCmd)
+
+	rootCmd.AddCommand(compareCmd)
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	if len(compareUsers) != 2 || compareUsers[0] == compareUsers[1] {
+		return fmt.Errorf("give two different users: --user-id A --user-id B")
+	}
+
+	var err error
+	if steamPath == "" {
+		if steamPath, err = detectSteamPath(); err != nil {
+			return fmt.Errorf("failed to detect Steam path: %w", err)
+		}
+	}
+
+	paths := make([]string, 2)
+	values := make([]map[string]string, 2)
+	for i, user := range compareUsers {
+		paths[i] = steam.GetLocalConfigPath(steamPath, user)
+		if _, err := os.Stat(paths[i]); err != nil {
+			return fmt.Errorf("no Steam config for user %s: %w", user, err)
+		}
+		if values[i], err = steam.GetAppValues(paths[i], steam.KeyLaunchOptions); err != nil {
+			return err
+		}
+	}
+
+	names := libraryNamesOrEmpty()
+
+	differing := launchOptionDiffs(values[0], values[1], compareApps)
+	if len(differing) == 0 {
+		fmt.Printf("Users %s and %s have the same launch options\n", compareUsers[0], compareUsers[1])
+		return nil
+	}
+
+	fmt.Printf("Launch options that differ between users %s and %s:\n\n", compareUsers[0], compareUsers[1])
+	for _, appID := range differing {
+		fmt.Printf("  %s\n", gameLabel(appID, names))
+		fmt.Printf("    %s: %q\n", compareUsers[0], values[0][appID])
+		fmt.Printf("    %s: %q\n", compareUsers[1], values[1][appID])
+	}
+	fmt.Printf("\n%d game(s) differ\n", len(differing))
+
+	if !compareCopy {
+		fmt.Printf("Copy them from %s to %s with --copy\n", compareUsers[0], compareUsers[1])
+		return nil
+	}
+
+	owned, err := steam.GetAllGameIDs(paths[1])
+	if err != nil {
+		return err
+	}
+	copied := copiedLaunchOptions(values[0], differing, owned, len(compareApps) > 0)
+	if skipped := len(differing) - len(copied); skipped > 0 {
+		fmt.Printf("\nSkipping %d game(s) user %s doesn't have or user %s has no launch options for (name them with --apps to clear them)\n",
+			skipped, compareUsers[1], compareUsers[0])
+	}
+	if len(copied) == 0 {
+		fmt.Println("Nothing to copy")
+		return nil
+	}
+	fmt.Printf("\nWill copy launch options for %d games from user %s to user %s\n", len(copied), compareUsers[0], compareUsers[1])
+	return writeCopiedValues(paths[1], copied, nil, names, "compare --copy")
+}
+
+// launchOptionDiffs returns the app IDs (limited to apps, if given) whose launch options
+// differ between a and b, in ascending order. A missing entry counts as no launch options
+func launchOptionDiffs(a, b map[string]string, apps []string) []string {
+	candidates := apps
+	if len(candidates) == 0 {
+		for appID := range a {
+			candidates = append(candidates, appID)
+		}
+		for appID := range b {
+			if _, inA := a[appID]; !inA {
+				candidates = append(candidates, appID)
+			}
+		}
+	}
+
+	var differing []string
+	seen := make(map[string]bool)
+	for _, appID := range candidates {
+		if !seen[appID] && a[appID] != b[appID] {
+			differing = append(differing, appID)
+		}
+		seen[appID] = true
+	}
+	steam.SortAppIDs(differing)
+	return differing
+}
+
+// copiedLaunchOptions picks the launch options in from to copy for the differing games the
+// destination account owns. Games without launch options in from are only cleared at the
+// destination when they were named explicitly
+func copiedLaunchOptions(from map[string]string, differing, owned []string, named bool) []steam.AppValue {
+	ownedSet := make(map[string]bool, len(owned))
+	for _, appID := range owned {
+		ownedSet[appID] = true
+	}
+	var copied []steam.AppValue
+	for _, appID := range differing {
+		if !ownedSet[appID] || (from[appID] == "" && !named) {
+			continue
+		}
+		copied = append(copied, steam.AppValue{AppID: appID, Value: from[appID]})
+	}
+	return copied
+}
+
+// gameLabel names a game as "Name (appid)", or just the app ID if the name is unknown
+func gameLabel(appID string, names map[string]string) string {
+	if name := names[appID]; name != "" {
+		return fmt.Sprintf("%s (%s)", name, appID)
+	}
+	return appID
+}
+
+// writeCopiedValues sets launch options copied from another user or install in
+// localConfigPath, and compatTools (may be nil) in the config.vdf of steamPath, closing
+// and restarting Steam around the writes and recording them
+func writeCopiedValues(localConfigPath string, values, compatTools []steam.AppValue, names map[string]string, event string) error {
+	if dryRun {
+		fmt.Println("\n[DRY RUN] Would set the following values:")
+		for _, v := range values {
+			fmt.Printf("  - %s: %q\n", gameLabel(v.AppID, names), v.Value)
+		}
+		for _, v := range compatTools {
+			fmt.Printf("  - %s: compatibility tool %s\n", gameLabel(v.AppID, names), v.Value)
+		}
+		return nil
+	}
+
+	shouldRestartSteam, err := closeSteamBeforeWrite()
+	if err != nil {
+		return err
+	}
+	if shouldRestartSteam {
+		defer restartSteam()
+	}
+
+	if len(values) > 0 {
+		result, err := steam.SetAppValues(localConfigPath, steam.KeyLaunchOptions, values, steam.UpdateOptions{SkipBackup: noBackup})
+		if err != nil {
+			return fmt.Errorf("failed to copy launch options: %w", err)
+		}
+		printUpdateSummary(result)
+		txID := recordTransaction(steam.KeyLaunchOptions, localConfigPath, result, names, "")
+		notifyWebhook(event, steam.KeyLaunchOptions, result, txID, "")
+	}
+
+	if len(compatTools) > 0 {
+		result, err := steam.SetCompatTools(steamPath, compatTools, steam.UpdateOptions{SkipBackup: noBackup})
+		if err != nil {
+			return fmt.Errorf("failed to copy compatibility tools: %w", err)
+		}
+		fmt.Printf("Compatibility tools: %d set, %d already set\n", result.Count(steam.UpdateChanged), result.Count(steam.UpdateUnchanged))
+		if result.BackupPath != "" {
+			fmt.Printf("Backup created at: %s\n", result.BackupPath)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/output"
+	"github.com/zerkz/gsca/steam"
+)
+
+var compatCmd = &cobra.Command{
+	Use:   "compat",
+	Short: "Inspect the compatibility tools (Proton versions) games can run with",
+}
+
+var compatToolsCmd = &cobra.Command{
+	Use:   "tools",
+	Short: "List installed Proton versions and custom compatibility tools",
+	Long: `List the official Proton versions installed in any library folder and the custom tools
+(e.g. GE-Proton) in compatibilitytools.d, with the internal names Steam uses for them in
+config.vdf, and how many games are forced to use each.
+
+Only the selected Steam install is scanned (see --steam-flavor), plus the system-wide
+compatibilitytools.d directories on Linux. Tools in another install's folders aren't
+listed, since this one can't use them.`,
+	Args: cobra.NoArgs,
+	RunE: runCompatTools,
+}
+
+func init() {
+	addFormatFlag(compatToolsCmd)
+	compatCmd.AddCommand(compatToolsCmd)
+	rootCmd.AddCommand(compatCmd)
+}
+
+func runCompatTools(cmd *cobra.Command, args []string) error {
+	restoreStdout, err := startFormat()
+	if err != nil {
+		return err
+	}
+	defer restoreStdout()
+
+	if steamPath == "" {
+		if steamPath, err = detectSteamPath(); err != nil {
+			return fmt.Errorf("failed to detect Steam path: %w", err)
+		}
+	}
+	tools, err := steam.FindCompatTools(steamPath)
+	if err != nil {
+		return fmt.Errorf("failed to find compatibility tools: %w", err)
+	}
+	warnOfflineLibraries()
+
+	games := make(map[string]int)
+	for _, tool := range steam.GetCompatTools(steamPath) {
+		games[tool]++
+	}
+
+	if outputFormat != "" {
+		table := output.Table{Columns: []string{"name", "display_name", "custom", "games", "path"}}
+		for _, tool := range tools {
+			table.Add(tool.Name, tool.DisplayName, tool.Custom, games[tool.Name], tool.Path)
+		}
+		return writeFormat(table)
+	}
+
+	if len(tools) == 0 {
+		fmt.Println("No compatibility tools found")
+		return nil
+	}
+	installed := make(map[string]bool, len(tools))
+	fmt.Printf("%-24s %-30s %-6s %s\n", "NAME", "DISPLAY NAME", "GAMES", "PATH")
+	for _, tool := range tools {
+		installed[tool.Name] = true
+		displayName := tool.DisplayName
+		if tool.Custom {
+			displayName += " (custom)"
+		}
+		fmt.Printf("%-24s %-30s %-6d %s\n", tool.Name, displayName, games[tool.Name], tool.Path)
+	}
+	fmt.Printf("\n%d tool(s)\n", len(tools))
+
+	var missing []string
+	for name := range games {
+		if !installed[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		fmt.Printf("Warning: %d game(s) are forced to use %s, which isn't installed\n", games[name], name)
+	}
+	return nil
+}
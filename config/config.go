@@ -0,0 +1,125 @@
+// Package config reads gsca's own settings file, config.json in the user's config directory
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Config holds the settings that can't be given as flags on every run
+type Config struct {
+	// SteamPaths are extra Steam installs to look for besides the usual locations
+	SteamPaths []string `json:"steam_paths,omitempty"`
+	// SteamExecutable is the Steam client to start and stop instead of the usual one for
+	// the platform, e.g. a wrapper script
+	SteamExecutable string `json:"steam_executable,omitempty"`
+	// StartCommand and StopCommand replace the commands that start and close Steam, as
+	// argument lists, e.g. ["flatpak", "run", "com.valvesoftware.Steam"]
+	StartCommand []string `json:"start_command,omitempty"`
+	StopCommand  []string `json:"stop_command,omitempty"`
+	// UserID is the Steam account to use when --user-id isn't given, instead of the one
+	// that signed in last
+	UserID string `json:"user_id,omitempty"`
+	// WebhookURL receives a summary after each run that changes something
+	WebhookURL string `json:"webhook_url,omitempty"`
+	// WebhookFormat is json, discord, or slack (detected from the URL if empty)
+	WebhookFormat string `json:"webhook_format,omitempty"`
+	// Aliases map shorthand names to app IDs, e.g. "cs2": "730"
+	Aliases map[string]string `json:"aliases,omitempty"`
+	// ArgAliases map short names to launch options, used as --args @name
+	ArgAliases map[string]string `json:"arg_aliases,omitempty"`
+	// Remote is another machine whose Steam is managed over SSH, as if --remote were given
+	Remote *Remote `json:"remote,omitempty"`
+}
+
+// Remote describes a machine whose Steam is managed over SSH
+type Remote struct {
+	// Host is the ssh destination, e.g. deck@steamdeck
+	Host string `json:"host"`
+	// SSHArgs are extra options for ssh, e.g. ["-p", "2222"]
+	SSHArgs []string `json:"ssh_args,omitempty"`
+	// SteamPath is the Steam install on that machine, detected if empty
+	SteamPath string `json:"steam_path,omitempty"`
+	// StartCommand and StopCommand take the place of Config's for that machine, as single
+	// shell command lines, e.g. "flatpak run com.valvesoftware.Steam"
+	StartCommand string `json:"start_command,omitempty"`
+	StopCommand  string `json:"stop_command,omitempty"`
+}
+
+// Alias returns the app ID an alias stands for. Aliases are matched case-insensitively
+func (c *Config) Alias(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	for alias, appID := range c.Aliases {
+		if strings.EqualFold(alias, name) {
+			return appID, true
+		}
+	}
+	return "", false
+}
+
+// DefaultPath returns the path of config.json under the user's config directory
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gsca", "config.json"), nil
+}
+
+// Load reads a config file. A missing file is an empty config
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Save writes the config to path, creating its directory if needed
+func (c *Config) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// ExpandArgs replaces "@name" with the launch options saved under name in ArgAliases.
+// Other values are returned unchanged
+func (c *Config) ExpandArgs(args string) (string, error) {
+	name, isAlias := strings.CutPrefix(strings.TrimSpace(args), "@")
+	if !isAlias {
+		return args, nil
+	}
+	if expanded, ok := c.ArgAliases[name]; ok {
+		return expanded, nil
+	}
+
+	names := make([]string, 0, len(c.ArgAliases))
+	for alias := range c.ArgAliases {
+		names = append(names, "@"+alias)
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("unknown args alias @%s: no arg_aliases in the config file", name)
+	}
+	sort.Strings(names)
+	return "", fmt.Errorf("unknown args alias @%s (known: %s)", name, strings.Join(names, ", "))
+}
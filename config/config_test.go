@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg, err := Load(filepath.Join(dir, "missing.json"))
+	if err != nil || len(cfg.SteamPaths) != 0 {
+		t.Errorf("Load() of a missing file = %+v, %v, want an empty config", cfg, err)
+	}
+
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"steam_paths": ["/opt/steam", "D:\\Games\\Steam"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = Load(path)
+	if err != nil || !reflect.DeepEqual(cfg.SteamPaths, []string{"/opt/steam", `D:\Games\Steam`}) {
+		t.Errorf("Load() = %+v, %v, want both steam paths", cfg, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"steam_paths": "/opt/steam"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load() of an invalid config error = nil, want error")
+	}
+}
+
+func TestSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gsca", "config.json")
+	cfg := &Config{UserID: "222", ArgAliases: map[string]string{"default": "gamemoderun %command%"}}
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil || !reflect.DeepEqual(loaded, cfg) {
+		t.Errorf("Load() after Save() = %+v, %v, want %+v", loaded, err, cfg)
+	}
+}
+
+func TestAlias(t *testing.T) {
+	cfg := &Config{Aliases: map[string]string{"cs2": "730", "TF2": "440"}}
+
+	tests := []struct {
+		name   string
+		want   string
+		wantOK bool
+	}{
+		{"cs2", "730", true},
+		{"tf2", "440", true},
+		{" CS2 ", "730", true},
+		{"dota", "", false},
+	}
+
+	for _, tt := range tests {
+		if got, ok := cfg.Alias(tt.name); got != tt.want || ok != tt.wantOK {
+			t.Errorf("Alias(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestExpandArgs(t *testing.T) {
+	cfg := &Config{ArgAliases: map[string]string{"deck-battery": "DXVK_FRAME_RATE=40 mangohud %command%"}}
+
+	tests := []struct {
+		args    string
+		want    string
+		wantErr bool
+	}{
+		{"@deck-battery", "DXVK_FRAME_RATE=40 mangohud %command%", false},
+		{"-novid", "-novid", false},
+		{"", "", false},
+		{"@unknown", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := cfg.ExpandArgs(tt.args)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ExpandArgs(%q) = %q, %v, want %q (error: %v)", tt.args, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// Characters used to lay out output. setupConsole swaps them for ASCII where the console
+// can't display them, or when GSCA_ASCII is set
+var (
+	bullet   = "•"
+	ruleChar = "─"
+)
+
+// setupConsole prepares the console for gsca's output and returns a function that undoes
+// any changes to it, to be called before exiting
+func setupConsole() (restore func()) {
+	restore, unicode := configureConsole()
+	if !unicode || os.Getenv("GSCA_ASCII") != "" {
+		bullet, ruleChar = "*", "-"
+	}
+	return restore
+}
+
+// rule returns a horizontal line separating sections of output
+func rule() string {
+	return strings.Repeat(ruleChar, 40)
+}
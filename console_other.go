@@ -0,0 +1,9 @@
+//go:build !windows
+
+package main
+
+// configureConsole has nothing to set up outside Windows: terminals take UTF-8 and escape
+// sequences as they are
+func configureConsole() (func(), bool) {
+	return func() {}, true
+}
@@ -0,0 +1,53 @@
+package main
+
+import "syscall"
+
+const (
+	cpUTF8                          = 65001
+	enableVirtualTerminalProcessing = 0x0004
+)
+
+var (
+	kernel32               = syscall.NewLazyDLL("kernel32.dll")
+	procGetConsoleCP       = kernel32.NewProc("GetConsoleCP")
+	procGetConsoleOutputCP = kernel32.NewProc("GetConsoleOutputCP")
+	procSetConsoleCP       = kernel32.NewProc("SetConsoleCP")
+	procSetConsoleOutputCP = kernel32.NewProc("SetConsoleOutputCP")
+	procSetConsoleMode     = kernel32.NewProc("SetConsoleMode")
+)
+
+// configureConsole switches the console to UTF-8, so game names outside the local code page
+// aren't garbled where output is piped through cmd or PowerShell, and enables escape
+// sequences for colors. Consoles that can't do both (legacy consoles before Windows 10)
+// can't draw the bullets and lines either, so it reports whether Unicode output is safe
+func configureConsole() (func(), bool) {
+	// 0 means no console is attached (e.g. started from a service or scheduled task)
+	inCP, _, _ := procGetConsoleCP.Call()
+	outCP, _, _ := procGetConsoleOutputCP.Call()
+	if outCP == 0 {
+		return func() {}, true
+	}
+
+	utf8, _, _ := procSetConsoleOutputCP.Call(cpUTF8)
+	_, _, _ = procSetConsoleCP.Call(cpUTF8)
+	restore := func() {
+		_, _, _ = procSetConsoleOutputCP.Call(outCP)
+		if inCP != 0 {
+			_, _, _ = procSetConsoleCP.Call(inCP)
+		}
+	}
+
+	// Output redirected to a file or pipe needs no escape sequence support
+	vt := true
+	for _, std := range []int{syscall.STD_OUTPUT_HANDLE, syscall.STD_ERROR_HANDLE} {
+		handle, err := syscall.GetStdHandle(std)
+		var mode uint32
+		if err != nil || syscall.GetConsoleMode(handle, &mode) != nil {
+			continue
+		}
+		if ok, _, _ := procSetConsoleMode.Call(uintptr(handle), uintptr(mode|enableVirtualTerminalProcessing)); ok == 0 {
+			vt = false
+		}
+	}
+	return restore, utf8 != 0 && vt
+}
@@ -0,0 +1,222 @@
+// Package diff produces unified diffs of text files
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// maxEdits bounds the work spent on a minimal diff; beyond it the differing
+// region is shown as one replacement
+const maxEdits = 2000
+
+// op is one line of an edit script: kind is ' ' (kept), '-' (removed) or '+' (added),
+// a and b are the line's position in each file
+type op struct {
+	kind byte
+	a, b int
+}
+
+// Unified returns a unified diff turning a into b with context lines around each
+// change, or "" if they are equal
+func Unified(fromName, toName string, a, b []byte, context int) string {
+	aLines, bLines := splitLines(a), splitLines(b)
+	ops := edits(aLines, bLines)
+
+	var out strings.Builder
+	for _, hunk := range hunks(ops, context) {
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+		}
+
+		aStart, aCount, bStart, bCount := hunk[0].a, 0, hunk[0].b, 0
+		for _, o := range hunk {
+			if o.kind != '+' {
+				aCount++
+			}
+			if o.kind != '-' {
+				bCount++
+			}
+		}
+		// Empty ranges point at the line before them
+		if aCount > 0 {
+			aStart++
+		}
+		if bCount > 0 {
+			bStart++
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+
+		for _, o := range hunk {
+			var line string
+			if o.kind == '+' {
+				line = bLines[o.b]
+			} else {
+				line = aLines[o.a]
+			}
+			out.WriteByte(o.kind)
+			out.WriteString(line)
+			if !strings.HasSuffix(line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+
+	return out.String()
+}
+
+// splitLines splits data into lines that keep their line endings
+func splitLines(data []byte) []string {
+	var lines []string
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, '\n') + 1
+		if end == 0 {
+			end = len(data)
+		}
+		lines = append(lines, string(data[:end]))
+		data = data[end:]
+	}
+	return lines
+}
+
+// hunks groups the changes in ops with up to context unchanged lines around them,
+// merging changes that are close enough to share context
+func hunks(ops []op, context int) [][]op {
+	var result [][]op
+	start, end := -1, -1
+
+	for i, o := range ops {
+		if o.kind == ' ' {
+			continue
+		}
+		from, to := max(i-context, 0), min(i+context+1, len(ops))
+		if start != -1 && from > end {
+			result = append(result, ops[start:end])
+			start = -1
+		}
+		if start == -1 {
+			start = from
+		}
+		end = to
+	}
+	if start != -1 {
+		result = append(result, ops[start:end])
+	}
+
+	return result
+}
+
+// edits returns an edit script turning a into b
+func edits(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []op
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, op{' ', i, i})
+	}
+	ops = append(ops, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], prefix, prefix)...)
+	for i := suffix; i > 0; i-- {
+		ops = append(ops, op{' ', len(a) - i, len(b) - i})
+	}
+
+	return ops
+}
+
+// myers finds a shortest edit script with Myers' O(ND) algorithm. Line positions
+// are offset by aOffset and bOffset
+func myers(a, b []string, aOffset, bOffset int) []op {
+	n, m := len(a), len(b)
+	limit := n + m
+	if limit == 0 {
+		return nil
+	}
+
+	// v[offset+k] is the furthest x reached on diagonal k; trace keeps the
+	// window of v each step read, for backtracking
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= limit && !found; d++ {
+		if d > maxEdits {
+			return replace(n, m, aOffset, bOffset)
+		}
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	var ops []op
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		window := trace[d]
+		at := func(k int) int { return window[k+d+1] }
+
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, op{' ', aOffset + x, bOffset + y})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				ops = append(ops, op{'+', aOffset + x, bOffset + y})
+			} else {
+				x--
+				ops = append(ops, op{'-', aOffset + x, bOffset + y})
+			}
+		}
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// replace removes all n lines of a and adds all m lines of b
+func replace(n, m, aOffset, bOffset int) []op {
+	ops := make([]op, 0, n+m)
+	for i := 0; i < n; i++ {
+		ops = append(ops, op{'-', aOffset + i, bOffset})
+	}
+	for i := 0; i < m; i++ {
+		ops = append(ops, op{'+', aOffset + n, bOffset + i})
+	}
+	return ops
+}
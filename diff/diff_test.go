@@ -0,0 +1,84 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		context int
+		want    string
+	}{
+		{name: "equal", a: "a\nb\n", b: "a\nb\n", context: 3, want: ""},
+		{
+			name:    "changed line",
+			a:       "1\n2\n3\n4\n5\n",
+			b:       "1\n2\nthree\n4\n5\n",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -2,3 +2,3 @@\n 2\n-3\n+three\n 4\n",
+		},
+		{
+			name:    "separate hunks",
+			a:       "1\n2\n3\n4\n5\n6\n7\n8\n",
+			b:       "one\n2\n3\n4\n5\n6\n7\neight\n",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -1,2 +1,2 @@\n-1\n+one\n 2\n@@ -7,2 +7,2 @@\n 7\n-8\n+eight\n",
+		},
+		{
+			name:    "close changes share a hunk",
+			a:       "1\n2\n3\n4\n",
+			b:       "one\n2\n3\nfour\n",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n-4\n+four\n",
+		},
+		{
+			name:    "insertion",
+			a:       "a\nc\n",
+			b:       "a\nb\nc\n",
+			context: 0,
+			want:    "--- old\n+++ new\n@@ -1,0 +2,1 @@\n+b\n",
+		},
+		{
+			name:    "from empty",
+			a:       "",
+			b:       "a\n",
+			context: 3,
+			want:    "--- old\n+++ new\n@@ -0,0 +1,1 @@\n+a\n",
+		},
+		{
+			name:    "missing final newline",
+			a:       "a\nb",
+			b:       "a\nc",
+			context: 3,
+			want:    "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Unified("old", "new", []byte(tt.a), []byte(tt.b), tt.context)
+			if got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditsMinimal(t *testing.T) {
+	a := strings.Split("a b c a b b a", " ")
+	b := strings.Split("c b a b a c", " ")
+
+	changes := 0
+	for _, o := range edits(a, b) {
+		if o.kind != ' ' {
+			changes++
+		}
+	}
+	// The classic example from Myers' paper has an edit distance of 5
+	if changes != 5 {
+		t.Errorf("edits() made %d changes, want 5", changes)
+	}
+}
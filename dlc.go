@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zerkz/gsca/steam"
+)
+
+// findDLC returns the apps in appIDs that are DLC, according to Steam's appinfo cache. Steam
+// only reads launch options from games, so values written for DLC never take effect.
+// Without the cache nothing is known to be DLC
+func findDLC(appIDs []string) map[string]steam.AppInfo {
+	dlc := make(map[string]steam.AppInfo)
+	if len(appIDs) == 0 {
+		return dlc
+	}
+	infos, err := steam.LoadAppInfo(steam.GetAppInfoPath(steamPath), appIDs)
+	if err != nil {
+		return dlc
+	}
+	for appID, info := range infos {
+		if info.IsDLC() {
+			dlc[appID] = info
+		}
+	}
+	return dlc
+}
+
+// markDLC flags the resolved list entries that are DLC
+func markDLC(entries []listEntry) {
+	var appIDs []string
+	for _, e := range entries {
+		if e.appID != "" {
+			appIDs = append(appIDs, e.appID)
+		}
+	}
+	dlc := findDLC(appIDs)
+	for i := range entries {
+		setDLC(&entries[i], dlc)
+	}
+}
+
+// setDLC flags e if its app is in dlc, naming it from the appinfo cache if needed
+func setDLC(e *listEntry, dlc map[string]steam.AppInfo) {
+	info, ok := dlc[e.appID]
+	if !ok {
+		return
+	}
+	e.dlc = true
+	e.dlcParent = info.Parent
+	if e.name == "" {
+		e.name = info.Name
+	}
+}
+
+// dlcLabel describes a DLC app and the game it belongs to
+func dlcLabel(info steam.AppInfo, library *steam.Library) string {
+	label := gameLabel(info.AppID, map[string]string{info.AppID: info.Name})
+	if info.Parent == "" {
+		return label + " is DLC"
+	}
+	parentName := ""
+	if game, found := library.LookupByID(info.Parent); found {
+		parentName = game.Name
+	}
+	return fmt.Sprintf("%s is DLC for %s", label, gameLabel(info.Parent, map[string]string{info.Parent: parentName}))
+}
+
+// substituteDLCAnswer is the answer to askAboutDLC, for replaceDLC to apply
+var substituteDLCAnswer bool
+
+// askAboutDLC checks games picked by --apps or an allow list for DLC. It warns about each one
+// and asks whether to update the games they belong to instead. It runs before Steam is
+// closed, so the question isn't asked while Steam is down
+func askAboutDLC() error {
+	if len(appIDList) == 0 && allowFile == "" {
+		return nil
+	}
+	var err error
+	items := appIDList
+	if len(items) == 0 {
+		if items, err = steam.LoadFilterList(allowFile); err != nil {
+			return fmt.Errorf("failed to load allow list: %w", err)
+		}
+	}
+	if steamPath == "" {
+		if steamPath, err = detectSteamPath(); err != nil {
+			return fmt.Errorf("failed to detect Steam path: %w", err)
+		}
+	}
+	library, err := steam.LoadLibrary(steamPath, nil)
+	if err != nil {
+		return fmt.Errorf("failed to load game library: %w", err)
+	}
+
+	appIDs, _ := steam.ResolveGameIDs(expandAliases(items), library)
+	dlc := findDLC(appIDs)
+	if len(dlc) == 0 {
+		return nil
+	}
+	fmt.Println()
+	for _, appID := range appIDs {
+		if info, ok := dlc[appID]; ok {
+			fmt.Printf("WARNING: %s; DLC can't have launch options\n", dlcLabel(info, library))
+		}
+	}
+	substituteDLCAnswer = confirm("Update the games they belong to instead? (Y/n): ")
+	return nil
+}
+
+// replaceDLC drops the DLC from games picked by --apps or an allow list, or puts the games
+// they belong to in their place as askAboutDLC was answered
+func replaceDLC(appIDs []string) []string {
+	if len(appIDList) == 0 && allowFile == "" {
+		return appIDs
+	}
+	dlc := findDLC(appIDs)
+	if len(dlc) == 0 {
+		return appIDs
+	}
+	return substituteDLC(appIDs, dlc, substituteDLCAnswer)
+}
+
+// substituteDLC drops the DLC from appIDs, putting the games they belong to in their place
+// if substitute is set. Each game is listed once
+func substituteDLC(appIDs []string, dlc map[string]steam.AppInfo, substitute bool) []string {
+	seen := make(map[string]bool, len(appIDs))
+	result := make([]string, 0, len(appIDs))
+	skipped := 0
+	for _, appID := range appIDs {
+		if info, ok := dlc[appID]; ok {
+			if !substitute || info.Parent == "" {
+				skipped++
+				continue
+			}
+			appID = info.Parent
+		}
+		if !seen[appID] {
+			seen[appID] = true
+			result = append(result, appID)
+		}
+	}
+	if skipped > 0 {
+		fmt.Printf("Skipping %d DLC\n", skipped)
+	}
+	return result
+}
+
+// offerListDLCFix offers to replace the DLC entries of a list file with the app IDs of the
+// games they belong to, keeping the previous version as <file>.bak
+func offerListDLCFix(filePath string, resolved []listEntry) error {
+	parents := make(map[string]string)
+	for _, e := range resolved {
+		if e.dlc && e.dlcParent != "" {
+			parents[e.entry] = e.dlcParent
+		}
+	}
+	if len(parents) == 0 || !canPrompt() {
+		return nil
+	}
+	if !confirm(fmt.Sprintf("Replace %d DLC entries with the games they belong to? (Y/n): ", len(parents))) {
+		return nil
+	}
+
+	original, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read list file: %w", err)
+	}
+	editor := &listEditor{lines: strings.Split(strings.TrimSuffix(string(original), "\n"), "\n")}
+	lines := editor.entries()
+	entries := make([]string, len(lines))
+	for i, line := range lines {
+		entries[i] = strings.TrimSpace(editor.lines[line])
+	}
+	for i, entry := range expandAliases(entries) {
+		if parent, ok := parents[entry]; ok {
+			editor.lines[lines[i]] = parent
+			editor.dirty = true
+		}
+	}
+	return writeEditedList(filePath, original, editor)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/i18n"
+	"github.com/zerkz/gsca/steam"
+	"github.com/zerkz/gsca/vdf"
+)
+
+var doctorRepair bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check localconfig.vdf for damage and optionally repair it",
+	Long: `Check that localconfig.vdf is well-formed and report the damaged region if it is not
+(unbalanced braces or truncation are common after crashes).
+
+With --repair, the damaged file is saved alongside the original and rewritten from everything
+that could still be parsed. If a good backup exists, the per-game settings can be rebuilt from it
+while keeping the rest of the salvaged file.`,
+	RunE: runDoctor,
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorRepair, "repair", false, "Salvage a damaged localconfig.vdf")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	localConfigPath, err := detectLocalConfigPath()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Steam path: %s\n", steamPath)
+	fmt.Printf("User ID: %s\n", userID)
+	fmt.Printf("Local config: %s\n\n", localConfigPath)
+
+	checkErr := steam.CheckLocalConfig(localConfigPath)
+	var syntaxErr *vdf.SyntaxError
+	if checkErr != nil && !errors.As(checkErr, &syntaxErr) {
+		return checkErr
+	}
+
+	if syntaxErr == nil {
+		fmt.Println("OK: localconfig.vdf is well-formed")
+	} else {
+		fmt.Printf("ERROR: localconfig.vdf is damaged at %v\n", syntaxErr)
+	}
+
+	backup, hasBackup := steam.NewestValidBackup(localConfigPath)
+	if hasBackup {
+		fmt.Printf("Newest good backup: %s (%s)\n", backup.Name, backup.ModTime.Format("2006-01-02 15:04:05"))
+	} else {
+		fmt.Println("No good backups found")
+	}
+
+	if syntaxErr == nil {
+		if doctorRepair {
+			fmt.Println("\nNothing to repair.")
+		}
+		return nil
+	}
+
+	if !doctorRepair {
+		fmt.Println("\nRun 'gsca doctor --repair' to salvage the file.")
+		return fmt.Errorf("localconfig.vdf is damaged")
+	}
+
+	// Steam keeps its own copy of the config in memory and writes it on exit
+	steamRunning, err := isSteamRunning()
+	if err != nil {
+		fmt.Println(i18n.T(i18n.SteamCheckFailed, err))
+	} else if steamRunning {
+		fmt.Println("\n" + i18n.T(i18n.SteamRunning))
+		if !canPrompt() && !assumeYes {
+			return errNoTerminal("Closing Steam", "close Steam first or use --yes")
+		}
+		if !confirm("\n" + i18n.T(i18n.PromptCloseAndApply)) {
+			return fmt.Errorf("%s", i18n.T(i18n.ErrAbortedApply))
+		}
+		if err := closeSteamAndWait(); err != nil {
+			return err
+		}
+	}
+
+	var appsBackupPath string
+	if hasBackup && confirm(fmt.Sprintf("\nRebuild per-game settings from %s? (Y/n): ", backup.Name)) {
+		appsBackupPath = backup.Path
+	}
+
+	result, err := steam.RepairLocalConfig(localConfigPath, appsBackupPath)
+	if err != nil {
+		return fmt.Errorf("failed to repair localconfig.vdf: %w", err)
+	}
+
+	fmt.Printf("\nDamaged file saved to: %s\n", result.DamagedCopy)
+	if result.AppsRestoredFrom != "" {
+		fmt.Printf("Per-game settings rebuilt from: %s\n", result.AppsRestoredFrom)
+	}
+	fmt.Printf("Repaired localconfig.vdf with %d game(s)\n", result.AppCount)
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/journal"
+	"github.com/zerkz/gsca/steam"
+)
+
+var driftSnapshot string
+
+var driftCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "Check whether Steam reverted any changes made with gsca",
+	Long: `Compare the live values in localconfig.vdf to what gsca last applied, as recorded in
+the journal (or to a snapshot with --snapshot). Nothing is changed.
+
+Exits non-zero when any game drifted, so it can run from cron or a monitoring check:
+  gsca drift || notify-send "Steam reverted launch options"`,
+	Args: cobra.NoArgs,
+	RunE: runDrift,
+	// Drift is a result, not a usage mistake
+	SilenceUsage: true,
+}
+
+func init() {
+	driftCmd.Flags().StringVar(&driftSnapshot, "snapshot", "", "Compare launch options to this snapshot instead of the journal")
+	rootCmd.AddCommand(driftCmd)
+}
+
+// driftedApp is a game whose live value differs from the expected one
+type driftedApp struct {
+	appID    string
+	name     string
+	expected string
+	actual   string
+	source   string
+}
+
+func runDrift(cmd *cobra.Command, args []string) error {
+	localConfigPath, err := detectLocalConfigPath()
+	if err != nil {
+		return err
+	}
+
+	// Expected values per key and app, and what set them
+	expected := make(map[string]map[string]journal.Applied)
+	if driftSnapshot != "" {
+		store, err := snapshotStore()
+		if err != nil {
+			return err
+		}
+		snap, err := store.Load(driftSnapshot)
+		if err != nil {
+			return err
+		}
+		localConfigPath = snap.LocalConfigPath
+		expected[steam.KeyLaunchOptions] = make(map[string]journal.Applied)
+		for appID, value := range snap.LaunchOptions {
+			expected[steam.KeyLaunchOptions][appID] = journal.Applied{Value: value}
+		}
+		fmt.Printf("Checking launch options against snapshot %q\n", snap.Name)
+	} else {
+		store, err := journalStore()
+		if err != nil {
+			return err
+		}
+		txs, err := store.List()
+		if err != nil {
+			return err
+		}
+		expected = journal.LastApplied(txs, localConfigPath)
+		if len(expected) == 0 {
+			fmt.Printf("Nothing to check: the journal has no changes to %s\n", localConfigPath)
+			return nil
+		}
+		fmt.Printf("Checking %s against the journal\n", localConfigPath)
+	}
+
+	var drifted []driftedApp
+	checked := 0
+	for key, apps := range expected {
+		live, err := steam.GetAppValues(localConfigPath, key)
+		if err != nil {
+			return err
+		}
+		for appID, applied := range apps {
+			checked++
+			if live[appID] != applied.Value {
+				source := key + " from snapshot " + driftSnapshot
+				if applied.TxID != "" {
+					source = key + " set by transaction " + applied.TxID
+				}
+				drifted = append(drifted, driftedApp{appID, applied.Name, applied.Value, live[appID], source})
+			}
+		}
+		// A snapshot also expects games it has no launch options for to have none
+		if driftSnapshot != "" {
+			for appID, value := range live {
+				if _, saved := apps[appID]; !saved && value != "" {
+					checked++
+					drifted = append(drifted, driftedApp{appID, "", "", value, key + " from snapshot " + driftSnapshot})
+				}
+			}
+		}
+	}
+
+	if len(drifted) == 0 {
+		fmt.Printf("No drift: %d value(s) match\n", checked)
+		return nil
+	}
+
+	names := libraryNamesOrEmpty()
+
+	sort.Slice(drifted, func(i, j int) bool { return drifted[i].appID < drifted[j].appID })
+	fmt.Printf("\n%d of %d value(s) drifted:\n", len(drifted), checked)
+	for _, app := range drifted {
+		name := app.appID
+		if app.name == "" {
+			app.name = names[app.appID]
+		}
+		if app.name != "" {
+			name = fmt.Sprintf("%s (%s)", app.name, app.appID)
+		}
+		fmt.Printf("  %s\n    expected %q, found %q (%s)\n", name, app.expected, app.actual, app.source)
+	}
+	fmt.Println("\nReapply with 'gsca update' or 'gsca snapshot revert'.")
+	return fmt.Errorf("%d value(s) drifted", len(drifted))
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/journal"
+)
+
+var (
+	expireWatch    bool
+	expireInterval time.Duration
+)
+
+var expireCmd = &cobra.Command{
+	Use:   "expire",
+	Short: "Revert changes made with --expires once their period is over",
+	Long: `Revert the transactions applied with 'gsca update --expires' whose period is over,
+like 'gsca undo --tx' for each. Games changed again since are left alone.
+
+While Steam is running nothing is reverted (Steam would overwrite the change when it
+exits) unless --force is given, so run it from cron or a scheduled task, or keep it
+running with --watch, which checks every --interval.
+
+Example:
+  gsca update --args "mangohud %command%" --apps 570 --expires 7d
+  gsca expire --watch`,
+	Args: cobra.NoArgs,
+	RunE: runExpire,
+}
+
+func init() {
+	expireCmd.Flags().BoolVar(&expireWatch, "watch", false, "Keep running and revert transactions as they expire")
+	expireCmd.Flags().DurationVar(&expireInterval, "interval", 5*time.Minute, "How often --watch checks for expired transactions")
+	expireCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be reverted without modifying files")
+	expireCmd.Flags().BoolVarP(&autoCloseSteam, "force", "f", false, "Close Steam if running instead of waiting for it to exit")
+	expireCmd.Flags().BoolVar(&noBackup, "no-backup", false, "Skip creating backup file")
+	addRestartFlags(expireCmd)
+
+	rootCmd.AddCommand(expireCmd)
+}
+
+func runExpire(cmd *cobra.Command, args []string) error {
+	if expireWatch && expireInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	store, err := journalStore()
+	if err != nil {
+		return err
+	}
+
+	for {
+		if err := revertExpired(store); err != nil {
+			if !expireWatch {
+				return err
+			}
+			fmt.Printf("Error: %v\n", err)
+		}
+		if !expireWatch {
+			return nil
+		}
+		// The remote machine's files are fetched again for each check
+		if remoteHost != nil {
+			if err := forgetRemote(); err != nil {
+				fmt.Printf("Error: %v\n", err)
+			}
+		}
+		time.Sleep(expireInterval)
+	}
+}
+
+// revertExpired reverts every expired transaction in the journal, unless Steam is running
+// and --force wasn't given
+func revertExpired(store journal.Store) error {
+	txs, err := store.List()
+	if err != nil {
+		return err
+	}
+	expired := journal.Expired(txs, time.Now())
+	if len(expired) == 0 {
+		if !expireWatch {
+			fmt.Println("No expired transactions")
+		}
+		return nil
+	}
+
+	if !autoCloseSteam && !dryRun {
+		if running, err := isSteamRunning(); err == nil && running {
+			fmt.Printf("%d transaction(s) expired, but Steam is running; not reverting until it exits (or use --force)\n", len(expired))
+			return nil
+		}
+	}
+
+	for _, tx := range expired {
+		fmt.Printf("\nTransaction %s expired %s\n", tx.ID, tx.ExpiresAt.Local().Format("2006-01-02 15:04"))
+		reverted, err := revertTransaction(tx, "expire")
+		if err != nil {
+			return err
+		}
+		// With nothing left to revert, no undo was recorded; mark the transaction as
+		// handled so it isn't picked up again
+		if reverted == 0 && !dryRun {
+			if err := store.Record(&journal.Transaction{Command: "gsca expire", Key: tx.Key, LocalConfigPath: tx.LocalConfigPath, UndoOf: tx.ID}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/zerkz/gsca/steam"
+)
+
+// Values of --family-shared
+const (
+	familySharedInclude = "include"
+	familySharedExclude = "exclude"
+	familySharedOnly    = "only"
+)
+
+// familyShared is --family-shared: whether games borrowed through Family Sharing are included
+var familyShared string
+
+// accountLabels names the accounts known to loginusers.vdf, by account ID
+var accountLabels map[string]string
+
+// checkFamilyShared validates --family-shared
+func checkFamilyShared() error {
+	switch familyShared {
+	case familySharedInclude, familySharedExclude, familySharedOnly:
+		return nil
+	}
+	return fmt.Errorf("invalid --family-shared %q (valid: %s, %s, %s)", familyShared, familySharedInclude, familySharedExclude, familySharedOnly)
+}
+
+// keepFamilyShared applies --family-shared to a game borrowed from sharedBy, or owned by
+// the user if sharedBy is empty
+func keepFamilyShared(sharedBy string) bool {
+	switch familyShared {
+	case familySharedExclude:
+		return sharedBy == ""
+	case familySharedOnly:
+		return sharedBy != ""
+	}
+	return true
+}
+
+// filterByFamilySharing applies --family-shared. Uninstalled games have no record of whose
+// license they use, so they count as the user's own
+func filterByFamilySharing(appIDs []string, library *steam.Library) ([]string, error) {
+	if err := checkFamilyShared(); err != nil || familyShared == familySharedInclude {
+		return appIDs, err
+	}
+
+	var kept []string
+	for _, appID := range appIDs {
+		sharedBy := ""
+		if game, found := library.LookupByID(appID); found {
+			sharedBy = steam.FamilySharedBy(game.LastOwner, userID)
+		}
+		if keepFamilyShared(sharedBy) {
+			kept = append(kept, appID)
+		}
+	}
+
+	if skipped := len(appIDs) - len(kept); skipped > 0 {
+		fmt.Printf("Skipping %d game(s) (--family-shared %s)\n", skipped, familyShared)
+	}
+	return kept, nil
+}
+
+// accountLabel names an account by ID, with its names if Steam remembers them
+func accountLabel(id string) string {
+	if accountLabels == nil {
+		accountLabels = make(map[string]string)
+		// Without loginusers.vdf, accounts are only known by ID
+		users, _ := steam.ListUsers(steamPath)
+		for _, user := range users {
+			if label := user.Label(); label != user.ID {
+				accountLabels[user.ID] = label
+			}
+		}
+	}
+	if label, found := accountLabels[id]; found {
+		return label
+	}
+	return "account " + id
+}
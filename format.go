@@ -0,0 +1,58 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/output"
+)
+
+// outputFormat is --format; empty means the command's usual text
+var outputFormat string
+
+// formatOut is where --format output goes, the real stdout while os.Stdout points at stderr
+var formatOut io.Writer = os.Stdout
+
+// addFormatFlag adds --format to a command that can print its results as data
+func addFormatFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&outputFormat, "format", "", "Print results as "+strings.Join(output.Formats, ", ")+" instead of the usual text")
+}
+
+// startFormat checks --format and, when it is set, sends everything else the command prints
+// (progress, warnings, prompts) to stderr, so stdout holds only the results. The returned
+// function undoes that
+func startFormat() (func(), error) {
+	if outputFormat == "" {
+		return func() {}, nil
+	}
+	if err := output.Check(outputFormat); err != nil {
+		return nil, err
+	}
+	stdout := os.Stdout
+	formatOut, os.Stdout = stdout, os.Stderr
+	return func() { os.Stdout = stdout }, nil
+}
+
+// writeFormat prints results in the --format format
+func writeFormat(t output.Table) error {
+	return output.Write(formatOut, outputFormat, t)
+}
+
+// optional returns nil for an empty value, so it is null rather than "" in JSON and YAML
+func optional(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// optionalTime formats t as RFC 3339, or returns nil if it isn't set
+func optionalTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.Format(time.RFC3339)
+}
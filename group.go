@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zerkz/gsca/steam"
+)
+
+// updateGroup is one --group: launch options for the games picked by its own selector
+type updateGroup struct {
+	spec      string
+	allowFile string
+	denyFile  string
+	appIDs    []string
+	args      string
+}
+
+// parseGroup parses a --group value such as allow=linux.txt,args="gamemoderun %command%"
+// Values may be quoted to contain commas
+func parseGroup(spec string) (updateGroup, error) {
+	group := updateGroup{spec: spec}
+	fields, err := splitGroupFields(spec)
+	if err != nil {
+		return group, fmt.Errorf("invalid --group %q: %w", spec, err)
+	}
+
+	selectors := 0
+	hasArgs := false
+	for _, field := range fields {
+		key, value, found := strings.Cut(field, "=")
+		if !found {
+			return group, fmt.Errorf("invalid --group %q: expected key=value, got %q (quote values that contain commas)", spec, field)
+		}
+
+		switch strings.TrimSpace(key) {
+		case "allow":
+			group.allowFile = value
+			selectors++
+		case "deny":
+			group.denyFile = value
+			selectors++
+		case "apps":
+			group.appIDs = strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })
+			selectors++
+		case "args":
+			group.args = value
+			hasArgs = true
+		default:
+			return group, fmt.Errorf("invalid --group %q: unknown key %q (use allow, deny, apps, or args)", spec, key)
+		}
+	}
+
+	switch {
+	case !hasArgs:
+		return group, fmt.Errorf("invalid --group %q: missing args=", spec)
+	case selectors != 1:
+		return group, fmt.Errorf("invalid --group %q: needs exactly one of allow=, deny=, or apps=", spec)
+	}
+	return group, nil
+}
+
+// splitGroupFields splits spec on commas outside of single or double quotes, removing the quotes
+func splitGroupFields(spec string) ([]string, error) {
+	var fields []string
+	var field strings.Builder
+	var quote rune
+
+	for _, r := range spec {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			field.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ',':
+			fields = append(fields, field.String())
+			field.Reset()
+		default:
+			field.WriteRune(r)
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	return append(fields, field.String()), nil
+}
+
+// groupsUpdate sets the launch options of each group's games to that group's args,
+// resolving the groups' lists up front. A game picked by more than one group is an error
+func groupsUpdate(groups []updateGroup) (appValueUpdate, error) {
+	selected := make([]map[string]bool, len(groups))
+	for i, group := range groups {
+		var ids []string
+		switch {
+		case group.allowFile != "":
+			resolved, err := loadAndResolveFilterList(group.allowFile, "allow", nil, ignoreMissing)
+			if err != nil {
+				return appValueUpdate{}, err
+			}
+			ids = resolved
+		case group.denyFile != "":
+			resolved, err := loadAndResolveFilterList(group.denyFile, "deny", nil, ignoreMissing)
+			if err != nil {
+				return appValueUpdate{}, err
+			}
+			ids = resolved
+		default:
+			resolved, notFound := steam.ResolveGameIDs(expandAliases(group.appIDs), nil)
+			if len(notFound) > 0 {
+				return appValueUpdate{}, fmt.Errorf("--group apps= only accepts app IDs and aliases, got: %s", strings.Join(notFound, ", "))
+			}
+			ids = resolved
+		}
+
+		selected[i] = make(map[string]bool, len(ids))
+		for _, id := range ids {
+			selected[i][id] = true
+		}
+	}
+
+	update := launchOptionsUpdate("")
+	update.values = func(appIDs []string) ([]steam.AppValue, error) {
+		var values []steam.AppValue
+		counts := make([]int, len(groups))
+		for _, appID := range appIDs {
+			match := -1
+			for i, group := range groups {
+				// A deny group picks every game not in its list
+				if selected[i][appID] == (group.denyFile == "") {
+					if match >= 0 {
+						return nil, fmt.Errorf("app %s is in more than one group (%q and %q)", appID, groups[match].spec, group.spec)
+					}
+					match = i
+				}
+			}
+			if match >= 0 {
+				values = append(values, steam.AppValue{AppID: appID, Value: groups[match].args})
+				counts[match]++
+			}
+		}
+
+		for i, group := range groups {
+			fmt.Printf("Group %d: %d game(s), args: %s\n", i+1, counts[i], group.args)
+		}
+		return values, nil
+	}
+	return update, nil
+}
@@ -0,0 +1,251 @@
+// Package httpcache fetches external metadata (ProtonDB, Steam Web API, store pages)
+// through an on-disk cache, so repeated lookups don't hit the network and still work offline
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultTTL is how long responses are reused when a request doesn't set its own TTL
+const DefaultTTL = 24 * time.Hour
+
+// Request pacing defaults; external services are free and easy to get banned from
+const (
+	DefaultMinInterval = time.Second
+	DefaultMaxRetries  = 3
+	initialBackoff     = 2 * time.Second
+	maxBackoff         = time.Minute
+)
+
+// maxBodySize guards against unexpectedly large responses
+const maxBodySize = 32 << 20
+
+// ErrOffline is returned in offline mode for URLs that aren't cached
+var ErrOffline = errors.New("not cached and network access is disabled")
+
+// statusError is a non-200 response
+type statusError struct {
+	url    string
+	status int
+	text   string
+	// retryAfter is the server's requested delay, if any
+	retryAfter time.Duration
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("failed to fetch %s: %s", e.url, e.text)
+}
+
+// retryable reports whether the request may succeed if repeated later
+func (e *statusError) retryable() bool {
+	return e.status == http.StatusTooManyRequests || e.status >= 500
+}
+
+// Client fetches URLs, reusing cached responses younger than their TTL
+type Client struct {
+	// Dir holds one file per cached URL
+	Dir string
+	// Refresh ignores cached responses (they are still updated after fetching)
+	Refresh bool
+	// Offline never touches the network; cached responses are used regardless of age
+	Offline bool
+	// HTTP is the client used for requests
+	HTTP *http.Client
+
+	// MinInterval is the minimum time between requests
+	MinInterval time.Duration
+	// MaxRetries is how often rate-limited (429) and server error responses are retried,
+	// with exponential backoff or the delay the server asks for
+	MaxRetries int
+
+	mu          sync.Mutex
+	lastRequest time.Time
+	// sleep is replaced in tests
+	sleep func(time.Duration)
+}
+
+// DefaultDir returns the cache directory under the user's cache directory
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gsca", "http"), nil
+}
+
+// New creates a client caching in dir
+func New(dir string) *Client {
+	return &Client{
+		Dir:         dir,
+		HTTP:        &http.Client{Timeout: 30 * time.Second},
+		MinInterval: DefaultMinInterval,
+		MaxRetries:  DefaultMaxRetries,
+		sleep:       time.Sleep,
+	}
+}
+
+// NewDefault creates a client caching in DefaultDir
+func NewDefault(refresh, offline bool) (*Client, error) {
+	dir, err := DefaultDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+
+	c := New(dir)
+	c.Refresh = refresh
+	c.Offline = offline
+	return c, nil
+}
+
+// Get returns the body of url, from the cache if it was fetched less than ttl ago
+// (DefaultTTL if ttl is 0). If fetching fails, an expired cached copy is returned
+// instead so lookups keep working offline. In offline mode only the cache is used
+// and ErrOffline is returned for anything not in it
+func (c *Client) Get(url string, ttl time.Duration) ([]byte, error) {
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	path := c.path(url)
+
+	cached, fetchedAt, cacheErr := c.read(path)
+	if c.Offline {
+		if cacheErr != nil {
+			return nil, fmt.Errorf("%s: %w", url, ErrOffline)
+		}
+		return cached, nil
+	}
+	if cacheErr == nil && !c.Refresh && time.Since(fetchedAt) < ttl {
+		return cached, nil
+	}
+
+	body, err := c.fetch(url)
+	if err != nil {
+		if cacheErr == nil {
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	// Failing to cache shouldn't fail the lookup
+	_ = c.write(path, body)
+	return body, nil
+}
+
+// fetch requests url, retrying rate-limited and server error responses with backoff
+func (c *Client) fetch(url string) ([]byte, error) {
+	backoff := initialBackoff
+	for attempt := 0; ; attempt++ {
+		body, err := c.fetchOnce(url)
+
+		var statusErr *statusError
+		if err == nil || !errors.As(err, &statusErr) || !statusErr.retryable() || attempt >= c.MaxRetries {
+			return body, err
+		}
+
+		delay := backoff
+		if statusErr.retryAfter > 0 {
+			delay = statusErr.retryAfter
+		}
+		c.sleep(min(delay, maxBackoff))
+		backoff *= 2
+	}
+}
+
+// wait blocks until MinInterval has passed since the previous request
+func (c *Client) wait() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.lastRequest.IsZero() {
+		if remaining := c.MinInterval - time.Since(c.lastRequest); remaining > 0 {
+			c.sleep(remaining)
+		}
+	}
+	c.lastRequest = time.Now()
+}
+
+func (c *Client) fetchOnce(url string) ([]byte, error) {
+	c.wait()
+
+	resp, err := c.HTTP.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{
+			url:        url,
+			status:     resp.StatusCode,
+			text:       resp.Status,
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", url, err)
+	}
+	return body, nil
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds (HTTP dates are ignored)
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// path returns the cache file for url
+func (c *Client) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:]))
+}
+
+// read returns a cached body and when it was fetched (the file's modification time)
+func (c *Client) read(path string) ([]byte, time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return body, info.ModTime(), nil
+}
+
+// write stores body atomically so a concurrent run never reads a partial file
+func (c *Client) write(path string, body []byte) error {
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(c.Dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(body); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,185 @@
+package httpcache
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprintf(w, "response %d", requests)
+	}))
+
+	client := New(t.TempDir())
+	client.sleep = func(time.Duration) {}
+
+	tests := []struct {
+		name         string
+		setup        func()
+		wantBody     string
+		wantRequests int
+	}{
+		{name: "first fetch", wantBody: "response 1", wantRequests: 1},
+		{name: "cached", wantBody: "response 1", wantRequests: 1},
+		{
+			name:         "refresh",
+			setup:        func() { client.Refresh = true },
+			wantBody:     "response 2",
+			wantRequests: 2,
+		},
+		{
+			name: "expired",
+			setup: func() {
+				client.Refresh = false
+				old := time.Now().Add(-2 * DefaultTTL)
+				if err := os.Chtimes(client.path(server.URL), old, old); err != nil {
+					t.Fatalf("Failed to age cache entry: %v", err)
+				}
+			},
+			wantBody:     "response 3",
+			wantRequests: 3,
+		},
+		{
+			name: "offline falls back to expired copy",
+			setup: func() {
+				server.Close()
+				old := time.Now().Add(-2 * DefaultTTL)
+				if err := os.Chtimes(client.path(server.URL), old, old); err != nil {
+					t.Fatalf("Failed to age cache entry: %v", err)
+				}
+			},
+			wantBody:     "response 3",
+			wantRequests: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+
+			body, err := client.Get(server.URL, 0)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if string(body) != tt.wantBody || requests != tt.wantRequests {
+				t.Errorf("Get() = %q after %d requests, want %q after %d", body, requests, tt.wantBody, tt.wantRequests)
+			}
+		})
+	}
+
+	// Nothing cached and no network
+	if _, err := client.Get(server.URL+"/uncached", 0); err == nil {
+		t.Error("Get() of an uncached URL while offline error = nil, want error")
+	}
+}
+
+func TestGetOffline(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, "online")
+	}))
+	defer server.Close()
+
+	client := New(t.TempDir())
+	if _, err := client.Get(server.URL, 0); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	// Offline uses even expired and refreshed entries without touching the network
+	old := time.Now().Add(-2 * DefaultTTL)
+	if err := os.Chtimes(client.path(server.URL), old, old); err != nil {
+		t.Fatalf("Failed to age cache entry: %v", err)
+	}
+	client.Offline = true
+	client.Refresh = true
+
+	body, err := client.Get(server.URL, 0)
+	if err != nil || string(body) != "online" {
+		t.Errorf("Get() = %q, %v, want cached body", body, err)
+	}
+	if _, err := client.Get(server.URL+"/uncached", 0); !errors.Is(err, ErrOffline) {
+		t.Errorf("Get() of an uncached URL error = %v, want ErrOffline", err)
+	}
+	if requests != 1 {
+		t.Errorf("server saw %d requests, want 1", requests)
+	}
+}
+
+func TestGetRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   int
+		status     int
+		retryAfter string
+		wantErr    bool
+		wantSleeps []time.Duration
+	}{
+		{
+			name:       "backoff on 429",
+			failures:   2,
+			status:     http.StatusTooManyRequests,
+			wantSleeps: []time.Duration{2 * time.Second, 4 * time.Second},
+		},
+		{
+			name:       "honors Retry-After",
+			failures:   1,
+			status:     http.StatusServiceUnavailable,
+			retryAfter: "7",
+			wantSleeps: []time.Duration{7 * time.Second},
+		},
+		{
+			name:       "gives up after MaxRetries",
+			failures:   10,
+			status:     http.StatusInternalServerError,
+			wantErr:    true,
+			wantSleeps: []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second},
+		},
+		{
+			name:     "no retry on 404",
+			failures: 10,
+			status:   http.StatusNotFound,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests <= tt.failures {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(tt.status)
+					return
+				}
+				fmt.Fprint(w, "ok")
+			}))
+			defer server.Close()
+
+			var sleeps []time.Duration
+			client := New(t.TempDir())
+			client.MinInterval = 0
+			client.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+			_, err := client.Get(server.URL, 0)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if fmt.Sprint(sleeps) != fmt.Sprint(tt.wantSleeps) {
+				t.Errorf("slept %v, want %v", sleeps, tt.wantSleeps)
+			}
+		})
+	}
+}
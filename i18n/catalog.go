@@ -0,0 +1,219 @@
+package i18n
+
+// Message keys
+const (
+	SteamCheckFailed      Key = "steam_check_failed"
+	SteamForceClosing     Key = "steam_force_closing"
+	SteamRunning          Key = "steam_running"
+	SteamOverwritesConfig Key = "steam_overwrites_config"
+	SteamMustCloseRestore Key = "steam_must_close_restore"
+	PromptCloseAndApply   Key = "prompt_close_and_apply"
+	PromptCloseAndRestore Key = "prompt_close_and_restore"
+	ErrAbortedApply       Key = "err_aborted_apply"
+	ErrAbortedRestore     Key = "err_aborted_restore"
+	ClosingSteam          Key = "closing_steam"
+	WaitingForSteam       Key = "waiting_for_steam"
+	WaitDone              Key = "wait_done"
+	ErrSteamStillRunning  Key = "err_steam_still_running"
+	RestartingSteam       Key = "restarting_steam"
+	SteamStartFailed      Key = "steam_start_failed"
+	StartSteamManually    Key = "start_steam_manually"
+	SteamStarted          Key = "steam_started"
+	UpdatingLaunchOptions Key = "updating_launch_options"
+	UpdatingAppValue      Key = "updating_app_value"
+	UpdateSuccess         Key = "update_success"
+	BackupCreated         Key = "backup_created"
+	SelectGamesHeader     Key = "select_games_header"
+	SelectHintNumbers     Key = "select_hint_numbers"
+	SelectHintAll         Key = "select_hint_all"
+	SelectHintSkip        Key = "select_hint_skip"
+	SelectHintPages       Key = "select_hint_pages"
+	PromptSelection       Key = "prompt_selection"
+	NoGamesSelected       Key = "no_games_selected"
+	NoBackups             Key = "no_backups"
+	SelectBackupHeader    Key = "select_backup_header"
+	SelectBackupCancel    Key = "select_backup_cancel"
+	Cancelled             Key = "cancelled"
+	RestoringBackup       Key = "restoring_backup"
+	RestoreSuccess        Key = "restore_success"
+	PromptSaveOrUpdate    Key = "prompt_save_or_update"
+	PromptLaunchOptions   Key = "prompt_launch_options"
+	PromptApplyChanges    Key = "prompt_apply_changes"
+	PromptReviewChange    Key = "prompt_review_change"
+	PromptReviewEdit      Key = "prompt_review_edit"
+)
+
+var catalog = map[string]map[Key]string{
+	English: {
+		SteamCheckFailed:      "Warning: Could not check if Steam is running: %v",
+		SteamForceClosing:     "WARNING: Steam is running - closing automatically (--force flag)",
+		SteamRunning:          "WARNING: Steam is currently running!",
+		SteamOverwritesConfig: "Steam overwrites localconfig.vdf when it closes, which will undo your changes.",
+		SteamMustCloseRestore: "Steam must be closed before restoring a backup.",
+		PromptCloseAndApply:   "Close Steam and apply changes? (Y/n): ",
+		PromptCloseAndRestore: "Close Steam and restore? (Y/n): ",
+		ErrAbortedApply:       "aborted - Steam must be closed to apply changes safely",
+		ErrAbortedRestore:     "aborted - Steam must be closed to restore backup",
+		ClosingSteam:          "Closing Steam...",
+		WaitingForSteam:       "Waiting for Steam to close",
+		WaitDone:              " done!",
+		ErrSteamStillRunning:  "Steam is still running after close attempt - please close it manually",
+		RestartingSteam:       "Restarting Steam...",
+		SteamStartFailed:      "Warning: Failed to start Steam: %v",
+		StartSteamManually:    "Please start Steam manually.",
+		SteamStarted:          "Steam started successfully!",
+		UpdatingLaunchOptions: "Updating launch options...",
+		UpdatingAppValue:      "Updating %s...",
+		UpdateSuccess:         "Successfully updated %d games!",
+		BackupCreated:         "Backup created at: %s",
+		SelectGamesHeader:     "Select games to export to file:",
+		SelectHintNumbers:     "Enter numbers (e.g., 1,3,5 or 1-3); ^ excludes (e.g., *,^2 or 1-10,^5-7)",
+		SelectHintAll:         "Enter * to select every match, including ones on other pages",
+		SelectHintPages:       "Enter n or p for the next or previous page (showing %d-%d of %d)",
+		SelectHintSkip:        "Press Enter to skip",
+		PromptSelection:       "Selection: ",
+		NoGamesSelected:       "No games selected. Exiting.",
+		NoBackups:             "No backups found.",
+		SelectBackupHeader:    "Enter the number of the backup to restore",
+		SelectBackupCancel:    "Press Enter to cancel",
+		Cancelled:             "Cancelled.",
+		RestoringBackup:       "Restoring %s...",
+		RestoreSuccess:        "Backup restored successfully!",
+		PromptSaveOrUpdate:    "Save as an [a]llow list or a [d]eny list, or [u]pdate these games now? (default: allow): ",
+		PromptLaunchOptions:   "Launch options (or @name for an arg alias): ",
+		PromptApplyChanges:    "Apply these changes? (Y/n): ",
+		PromptReviewChange:    "Apply? [y]es/[n]o/[e]dit/[q]uit: ",
+		PromptReviewEdit:      "New value (empty keeps the proposed one): ",
+	},
+	German: {
+		SteamCheckFailed:      "Warnung: Konnte nicht prüfen, ob Steam läuft: %v",
+		SteamForceClosing:     "WARNUNG: Steam läuft - wird automatisch beendet (--force)",
+		SteamRunning:          "WARNUNG: Steam läuft gerade!",
+		SteamOverwritesConfig: "Steam überschreibt localconfig.vdf beim Beenden, wodurch deine Änderungen verloren gehen.",
+		SteamMustCloseRestore: "Steam muss beendet sein, bevor ein Backup wiederhergestellt wird.",
+		PromptCloseAndApply:   "Steam beenden und Änderungen anwenden? (J/n): ",
+		PromptCloseAndRestore: "Steam beenden und wiederherstellen? (J/n): ",
+		ErrAbortedApply:       "abgebrochen - Steam muss beendet sein, um Änderungen sicher anzuwenden",
+		ErrAbortedRestore:     "abgebrochen - Steam muss beendet sein, um das Backup wiederherzustellen",
+		ClosingSteam:          "Steam wird beendet...",
+		WaitingForSteam:       "Warte auf das Beenden von Steam",
+		WaitDone:              " fertig!",
+		ErrSteamStillRunning:  "Steam läuft nach dem Beenden-Versuch noch - bitte manuell beenden",
+		RestartingSteam:       "Steam wird neu gestartet...",
+		SteamStartFailed:      "Warnung: Steam konnte nicht gestartet werden: %v",
+		StartSteamManually:    "Bitte starte Steam manuell.",
+		SteamStarted:          "Steam erfolgreich gestartet!",
+		UpdatingLaunchOptions: "Startoptionen werden aktualisiert...",
+		UpdatingAppValue:      "%s wird aktualisiert...",
+		UpdateSuccess:         "%d Spiele erfolgreich aktualisiert!",
+		BackupCreated:         "Backup erstellt unter: %s",
+		SelectGamesHeader:     "Spiele zum Speichern in eine Datei auswählen:",
+		SelectHintNumbers:     "Nummern eingeben (z. B. 1,3,5 oder 1-3); ^ schließt aus (z. B. *,^2 oder 1-10,^5-7)",
+		SelectHintAll:         "* eingeben, um alle Treffer auszuwählen, auch die auf anderen Seiten",
+		SelectHintPages:       "n oder p für die nächste oder vorherige Seite eingeben (%d-%d von %d)",
+		SelectHintSkip:        "Enter drücken zum Überspringen",
+		PromptSelection:       "Auswahl: ",
+		NoGamesSelected:       "Keine Spiele ausgewählt. Beende.",
+		NoBackups:             "Keine Backups gefunden.",
+		SelectBackupHeader:    "Nummer des wiederherzustellenden Backups eingeben",
+		SelectBackupCancel:    "Enter drücken zum Abbrechen",
+		Cancelled:             "Abgebrochen.",
+		RestoringBackup:       "%s wird wiederhergestellt...",
+		RestoreSuccess:        "Backup erfolgreich wiederhergestellt!",
+		PromptSaveOrUpdate:    "Als [a]llow-Liste oder [d]eny-Liste speichern, oder diese Spiele jetzt aktualisieren ([u]pdate)? (Standard: allow): ",
+		PromptLaunchOptions:   "Startoptionen (oder @name für einen Argument-Alias): ",
+		PromptApplyChanges:    "Diese Änderungen anwenden? (J/n): ",
+		PromptReviewChange:    "Anwenden? [y] ja/[n] nein/[e] bearbeiten/[q] beenden: ",
+		PromptReviewEdit:      "Neuer Wert (leer behält den vorgeschlagenen): ",
+	},
+	Portuguese: {
+		SteamCheckFailed:      "Aviso: Não foi possível verificar se o Steam está em execução: %v",
+		SteamForceClosing:     "AVISO: O Steam está em execução - fechando automaticamente (--force)",
+		SteamRunning:          "AVISO: O Steam está em execução!",
+		SteamOverwritesConfig: "O Steam sobrescreve o localconfig.vdf ao fechar, o que desfaria suas alterações.",
+		SteamMustCloseRestore: "O Steam precisa estar fechado antes de restaurar um backup.",
+		PromptCloseAndApply:   "Fechar o Steam e aplicar as alterações? (S/n): ",
+		PromptCloseAndRestore: "Fechar o Steam e restaurar? (S/n): ",
+		ErrAbortedApply:       "abortado - o Steam precisa estar fechado para aplicar as alterações com segurança",
+		ErrAbortedRestore:     "abortado - o Steam precisa estar fechado para restaurar o backup",
+		ClosingSteam:          "Fechando o Steam...",
+		WaitingForSteam:       "Aguardando o Steam fechar",
+		WaitDone:              " pronto!",
+		ErrSteamStillRunning:  "O Steam ainda está em execução após a tentativa de fechar - feche-o manualmente",
+		RestartingSteam:       "Reiniciando o Steam...",
+		SteamStartFailed:      "Aviso: Falha ao iniciar o Steam: %v",
+		StartSteamManually:    "Inicie o Steam manualmente.",
+		SteamStarted:          "Steam iniciado com sucesso!",
+		UpdatingLaunchOptions: "Atualizando opções de inicialização...",
+		UpdatingAppValue:      "Atualizando %s...",
+		UpdateSuccess:         "%d jogos atualizados com sucesso!",
+		BackupCreated:         "Backup criado em: %s",
+		SelectGamesHeader:     "Selecione os jogos para exportar para um arquivo:",
+		SelectHintNumbers:     "Digite números (ex.: 1,3,5 ou 1-3); ^ exclui (ex.: *,^2 ou 1-10,^5-7)",
+		SelectHintAll:         "Digite * para selecionar todos os resultados, inclusive os de outras páginas",
+		SelectHintPages:       "Digite n ou p para a próxima página ou a anterior (mostrando %d-%d de %d)",
+		SelectHintSkip:        "Pressione Enter para pular",
+		PromptSelection:       "Seleção: ",
+		NoGamesSelected:       "Nenhum jogo selecionado. Saindo.",
+		NoBackups:             "Nenhum backup encontrado.",
+		SelectBackupHeader:    "Digite o número do backup a restaurar",
+		SelectBackupCancel:    "Pressione Enter para cancelar",
+		Cancelled:             "Cancelado.",
+		RestoringBackup:       "Restaurando %s...",
+		RestoreSuccess:        "Backup restaurado com sucesso!",
+		PromptSaveOrUpdate:    "Salvar como lista [a]llow ou lista [d]eny, ou atualizar estes jogos agora ([u]pdate)? (padrão: allow): ",
+		PromptLaunchOptions:   "Opções de inicialização (ou @nome para um alias de argumentos): ",
+		PromptApplyChanges:    "Aplicar estas alterações? (S/n): ",
+		PromptReviewChange:    "Aplicar? [y] sim/[n] não/[e] editar/[q] sair: ",
+		PromptReviewEdit:      "Novo valor (vazio mantém o proposto): ",
+	},
+	Chinese: {
+		SteamCheckFailed:      "警告：无法检查 Steam 是否正在运行：%v",
+		SteamForceClosing:     "警告：Steam 正在运行 - 将自动关闭（--force）",
+		SteamRunning:          "警告：Steam 正在运行！",
+		SteamOverwritesConfig: "Steam 关闭时会覆盖 localconfig.vdf，从而撤销你的更改。",
+		SteamMustCloseRestore: "恢复备份前必须关闭 Steam。",
+		PromptCloseAndApply:   "关闭 Steam 并应用更改？(Y/n)：",
+		PromptCloseAndRestore: "关闭 Steam 并恢复？(Y/n)：",
+		ErrAbortedApply:       "已中止 - 必须关闭 Steam 才能安全地应用更改",
+		ErrAbortedRestore:     "已中止 - 必须关闭 Steam 才能恢复备份",
+		ClosingSteam:          "正在关闭 Steam...",
+		WaitingForSteam:       "正在等待 Steam 关闭",
+		WaitDone:              " 完成！",
+		ErrSteamStillRunning:  "尝试关闭后 Steam 仍在运行 - 请手动关闭",
+		RestartingSteam:       "正在重新启动 Steam...",
+		SteamStartFailed:      "警告：启动 Steam 失败：%v",
+		StartSteamManually:    "请手动启动 Steam。",
+		SteamStarted:          "Steam 已成功启动！",
+		UpdatingLaunchOptions: "正在更新启动选项...",
+		UpdatingAppValue:      "正在更新 %s...",
+		UpdateSuccess:         "已成功更新 %d 个游戏！",
+		BackupCreated:         "备份已创建：%s",
+		SelectGamesHeader:     "选择要导出到文件的游戏：",
+		SelectHintNumbers:     "输入编号（例如 1,3,5 或 1-3）；^ 表示排除（例如 *,^2 或 1-10,^5-7）",
+		SelectHintAll:         "输入 * 选择所有匹配项（包括其他页上的）",
+		SelectHintPages:       "输入 n 或 p 翻到下一页或上一页（显示第 %d-%d 项，共 %d 项）",
+		SelectHintSkip:        "按 Enter 跳过",
+		PromptSelection:       "选择：",
+		NoGamesSelected:       "未选择任何游戏。退出。",
+		NoBackups:             "未找到备份。",
+		SelectBackupHeader:    "输入要恢复的备份编号",
+		SelectBackupCancel:    "按 Enter 取消",
+		Cancelled:             "已取消。",
+		RestoringBackup:       "正在恢复 %s...",
+		RestoreSuccess:        "备份已成功恢复！",
+		PromptSaveOrUpdate:    "保存为允许列表 [a]、拒绝列表 [d]，或立即更新这些游戏 [u]？（默认：允许）：",
+		PromptLaunchOptions:   "启动选项（或使用 @名称 引用参数别名）：",
+		PromptApplyChanges:    "应用这些更改？(Y/n)：",
+		PromptReviewChange:    "应用？[y] 是/[n] 否/[e] 编辑/[q] 退出：",
+		PromptReviewEdit:      "新值（留空则保留建议值）：",
+	},
+}
+
+// yesWords are the affirmative answers accepted by IsYes per locale
+var yesWords = map[string][]string{
+	English:    {"y", "yes"},
+	German:     {"j", "ja"},
+	Portuguese: {"s", "sim"},
+	Chinese:    {"是", "好"},
+}
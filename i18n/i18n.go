@@ -0,0 +1,94 @@
+// Package i18n provides translated user-facing messages
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Supported locales
+const (
+	English    = "en"
+	German     = "de"
+	Portuguese = "pt"
+	Chinese    = "zh-CN"
+)
+
+// Key identifies a message in the catalog
+type Key string
+
+var locale = Detect()
+
+// Detect returns the supported locale matching the environment, defaulting to English
+// GSCA_LANG takes precedence over the usual LC_ALL, LC_MESSAGES, and LANG variables
+func Detect() string {
+	for _, env := range []string{"GSCA_LANG", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(env); value != "" {
+			return Normalize(value)
+		}
+	}
+	return English
+}
+
+// Normalize maps a POSIX locale string (e.g. "pt_BR.UTF-8") to a supported locale
+// Unsupported locales fall back to English
+func Normalize(value string) string {
+	value = strings.ToLower(value)
+	if i := strings.IndexAny(value, ".@"); i >= 0 {
+		value = value[:i]
+	}
+	value = strings.ReplaceAll(value, "-", "_")
+
+	lang, region, _ := strings.Cut(value, "_")
+	switch lang {
+	case "de":
+		return German
+	case "pt":
+		return Portuguese
+	case "zh":
+		// Traditional Chinese regions aren't covered by the Simplified catalog
+		if region == "tw" || region == "hk" || region == "mo" || region == "hant" {
+			return English
+		}
+		return Chinese
+	}
+
+	return English
+}
+
+// Locale returns the active locale
+func Locale() string {
+	return locale
+}
+
+// SetLocale overrides the detected locale
+func SetLocale(l string) {
+	locale = Normalize(l)
+}
+
+// T returns the message for key in the active locale, formatted with args
+// Messages missing from a locale fall back to English
+func T(key Key, args ...any) string {
+	msg, ok := catalog[locale][key]
+	if !ok {
+		msg = catalog[English][key]
+	}
+
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// IsYes reports whether a prompt response is an affirmative answer
+// English answers are always accepted alongside the active locale's own
+func IsYes(response string) bool {
+	response = strings.ToLower(strings.TrimSpace(response))
+	for _, word := range append(yesWords[English], yesWords[locale]...) {
+		if response == word {
+			return true
+		}
+	}
+	return false
+}
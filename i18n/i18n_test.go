@@ -0,0 +1,92 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"en_US.UTF-8", English},
+		{"de_DE.UTF-8", German},
+		{"de_AT@euro", German},
+		{"pt_BR.UTF-8", Portuguese},
+		{"pt-PT", Portuguese},
+		{"zh_CN.UTF-8", Chinese},
+		{"zh_SG", Chinese},
+		{"zh_TW.UTF-8", English},
+		{"fr_FR.UTF-8", English},
+		{"C", English},
+		{"POSIX", English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := Normalize(tt.input); got != tt.want {
+				t.Errorf("Normalize(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCatalogPlaceholders(t *testing.T) {
+	for key, english := range catalog[English] {
+		for locale, messages := range catalog {
+			msg, ok := messages[key]
+			if !ok {
+				continue // Falls back to English
+			}
+			if strings.Count(msg, "%") != strings.Count(english, "%") {
+				t.Errorf("%s message %q has different placeholders than English %q", locale, msg, english)
+			}
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	defer SetLocale(Locale())
+
+	SetLocale("de_DE.UTF-8")
+	if got := T(UpdateSuccess, 3); got != "3 Spiele erfolgreich aktualisiert!" {
+		t.Errorf("T() = %q", got)
+	}
+
+	// Keys missing from a locale fall back to English
+	delete(catalog[German], Cancelled)
+	defer func() { catalog[German][Cancelled] = "Abgebrochen." }()
+	if got := T(Cancelled); got != "Cancelled." {
+		t.Errorf("T() fallback = %q, want English", got)
+	}
+}
+
+func TestIsYes(t *testing.T) {
+	defer SetLocale(Locale())
+
+	tests := []struct {
+		locale   string
+		response string
+		want     bool
+	}{
+		{English, "y", true},
+		{English, " YES ", true},
+		{English, "n", false},
+		{English, "ja", false},
+		{German, "ja", true},
+		{German, "y", true},
+		{Portuguese, "sim", true},
+		{Chinese, "是", true},
+		{Chinese, "否", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale+"/"+tt.response, func(t *testing.T) {
+			SetLocale(tt.locale)
+			if got := IsYes(tt.response); got != tt.want {
+				t.Errorf("IsYes(%q) = %v, want %v", tt.response, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/journal"
+	"github.com/zerkz/gsca/output"
+	"github.com/zerkz/gsca/steam"
+)
+
+var undoTxID string
+
+var journalCmd = &cobra.Command{
+	Use:   "journal",
+	Short: "Inspect the changes made by past runs",
+	Long: `Every run that changes localconfig.vdf is recorded in the journal as a transaction,
+with each game's value before and after. Use 'gsca undo --tx <id>' to revert one.`,
+}
+
+var journalListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recorded transactions",
+	Args:  cobra.NoArgs,
+	RunE:  runJournalList,
+}
+
+var journalShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show the changes in a transaction",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runJournalShow,
+}
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Revert the changes of a past transaction",
+	Long: `Revert a transaction from the journal (the most recent one unless --tx is given).
+
+Only games whose value is still what the transaction set are reverted; games changed
+again since are left alone, so older transactions can be undone independently.
+The undo is recorded as a transaction of its own.`,
+	Args: cobra.NoArgs,
+	RunE: runUndo,
+}
+
+func init() {
+	undoCmd.Flags().StringVar(&undoTxID, "tx", "", "Transaction ID to revert (default: the most recent)")
+	undoCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be reverted without modifying files")
+	undoCmd.Flags().BoolVarP(&autoCloseSteam, "force", "f", false, "Automatically close Steam if running (no prompt)")
+	undoCmd.Flags().BoolVar(&noBackup, "no-backup", false, "Skip creating backup file")
+	addRestartFlags(undoCmd)
+
+	addFormatFlag(journalListCmd)
+	addFormatFlag(journalShowCmd)
+	journalCmd.AddCommand(journalListCmd)
+	journalCmd.AddCommand(journalShowCmd)
+	rootCmd.AddCommand(journalCmd)
+	rootCmd.AddCommand(undoCmd)
+}
+
+// journalStore returns the journal in the user's config directory
+func journalStore() (journal.Store, error) {
+	dir, err := journal.DefaultDir()
+	if err != nil {
+		return journal.Store{}, fmt.Errorf("failed to locate journal directory: %w", err)
+	}
+	return journal.Store{Dir: dir}, nil
+}
+
+// recordTransaction adds the changed apps in result to the journal, labeled with names
+// (app ID to game name, may be incomplete), and prints the transaction ID
+// Failing to record only warns, since the changes are already written. Returns the
+// transaction ID, or "" if nothing was recorded
+func recordTransaction(key, localConfigPath string, result *steam.UpdateResult, names map[string]string, undoOf string) string {
+	tx := &journal.Transaction{
+		Command:         strings.Join(os.Args, " "),
+		Key:             key,
+		LocalConfigPath: localConfigPath,
+		BackupPath:      result.BackupPath,
+		UndoOf:          undoOf,
+	}
+	if expiresAfter > 0 && undoOf == "" {
+		expiresAt := time.Now().Add(expiresAfter).UTC()
+		tx.ExpiresAt = &expiresAt
+	}
+	for _, app := range result.Apps {
+		if app.Status != steam.UpdateChanged {
+			continue
+		}
+		tx.Changes = append(tx.Changes, journal.Change{
+			AppID:    app.AppID,
+			Name:     names[app.AppID],
+			OldValue: app.PreviousValue,
+			NewValue: app.NewValue,
+		})
+	}
+	if len(tx.Changes) == 0 {
+		return ""
+	}
+
+	store, err := journalStore()
+	if err == nil {
+		err = store.Record(tx)
+	}
+	if err != nil {
+		fmt.Printf("Warning: Failed to record the changes in the journal: %v\n", err)
+		return ""
+	}
+	fmt.Printf("Transaction: %s (revert with 'gsca undo --tx %s')\n", tx.ID, tx.ID)
+	if tx.ExpiresAt != nil {
+		fmt.Printf("Expires %s: 'gsca expire' reverts it when run after that (e.g. from cron, or keep 'gsca expire --watch' running)\n",
+			tx.ExpiresAt.Local().Format("2006-01-02 15:04"))
+	}
+	return tx.ID
+}
+
+// libraryNames maps the app IDs in library to game names
+func libraryNames(library *steam.Library) map[string]string {
+	names := make(map[string]string)
+	for _, game := range library.All() {
+		names[game.AppID] = game.Name
+	}
+	return names
+}
+
+// libraryNamesOrEmpty maps the app IDs of the games installed at steamPath to their names.
+// Names are only for display, so a library that can't be read gives no names rather than
+// an error
+func libraryNamesOrEmpty() map[string]string {
+	library, err := steam.LoadLibrary(steamPath, nil)
+	if err != nil {
+		return make(map[string]string)
+	}
+	return libraryNames(library)
+}
+
+func runJournalList(cmd *cobra.Command, args []string) error {
+	restoreStdout, err := startFormat()
+	if err != nil {
+		return err
+	}
+	defer restoreStdout()
+
+	store, err := journalStore()
+	if err != nil {
+		return err
+	}
+	txs, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	if outputFormat != "" {
+		table := output.Table{Columns: []string{"id", "time", "key", "games", "undo_of", "expires_at", "command"}}
+		for _, tx := range txs {
+			table.Add(tx.ID, tx.Time.Format(time.RFC3339), tx.Key, len(tx.Changes), optional(tx.UndoOf), optionalTime(tx.ExpiresAt), tx.Command)
+		}
+		return writeFormat(table)
+	}
+
+	if len(txs) == 0 {
+		fmt.Println("No transactions recorded yet.")
+		return nil
+	}
+
+	for _, tx := range txs {
+		note := ""
+		if tx.UndoOf != "" {
+			note = fmt.Sprintf(" (undo of %s)", tx.UndoOf)
+		} else if tx.ExpiresAt != nil {
+			note = fmt.Sprintf(" (expires %s)", tx.ExpiresAt.Local().Format("2006-01-02 15:04"))
+		}
+		fmt.Printf("%-18s %s  %-14s %3d game(s)%s\n",
+			tx.ID, tx.Time.Local().Format("2006-01-02 15:04"), tx.Key, len(tx.Changes), note)
+	}
+	return nil
+}
+
+func runJournalShow(cmd *cobra.Command, args []string) error {
+	restoreStdout, err := startFormat()
+	if err != nil {
+		return err
+	}
+	defer restoreStdout()
+
+	store, err := journalStore()
+	if err != nil {
+		return err
+	}
+	tx, err := store.Load(args[0])
+	if err != nil {
+		return err
+	}
+
+	// One row per change, each with the transaction it belongs to
+	if outputFormat != "" {
+		table := output.Table{Columns: []string{"id", "time", "key", "app_id", "name", "old_value", "new_value"}}
+		for _, change := range tx.Changes {
+			table.Add(tx.ID, tx.Time.Format(time.RFC3339), tx.Key, change.AppID, change.Name, change.OldValue, change.NewValue)
+		}
+		return writeFormat(table)
+	}
+
+	fmt.Printf("Transaction: %s\n", tx.ID)
+	fmt.Printf("Time:        %s\n", tx.Time.Local().Format("2006-01-02 15:04:05"))
+	if tx.Command != "" {
+		fmt.Printf("Command:     %s\n", tx.Command)
+	}
+	if tx.UndoOf != "" {
+		fmt.Printf("Undo of:     %s\n", tx.UndoOf)
+	}
+	if tx.ExpiresAt != nil {
+		fmt.Printf("Expires:     %s\n", tx.ExpiresAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	fmt.Printf("Config:      %s\n", tx.LocalConfigPath)
+	if tx.BackupPath != "" {
+		fmt.Printf("Backup:      %s\n", tx.BackupPath)
+	}
+	fmt.Printf("Key:         %s\n\n", tx.Key)
+
+	for _, change := range tx.Changes {
+		name := change.AppID
+		if change.Name != "" {
+			name = fmt.Sprintf("%s (%s)", change.Name, change.AppID)
+		}
+		fmt.Printf("  %s\n    %q -> %q\n", name, change.OldValue, change.NewValue)
+	}
+	return nil
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	store, err := journalStore()
+	if err != nil {
+		return err
+	}
+
+	var tx *journal.Transaction
+	if undoTxID != "" {
+		tx, err = store.Load(undoTxID)
+	} else if tx, err = store.Latest(); err == nil && tx == nil {
+		return fmt.Errorf("no transactions recorded yet")
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = revertTransaction(tx, "undo")
+	return err
+}
+
+// revertTransaction restores the values a transaction replaced, for games still set to what
+// it wrote, and records the revert as a transaction of its own. event names the run for
+// webhooks. Returns how many games there were to revert
+func revertTransaction(tx *journal.Transaction, event string) (int, error) {
+	localConfigPath, err := localConfigFor(tx.LocalConfigPath)
+	if err != nil {
+		return 0, err
+	}
+	current, err := steam.GetAppValues(localConfigPath, tx.Key)
+	if err != nil {
+		return 0, err
+	}
+
+	// Only revert games that still have the value the transaction set
+	var values []steam.AppValue
+	names := make(map[string]string)
+	for _, change := range tx.Changes {
+		names[change.AppID] = change.Name
+		if current[change.AppID] != change.NewValue {
+			fmt.Printf("Skipping %s: changed again since transaction %s\n", change.AppID, tx.ID)
+			continue
+		}
+		values = append(values, steam.AppValue{AppID: change.AppID, Value: change.OldValue})
+	}
+
+	fmt.Printf("Will revert %s for %d of %d games from transaction %s\n", tx.Key, len(values), len(tx.Changes), tx.ID)
+	if len(values) == 0 {
+		return 0, nil
+	}
+	if dryRun {
+		fmt.Println("\n[DRY RUN] Would restore the following values:")
+		for _, v := range values {
+			fmt.Printf("  - %s: %q\n", v.AppID, v.Value)
+		}
+		return len(values), nil
+	}
+
+	shouldRestartSteam, err := closeSteamBeforeWrite()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := steam.SetAppValues(localConfigPath, tx.Key, values, steam.UpdateOptions{SkipBackup: noBackup})
+	if err != nil {
+		if shouldRestartSteam {
+			restartSteam()
+		}
+		return 0, fmt.Errorf("failed to revert transaction %s: %w", tx.ID, err)
+	}
+	printUpdateSummary(result)
+	txID := recordTransaction(tx.Key, localConfigPath, result, names, tx.ID)
+	notifyWebhook(event, tx.Key, result, txID, "reverted transaction "+tx.ID)
+
+	if shouldRestartSteam {
+		restartSteam()
+	}
+	return len(values), nil
+}
@@ -0,0 +1,202 @@
+// Package journal records the changes each gsca run makes as a transaction, so any
+// past batch can be inspected and reverted on its own
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/zerkz/gsca/jsonstore"
+)
+
+// idFormat names transactions after when they were recorded (UTC)
+const idFormat = "20060102-150405"
+
+// validID matches generated IDs, keeping lookups inside the journal directory
+var validID = regexp.MustCompile(`^\d{8}-\d{6}(-\d+)?$`)
+
+// Change is one app's value before and after a transaction
+type Change struct {
+	AppID    string `json:"app_id"`
+	Name     string `json:"name,omitempty"`
+	OldValue string `json:"old_value"`
+	NewValue string `json:"new_value"`
+}
+
+// Transaction is the set of changes one run made to a localconfig.vdf key
+type Transaction struct {
+	ID      string    `json:"id"`
+	Time    time.Time `json:"time"`
+	Command string    `json:"command,omitempty"`
+	// Key is the per-app localconfig key that was set (e.g. LaunchOptions)
+	Key             string   `json:"key"`
+	LocalConfigPath string   `json:"localconfig_path"`
+	BackupPath      string   `json:"backup_path,omitempty"`
+	Changes         []Change `json:"changes"`
+	// UndoOf is the ID of the transaction this one reverted
+	UndoOf string `json:"undo_of,omitempty"`
+	// ExpiresAt, if set, is when the transaction should be reverted ('gsca update --expires')
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Store keeps one JSON file per transaction in Dir
+type Store struct {
+	Dir string
+}
+
+// DefaultDir returns the journal directory under the user's config directory
+func DefaultDir() (string, error) {
+	return jsonstore.DefaultDir("journal")
+}
+
+// Record saves a transaction, assigning its ID and time
+func (s Store) Record(tx *Transaction) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	tx.Time = time.Now().UTC()
+	base := tx.Time.Format(idFormat)
+
+	// Runs within the same second get a numbered suffix
+	for n := 1; ; n++ {
+		tx.ID = base
+		if n > 1 {
+			tx.ID = fmt.Sprintf("%s-%d", base, n)
+		}
+
+		f, err := os.OpenFile(s.path(tx.ID), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to record transaction: %w", err)
+		}
+
+		content, err := json.MarshalIndent(tx, "", "  ")
+		if err == nil {
+			_, err = f.Write(append(content, '\n'))
+		}
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(s.path(tx.ID))
+			return fmt.Errorf("failed to record transaction: %w", err)
+		}
+		return nil
+	}
+}
+
+// Load reads a transaction by ID
+func (s Store) Load(id string) (*Transaction, error) {
+	if !validID.MatchString(id) {
+		return nil, fmt.Errorf("invalid transaction ID %q", id)
+	}
+
+	var tx Transaction
+	err := jsonstore.Dir(s.Dir).Load(id, &tx)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("transaction %s not found (see 'gsca journal list')", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transaction %s: %w", id, err)
+	}
+	return &tx, nil
+}
+
+// List returns all transactions, oldest first
+func (s Store) List() ([]*Transaction, error) {
+	names, err := jsonstore.Dir(s.Dir).Names()
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, id := range names {
+		if validID.MatchString(id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return idLess(ids[i], ids[j]) })
+
+	txs := make([]*Transaction, 0, len(ids))
+	for _, id := range ids {
+		tx, err := s.Load(id)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// Latest returns the most recent transaction, or nil if the journal is empty
+func (s Store) Latest() (*Transaction, error) {
+	txs, err := s.List()
+	if err != nil || len(txs) == 0 {
+		return nil, err
+	}
+	return txs[len(txs)-1], nil
+}
+
+// Applied is the value the most recent transaction set for an app
+type Applied struct {
+	Value string
+	TxID  string
+	Name  string
+}
+
+// LastApplied returns, per key and app ID, the value last set in localConfigPath by txs
+// (oldest first, as List returns them)
+func LastApplied(txs []*Transaction, localConfigPath string) map[string]map[string]Applied {
+	applied := make(map[string]map[string]Applied)
+	for _, tx := range txs {
+		if tx.LocalConfigPath != localConfigPath {
+			continue
+		}
+		if applied[tx.Key] == nil {
+			applied[tx.Key] = make(map[string]Applied)
+		}
+		for _, change := range tx.Changes {
+			applied[tx.Key][change.AppID] = Applied{Value: change.NewValue, TxID: tx.ID, Name: change.Name}
+		}
+	}
+	return applied
+}
+
+// Expired returns the transactions in txs whose expiry is at or before now and that no
+// other transaction in txs reverted yet
+func Expired(txs []*Transaction, now time.Time) []*Transaction {
+	undone := make(map[string]bool)
+	for _, tx := range txs {
+		if tx.UndoOf != "" {
+			undone[tx.UndoOf] = true
+		}
+	}
+
+	var expired []*Transaction
+	for _, tx := range txs {
+		if tx.ExpiresAt != nil && !tx.ExpiresAt.After(now) && !undone[tx.ID] {
+			expired = append(expired, tx)
+		}
+	}
+	return expired
+}
+
+// idLess orders IDs by time, then by suffix number
+func idLess(a, b string) bool {
+	if a[:len(idFormat)] != b[:len(idFormat)] {
+		return a < b
+	}
+	return len(a) < len(b) || (len(a) == len(b) && a < b)
+}
+
+func (s Store) path(id string) string {
+	return jsonstore.Dir(s.Dir).Path(id)
+}
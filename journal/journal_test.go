@@ -0,0 +1,108 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	store := Store{Dir: t.TempDir()}
+
+	if tx, err := store.Latest(); err != nil || tx != nil {
+		t.Fatalf("Latest() of an empty journal = %v, %v, want nil, nil", tx, err)
+	}
+
+	first := &Transaction{Key: "LaunchOptions", Changes: []Change{{AppID: "570", OldValue: "", NewValue: "-novid"}}}
+	second := &Transaction{Key: "LaunchOptions", Changes: []Change{{AppID: "570", OldValue: "-novid", NewValue: ""}}, UndoOf: "x"}
+	for _, tx := range []*Transaction{first, second} {
+		if err := store.Record(tx); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	if first.ID == "" || first.ID == second.ID {
+		t.Fatalf("Record() IDs = %q, %q, want distinct IDs", first.ID, second.ID)
+	}
+
+	got, err := store.Load(first.ID)
+	if err != nil || got.Changes[0].NewValue != "-novid" {
+		t.Errorf("Load(%q) = %+v, %v", first.ID, got, err)
+	}
+
+	latest, err := store.Latest()
+	if err != nil || latest.ID != second.ID {
+		t.Errorf("Latest() = %+v, %v, want %s", latest, err, second.ID)
+	}
+
+	// Files that are not transactions are ignored
+	if err := os.WriteFile(filepath.Join(store.Dir, "notes.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	txs, err := store.List()
+	if err != nil || len(txs) != 2 {
+		t.Errorf("List() = %d transactions, %v, want 2", len(txs), err)
+	}
+
+	for _, id := range []string{"../secret", "20260101", "missing"} {
+		if _, err := store.Load(id); err == nil {
+			t.Errorf("Load(%q) error = nil, want error", id)
+		}
+	}
+	if _, err := store.Load("20000101-000000"); err == nil {
+		t.Error("Load() of a missing transaction error = nil, want error")
+	}
+}
+
+func TestLastApplied(t *testing.T) {
+	txs := []*Transaction{
+		{ID: "1", Key: "LaunchOptions", LocalConfigPath: "a", Changes: []Change{{AppID: "570", NewValue: "-novid"}, {AppID: "730", NewValue: "-high"}}},
+		{ID: "2", Key: "LaunchOptions", LocalConfigPath: "b", Changes: []Change{{AppID: "570", NewValue: "other user"}}},
+		{ID: "3", Key: "LaunchOptions", LocalConfigPath: "a", Changes: []Change{{AppID: "570", NewValue: ""}}},
+		{ID: "4", Key: "OverlayAppEnable", LocalConfigPath: "a", Changes: []Change{{AppID: "570", NewValue: "0"}}},
+	}
+
+	applied := LastApplied(txs, "a")
+	want := map[string]map[string]Applied{
+		"LaunchOptions":    {"570": {Value: "", TxID: "3"}, "730": {Value: "-high", TxID: "1"}},
+		"OverlayAppEnable": {"570": {Value: "0", TxID: "4"}},
+	}
+	if !reflect.DeepEqual(applied, want) {
+		t.Errorf("LastApplied() = %+v, want %+v", applied, want)
+	}
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Date(2026, 1, 8, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	txs := []*Transaction{
+		{ID: "1", ExpiresAt: &past},
+		{ID: "2", ExpiresAt: &past},
+		{ID: "3", ExpiresAt: &future},
+		{ID: "4"},
+		{ID: "5", UndoOf: "2"},
+		{ID: "6", ExpiresAt: &now},
+	}
+
+	var ids []string
+	for _, tx := range Expired(txs, now) {
+		ids = append(ids, tx.ID)
+	}
+	if want := []string{"1", "6"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Expired() = %v, want %v", ids, want)
+	}
+}
+
+func TestIDLess(t *testing.T) {
+	ids := []string{"20260101-120000-10", "20260101-120001", "20260101-120000-2", "20260101-120000"}
+	sort.Slice(ids, func(i, j int) bool { return idLess(ids[i], ids[j]) })
+
+	want := []string{"20260101-120000", "20260101-120000-2", "20260101-120000-10", "20260101-120001"}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("sorted IDs = %v, want %v", ids, want)
+		}
+	}
+}
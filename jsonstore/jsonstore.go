@@ -0,0 +1,86 @@
+// Package jsonstore keeps named records as one JSON file each in a directory, as the
+// journal, presets and snapshots are stored
+package jsonstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// validName keeps names usable as file names and on the command line
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidName reports whether name can name a record: letters, digits, '.', '_' and '-',
+// starting with a letter or digit
+func ValidName(name string) bool {
+	return validName.MatchString(name)
+}
+
+// DefaultDir returns the directory called name under gsca's config directory
+func DefaultDir(name string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gsca", name), nil
+}
+
+// Dir is a directory holding a JSON file per record
+type Dir string
+
+// Path returns the file of the record called name
+func (d Dir) Path(name string) string {
+	return filepath.Join(string(d), name+".json")
+}
+
+// Save writes v as the record called name, creating the directory if needed. It reports
+// whether a record was replaced
+func (d Dir) Save(name string, v any) (bool, error) {
+	path := d.Path(name)
+	_, statErr := os.Stat(path)
+	replaced := statErr == nil
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return false, err
+	}
+	if err := os.MkdirAll(string(d), 0755); err != nil {
+		return false, fmt.Errorf("failed to create %s: %w", d, err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return false, err
+	}
+	return replaced, nil
+}
+
+// Load reads the record called name into v. A missing record is an error matching
+// os.ErrNotExist
+func (d Dir) Load(name string, v any) error {
+	data, err := os.ReadFile(d.Path(name))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return nil
+}
+
+// Names returns the names of the records, sorted
+func (d Dir) Names() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(string(d), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, strings.TrimSuffix(filepath.Base(file), ".json"))
+	}
+	sort.Strings(names)
+	return names, nil
+}
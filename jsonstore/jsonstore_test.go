@@ -0,0 +1,51 @@
+package jsonstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDir(t *testing.T) {
+	dir := Dir(filepath.Join(t.TempDir(), "records"))
+	type record struct {
+		Value string `json:"value"`
+	}
+
+	var got record
+	if err := dir.Load("missing", &got); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load() of a missing record error = %v, want os.ErrNotExist", err)
+	}
+	for i, name := range []string{"b", "a", "b"} {
+		replaced, err := dir.Save(name, record{Value: name})
+		if err != nil {
+			t.Fatalf("Save(%q) error = %v", name, err)
+		}
+		if want := i == 2; replaced != want {
+			t.Errorf("Save(%q) replaced = %v, want %v", name, replaced, want)
+		}
+	}
+	if err := dir.Load("a", &got); err != nil || got.Value != "a" {
+		t.Errorf("Load() = %+v, %v; want a", got, err)
+	}
+	if names, err := dir.Names(); err != nil || !reflect.DeepEqual(names, []string{"a", "b"}) {
+		t.Errorf("Names() = %v, %v; want [a b]", names, err)
+	}
+}
+
+func TestValidName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"before-update": true,
+		"v1.2_Deck":     true,
+		"":              false,
+		".hidden":       false,
+		"../escape":     false,
+		"with space":    false,
+	} {
+		if got := ValidName(name); got != want {
+			t.Errorf("ValidName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/steam"
+)
+
+var languageCmd = &cobra.Command{
+	Use:   "language",
+	Short: "Manage per-game language overrides",
+}
+
+var languageSetCmd = &cobra.Command{
+	Use:   "set <language>",
+	Short: "Set the language selected games run in",
+	Long: `Set the language Steam downloads and launches installed games in, overriding the client
+language, like the Language option in each game's Steam properties. Accepts language codes
+(ja, pt-BR, zh-CN) or Steam language names (japanese, brazilian, schinese).
+Steam downloads the language files the next time it starts.
+
+Games are selected with --all, --allow, --deny, or --apps, exactly like 'gsca update'.
+
+Example:
+  gsca language set ja --apps 1245620`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLanguageSet,
+}
+
+func init() {
+	addSelectionFlags(languageSetCmd)
+
+	languageCmd.AddCommand(languageSetCmd)
+	rootCmd.AddCommand(languageCmd)
+}
+
+func runLanguageSet(cmd *cobra.Command, args []string) error {
+	language, ok := steam.SteamLanguage(args[0])
+	if !ok {
+		return fmt.Errorf("unknown language %q (supported: %s)", args[0], strings.Join(steam.SteamLanguages(), ", "))
+	}
+
+	return applyManifestValue("the game language", "Language", language, func(appIDs []string) (*steam.UpdateResult, error) {
+		return steam.SetGameLanguage(steamPath, appIDs, language, steam.UpdateOptions{SkipBackup: noBackup})
+	})
+}
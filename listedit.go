@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// commentedOutPrefix marks the entries c comments out, so they aren't taken for the comment
+// of the entry below
+const commentedOutPrefix = "#- "
+
+// listEditor edits the lines of a list file. Entries are the lines that aren't blank or
+// comments. A comment directly above an entry, like the game names merge-lists --annotate
+// and the wizard write, goes with it when it's removed or moved; other blank lines and
+// comments, including commented-out entries, are kept where they are
+type listEditor struct {
+	lines []string
+	dirty bool
+}
+
+// entries returns the line numbers of the entries
+func (e *listEditor) entries() []int {
+	var entries []int
+	for i, line := range e.lines {
+		if trimmed := strings.TrimSpace(line); trimmed != "" && !isListComment(trimmed) {
+			entries = append(entries, i)
+		}
+	}
+	return entries
+}
+
+// span returns the lines that belong to the entry on line: the entry and the comment
+// directly above it, unless that comment is a commented-out entry
+func (e *listEditor) span(line int) []int {
+	if line > 0 && isAnnotation(strings.TrimSpace(e.lines[line-1])) {
+		return []int{line - 1, line}
+	}
+	return []int{line}
+}
+
+func isListComment(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "#")
+}
+
+// isAnnotation reports whether a comment describes the entry below it rather than being an
+// entry commented out, by c or by hand as an app ID
+func isAnnotation(trimmed string) bool {
+	if !isListComment(trimmed) || strings.HasPrefix(trimmed, strings.TrimSpace(commentedOutPrefix)) {
+		return false
+	}
+	return !isAppID(strings.TrimSpace(strings.TrimPrefix(trimmed, "#")))
+}
+
+// selectedLines returns the line numbers of the entries picked by a selection ("1,3-5")
+func (e *listEditor) selectedLines(selection string) []int {
+	entries := e.entries()
+	var lines []int
+	for _, idx := range parseSelection(selection, len(entries)) {
+		lines = append(lines, entries[idx])
+	}
+	return lines
+}
+
+// remove deletes the selected entries and their comments
+func (e *listEditor) remove(selection string) int {
+	lines := e.selectedLines(selection)
+	drop := make(map[int]bool)
+	for _, line := range lines {
+		for _, i := range e.span(line) {
+			drop[i] = true
+		}
+	}
+	e.keep(func(i int) bool { return !drop[i] })
+	return len(lines)
+}
+
+// comment turns the selected entries into comments, so they can be restored later
+func (e *listEditor) comment(selection string) int {
+	lines := e.selectedLines(selection)
+	for _, line := range lines {
+		e.lines[line] = commentedOutPrefix + e.lines[line]
+	}
+	e.dirty = e.dirty || len(lines) > 0
+	return len(lines)
+}
+
+// move puts the selected entries, in selection order, before entry number to (after the
+// last entry if to is past it)
+func (e *listEditor) move(selection string, to int) int {
+	lines := e.selectedLines(selection)
+	if len(lines) == 0 {
+		return 0
+	}
+	moving := make(map[int]bool, len(lines))
+	var moved []string
+	for _, line := range lines {
+		for _, i := range e.span(line) {
+			moving[i] = true
+			moved = append(moved, e.lines[i])
+		}
+	}
+
+	// The target is the first entry at or after position to that isn't moving, or its comment
+	target := len(e.lines)
+	for n, line := range e.entries() {
+		if n+1 >= to && !moving[line] {
+			target = e.span(line)[0]
+			break
+		}
+	}
+
+	var result []string
+	for i, line := range e.lines {
+		if i == target {
+			result = append(result, moved...)
+		}
+		if !moving[i] {
+			result = append(result, line)
+		}
+	}
+	if target == len(e.lines) {
+		result = append(result, moved...)
+	}
+	e.lines = result
+	e.dirty = true
+	return len(lines)
+}
+
+// keep drops the lines for which keep returns false
+func (e *listEditor) keep(keep func(int) bool) {
+	var kept []string
+	for i, line := range e.lines {
+		if keep(i) {
+			kept = append(kept, line)
+		}
+	}
+	e.dirty = e.dirty || len(kept) != len(e.lines)
+	e.lines = kept
+}
+
+// editList lets the user remove, comment out, and reorder the entries of a list file,
+// then writes it back, keeping the previous version as <file>.bak
+func editList(filePath string, names map[string]string) error {
+	if !canPrompt() {
+		return errNoTerminal("list --edit", "edit the file in a text editor instead")
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read list file: %w", err)
+	}
+	editor := &listEditor{lines: strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")}
+
+	for {
+		fmt.Printf("\nEntries in %s:\n", filePath)
+		for n, line := range editor.entries() {
+			entry := strings.TrimSpace(editor.lines[line])
+			if name := names[expandAliases([]string{entry})[0]]; name != "" && name != entry {
+				fmt.Printf("[%d] %s (%s)\n", n+1, entry, name)
+			} else {
+				fmt.Printf("[%d] %s\n", n+1, entry)
+			}
+		}
+		fmt.Println("\nCommands (selections as in query, e.g. 1,3-5 or *,^2):")
+		fmt.Println("  d <selection>          Remove entries")
+		fmt.Println("  c <selection>          Comment out entries")
+		fmt.Println("  m <selection> <n>      Move entries before entry n (a number past the end moves them last)")
+		fmt.Println("  w                      Write the file and quit")
+		fmt.Println("  q                      Quit without saving")
+		fmt.Print("\n> ")
+
+		fields := strings.Fields(readLine())
+		if len(fields) == 0 {
+			continue
+		}
+		switch command := strings.ToLower(fields[0]); {
+		case command == "w":
+			return writeEditedList(filePath, data, editor)
+		case command == "q":
+			if editor.dirty && !confirm("Discard your changes? (Y/n): ") {
+				continue
+			}
+			fmt.Println("No changes written")
+			return nil
+		case command == "d" && len(fields) == 2:
+			fmt.Printf("Removed %d entries\n", editor.remove(fields[1]))
+		case command == "c" && len(fields) == 2:
+			fmt.Printf("Commented out %d entries\n", editor.comment(fields[1]))
+		case command == "m" && len(fields) == 3:
+			to, err := strconv.Atoi(fields[2])
+			if err != nil || to < 1 {
+				fmt.Println("The position must be an entry number")
+				continue
+			}
+			fmt.Printf("Moved %d entries\n", editor.move(fields[1], to))
+		default:
+			fmt.Println("Unknown command")
+		}
+	}
+}
+
+// writeEditedList saves the edited list, backing up the original contents first
+func writeEditedList(filePath string, original []byte, editor *listEditor) error {
+	if !editor.dirty {
+		fmt.Println("No changes to write")
+		return nil
+	}
+	backupPath := filePath + ".bak"
+	if err := os.WriteFile(backupPath, original, 0644); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", filePath, err)
+	}
+
+	var content string
+	if len(editor.lines) > 0 {
+		content = strings.Join(editor.lines, "\n") + "\n"
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filePath, []byte(content), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+	fmt.Printf("Wrote %d entries to %s (previous version: %s)\n", len(editor.entries()), filePath, backupPath)
+	return nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zerkz/gsca/steam"
+)
+
+// listFinding is a problem found in a list file
+type listFinding struct {
+	// line is 1-based
+	line    int
+	entry   string
+	problem string
+	// strictOnly findings are warnings unless --strict is set
+	strictOnly bool
+}
+
+// lintList checks the lines of a list file. alias looks up aliases from the config file;
+// resolve looks entries up in the Steam library and is nil when there is no Steam install
+// to check against, in which case only the format of the entries is checked
+func lintList(lines []string, alias func(string) (string, bool), resolve func(string) listEntry) []listFinding {
+	var findings []listFinding
+	firstLine := make(map[string]int)
+	editor := &listEditor{lines: lines}
+	for _, i := range editor.entries() {
+		entry := strings.TrimSpace(lines[i])
+		add := func(strictOnly bool, format string, args ...any) {
+			findings = append(findings, listFinding{line: i + 1, entry: entry, problem: fmt.Sprintf(format, args...), strictOnly: strictOnly})
+		}
+
+		appID := entry
+		if target, ok := alias(entry); ok {
+			appID = target
+			add(true, "alias for app %s; other machines may not define it", target)
+		} else if !isAppID(entry) {
+			if resolve == nil {
+				add(true, "not an app ID (update only accepts app IDs)")
+				continue
+			}
+			e := resolve(entry)
+			switch e.status {
+			case listStatusNotFound:
+				add(false, "matches no game")
+				continue
+			case listStatusAmbiguous:
+				add(false, "matches several games (%s)", strings.Join(e.candidates, ", "))
+				continue
+			}
+			appID = e.appID
+			add(true, "game name; update only accepts app IDs (use %s)", appID)
+		} else if resolve != nil && resolve(entry).status == listStatusNotInLibrary {
+			add(true, "app %s isn't in this Steam library", entry)
+		}
+		if resolve != nil {
+			switch e := resolve(appID); {
+			case e.dlc && e.dlcParent != "":
+				add(false, "DLC, which can't have launch options (use its game, %s)", e.dlcParent)
+			case e.dlc:
+				add(false, "DLC, which can't have launch options")
+			}
+		}
+
+		if first, seen := firstLine[appID]; seen {
+			add(true, "duplicate of line %d", first)
+			continue
+		}
+		firstLine[appID] = i + 1
+	}
+	return findings
+}
+
+// lintListFile prints the problems in a list file and fails if any of them count: entries
+// that match no game or several, and with --strict every finding. Without a Steam install
+// (e.g. validating a shared list in CI), only the format of the entries is checked
+func lintListFile(filePath string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read list file: %w", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	editor := &listEditor{lines: lines}
+	var entries []string
+	for _, i := range editor.entries() {
+		entries = append(entries, strings.TrimSpace(lines[i]))
+	}
+	resolve, err := listResolver(expandAliases(entries))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Not checking entries against a Steam library: %v\n", err)
+	}
+
+	failed := 0
+	for _, f := range lintList(lines, cfg.Alias, resolve) {
+		level := "warning"
+		if listStrict || !f.strictOnly {
+			level = "error"
+			failed++
+		}
+		fmt.Printf("%s:%d: %s: %q: %s\n", filePath, f.line, level, f.entry, f.problem)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d problem(s) in %s", failed, filePath)
+	}
+	fmt.Printf("%s: OK\n", filePath)
+	return nil
+}
+
+// listResolver returns resolveListEntry for the detected Steam install and account, also
+// flagging entries that are DLC if they are among entries
+func listResolver(entries []string) (func(string) listEntry, error) {
+	localConfigPath, err := detectLocalConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	session := steam.NewSession(steamPath, localConfigPath)
+	library, err := session.Library()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load game library: %w", err)
+	}
+	gameInfoMap, err := loadGameInfoMap(session)
+	if err != nil {
+		return nil, err
+	}
+	var appIDs []string
+	for _, entry := range entries {
+		if e := resolveListEntry(entry, gameInfoMap, library); e.appID != "" {
+			appIDs = append(appIDs, e.appID)
+		}
+	}
+	dlc := findDLC(appIDs)
+	return func(entry string) listEntry {
+		e := resolveListEntry(entry, gameInfoMap, library)
+		setDLC(&e, dlc)
+		return e
+	}, nil
+}
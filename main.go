@@ -2,38 +2,90 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/config"
+	"github.com/zerkz/gsca/diff"
+	"github.com/zerkz/gsca/i18n"
+	"github.com/zerkz/gsca/output"
 	"github.com/zerkz/gsca/steam"
+	"golang.org/x/term"
 )
 
 // Global flags
 var (
-	steamPath    string
-	userID       string
-	includeTools bool
+	steamPath     string
+	steamFlavor   string
+	userID        string
+	includeTools  bool
+	includeHidden bool
+	vrOnly        bool
+	noVR          bool
+	publisher     string
+	developer     string
+	genre         string
+	installState  string
+
+	// refreshMetadata bypasses the httpcache for online lookups, offlineMode restricts them to it
+	refreshMetadata bool
+	offlineMode     bool
 )
 
 // Update command flags
 var (
 	launchArgs     string
+	groupSpecs     []string
 	allowFile      string
 	denyFile       string
 	dryRun         bool
 	autoCloseSteam bool
+	assumeYes      bool
 	noBackup       bool
 	ignoreMissing  bool
 	openConfig     bool
 	updateAll      bool
+	surgical       bool
+	showDiff       bool
+	planFile       string
+	interactive    bool
+	tagUpdated     string
+	expiresIn      string
+	queryLimit     int
+	queryRegex     bool
+	queryExact     bool
+	queryHasArgs   bool
+	queryNoArgs    bool
+	querySelect    string
+	querySave      string
+	appIDList      []string
+	favoritesOnly  bool
+
+	// noRestart, restartBigPicture, restartMinimized, restartArgs, and restartWait control
+	// how Steam is started again
+	noRestart         bool
+	restartBigPicture bool
+	restartMinimized  bool
+	restartArgs       string
+	restartWait       time.Duration
+
+	// expiresAfter is the parsed --expires period recorded with the transaction
+	expiresAfter time.Duration
 )
 
 const statusNotInstalled = " [NOT INSTALLED]"
 
+// version is set at build time via -ldflags "-X main.version=..."
+var version = "dev"
+
 var rootCmd = &cobra.Command{
 	Use:   "gsca",
 	Short: "Global Steam Command Args - Manage Steam game launch options",
@@ -80,173 +132,309 @@ var restoreBackupCmd = &cobra.Command{
 	RunE:  runRestoreBackup,
 }
 
-var listFile string
+var (
+	listFile   string
+	listEdit   bool
+	listLint   bool
+	listStrict bool
+)
 
 func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVarP(&steamPath, "steam-path", "s", "", "Override Steam installation path (auto-detected if not specified)")
-	rootCmd.PersistentFlags().StringVarP(&userID, "user-id", "u", "", "Override Steam user ID (auto-detected if not specified)")
+	rootCmd.PersistentFlags().StringVar(&steamFlavor, "steam-flavor", "", "Steam install to use when there are several: "+strings.Join(steam.Flavors, ", "))
+	rootCmd.PersistentFlags().StringVarP(&userID, "user-id", "u", "", "Override Steam user ID (default: user_id in the config file, or the account that signed in last)")
 	rootCmd.PersistentFlags().BoolVar(&includeTools, "include-tools", false, "Include Steam tools (Proton, runtimes, etc.)")
+	rootCmd.PersistentFlags().BoolVar(&includeHidden, "include-hidden", false, "Include games hidden in the Steam library")
+	rootCmd.PersistentFlags().BoolVar(&vrOnly, "vr-only", false, "Only include VR titles (from Steam's appinfo cache)")
+	rootCmd.PersistentFlags().BoolVar(&noVR, "no-vr", false, "Exclude VR titles, including ones with optional VR support")
+	rootCmd.MarkFlagsMutuallyExclusive("vr-only", "no-vr")
+	rootCmd.PersistentFlags().StringVar(&publisher, "publisher", "", "Only include games from this publisher (e.g. \"Valve\")")
+	rootCmd.PersistentFlags().StringVar(&developer, "developer", "", "Only include games from this developer (e.g. \"FromSoftware\")")
+	rootCmd.PersistentFlags().StringVar(&installState, "install-state", "", "Only include installed games in these states: installed, updating, preallocating, unavailable (comma-separated)")
+	rootCmd.PersistentFlags().StringVar(&familyShared, "family-shared", familySharedInclude, "Games borrowed through Family Sharing: include, exclude, or only")
+	rootCmd.PersistentFlags().StringVar(&genre, "genre", "", "Only include games in this store genre (e.g. \"Racing\")")
+	rootCmd.PersistentFlags().BoolVar(&refreshMetadata, "refresh", false, "Fetch online metadata again instead of using cached copies")
+	rootCmd.PersistentFlags().BoolVar(&offlineMode, "offline", false, "Never use the network; online metadata comes from the cache only")
+	rootCmd.MarkFlagsMutuallyExclusive("refresh", "offline")
+	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "Answer yes to every confirmation (closing Steam, restoring a changed backup, repairs) for unattended runs")
 
 	// Update command flags
-	updateCmd.Flags().StringVarP(&launchArgs, "args", "a", "", "Launch arguments to set for games (required)")
-	updateCmd.Flags().StringVarP(&allowFile, "allow", "l", "", "Path to allow list file (one game name or ID per line)")
-	updateCmd.Flags().StringVarP(&denyFile, "deny", "d", "", "Path to deny list file (one game name or ID per line)")
-	updateCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be changed without actually modifying files")
-	updateCmd.Flags().BoolVarP(&autoCloseSteam, "force", "f", false, "Automatically close Steam if running (no prompt)")
-	updateCmd.Flags().BoolVar(&noBackup, "no-backup", false, "Skip creating backup file")
-	updateCmd.Flags().BoolVar(&ignoreMissing, "ignore-missing", false, "Continue even if games in allow/deny list are not found")
-	updateCmd.Flags().BoolVarP(&openConfig, "open", "o", false, "Open the config file after updating")
-	updateCmd.Flags().BoolVar(&updateAll, "all", false, "Update all games (use with caution)")
-	_ = updateCmd.MarkFlagRequired("args")
+	updateCmd.Flags().StringVarP(&launchArgs, "args", "a", "", "Launch arguments to set for games (or @name for an arg alias from the config file)")
+	updateCmd.Flags().StringArrayVar(&groupSpecs, "group", nil, "Set different args per set of games, e.g. allow=linux.txt,args=\"gamemoderun %command%\" (repeatable)")
+	addUpdateFlags(updateCmd)
+	updateCmd.MarkFlagsMutuallyExclusive("args", "group")
+	updateCmd.MarkFlagsOneRequired("args", "group")
 
 	// List command flags
 	listCmd.Flags().StringVarP(&listFile, "file", "f", "selected-games.txt", "Path to game list file")
+	listCmd.Flags().BoolVar(&listEdit, "edit", false, "Remove, comment out, or reorder entries interactively, then rewrite the file (keeping a .bak copy)")
+	listCmd.Flags().BoolVar(&listLint, "lint", false, "Check the file for entries that match no game or several, and exit non-zero if there are any")
+	listCmd.Flags().BoolVar(&listStrict, "strict", false, "With --lint, also fail on duplicates, names and aliases instead of app IDs, and games not in the library")
+	addFormatFlag(listCmd)
+	listCmd.MarkFlagsMutuallyExclusive("edit", "format", "lint")
 
 	// Add subcommands
 	rootCmd.AddCommand(updateCmd)
+	queryCmd.Flags().IntVar(&queryLimit, "limit", 20, "Matches shown per page when selecting (0 shows all); selections can refer to any match")
+	queryCmd.Flags().BoolVar(&queryRegex, "regex", false, "Treat the search term as a regular expression matched against game names (ignoring case)")
+	queryCmd.Flags().BoolVar(&queryExact, "exact", false, "Only match games whose whole name (ignoring case and accents) or app ID is the search term")
+	queryCmd.MarkFlagsMutuallyExclusive("regex", "exact")
+	queryCmd.Flags().BoolVar(&queryHasArgs, "has-args", false, "Only show games that have launch options set")
+	queryCmd.Flags().BoolVar(&queryNoArgs, "no-args", false, "Only show games without launch options")
+	queryCmd.MarkFlagsMutuallyExclusive("has-args", "no-args")
+	queryCmd.Flags().StringVar(&querySelect, "select", "", "Select matches without prompting, using the selection syntax (e.g. \"1-4,7\" or \"*\")")
+	queryCmd.Flags().StringVar(&querySave, "save", "", "Append the selected games to this allow list file without prompting, skipping games already in it")
+	addFormatFlag(queryCmd)
+	queryCmd.MarkFlagsMutuallyExclusive("select", "format")
+	queryCmd.MarkFlagsMutuallyExclusive("save", "format")
 	rootCmd.AddCommand(queryCmd)
 	rootCmd.AddCommand(listCmd)
+	restoreBackupCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the launch options restoring would change without modifying files")
+	restoreBackupCmd.Flags().BoolVar(&showDiff, "diff", false, "With --dry-run, show a unified diff of the changes to localconfig.vdf")
 	rootCmd.AddCommand(restoreBackupCmd)
 }
 
+// addUpdateFlags registers the game selection and write flags shared by update-style commands
+func addUpdateFlags(cmd *cobra.Command) {
+	addSelectionFlags(cmd)
+	cmd.Flags().BoolVarP(&openConfig, "open", "o", false, "Open the config file after updating")
+	cmd.Flags().BoolVar(&surgical, "surgical", false, "Only rewrite changed lines, leaving the rest of the file byte-identical")
+	cmd.Flags().BoolVar(&showDiff, "diff", false, "With --dry-run, show a unified diff of the changes to localconfig.vdf")
+	cmd.Flags().StringVar(&planFile, "plan", "", "Write the planned changes to this JSON file instead of applying them")
+	cmd.Flags().BoolVar(&interactive, "interactive", false, "Confirm, skip, or edit the change for each game")
+	cmd.Flags().StringVar(&tagUpdated, "tag-updated", "", "Add the changed games to this Steam collection (e.g. \"gsca\")")
+	cmd.Flags().StringVar(&expiresIn, "expires", "", "Revert the changes after this long (e.g. 7d, 12h) when 'gsca expire' next runs")
+	cmd.Flags().BoolVar(&allUsers, "all-users", false, "Update every account on this Steam install, several at a time")
+}
+
+// addSelectionFlags registers --all/--allow/--deny/--apps/--favorites and the dry-run, Steam, and backup flags
+func addSelectionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&allowFile, "allow", "l", "", "Path to allow list file (one game name or ID per line)")
+	cmd.Flags().StringVarP(&denyFile, "deny", "d", "", "Path to deny list file (one game name or ID per line)")
+	cmd.Flags().BoolVar(&updateAll, "all", false, "Update all games (use with caution)")
+	cmd.Flags().StringSliceVar(&appIDList, "apps", nil, "Comma-separated app IDs to update")
+	cmd.Flags().BoolVar(&favoritesOnly, "favorites", false, "Update the games in your Steam Favorites collection")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be changed without actually modifying files")
+	cmd.Flags().BoolVarP(&autoCloseSteam, "force", "f", false, "Automatically close Steam if running (no prompt)")
+	cmd.Flags().BoolVar(&noBackup, "no-backup", false, "Skip creating backup file")
+	cmd.Flags().BoolVar(&ignoreMissing, "ignore-missing", false, "Continue even if games in allow/deny list are not found")
+	addRestartFlags(cmd)
+}
+
+// addRestartFlags registers the flags controlling how Steam is restarted after it was closed for a write
+func addRestartFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&noRestart, "no-restart", false, "Leave Steam closed after updating")
+	cmd.Flags().BoolVar(&restartBigPicture, "restart-big-picture", false, "Restart Steam in Big Picture mode")
+	cmd.Flags().BoolVar(&restartMinimized, "restart-minimized", false, "Restart Steam minimized to the tray without focusing its window")
+	cmd.Flags().StringVar(&restartArgs, "restart-args", "", "Extra arguments for Steam when restarting it (e.g. \"-silent\")")
+	cmd.MarkFlagsMutuallyExclusive("no-restart", "restart-big-picture")
+	cmd.MarkFlagsMutuallyExclusive("no-restart", "restart-minimized")
+	cmd.Flags().DurationVar(&restartWait, "restart-wait", 0, "After restarting Steam, wait up to this long (e.g. 2m) until it is logged in and ready")
+	cmd.MarkFlagsMutuallyExclusive("no-restart", "restart-args")
+	cmd.MarkFlagsMutuallyExclusive("no-restart", "restart-wait")
+	cmd.MarkFlagsMutuallyExclusive("restart-big-picture", "restart-minimized")
+}
+
 func runUpdate(cmd *cobra.Command, args []string) error {
-	// Validate flags
-	if allowFile != "" && denyFile != "" {
-		return fmt.Errorf("cannot specify both --allow and --deny flags")
-	}
-	if !updateAll && allowFile == "" && denyFile == "" {
-		return fmt.Errorf("must specify --all, --allow, or --deny flag")
-	}
-	if updateAll && (allowFile != "" || denyFile != "") {
-		return fmt.Errorf("cannot combine --all with --allow or --deny flags")
+	if len(groupSpecs) == 0 {
+		args, err := expandArgAlias(launchArgs)
+		if err != nil {
+			return err
+		}
+		return applyAppValue(launchOptionsUpdate(args))
 	}
 
-	// Check if Steam is running (skip in dry-run mode)
-	var shouldRestartSteam bool
-	if !dryRun {
-		steamRunning, err := steam.IsSteamRunning()
+	groups := make([]updateGroup, len(groupSpecs))
+	for i, spec := range groupSpecs {
+		group, err := parseGroup(spec)
 		if err != nil {
-			fmt.Printf("Warning: Could not check if Steam is running: %v\n", err)
-		} else if steamRunning {
-			var shouldClose bool
-
-			if autoCloseSteam {
-				// Force mode - automatically close Steam
-				fmt.Println("WARNING: Steam is running - closing automatically (--force flag)")
-				shouldClose = true
-			} else {
-				// Interactive mode - ask user
-				fmt.Println("\nWARNING: Steam is currently running!")
-				fmt.Println("Steam overwrites localconfig.vdf when it closes, which will undo your changes.")
-				fmt.Print("\nClose Steam and apply changes? (Y/n): ")
-
-				var response string
-				_, _ = fmt.Scanln(&response)
-				response = strings.ToLower(strings.TrimSpace(response))
-
-				if response == "" || response == "y" || response == "yes" {
-					shouldClose = true
-				} else {
-					return fmt.Errorf("aborted - Steam must be closed to apply changes safely")
-				}
-			}
+			return err
+		}
+		if group.args, err = expandArgAlias(group.args); err != nil {
+			return err
+		}
+		groups[i] = group
+	}
+	update, err := groupsUpdate(groups)
+	if err != nil {
+		return err
+	}
 
-			if shouldClose {
-				fmt.Println("Closing Steam...")
-				if err := steam.CloseSteam(); err != nil {
-					return fmt.Errorf("failed to close Steam: %w", err)
-				}
+	// Groups pick their own games; the global selectors only narrow them further
+	selectAllByDefault()
+	return applyAppValue(update)
+}
 
-				// Wait for Steam to fully close
-				fmt.Print("Waiting for Steam to close")
-				for i := 0; i < 10; i++ {
-					time.Sleep(1 * time.Second)
-					fmt.Print(".")
-					running, _ := steam.IsSteamRunning()
-					if !running {
-						break
-					}
-				}
-				fmt.Println(" done!")
+// selectAllByDefault selects all games when none of the selectors was given
+func selectAllByDefault() {
+	if !updateAll && allowFile == "" && denyFile == "" && len(appIDList) == 0 && !favoritesOnly {
+		updateAll = true
+	}
+}
 
-				// Verify Steam is closed
-				stillRunning, _ := steam.IsSteamRunning()
-				if stillRunning {
-					return fmt.Errorf("Steam is still running after close attempt - please close it manually")
-				}
+// launchOptionsUpdate sets the launch options of the targeted games to args
+func launchOptionsUpdate(args string) appValueUpdate {
+	return appValueUpdate{
+		key:        steam.KeyLaunchOptions,
+		value:      args,
+		label:      "launch options",
+		valueLabel: "Launch args",
+	}
+}
 
-				shouldRestartSteam = true
-			}
+// appValueUpdate is a per-app localconfig value written by update-style commands
+type appValueUpdate struct {
+	key   string
+	value string
+	// label and valueLabel name the setting and its value in output
+	label      string
+	valueLabel string
+	// values (optional) picks a value per targeted game instead of value; games it leaves
+	// out are not updated
+	values func(appIDs []string) ([]steam.AppValue, error)
+	// write (optional) stores the values somewhere other than localconfig.vdf, such as the
+	// appmanifests, instead of setting key. Only installed games are targeted then, and the
+	// change isn't journaled
+	write func(values []steam.AppValue) (*steam.UpdateResult, error)
+}
 
-			fmt.Println()
+// applyAppValue runs the shared update pipeline: resolve the targeted games from
+// --all/--allow/--deny, close Steam if needed, then set the value with backup and dry-run support
+func applyAppValue(update appValueUpdate) error {
+	if err := validateSelectionFlags(); err != nil {
+		return err
+	}
+	if showDiff && !dryRun {
+		return fmt.Errorf("--diff requires --dry-run")
+	}
+	if interactive && !canPrompt() {
+		return errNoTerminal("--interactive", "drop it to apply every change")
+	}
+	if err := validateAllUsersFlags(); err != nil {
+		return err
+	}
+	if expiresIn != "" {
+		if planFile != "" {
+			return fmt.Errorf("--expires can't be used with --plan")
 		}
+		var err error
+		if expiresAfter, err = parseExpiry(expiresIn); err != nil {
+			return err
+		}
+	}
+	if err := askAboutDLC(); err != nil {
+		return err
 	}
 
-	// Get Steam path
-	var err error
-	if steamPath == "" {
-		steamPath, err = steam.GetSteamPath()
+	var reviewed []steam.AppValue
+	updater := &steam.Updater{
+		SteamPath: steamPath,
+		UserID:    userID,
+		Key:       update.key,
+		// Planning doesn't write either, so it leaves Steam running
+		DryRun:  dryRun || planFile != "",
+		Options: steam.UpdateOptions{SkipBackup: noBackup, Surgical: surgical},
+		Apply:   update.write,
+		Select: func(p *steam.PreparedUpdate) ([]steam.AppValue, error) {
+			if reviewed != nil {
+				return reviewed, nil
+			}
+			return targetAppValues(update, p.Session)
+		},
+		DetectSteamPath: detectSteamPath,
+		DetectUserID: func(steamPath string) (string, error) {
+			// Selection reads the account's files through the userID global
+			id, err := detectUserID(steamPath)
+			userID = id
+			return id, err
+		},
+		CloseSteam:   closeSteamBeforeWrite,
+		RestartSteam: restartSteam,
+		Log:          func(format string, args ...any) { fmt.Printf(format+"\n", args...) },
+	}
+	if interactive {
+		// Review before Steam is closed, so it isn't left down while the user decides
+		review := *updater
+		review.DryRun = true
+		prepared, _, err := review.Run()
 		if err != nil {
-			return fmt.Errorf("failed to detect Steam path: %w", err)
+			return err
 		}
-	}
-	fmt.Printf("Steam path: %s\n", steamPath)
-
-	// Get user ID
-	if userID == "" {
-		userID, err = steam.GetUserID(steamPath)
+		current, err := steam.GetAppValues(prepared.LocalConfigPath, update.key)
 		if err != nil {
-			return fmt.Errorf("failed to detect user ID: %w", err)
+			return err
+		}
+		reviewed = reviewAppValues(prepared.Values, current, prepared.Library)
+		if len(reviewed) == 0 {
+			fmt.Println("\nNo changes accepted")
+			return nil
 		}
+		updater.SteamPath, updater.UserID = review.SteamPath, review.UserID
+		// Values may have been edited per game
+		update.values = func([]string) ([]steam.AppValue, error) { return reviewed, nil }
 	}
-	fmt.Printf("User ID: %s\n", userID)
-
-	// Get localconfig path
-	localConfigPath := steam.GetLocalConfigPath(steamPath, userID)
-	fmt.Printf("Local config: %s\n", localConfigPath)
+	if err := updater.Start(); err != nil {
+		return err
+	}
+	// Steam is started again however this ends; Finish only does it once
+	defer updater.Finish()
+	steamPath = updater.SteamPath
 
-	// Get game mapping
-	fmt.Println("Loading game mapping...")
-	mapping, err := steam.GetGameMapping(steamPath)
-	if err != nil {
-		return fmt.Errorf("failed to get game mapping: %w", err)
+	if allUsers {
+		return applyAllUsers(update, updater)
 	}
-	fmt.Printf("Found %d games\n", len(mapping)/2)
 
-	// Get all game IDs from localconfig
-	allGameIDs, err := steam.GetAllGameIDs(localConfigPath)
+	prepared, err := updater.Prepare()
 	if err != nil {
-		return fmt.Errorf("failed to get game IDs: %w", err)
+		return err
 	}
+	localConfigPath, library := prepared.LocalConfigPath, prepared.Library
+	appValues := prepared.Values
 
-	// Load and resolve allow/deny lists
-	var targetGameIDs []string
-
-	if allowFile != "" {
-		resolvedIDs, loadErr := loadAndResolveFilterList(allowFile, "allow", mapping, ignoreMissing)
-		if loadErr != nil {
-			return loadErr
-		}
-		targetGameIDs = steam.FilterGameIDs(allGameIDs, resolvedIDs, nil)
-	} else if denyFile != "" {
-		resolvedIDs, loadErr := loadAndResolveFilterList(denyFile, "deny", mapping, ignoreMissing)
-		if loadErr != nil {
-			return loadErr
+	if update.key == steam.KeyLaunchOptions {
+		if err := checkLaunchOptions(appValues); err != nil {
+			return err
 		}
-		targetGameIDs = steam.FilterGameIDs(allGameIDs, nil, resolvedIDs)
-	} else {
-		// No filter - update all games
-		targetGameIDs = allGameIDs
 	}
 
-	fmt.Printf("\nWill update launch options for %d games\n", len(targetGameIDs))
-	fmt.Printf("Launch args: %s\n", launchArgs)
+	fmt.Printf("\nWill update %s for %d games\n", update.label, len(appValues))
+	if update.values == nil {
+		fmt.Printf("%s: %s\n", update.valueLabel, update.value)
+	}
+
+	if planFile != "" {
+		return writeUpdatePlan(update.key, appValues, localConfigPath, library)
+	}
 
 	if dryRun {
 		fmt.Println("\n[DRY RUN] Would update the following app IDs:")
-		for _, appID := range targetGameIDs {
-			fmt.Printf("  - %s\n", appID)
+		for _, v := range appValues {
+			if update.values != nil {
+				fmt.Printf("  - %s: %s\n", v.AppID, v.Value)
+			} else {
+				fmt.Printf("  - %s\n", v.AppID)
+			}
+		}
+
+		if tagUpdated != "" {
+			fmt.Printf("\n[DRY RUN] Would add the changed games to the %q collection\n", tagUpdated)
+		}
+		if expiresAfter > 0 {
+			fmt.Printf("\n[DRY RUN] Would revert the changes after %s\n", time.Now().Add(expiresAfter).Format("2006-01-02 15:04"))
+		}
+
+		if showDiff {
+			preview, err := updater.Preview(prepared)
+			if err != nil {
+				return fmt.Errorf("failed to preview changes: %w", err)
+			}
+			if d := diff.Unified(localConfigPath, localConfigPath, preview.Before, preview.After, 3); d != "" {
+				fmt.Println()
+				fmt.Print(d)
+			} else {
+				fmt.Println("\nNo changes to localconfig.vdf")
+			}
 		}
 
 		// Open config file if requested (useful to see current state)
@@ -261,28 +449,45 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Update launch options
-	fmt.Println("\nUpdating launch options...")
-	backupPath, err := steam.UpdateLaunchOptions(localConfigPath, targetGameIDs, launchArgs, noBackup)
+	// Update the value for each game
+	switch {
+	case update.key == steam.KeyLaunchOptions:
+		fmt.Println("\n" + i18n.T(i18n.UpdatingLaunchOptions))
+	case update.write != nil:
+		fmt.Printf("\nSetting %s...\n", update.label)
+	default:
+		fmt.Println("\n" + i18n.T(i18n.UpdatingAppValue, update.key))
+	}
+	result, err := updater.Write(prepared)
 	if err != nil {
-		return fmt.Errorf("failed to update launch options: %w", err)
+		if errors.Is(err, steam.ErrConfigModified) {
+			return err
+		}
+		return fmt.Errorf("failed to update %s: %w", update.label, err)
 	}
 
-	fmt.Printf("\nSuccessfully updated %d games!\n", len(targetGameIDs))
-	if backupPath != "" {
-		fmt.Printf("Backup created at: %s\n", backupPath)
+	for _, app := range result.Apps {
+		switch {
+		case app.Status == steam.UpdateFailed:
+			fmt.Printf("ERROR: Failed to update app %s: %v\n", app.AppID, app.Err)
+		case app.Status == steam.UpdateChanged && update.write != nil:
+			previous := app.PreviousValue
+			if previous == "" {
+				previous = "(default)"
+			}
+			fmt.Printf("  %s: %s -> %s\n", app.AppID, previous, app.NewValue)
+		}
 	}
 
-	// Restart Steam if we closed it
-	if shouldRestartSteam {
-		fmt.Println("\nRestarting Steam...")
-		if err := steam.StartSteam(); err != nil {
-			fmt.Printf("Warning: Failed to start Steam: %v\n", err)
-			fmt.Println("Please start Steam manually.")
-		} else {
-			fmt.Println("Steam started successfully!")
-		}
+	printUpdateSummary(result)
+	if update.write == nil {
+		txID := recordTransaction(update.key, localConfigPath, result, libraryNames(library), "")
+		notifyWebhook("update", update.key, result, txID, "")
 	}
+	tagUpdatedGames(result)
+
+	// Restart Steam if we closed it
+	updater.Finish()
 
 	// Open config file if requested
 	if openConfig {
@@ -296,16 +501,163 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// targetAppValues selects the games of the current account to update, from its
+// localconfig.vdf and the selection flags, and computes their values
+func targetAppValues(update appValueUpdate, session *steam.Session) ([]steam.AppValue, error) {
+	library, err := session.Library()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load game library: %w", err)
+	}
+	var allGameIDs []string
+	if update.write != nil {
+		// Settings outside localconfig.vdf live in the appmanifests of installed games
+		for _, game := range library.All() {
+			if includeTools || !steam.IsToolName(game.Name) {
+				allGameIDs = append(allGameIDs, game.AppID)
+			}
+		}
+	} else {
+		if allGameIDs, err = session.GameIDs(); err != nil {
+			return nil, fmt.Errorf("failed to get game IDs: %w", err)
+		}
+		allGameIDs = steam.AddInstalledGameIDs(allGameIDs, library)
+	}
+
+	// Load and resolve allow/deny lists
+	targetGameIDs, err := selectTargetGameIDs(allGameIDs, library)
+	if err != nil {
+		return nil, err
+	}
+	targetGameIDs, err = excludeHiddenGames(targetGameIDs, session)
+	if err != nil {
+		return nil, err
+	}
+	targetGameIDs = replaceDLC(targetGameIDs)
+	if targetGameIDs, err = filterByStoreMetadata(targetGameIDs); err != nil {
+		return nil, err
+	}
+	if targetGameIDs, err = filterByInstallState(targetGameIDs, library); err != nil {
+		return nil, err
+	}
+	if targetGameIDs, err = filterByFamilySharing(targetGameIDs, library); err != nil {
+		return nil, err
+	}
+
+	if update.values != nil {
+		return update.values(targetGameIDs)
+	}
+	appValues := make([]steam.AppValue, 0, len(targetGameIDs))
+	for _, appID := range targetGameIDs {
+		appValues = append(appValues, steam.AppValue{AppID: appID, Value: update.value})
+	}
+	return appValues, nil
+}
+
+// tagUpdatedGames adds the games changed by result to the --tag-updated collection.
+// Failing to tag is only a warning since the update itself succeeded
+func tagUpdatedGames(result *steam.UpdateResult) {
+	if tagUpdated == "" {
+		return
+	}
+	var changed []string
+	for _, app := range result.Apps {
+		if app.Status == steam.UpdateChanged {
+			changed = append(changed, app.AppID)
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	tagged, err := steam.TagApps(steam.GetSharedConfigPath(steamPath, userID), tagUpdated, changed, steam.UpdateOptions{SkipBackup: noBackup})
+	if err != nil {
+		fmt.Printf("Warning: failed to add games to the %q collection: %v\n", tagUpdated, err)
+		return
+	}
+	fmt.Printf("Added %d game(s) to the %q collection\n", tagged.Count(steam.UpdateChanged), tagUpdated)
+}
+
+// checkLaunchOptions prints warnings about the launch options in values, once per distinct
+// value, including tokens that may trip anti-cheat, and returns an error for values that
+// can't be written
+func checkLaunchOptions(values []steam.AppValue) error {
+	checked := make(map[string]bool)
+	for _, v := range values {
+		if checked[v.Value] {
+			continue
+		}
+		checked[v.Value] = true
+
+		warnings, err := steam.CheckLaunchOptions(v.Value)
+		if err != nil {
+			return fmt.Errorf("app %s: %w", v.AppID, err)
+		}
+		for _, warning := range warnings {
+			fmt.Printf("WARNING: app %s: %s\n", v.AppID, warning)
+		}
+	}
+
+	if db, err := loadArgDB(); err == nil {
+		warnAntiCheat(db, values)
+	}
+	return nil
+}
+
+// writeUpdatePlan saves the changes setting key to values would make to planFile
+func writeUpdatePlan(key string, values []steam.AppValue, localConfigPath string, library *steam.Library) error {
+	preview, err := steam.PreviewAppValues(localConfigPath, key, values, steam.UpdateOptions{Surgical: surgical})
+	if err != nil {
+		return fmt.Errorf("failed to plan changes: %w", err)
+	}
+
+	plan := &steam.Plan{
+		CreatedAt: time.Now().UTC(),
+		Environment: steam.Environment{
+			OS:              runtime.GOOS,
+			GscaVersion:     version,
+			SteamPath:       steamPath,
+			UserID:          userID,
+			LocalConfigPath: localConfigPath,
+			LocalConfig:     preview.Fingerprint,
+		},
+		Key:      key,
+		Surgical: surgical,
+		Changes:  []steam.PlanChange{},
+	}
+	for _, app := range preview.Result.Apps {
+		if app.Status != steam.UpdateChanged {
+			continue
+		}
+		change := steam.PlanChange{AppID: app.AppID, OldValue: app.PreviousValue, NewValue: app.NewValue}
+		if game, found := library.LookupByID(app.AppID); found {
+			change.Name = game.Name
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	if err := steam.WritePlan(planFile, plan); err != nil {
+		return err
+	}
+	fmt.Printf("\nPlan with %d change(s) written to %s (%d already up to date)\n",
+		len(plan.Changes), planFile, preview.Result.Count(steam.UpdateUnchanged))
+	return nil
+}
+
 func runQuery(cmd *cobra.Command, args []string) error {
 	var query string
 	if len(args) > 0 {
 		query = strings.Join(args, " ")
 	}
 
+	restoreStdout, err := startFormat()
+	if err != nil {
+		return err
+	}
+	defer restoreStdout()
+
 	// Get Steam path
-	var err error
 	if steamPath == "" {
-		steamPath, err = steam.GetSteamPath()
+		steamPath, err = detectSteamPath()
 		if err != nil {
 			return fmt.Errorf("failed to detect Steam path: %w", err)
 		}
@@ -313,7 +665,7 @@ func runQuery(cmd *cobra.Command, args []string) error {
 
 	// Get user ID
 	if userID == "" {
-		userID, err = steam.GetUserID(steamPath)
+		userID, err = detectUserID(steamPath)
 		if err != nil {
 			return fmt.Errorf("failed to detect user ID: %w", err)
 		}
@@ -322,33 +674,60 @@ func runQuery(cmd *cobra.Command, args []string) error {
 	localConfigPath := steam.GetLocalConfigPath(steamPath, userID)
 
 	// Get all games (installed and uninstalled)
-	fmt.Println("Loading game library...")
-	allGames, err := steam.GetAllGames(steamPath, localConfigPath)
+	session := steam.NewSession(steamPath, localConfigPath)
+	session.Progress = printProgress("Loading game library...")
+	allGames, err := session.Games()
 	if err != nil {
 		return fmt.Errorf("failed to get game library: %w", err)
 	}
 
-	// Get game mapping for duplicate detection
-	mapping, err := steam.GetGameMapping(steamPath)
+	// Installed games for duplicate detection, from the same appmanifest scan
+	library, err := session.Library()
+	if err != nil {
+		return fmt.Errorf("failed to load game library: %w", err)
+	}
+	warnOfflineLibraries()
+
+	states, err := installStateFilter()
 	if err != nil {
-		return fmt.Errorf("failed to get game mapping: %w", err)
+		return err
+	}
+	if err := checkFamilyShared(); err != nil {
+		return err
 	}
 
 	// Filter to only installed games and exclude Steam tools by default
 	var installedGames []steam.GameInfo
 	for _, game := range allGames {
-		if !game.Installed {
+		if !game.Installed && game.InstallState != steam.InstallStateUnavailable {
 			continue
 		}
 
 		// Skip Steam tools unless --include-tools is set
-		if !includeTools && isSteamTool(game.Name) {
+		if !includeTools && game.AppType == steam.AppTypeTool {
+			continue
+		}
+
+		// Skip hidden games unless --include-hidden is set
+		if !includeHidden && game.Hidden {
+			continue
+		}
+
+		if states != nil && !states[game.InstallState] {
+			continue
+		}
+
+		if !matchesArgsFilter(game.LaunchOptions) || !keepFamilyShared(game.SharedBy) {
 			continue
 		}
 
 		installedGames = append(installedGames, game)
 	}
 
+	if installedGames, err = filterGameInfosByStoreMetadata(installedGames); err != nil {
+		return err
+	}
+
 	// Search or show all games
 	var matches []steam.GameInfo
 	if query == "" {
@@ -358,17 +737,38 @@ func runQuery(cmd *cobra.Command, args []string) error {
 	} else {
 		// Search installed games
 		fmt.Printf("\nSearching for: \"%s\"\n", query)
-		queryLower := strings.ToLower(query)
+		aliasID := expandAliases([]string{query})[0]
+		if aliasID != query {
+			fmt.Printf("Alias for app %s\n", aliasID)
+		}
 
+		match, err := queryMatcher(query)
+		if err != nil {
+			return err
+		}
 		for _, game := range installedGames {
-			// Search by name or app ID
-			if strings.Contains(strings.ToLower(game.Name), queryLower) ||
-				strings.Contains(game.AppID, queryLower) {
+			if aliasID != query {
+				// An alias selects its game instead of searching names
+				if game.AppID == aliasID {
+					matches = append(matches, game)
+				}
+				continue
+			}
+			if match(game) {
 				matches = append(matches, game)
 			}
 		}
 	}
 
+	// --format prints the matches as data instead of offering to select them
+	if outputFormat != "" {
+		table := output.Table{Columns: []string{"app_id", "name", "install_state", "shared_by", "launch_options"}}
+		for _, game := range matches {
+			table.Add(game.AppID, game.Name, game.InstallState, optional(game.SharedBy), game.LaunchOptions)
+		}
+		return writeFormat(table)
+	}
+
 	if len(matches) == 0 {
 		fmt.Println("\nNo games found matching your query.")
 		fmt.Println("\nTips:")
@@ -381,39 +781,30 @@ func runQuery(cmd *cobra.Command, args []string) error {
 	// Display results
 	fmt.Printf("\nFound %d match(es):\n", len(matches))
 
-	for i := 0; i < len(matches); i++ {
-		game := matches[i]
-		fmt.Printf("[%d] %s\n", i+1, game.Name)
-		fmt.Printf("    App ID: %s\n", game.AppID)
-
-		if game.LaunchOptions != "" {
-			fmt.Printf("    Launch Options: %s\n", game.LaunchOptions)
-		} else {
-			fmt.Printf("    Launch Options: (none)\n")
+	// Piped or scripted runs just get all the results, unless --select picks some
+	input := querySelect
+	if input != "" {
+		printMatches(matches, 0, len(matches))
+	} else if !canPrompt() {
+		printMatches(matches, 0, len(matches))
+		if querySave != "" {
+			return errNoTerminal("Selecting the games to save", `pick them with --select, e.g. --select "1-4,7"`)
 		}
-		fmt.Println()
+		return nil
+	} else {
+		input = selectMatches(matches, queryLimit)
 	}
-
-	// Interactive selection
-	fmt.Println("────────────────────────────────────────")
-	fmt.Println("Select games to export to file:")
-	fmt.Println("  • Enter numbers (e.g., 1,3,5 or 1-3)")
-	fmt.Println("  • Enter * to select all")
-	fmt.Println("  • Press Enter to skip")
-	fmt.Print("\nSelection: ")
-
-	reader := bufio.NewReader(os.Stdin)
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimSpace(input)
-
 	if input == "" {
-		fmt.Println("\nNo games selected. Exiting.")
+		fmt.Println("\n" + i18n.T(i18n.NoGamesSelected))
 		return nil
 	}
 
-	// Parse selection
+	// Parse selection; numbers refer to all matches, not just the page shown
 	selected := parseSelection(input, len(matches))
 	if len(selected) == 0 {
+		if querySelect != "" {
+			return fmt.Errorf("--select %q picks none of the %d match(es)", querySelect, len(matches))
+		}
 		fmt.Println("\nInvalid selection. Exiting.")
 		return nil
 	}
@@ -423,18 +814,40 @@ func runQuery(cmd *cobra.Command, args []string) error {
 	var selectedIDs []string
 	for _, idx := range selected {
 		game := matches[idx]
-		fmt.Printf("  • %s (ID: %s)\n", game.Name, game.AppID)
+		fmt.Printf("  %s %s (ID: %s)\n", bullet, game.Name, game.AppID)
 		selectedIDs = append(selectedIDs, game.AppID)
 	}
 
+	if querySave != "" {
+		return saveSelection(querySave, "allow", selectedIDs, matches, library)
+	}
+	if !canPrompt() {
+		return errNoTerminal("Saving the selection", "add --save <file>")
+	}
+
+	// Selections can be saved for --allow or, to update everything else, --deny, or updated
+	// right away
+	listFlag, defaultFile := "allow", "selected-games.txt"
+	fmt.Print("\n" + i18n.T(i18n.PromptSaveOrUpdate))
+	switch answer := strings.ToLower(readLine()); answer {
+	case "d", "deny":
+		listFlag, defaultFile = "deny", "excluded-games.txt"
+	case "u", "update":
+		return updateSelectedGames(selectedIDs)
+	}
+
 	// Ask where to save
-	fmt.Print("\nSave to file (default: selected-games.txt): ")
-	filename, _ := reader.ReadString('\n')
-	filename = strings.TrimSpace(filename)
+	fmt.Printf("Save to file (default: %s): ", defaultFile)
+	filename := readLine()
 	if filename == "" {
-		filename = "selected-games.txt"
+		filename = defaultFile
 	}
+	return saveSelection(filename, listFlag, selectedIDs, matches, library)
+}
 
+// saveSelection appends the selected games to a list file for --allow or --deny, skipping
+// games already in it
+func saveSelection(filename, listFlag string, selectedIDs []string, matches []steam.GameInfo, library *steam.Library) error {
 	// Load existing entries to check for duplicates
 	existingAppIDs := make(map[string]bool)
 	fileExists := false
@@ -442,7 +855,7 @@ func runQuery(cmd *cobra.Command, args []string) error {
 	if existingEntries, err := steam.LoadFilterList(filename); err == nil {
 		fileExists = true
 		// Resolve existing entries to app IDs
-		resolvedIDs, _ := steam.ResolveGameIDs(existingEntries, mapping)
+		resolvedIDs, _ := steam.ResolveGameIDs(existingEntries, library)
 		for _, id := range resolvedIDs {
 			existingAppIDs[id] = true
 		}
@@ -471,7 +884,7 @@ func runQuery(cmd *cobra.Command, args []string) error {
 	if len(skipped) > 0 {
 		fmt.Println("\nWARNING:Skipped duplicates (already in file):")
 		for _, name := range skipped {
-			fmt.Printf("  • %s\n", name)
+			fmt.Printf("  %s %s\n", bullet, name)
 		}
 	}
 
@@ -496,12 +909,79 @@ func runQuery(cmd *cobra.Command, args []string) error {
 		fmt.Printf("\nWARNING:No new games to add (all selections already in %s)\n", filename)
 	}
 
-	fmt.Println("\nTo update these games, run:")
-	fmt.Printf("   gsca update --args \"your launch options\" --allow %s\n", filename)
+	if listFlag == "deny" {
+		fmt.Println("\nTo update every game except these, run:")
+	} else {
+		fmt.Println("\nTo update these games, run:")
+	}
+	fmt.Printf("   gsca update --args \"your launch options\" --%s %s\n", listFlag, filename)
 
 	return nil
 }
 
+// matchesArgsFilter reports whether a game with these launch options passes --has-args
+// and --no-args; options that are only whitespace count as none
+func matchesArgsFilter(launchOptions string) bool {
+	hasArgs := strings.TrimSpace(launchOptions) != ""
+	return !(queryHasArgs && !hasArgs) && !(queryNoArgs && hasArgs)
+}
+
+// queryMatcher returns how query matches games: by default a name containing it (ignoring
+// case and accents) or an app ID containing it, with --exact the whole name or app ID, and
+// with --regex a regular expression matched against the name (ignoring case)
+func queryMatcher(query string) (func(steam.GameInfo) bool, error) {
+	switch {
+	case queryRegex:
+		re, err := regexp.Compile("(?i)" + query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --regex search: %w", err)
+		}
+		return func(game steam.GameInfo) bool {
+			return re.MatchString(game.Name)
+		}, nil
+	case queryExact:
+		folded := steam.FoldName(strings.TrimSpace(query))
+		return func(game steam.GameInfo) bool {
+			return steam.FoldName(game.Name) == folded || game.AppID == query
+		}, nil
+	}
+	return func(game steam.GameInfo) bool {
+		return steam.NameContains(game.Name, query) || strings.Contains(game.AppID, query)
+	}, nil
+}
+
+// updateSelectedGames asks for launch options and sets them for the games selected in
+// query, after showing a dry run of the change
+func updateSelectedGames(appIDs []string) error {
+	fmt.Print(i18n.T(i18n.PromptLaunchOptions))
+	args, err := expandArgAlias(readLine())
+	if err != nil {
+		return err
+	}
+	if args == "" {
+		fmt.Println("No launch options entered; nothing changed")
+		return nil
+	}
+	if _, err := steam.CheckLaunchOptions(args); err != nil {
+		return err
+	}
+
+	appIDList = appIDs
+	update := launchOptionsUpdate(args)
+	dryRun = true
+	if err := applyAppValue(update); err != nil {
+		return err
+	}
+
+	if !confirm("\n" + i18n.T(i18n.PromptApplyChanges)) {
+		fmt.Println(i18n.T(i18n.Cancelled))
+		return nil
+	}
+	fmt.Println()
+	dryRun = false
+	return applyAppValue(update)
+}
+
 func runList(cmd *cobra.Command, args []string) error {
 	// Use provided file path or default
 	filePath := listFile
@@ -509,10 +989,22 @@ func runList(cmd *cobra.Command, args []string) error {
 		filePath = args[0]
 	}
 
+	restoreStdout, err := startFormat()
+	if err != nil {
+		return err
+	}
+	defer restoreStdout()
+
+	if listStrict && !listLint {
+		return fmt.Errorf("--strict only applies with --lint")
+	}
+	if listLint {
+		return lintListFile(filePath)
+	}
+
 	// Get Steam path
-	var err error
 	if steamPath == "" {
-		steamPath, err = steam.GetSteamPath()
+		steamPath, err = detectSteamPath()
 		if err != nil {
 			return fmt.Errorf("failed to detect Steam path: %w", err)
 		}
@@ -520,35 +1012,28 @@ func runList(cmd *cobra.Command, args []string) error {
 
 	// Get user ID
 	if userID == "" {
-		userID, err = steam.GetUserID(steamPath)
+		userID, err = detectUserID(steamPath)
 		if err != nil {
 			return fmt.Errorf("failed to detect user ID: %w", err)
 		}
 	}
 
-	localConfigPath := steam.GetLocalConfigPath(steamPath, userID)
+	session := steam.NewSession(steamPath, steam.GetLocalConfigPath(steamPath, userID))
 
-	// Load game mapping (for name/ID resolution)
+	// Load installed games (for name/ID resolution)
 	fmt.Println("Loading game library...")
-	mapping, err := steam.GetGameMapping(steamPath)
+	library, err := session.Library()
 	if err != nil {
-		return fmt.Errorf("failed to get game mapping: %w", err)
+		return fmt.Errorf("failed to load game library: %w", err)
 	}
 
-	// Get all games for detailed info
-	allGames, err := steam.GetAllGames(steamPath, localConfigPath)
-	if err != nil {
-		return fmt.Errorf("failed to get game library: %w", err)
+	if listEdit {
+		return editList(filePath, libraryNames(library))
 	}
 
-	// Build app ID to game info map (filter Steam tools by default)
-	gameInfoMap := make(map[string]steam.GameInfo)
-	for _, game := range allGames {
-		// Skip Steam tools unless --include-tools is set
-		if !includeTools && isSteamTool(game.Name) {
-			continue
-		}
-		gameInfoMap[game.AppID] = game
+	gameInfoMap, err := loadGameInfoMap(session)
+	if err != nil {
+		return err
 	}
 
 	// Load the list file
@@ -556,94 +1041,183 @@ func runList(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to load list file: %w", err)
 	}
+	entries = expandAliases(entries)
 
 	if len(entries) == 0 {
 		fmt.Printf("\nWARNING:File is empty: %s\n", filePath)
-		return nil
+		if outputFormat == "" {
+			return nil
+		}
 	}
 
 	// Resolve entries and display
-	fmt.Printf("\nGames in %s:\n\n", filePath)
-
+	resolved := make([]listEntry, len(entries))
 	for i, entry := range entries {
-		entryLower := strings.ToLower(entry)
-
-		// First check if entry is an app ID (numeric check or exists in gameInfoMap)
-		isNumeric := true
-		for _, c := range entry {
-			if c < '0' || c > '9' {
-				isNumeric = false
-				break
-			}
-		}
-
-		if isNumeric {
-			// Entry looks like an app ID - check if it's in our library
-			if gameInfo, found := gameInfoMap[entry]; found {
-				status := ""
-				if !gameInfo.Installed {
-					status = statusNotInstalled
-				}
-
-				if gameInfo.Name == entry {
-					// No name available (uninstalled), just show ID
-					fmt.Printf("[%d] App ID: %s%s\n", i+1, entry, status)
-				} else {
-					// Show both name and ID
-					fmt.Printf("[%d] %s\n", i+1, gameInfo.Name)
-					fmt.Printf("    App ID: %s%s\n", entry, status)
-				}
-
-				if gameInfo.LaunchOptions != "" {
-					fmt.Printf("    Launch Options: %s\n", gameInfo.LaunchOptions)
-				}
-			} else {
-				fmt.Printf("[%d] App ID: %s [NOT IN LIBRARY]\n", i+1, entry)
-			}
-		} else if appID, exists := mapping[entryLower]; exists {
-			// Entry is a game name
-			if gameInfo, found := gameInfoMap[appID]; found {
-				status := ""
-				if !gameInfo.Installed {
-					status = statusNotInstalled
-				}
-
-				fmt.Printf("[%d] %s\n", i+1, entry)
-				fmt.Printf("    App ID: %s%s\n", appID, status)
-
-				if gameInfo.LaunchOptions != "" {
-					fmt.Printf("    Launch Options: %s\n", gameInfo.LaunchOptions)
-				}
-			} else {
-				fmt.Printf("[%d] %s\n", i+1, entry)
-				fmt.Printf("    App ID: %s [NOT IN LIBRARY]\n", appID)
-			}
-		} else {
-			// Entry not found
-			fmt.Printf("[%d] %s [NOT FOUND]\n", i+1, entry)
+		resolved[i] = resolveListEntry(entry, gameInfoMap, library)
+	}
+	markDLC(resolved)
+	if outputFormat != "" {
+		table := output.Table{Columns: []string{"entry", "app_id", "name", "status", "shared_by", "dlc_parent", "launch_options"}}
+		for _, e := range resolved {
+			table.Add(e.entry, e.appID, e.name, e.status, optional(e.sharedBy), optional(e.dlcParent), e.launchOptions)
 		}
+		return writeFormat(table)
+	}
 
+	fmt.Printf("\nGames in %s:\n\n", filePath)
+	for i, e := range resolved {
+		printListEntry(i+1, e, library)
 		fmt.Println()
 	}
 
 	fmt.Printf("Total: %d game(s)\n", len(entries))
 
-	return nil
+	return offerListDLCFix(filePath, resolved)
 }
 
-func runRestoreBackup(cmd *cobra.Command, args []string) error {
-	// Get Steam path
-	var err error
-	if steamPath == "" {
-		steamPath, err = steam.GetSteamPath()
-		if err != nil {
-			return fmt.Errorf("failed to detect Steam path: %w", err)
+// loadGameInfoMap returns the games of the account by app ID, without Steam tools unless
+// --include-tools is set
+func loadGameInfoMap(session *steam.Session) (map[string]steam.GameInfo, error) {
+	session.Progress = printProgress("Reading game details...")
+	allGames, err := session.Games()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get game library: %w", err)
+	}
+
+	gameInfoMap := make(map[string]steam.GameInfo)
+	for _, game := range allGames {
+		if !includeTools && game.AppType == steam.AppTypeTool {
+			continue
+		}
+		gameInfoMap[game.AppID] = game
+	}
+	return gameInfoMap, nil
+}
+
+// Statuses of list file entries
+const (
+	listStatusInstalled    = "installed"
+	listStatusNotInstalled = "not-installed"
+	listStatusNotInLibrary = "not-in-library"
+	listStatusNotFound     = "not-found"
+	listStatusAmbiguous    = "ambiguous"
+)
+
+// listEntry is a list file entry resolved against the library
+type listEntry struct {
+	entry string
+	// appID is empty for names that match no game or several
+	appID         string
+	name          string
+	status        string
+	launchOptions string
+	// sharedBy is the owner of a game borrowed through Family Sharing
+	sharedBy string
+	// dlc entries can't have launch options; dlcParent is the game they belong to, if known
+	dlc       bool
+	dlcParent string
+	// candidates are the app IDs an ambiguous name matches
+	candidates []string
+}
+
+// resolveListEntry looks up a list file entry, an app ID or a game name
+func resolveListEntry(entry string, gameInfoMap map[string]steam.GameInfo, library *steam.Library) listEntry {
+	e := listEntry{entry: entry, status: listStatusNotInLibrary}
+	if isAppID(entry) {
+		e.appID = entry
+	} else {
+		match, err := library.LookupByName(entry)
+		var ambiguous *steam.AmbiguousNameError
+		switch {
+		case errors.As(err, &ambiguous):
+			e.status = listStatusAmbiguous
+			e.candidates = ambiguous.AppIDs
+			return e
+		case err != nil:
+			e.status = listStatusNotFound
+			return e
+		}
+		e.appID = match.AppID
+	}
+
+	if gameInfo, found := gameInfoMap[e.appID]; found {
+		e.status = listStatusInstalled
+		if !gameInfo.Installed {
+			e.status = listStatusNotInstalled
+		}
+		// Uninstalled games may have no name, just their ID
+		if gameInfo.Name != e.appID {
+			e.name = gameInfo.Name
+		}
+		e.launchOptions = gameInfo.LaunchOptions
+		e.sharedBy = gameInfo.SharedBy
+	}
+	return e
+}
+
+// printListEntry prints a resolved list file entry numbered n
+func printListEntry(n int, e listEntry, library *steam.Library) {
+	status := ""
+	switch e.status {
+	case listStatusNotInstalled:
+		status = statusNotInstalled
+	case listStatusNotInLibrary:
+		status = " [NOT IN LIBRARY]"
+	case listStatusNotFound:
+		fmt.Printf("[%d] %s [NOT FOUND]\n", n, e.entry)
+		return
+	case listStatusAmbiguous:
+		fmt.Printf("[%d] %s [AMBIGUOUS]\n", n, e.entry)
+		for _, id := range e.candidates {
+			if game, found := library.LookupByID(id); found {
+				fmt.Printf("    App ID: %s (%s)\n", id, game.Name)
+			} else {
+				fmt.Printf("    App ID: %s\n", id)
+			}
+		}
+		fmt.Println("    Replace the name with one of these app IDs to pick a game")
+		return
+	}
+
+	switch {
+	case e.entry != e.appID:
+		// Entry is a game name
+		fmt.Printf("[%d] %s\n", n, e.entry)
+		fmt.Printf("    App ID: %s%s\n", e.appID, status)
+	case e.name == "":
+		fmt.Printf("[%d] App ID: %s%s\n", n, e.appID, status)
+	default:
+		fmt.Printf("[%d] %s\n", n, e.name)
+		fmt.Printf("    App ID: %s%s\n", e.appID, status)
+	}
+	if e.sharedBy != "" {
+		fmt.Printf("    Family Sharing: borrowed from %s\n", accountLabel(e.sharedBy))
+	}
+	if e.dlc {
+		fmt.Printf("    WARNING: %s; launch options only apply to games\n", dlcLabel(steam.AppInfo{AppID: e.appID, Name: e.name, Parent: e.dlcParent}, library))
+	}
+	if e.launchOptions != "" {
+		fmt.Printf("    Launch Options: %s\n", e.launchOptions)
+	}
+}
+
+func runRestoreBackup(cmd *cobra.Command, args []string) error {
+	if showDiff && !dryRun {
+		return fmt.Errorf("--diff requires --dry-run")
+	}
+
+	// Get Steam path
+	var err error
+	if steamPath == "" {
+		steamPath, err = detectSteamPath()
+		if err != nil {
+			return fmt.Errorf("failed to detect Steam path: %w", err)
 		}
 	}
 
 	// Get user ID
 	if userID == "" {
-		userID, err = steam.GetUserID(steamPath)
+		userID, err = detectUserID(steamPath)
 		if err != nil {
 			return fmt.Errorf("failed to detect user ID: %w", err)
 		}
@@ -658,7 +1232,7 @@ func runRestoreBackup(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(backups) == 0 {
-		fmt.Println("No backups found.")
+		fmt.Println(i18n.T(i18n.NoBackups))
 		return nil
 	}
 
@@ -669,18 +1243,20 @@ func runRestoreBackup(cmd *cobra.Command, args []string) error {
 		fmt.Printf("    Created: %s\n\n", backup.ModTime.Format("2006-01-02 15:04:05"))
 	}
 
+	if !canPrompt() {
+		return errNoTerminal("Picking a backup", "run restore-backup in a terminal")
+	}
+
 	// Interactive selection
-	fmt.Println("────────────────────────────────────────")
-	fmt.Println("Enter the number of the backup to restore")
-	fmt.Println("Press Enter to cancel")
-	fmt.Print("\nSelection: ")
+	fmt.Println(rule())
+	fmt.Println(i18n.T(i18n.SelectBackupHeader))
+	fmt.Println(i18n.T(i18n.SelectBackupCancel))
+	fmt.Print("\n" + i18n.T(i18n.PromptSelection))
 
-	reader := bufio.NewReader(os.Stdin)
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimSpace(input)
+	input := readLine()
 
 	if input == "" {
-		fmt.Println("\nCancelled.")
+		fmt.Println("\n" + i18n.T(i18n.Cancelled))
 		return nil
 	}
 
@@ -692,62 +1268,471 @@ func runRestoreBackup(cmd *cobra.Command, args []string) error {
 
 	selectedBackup := backups[selection-1]
 
+	check := steam.VerifyBackup(selectedBackup)
+	if check.Status == steam.BackupDamaged {
+		// Malformed files are never written to localconfig.vdf
+		return fmt.Errorf("can't restore %s, it is damaged: %w", selectedBackup.Name, check.Err)
+	}
+	if check.Err != nil {
+		fmt.Printf("\nWARNING: %s is %s: %v\n", selectedBackup.Name, check.Status, check.Err)
+		fmt.Print("Restore it anyway? [y/N]: ")
+		if assumeYes {
+			fmt.Println("yes (--yes)")
+		} else if !i18n.IsYes(readLine()) {
+			return fmt.Errorf("%s", i18n.T(i18n.ErrAbortedRestore))
+		}
+	}
+
+	if dryRun {
+		return printRestorePreview(localConfigPath, selectedBackup.Path)
+	}
+
 	// Check if Steam is running
-	steamRunning, err := steam.IsSteamRunning()
+	steamRunning, err := isSteamRunning()
 	if err != nil {
-		fmt.Printf("Warning: Could not check if Steam is running: %v\n", err)
+		fmt.Println(i18n.T(i18n.SteamCheckFailed, err))
 	} else if steamRunning {
-		fmt.Println("\nWARNING: Steam is currently running!")
-		fmt.Println("Steam must be closed before restoring a backup.")
-		fmt.Print("\nClose Steam and restore? (Y/n): ")
+		fmt.Println("\n" + i18n.T(i18n.SteamRunning))
+		fmt.Println(i18n.T(i18n.SteamMustCloseRestore))
+		if !canPrompt() && !assumeYes {
+			return errNoTerminal("Closing Steam", "close Steam first or use --yes")
+		}
 
-		response, _ := reader.ReadString('\n')
-		response = strings.ToLower(strings.TrimSpace(response))
+		if !confirm("\n" + i18n.T(i18n.PromptCloseAndRestore)) {
+			return fmt.Errorf("%s", i18n.T(i18n.ErrAbortedRestore))
+		}
 
-		if response != "" && response != "y" && response != "yes" {
-			return fmt.Errorf("aborted - Steam must be closed to restore backup")
+		if err := closeSteamAndWait(); err != nil {
+			return err
 		}
+	}
 
-		fmt.Println("Closing Steam...")
-		if err := steam.CloseSteam(); err != nil {
-			return fmt.Errorf("failed to close Steam: %w", err)
+	// Restore the backup
+	fmt.Println("\n" + i18n.T(i18n.RestoringBackup, selectedBackup.Name))
+	replacedBackup, err := steam.RestoreBackup(selectedBackup.Path, localConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+
+	fmt.Println(i18n.T(i18n.RestoreSuccess))
+	if replacedBackup != "" {
+		fmt.Println(i18n.T(i18n.BackupCreated, replacedBackup))
+	}
+	notifyWebhook("restore", "", nil, "", "restored "+selectedBackup.Path)
+	return nil
+}
+
+// printRestorePreview shows what restoring a backup would change: the launch options of each
+// game before and after, and with --diff a unified diff of the whole file
+func printRestorePreview(localConfigPath, backupPath string) error {
+	current, err := steam.GetAppValues(localConfigPath, steam.KeyLaunchOptions)
+	if err != nil {
+		return err
+	}
+	restored, err := steam.GetAppValues(backupPath, steam.KeyLaunchOptions)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	names := libraryNamesOrEmpty()
+
+	changed := changedAppValues(current, restored)
+	if len(changed) == 0 {
+		fmt.Println("\n[DRY RUN] Restoring wouldn't change any launch options")
+	} else {
+		fmt.Printf("\n[DRY RUN] Restoring would change launch options for %d games:\n", len(changed))
+		for _, appID := range changed {
+			fmt.Printf("  - %s: %q -> %q\n", gameLabel(appID, names), current[appID], restored[appID])
+		}
+	}
+
+	if showDiff {
+		before, err := os.ReadFile(localConfigPath)
+		if err != nil {
+			return err
+		}
+		after, err := os.ReadFile(backupPath)
+		if err != nil {
+			return fmt.Errorf("failed to read backup: %w", err)
+		}
+		if d := diff.Unified(localConfigPath, backupPath, before, after, 3); d != "" {
+			fmt.Println()
+			fmt.Print(d)
+		} else {
+			fmt.Println("\nNo changes to localconfig.vdf")
+		}
+	}
+
+	fmt.Println("\n[DRY RUN] No files were changed")
+	return nil
+}
+
+// changedAppValues returns the apps whose values differ between two localconfig.vdf files,
+// sorted. Apps missing from one of them have no value there
+func changedAppValues(from, to map[string]string) []string {
+	var changed []string
+	for appID, value := range from {
+		if to[appID] != value {
+			changed = append(changed, appID)
+		}
+	}
+	for appID, value := range to {
+		if _, ok := from[appID]; !ok && value != "" {
+			changed = append(changed, appID)
 		}
+	}
+	steam.SortAppIDs(changed)
+	return changed
+}
 
-		// Wait for Steam to close
-		fmt.Print("Waiting for Steam to close")
-		for i := 0; i < 10; i++ {
-			time.Sleep(1 * time.Second)
-			fmt.Print(".")
-			running, _ := steam.IsSteamRunning()
-			if !running {
-				break
+// detectSteamPath finds the Steam install to use. If there are several, --steam-flavor
+// picks one, otherwise the user chooses (defaulting to the most recently used). If there
+// are none in the usual locations, the install of a running Steam client is used
+func detectSteamPath() (string, error) {
+	if remoteHost != nil {
+		return fetchRemote()
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return "", err
+	}
+	installs, err := steam.FindInstalls(cfg.SteamPaths)
+	if err != nil {
+		return "", err
+	}
+
+	if steamFlavor != "" {
+		var matching []steam.Install
+		for _, install := range installs {
+			if install.Flavor == steamFlavor {
+				matching = append(matching, install)
+			}
+		}
+		if len(matching) == 0 {
+			found := make([]string, len(installs))
+			for i, install := range installs {
+				found[i] = describeInstall(install)
 			}
+			if len(found) == 0 {
+				found = []string{"none"}
+			}
+			return "", fmt.Errorf("no %s Steam install found (valid flavors: %s; found: %s)",
+				steamFlavor, strings.Join(steam.Flavors, ", "), strings.Join(found, "; "))
 		}
-		fmt.Println(" done!")
+		installs = matching
+	}
 
-		// Verify Steam is closed
-		stillRunning, _ := steam.IsSteamRunning()
-		if stillRunning {
-			return fmt.Errorf("Steam is still running - please close it manually")
+	switch len(installs) {
+	case 0:
+		// A running client may have been installed somewhere unusual
+		if path := steam.SteamPathFromProcess(); path != "" {
+			fmt.Printf("Using the Steam install of the running client: %s\n", path)
+			return path, nil
 		}
+		// Explains where Steam was expected
+		return steam.GetSteamPath()
+	case 1:
+		return installs[0].Path, nil
 	}
 
-	// Restore the backup
-	fmt.Printf("\nRestoring %s...\n", selectedBackup.Name)
-	if err := steam.RestoreBackup(selectedBackup.Path, localConfigPath); err != nil {
-		return fmt.Errorf("failed to restore backup: %w", err)
+	fmt.Println("Found several Steam installs:")
+	for i, install := range installs {
+		fmt.Printf("  %d. %s\n", i+1, describeInstall(install))
+	}
+	if !canPrompt() {
+		fmt.Printf("Using %s (pick another with --steam-flavor or --steam-path)\n", installs[0].Path)
+		return installs[0].Path, nil
+	}
+	fmt.Printf("Which one? [1-%d] (default 1; skip this with --steam-flavor or --steam-path): ", len(installs))
+	input := readLine()
+	if input == "" {
+		return installs[0].Path, nil
+	}
+	choice, err := strconv.Atoi(input)
+	if err != nil || choice < 1 || choice > len(installs) {
+		return "", fmt.Errorf("invalid choice %q", input)
+	}
+	return installs[choice-1].Path, nil
+}
+
+// loadConfig reads gsca's config file; without a config directory there are no settings
+func loadConfig() (*config.Config, error) {
+	path, err := config.DefaultPath()
+	if err != nil {
+		return &config.Config{}, nil
+	}
+	return config.Load(path)
+}
+
+// expandArgAlias replaces an @name launch options value with its definition in the config file
+func expandArgAlias(args string) (string, error) {
+	if !strings.HasPrefix(strings.TrimSpace(args), "@") {
+		return args, nil
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return "", err
+	}
+	return cfg.ExpandArgs(args)
+}
+
+// expandAliases replaces the entries that are aliases in the config file with their app IDs
+func expandAliases(items []string) []string {
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: aliases not loaded: %v\n", err)
+		return items
+	}
+	expanded := make([]string, len(items))
+	for i, item := range items {
+		expanded[i] = item
+		if appID, ok := cfg.Alias(item); ok {
+			expanded[i] = appID
+		}
+	}
+	return expanded
+}
+
+// describeInstall summarizes an install and its user data on one line
+func describeInstall(install steam.Install) string {
+	if install.Users == 0 {
+		return fmt.Sprintf("[%s] %s (no user data)", install.Flavor, install.Path)
+	}
+	return fmt.Sprintf("[%s] %s (%d user(s), last used %s)",
+		install.Flavor, install.Path, install.Users, install.LastUsed.Format("2006-01-02 15:04"))
+}
+
+// detectLocalConfigPath fills in steamPath and userID (unless overridden by flags)
+// and returns the path to the user's localconfig.vdf
+func detectLocalConfigPath() (string, error) {
+	var err error
+	if steamPath == "" {
+		steamPath, err = detectSteamPath()
+		if err != nil {
+			return "", fmt.Errorf("failed to detect Steam path: %w", err)
+		}
+	}
+
+	if userID == "" {
+		userID, err = detectUserID(steamPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to detect user ID: %w", err)
+		}
+	}
+
+	return steam.GetLocalConfigPath(steamPath, userID), nil
+}
+
+// detectUserID returns the account set as user_id in the config file, or else the one that
+// signed in last. A configured account without userdata in this install is ignored, since
+// it may belong to another install
+func detectUserID(steamPath string) (string, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return "", err
+	}
+	if cfg.UserID != "" {
+		if info, err := os.Stat(filepath.Join(steamPath, "userdata", cfg.UserID)); err == nil && info.IsDir() {
+			return cfg.UserID, nil
+		}
+	}
+	return steam.GetUserID(steamPath)
+}
+
+// stdin is shared by all prompts so buffered input isn't lost between reads
+var stdin = bufio.NewReader(os.Stdin)
+
+// readLine reads a line of user input with surrounding whitespace removed
+func readLine() string {
+	line, _ := readAnswer()
+	return line
+}
+
+// readAnswer is readLine, also reporting whether anything answered: false when the input
+// ended without even an empty line
+func readAnswer() (string, bool) {
+	input, err := stdin.ReadString('\n')
+	return strings.TrimSpace(input), err == nil || input != ""
+}
+
+// canPrompt reports whether someone can answer prompts: stdin and stdout are both
+// terminals, not pipes, files, or a cron job
+func canPrompt() bool {
+	return isTerminal(os.Stdin) && isTerminal(os.Stdout)
+}
+
+// isTerminal reports whether f is a terminal (a console on Windows). Other character
+// devices, like /dev/null, aren't
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// errNoTerminal explains that a step needed an answer that can't be asked for, and how to avoid it
+func errNoTerminal(step, hint string) error {
+	return fmt.Errorf("%s needs an answer, but gsca isn't running in a terminal; %s", step, hint)
+}
+
+// confirm prints a Y/n prompt and reports whether the answer was yes (the default)
+// --yes answers it; otherwise, without a terminal or once the input ended nobody can answer,
+// so the answer is no
+func confirm(prompt string) bool {
+	fmt.Print(prompt)
+	if assumeYes {
+		fmt.Println("yes (--yes)")
+		return true
+	}
+	if !canPrompt() {
+		fmt.Println("no (not running in a terminal)")
+		return false
+	}
+	response, answered := readAnswer()
+	if !answered {
+		fmt.Println("no (end of input)")
+		return false
+	}
+	return response == "" || i18n.IsYes(response)
+}
+
+// closeSteamAndWait closes Steam and waits up to 10 seconds for it to exit
+func closeSteamAndWait() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	fmt.Println(i18n.T(i18n.ClosingSteam))
+	if remoteHost != nil {
+		err = remoteHost.CloseSteam()
+	} else {
+		err = steam.CloseSteamWith(steam.StopOptions{Executable: cfg.SteamExecutable, Command: cfg.StopCommand})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to close Steam: %w", err)
+	}
+
+	// Wait for Steam to fully close
+	fmt.Print(i18n.T(i18n.WaitingForSteam))
+	for i := 0; i < 10; i++ {
+		time.Sleep(1 * time.Second)
+		fmt.Print(".")
+		running, _ := isSteamRunning()
+		if !running {
+			break
+		}
+	}
+	fmt.Println(i18n.T(i18n.WaitDone))
+
+	// Verify Steam is closed
+	stillRunning, _ := isSteamRunning()
+	if stillRunning {
+		return fmt.Errorf("%s", i18n.T(i18n.ErrSteamStillRunning))
+	}
+
+	// Steam saved its settings as it exited, so an earlier copy of them is stale
+	if err := refetchRemote(); err != nil {
+		return err
 	}
 
-	fmt.Println("Backup restored successfully!")
 	return nil
 }
 
-// parseSelection parses user input like "1,3,5", "1-3", or "*" into indices
+// printMatches prints matches[start:end], numbered from start+1
+func printMatches(matches []steam.GameInfo, start, end int) {
+	for i := start; i < end; i++ {
+		game := matches[i]
+		fmt.Printf("[%d] %s\n", i+1, game.Name)
+		fmt.Printf("    App ID: %s\n", game.AppID)
+		if game.InstallState != steam.InstallStateInstalled {
+			fmt.Printf("    State: %s\n", game.InstallState)
+		}
+		if game.SharedBy != "" {
+			fmt.Printf("    Family Sharing: borrowed from %s\n", accountLabel(game.SharedBy))
+		}
+
+		if game.LaunchOptions != "" {
+			fmt.Printf("    Launch Options: %s\n", game.LaunchOptions)
+		} else {
+			fmt.Printf("    Launch Options: (none)\n")
+		}
+		fmt.Println()
+	}
+}
+
+// selectMatches shows matches a page of limit at a time (all of them if limit is 0) and
+// returns the selection entered, moving between pages on n and p
+func selectMatches(matches []steam.GameInfo, limit int) string {
+	if limit <= 0 || limit > len(matches) {
+		limit = len(matches)
+	}
+	pages := (len(matches) + limit - 1) / limit
+
+	for page := 0; ; {
+		start := page * limit
+		end := min(start+limit, len(matches))
+		printMatches(matches, start, end)
+
+		fmt.Println(rule())
+		fmt.Println(i18n.T(i18n.SelectGamesHeader))
+		fmt.Println("  " + bullet + " " + i18n.T(i18n.SelectHintNumbers))
+		fmt.Println("  " + bullet + " " + i18n.T(i18n.SelectHintAll))
+		if pages > 1 {
+			fmt.Println("  " + bullet + " " + i18n.T(i18n.SelectHintPages, start+1, end, len(matches)))
+		}
+		fmt.Println("  " + bullet + " " + i18n.T(i18n.SelectHintSkip))
+		fmt.Print("\n" + i18n.T(i18n.PromptSelection))
+
+		input := readLine()
+		switch {
+		case pages > 1 && strings.EqualFold(input, "n"):
+			page = (page + 1) % pages
+		case pages > 1 && strings.EqualFold(input, "p"):
+			page = (page + pages - 1) % pages
+		default:
+			return input
+		}
+		fmt.Println()
+	}
+}
+
+// parseSelection parses user input like "1,3,5", "1-3", or "*" into indices. Parts
+// starting with ^ (e.g. "*,^3" or "1-10,^5-7") exclude items; exclusions alone exclude
+// from every item
 func parseSelection(input string, max int) []int {
-	input = strings.TrimSpace(input)
+	var include, exclude []int
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		if strings.HasPrefix(part, "^") {
+			exclude = append(exclude, parseSelectionPart(strings.TrimPrefix(part, "^"), max)...)
+		} else {
+			include = append(include, parseSelectionPart(part, max)...)
+		}
+	}
+	if len(include) == 0 && len(exclude) > 0 {
+		include = parseSelectionPart("*", max)
+	}
+
+	excluded := make(map[int]bool, len(exclude))
+	for _, i := range exclude {
+		excluded[i] = true
+	}
+
+	var indices []int
+	seen := make(map[int]bool)
+	for _, i := range include {
+		if !seen[i] && !excluded[i] {
+			indices = append(indices, i)
+			seen[i] = true
+		}
+	}
+	return indices
+}
+
+// parseSelectionPart parses one part of a selection ("3", "1-5", or "*") into indices,
+// returning nil if it is invalid or out of range
+func parseSelectionPart(part string, max int) []int {
+	part = strings.TrimSpace(part)
 
 	// Check for wildcard - select all
-	if input == "*" {
+	if part == "*" {
 		indices := make([]int, max)
 		for i := 0; i < max; i++ {
 			indices[i] = i
@@ -755,58 +1740,372 @@ func parseSelection(input string, max int) []int {
 		return indices
 	}
 
-	var indices []int
-	seen := make(map[int]bool)
+	// Check for range (e.g., "1-3")
+	if strings.Contains(part, "-") {
+		rangeParts := strings.Split(part, "-")
+		if len(rangeParts) != 2 {
+			return nil
+		}
+		start, err1 := strconv.Atoi(strings.TrimSpace(rangeParts[0]))
+		end, err2 := strconv.Atoi(strings.TrimSpace(rangeParts[1]))
+		if err1 != nil || err2 != nil || start <= 0 || end > max || start > end {
+			return nil
+		}
+		var indices []int
+		for i := start; i <= end; i++ {
+			indices = append(indices, i-1)
+		}
+		return indices
+	}
 
-	parts := strings.Split(input, ",")
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
+	// Single number
+	num, err := strconv.Atoi(part)
+	if err != nil || num <= 0 || num > max {
+		return nil
+	}
+	return []int{num - 1}
+}
 
-		// Check for range (e.g., "1-3")
-		if strings.Contains(part, "-") {
-			rangeParts := strings.Split(part, "-")
-			if len(rangeParts) == 2 {
-				start, err1 := strconv.Atoi(strings.TrimSpace(rangeParts[0]))
-				end, err2 := strconv.Atoi(strings.TrimSpace(rangeParts[1]))
-
-				if err1 == nil && err2 == nil && start > 0 && end <= max && start <= end {
-					for i := start; i <= end; i++ {
-						if !seen[i-1] {
-							indices = append(indices, i-1)
-							seen[i-1] = true
-						}
-					}
-				}
+// printProgress returns a ProgressFunc that renders a single-line counter after label. When
+// stdout isn't a terminal only the final count is printed
+func printProgress(label string) steam.ProgressFunc {
+	return func(done, total int) {
+		if !isTerminal(os.Stdout) {
+			if done == total {
+				fmt.Printf("%s %d/%d\n", label, done, total)
 			}
+			return
+		}
+		fmt.Printf("\r%s %d/%d", label, done, total)
+		if done == total {
+			fmt.Println()
+		}
+	}
+}
+
+// validateSelectionFlags checks that at most one of --all, --allow, --deny, --apps, or --favorites
+// was given, and that games are selected somehow (store filters like --genre alone imply --all)
+func validateSelectionFlags() error {
+	selectors := 0
+	for _, set := range []bool{updateAll, allowFile != "", denyFile != "", len(appIDList) > 0, favoritesOnly} {
+		if set {
+			selectors++
+		}
+	}
+
+	switch {
+	case selectors == 0 && publisher == "" && developer == "" && genre == "":
+		return fmt.Errorf("must specify --all, --allow, --deny, --apps, --favorites, --publisher, --developer, or --genre flag")
+	case selectors > 1:
+		return fmt.Errorf("only one of --all, --allow, --deny, --apps, or --favorites can be used")
+	}
+	return nil
+}
+
+// parseExpiry parses an --expires period: a Go duration (90m, 12h) or a number of days or
+// weeks (7d, 2w)
+func parseExpiry(s string) (time.Duration, error) {
+	units := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	var d time.Duration
+	var err error
+	if unit, ok := units[s[len(s)-1]]; ok {
+		var n int
+		n, err = strconv.Atoi(s[:len(s)-1])
+		d = time.Duration(n) * unit
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid --expires %q (use e.g. 7d, 2w, or 12h)", s)
+	}
+	return d, nil
+}
+
+// closeSteamBeforeWrite closes Steam if it is running, asking first unless --force was given
+// Returns true if Steam was closed and should be restarted afterwards
+func closeSteamBeforeWrite() (bool, error) {
+	steamRunning, err := isSteamRunning()
+	if err != nil {
+		fmt.Println(i18n.T(i18n.SteamCheckFailed, err))
+		return false, nil
+	}
+	if !steamRunning {
+		return false, nil
+	}
+
+	if autoCloseSteam || assumeYes {
+		// Force mode - automatically close Steam
+		fmt.Println(i18n.T(i18n.SteamForceClosing))
+	} else {
+		// Interactive mode - ask user
+		fmt.Println("\n" + i18n.T(i18n.SteamRunning))
+		fmt.Println(i18n.T(i18n.SteamOverwritesConfig))
+		if !canPrompt() {
+			return false, errNoTerminal("Closing Steam", "use --force or --yes to close it without asking")
+		}
+
+		if !confirm("\n" + i18n.T(i18n.PromptCloseAndApply)) {
+			return false, fmt.Errorf("%s", i18n.T(i18n.ErrAbortedApply))
+		}
+	}
+
+	if err := closeSteamAndWait(); err != nil {
+		return false, err
+	}
+
+	fmt.Println()
+	return true, nil
+}
+
+// printUpdateSummary reports how many apps changed, were already up to date, or failed,
+// and where the backup went
+func printUpdateSummary(result *steam.UpdateResult) {
+	fmt.Print("\n" + i18n.T(i18n.UpdateSuccess, result.Count(steam.UpdateChanged)))
+	if unchanged := result.Count(steam.UpdateUnchanged); unchanged > 0 {
+		fmt.Printf(" (%d already up to date)", unchanged)
+	}
+	fmt.Println()
+	if failed := result.Count(steam.UpdateFailed); failed > 0 {
+		fmt.Printf("WARNING: %d game(s) failed to update\n", failed)
+	}
+	if result.BackupPath != "" {
+		fmt.Println(i18n.T(i18n.BackupCreated, result.BackupPath))
+	}
+}
+
+// restartSteam starts Steam again after it was closed for a write, unless --no-restart was given
+func restartSteam() {
+	if noRestart {
+		fmt.Println("\nLeaving Steam closed (--no-restart)")
+		return
+	}
+
+	fmt.Println("\n" + i18n.T(i18n.RestartingSteam))
+	if remoteHost != nil {
+		restartRemoteSteam()
+		return
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Warning: %v; starting Steam the usual way\n", err)
+		cfg = &config.Config{}
+	}
+	opts := steam.StartOptions{
+		BigPicture: restartBigPicture,
+		Minimized:  restartMinimized,
+		Args:       strings.Fields(restartArgs),
+		SteamPath:  steamPath,
+		Executable: cfg.SteamExecutable,
+		Command:    cfg.StartCommand,
+	}
+	if err := steam.StartSteamWith(opts); err != nil {
+		fmt.Println(i18n.T(i18n.SteamStartFailed, err))
+		fmt.Println(i18n.T(i18n.StartSteamManually))
+		return
+	}
+	fmt.Println(i18n.T(i18n.SteamStarted))
+
+	if restartWait > 0 {
+		fmt.Println("Waiting for Steam to log in...")
+		if err := steam.WaitForSteam(restartWait, time.Second); err != nil {
+			fmt.Printf("Warning: %v\n", err)
 		} else {
-			// Single number
-			num, err := strconv.Atoi(part)
-			if err == nil && num > 0 && num <= max {
-				if !seen[num-1] {
-					indices = append(indices, num-1)
-					seen[num-1] = true
-				}
+			fmt.Println("Steam is ready")
+		}
+	}
+}
+
+// selectTargetGameIDs narrows allGameIDs using the --apps, --favorites, --allow, or --deny selector, if any
+func selectTargetGameIDs(allGameIDs []string, library *steam.Library) ([]string, error) {
+	if favoritesOnly {
+		// Favorites are per user, so make sure we know which one
+		if userID == "" {
+			var err error
+			if userID, err = detectUserID(steamPath); err != nil {
+				return nil, fmt.Errorf("failed to detect user ID: %w", err)
 			}
 		}
+
+		sharedConfig, err := steam.LoadSharedConfig(steam.GetSharedConfigPath(steamPath, userID))
+		if err != nil {
+			return nil, err
+		}
+		favorites := sharedConfig.AppsWithTag(steam.TagFavorite)
+		fmt.Printf("Found %d favorite games\n", len(favorites))
+		if len(favorites) == 0 {
+			// An empty allow list would mean "no filter"
+			return nil, nil
+		}
+		return steam.FilterGameIDs(allGameIDs, favorites, nil), nil
 	}
 
-	return indices
+	if len(appIDList) > 0 {
+		resolvedIDs, notFound := steam.ResolveGameIDs(expandAliases(appIDList), library)
+		if len(notFound) > 0 {
+			return nil, fmt.Errorf("--apps only accepts numeric app IDs, got: %s", strings.Join(notFound, ", "))
+		}
+		return steam.FilterGameIDs(allGameIDs, resolvedIDs, nil), nil
+	}
+
+	if allowFile != "" {
+		resolvedIDs, err := loadAndResolveFilterList(allowFile, "allow", library, ignoreMissing)
+		if err != nil {
+			return nil, err
+		}
+		return steam.FilterGameIDs(allGameIDs, resolvedIDs, nil), nil
+	}
+
+	if denyFile != "" {
+		resolvedIDs, err := loadAndResolveFilterList(denyFile, "deny", library, ignoreMissing)
+		if err != nil {
+			return nil, err
+		}
+		return steam.FilterGameIDs(allGameIDs, nil, resolvedIDs), nil
+	}
+
+	// No filter - all games
+	return allGameIDs, nil
+}
+
+// excludeHiddenGames drops games hidden in the Steam library unless --include-hidden is set.
+// Games named with --apps or an allow list are kept, hidden or not
+func excludeHiddenGames(appIDs []string, session *steam.Session) ([]string, error) {
+	if includeHidden || len(appIDList) > 0 || allowFile != "" {
+		return appIDs, nil
+	}
+
+	sharedConfig, err := session.SharedConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []string
+	for _, appID := range appIDs {
+		if !sharedConfig.IsHidden(appID) {
+			kept = append(kept, appID)
+		}
+	}
+
+	if skipped := len(appIDs) - len(kept); skipped > 0 {
+		fmt.Printf("Skipping %d hidden game(s) (use --include-hidden to include them)\n", skipped)
+	}
+
+	return kept, nil
+}
+
+// filterByStoreMetadata applies --vr-only, --no-vr, --publisher, --developer, and --genre using
+// Steam's appinfo cache. Apps missing from the cache are treated as non-VR with no publisher or genre
+func filterByStoreMetadata(appIDs []string) ([]string, error) {
+	if !vrOnly && !noVR && publisher == "" && developer == "" && genre == "" {
+		return appIDs, nil
+	}
+
+	infos, err := steam.LoadAppInfo(steam.GetAppInfoPath(steamPath), appIDs)
+	if err != nil {
+		return nil, fmt.Errorf("filtering by store metadata needs Steam's appinfo cache: %w", err)
+	}
+
+	var kept []string
+	for _, appID := range appIDs {
+		info := infos[appID]
+		switch {
+		case (vrOnly || noVR) && info.VRSupport != vrOnly:
+		case publisher != "" && !steam.MadeBy(info.Publishers, publisher):
+		case developer != "" && !steam.MadeBy(info.Developers, developer):
+		case genre != "" && !info.HasGenre(genre):
+		default:
+			kept = append(kept, appID)
+		}
+	}
+
+	if skipped := len(appIDs) - len(kept); skipped > 0 {
+		fmt.Printf("Skipping %d game(s) not matching the store filters\n", skipped)
+	}
+
+	return kept, nil
 }
 
-// isSteamTool checks if a game name is a Steam tool (Proton, Runtime, etc.)
-func isSteamTool(name string) bool {
-	return strings.Contains(name, "Proton") || strings.Contains(name, "Runtime")
+// warnOfflineLibraries reports library folders that were skipped because they can't be read
+func warnOfflineLibraries() {
+	for _, offline := range steam.OfflineLibraries(steamPath) {
+		fmt.Printf("Warning: Skipped library %s (not mounted or not responding); its %d game(s) are unavailable\n", offline.Path, len(offline.AppIDs))
+	}
+}
+
+// installStateFilter returns the states allowed by --install-state, or nil if it wasn't given
+func installStateFilter() (map[string]bool, error) {
+	if installState == "" {
+		return nil, nil
+	}
+	return steam.ParseInstallStates(installState)
+}
+
+// filterByInstallState applies --install-state; uninstalled games only match "unavailable", if they are in an offline library
+func filterByInstallState(appIDs []string, library *steam.Library) ([]string, error) {
+	states, err := installStateFilter()
+	if err != nil || states == nil {
+		return appIDs, err
+	}
+
+	// Games in offline libraries aren't in the library, which only has readable appmanifests
+	unavailable := make(map[string]bool)
+	if states[steam.InstallStateUnavailable] {
+		for _, offline := range steam.OfflineLibraries(steamPath) {
+			for _, appID := range offline.AppIDs {
+				unavailable[appID] = true
+			}
+		}
+	}
+
+	var kept []string
+	for _, appID := range appIDs {
+		game, found := library.LookupByID(appID)
+		if unavailable[appID] || found && states[game.State] {
+			kept = append(kept, appID)
+		}
+	}
+
+	if skipped := len(appIDs) - len(kept); skipped > 0 {
+		fmt.Printf("Skipping %d game(s) not in install state %s\n", skipped, installState)
+	}
+	return kept, nil
+}
+
+// filterGameInfosByStoreMetadata is filterByStoreMetadata for a list of games
+func filterGameInfosByStoreMetadata(games []steam.GameInfo) ([]steam.GameInfo, error) {
+	appIDs := make([]string, len(games))
+	for i, game := range games {
+		appIDs[i] = game.AppID
+	}
+
+	kept, err := filterByStoreMetadata(appIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := make(map[string]bool, len(kept))
+	for _, appID := range kept {
+		keep[appID] = true
+	}
+
+	var filtered []steam.GameInfo
+	for _, game := range games {
+		if keep[game.AppID] {
+			filtered = append(filtered, game)
+		}
+	}
+	return filtered, nil
 }
 
 // loadAndResolveFilterList loads a filter list file and resolves game IDs
-func loadAndResolveFilterList(filePath, listType string, mapping map[string]string, ignoreMissing bool) ([]string, error) {
+func loadAndResolveFilterList(filePath, listType string, library *steam.Library, ignoreMissing bool) ([]string, error) {
 	fmt.Printf("Loading %s list from: %s\n", listType, filePath)
 	items, err := steam.LoadFilterList(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load %s list: %w", listType, err)
 	}
 
-	resolvedIDs, notFound := steam.ResolveGameIDs(items, mapping)
+	resolvedIDs, notFound := steam.ResolveGameIDs(expandAliases(items), library)
 	if len(notFound) > 0 {
 		fmt.Printf("\nERROR: Invalid entries in %s list (%d non-numeric entries):\n", listType, len(notFound))
 		for _, item := range notFound {
@@ -828,7 +2127,22 @@ func loadAndResolveFilterList(filePath, listType string, mapping map[string]stri
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
+	restoreConsole := setupConsole()
+
+	// Unknown subcommands are dispatched to gsca-<name> executables on PATH
+	if pluginPath, ok := findPlugin(os.Args[1:]); ok {
+		code := runPlugin(pluginPath, os.Args[2:])
+		restoreConsole()
+		os.Exit(code)
+	}
+
+	err := rootCmd.Execute()
+	if pushErr := pushRemote(); pushErr != nil && err == nil {
+		err = pushErr
+	}
+	writeAuditLog()
+	restoreConsole()
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
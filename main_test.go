@@ -1,8 +1,22 @@
 package main
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/zerkz/gsca/anticheat"
+	"github.com/zerkz/gsca/config"
+	"github.com/zerkz/gsca/steam"
+	"github.com/zerkz/gsca/steam/steamtest"
 )
 
 func TestParseSelection(t *testing.T) {
@@ -90,6 +104,30 @@ func TestParseSelection(t *testing.T) {
 			want:  []int{0},
 			max:   10,
 		},
+		{
+			name:  "exclusion from wildcard",
+			input: "*,^2,^4-5",
+			want:  []int{0, 2, 5},
+			max:   6,
+		},
+		{
+			name:  "exclusion from range",
+			input: "^5-7, 1-10",
+			want:  []int{0, 1, 2, 3, 7, 8, 9},
+			max:   10,
+		},
+		{
+			name:  "exclusions alone exclude from everything",
+			input: "^1,^3",
+			want:  []int{1, 3},
+			max:   4,
+		},
+		{
+			name:  "everything excluded",
+			input: "1-3,^1-3",
+			want:  nil,
+			max:   10,
+		},
 	}
 
 	for _, tt := range tests {
@@ -169,3 +207,909 @@ func TestParseSelectionEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestFindPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin fixture uses a shell script")
+	}
+
+	binDir := t.TempDir()
+	pluginPath := filepath.Join(binDir, "gsca-hello")
+	if err := os.WriteFile(pluginPath, []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+		t.Fatalf("Failed to create plugin: %v", err)
+	}
+	// Built-in commands take precedence over plugins with the same name
+	if err := os.WriteFile(filepath.Join(binDir, "gsca-update"), []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+		t.Fatalf("Failed to create plugin: %v", err)
+	}
+	t.Setenv("PATH", binDir)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantPath string
+		wantOK   bool
+	}{
+		{name: "plugin on PATH", args: []string{"hello", "--flag"}, wantPath: pluginPath, wantOK: true},
+		{name: "built-in command", args: []string{"update"}, wantOK: false},
+		{name: "missing plugin", args: []string{"missing"}, wantOK: false},
+		{name: "flag", args: []string{"--help"}, wantOK: false},
+		{name: "no args", args: nil, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotPath, gotOK := findPlugin(tt.args)
+			if gotOK != tt.wantOK || gotPath != tt.wantPath {
+				t.Errorf("findPlugin() = %v, %v, want %v, %v", gotPath, gotOK, tt.wantPath, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestValidateSelectionFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		all       bool
+		allow     string
+		deny      string
+		apps      []string
+		favorites bool
+		wantErr   bool
+	}{
+		{name: "all", all: true},
+		{name: "allow", allow: "games.txt"},
+		{name: "favorites", favorites: true},
+		{name: "apps", apps: []string{"570"}},
+		{name: "nothing", wantErr: true},
+		{name: "allow and deny", allow: "a.txt", deny: "d.txt", wantErr: true},
+		{name: "all and favorites", all: true, favorites: true, wantErr: true},
+		{name: "apps and allow", apps: []string{"570"}, allow: "a.txt", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updateAll, allowFile, denyFile, appIDList, favoritesOnly = tt.all, tt.allow, tt.deny, tt.apps, tt.favorites
+			t.Cleanup(func() {
+				updateAll, allowFile, denyFile, appIDList, favoritesOnly = false, "", "", nil, false
+			})
+
+			if err := validateSelectionFlags(); (err != nil) != tt.wantErr {
+				t.Errorf("validateSelectionFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseGroup(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    updateGroup
+		wantErr bool
+	}{
+		{
+			name: "allow",
+			spec: "allow=linux.txt,args=gamemoderun %command%",
+			want: updateGroup{allowFile: "linux.txt", args: "gamemoderun %command%"},
+		},
+		{
+			name: "quoted args with commas",
+			spec: `deny=skip.txt,args="-foo,bar %command%"`,
+			want: updateGroup{denyFile: "skip.txt", args: "-foo,bar %command%"},
+		},
+		{
+			name: "quoted apps",
+			spec: `apps='570,730',args=`,
+			want: updateGroup{appIDs: []string{"570", "730"}},
+		},
+		{name: "missing args", spec: "allow=linux.txt", wantErr: true},
+		{name: "missing selector", spec: "args=-novid", wantErr: true},
+		{name: "two selectors", spec: "allow=a.txt,deny=d.txt,args=-novid", wantErr: true},
+		{name: "unknown key", spec: "allow=a.txt,args=-novid,user=1", wantErr: true},
+		{name: "unquoted comma", spec: "allow=a.txt,args=-foo,bar", wantErr: true},
+		{name: "unterminated quote", spec: `allow=a.txt,args="-novid`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGroup(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGroup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			tt.want.spec = tt.spec
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseGroup() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupsUpdateAliases(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("APPDATA", home)
+	path, err := config.DefaultPath()
+	if err != nil {
+		t.Skip(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"aliases": {"mygame": "730"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	update, err := groupsUpdate([]updateGroup{{spec: "apps=mygame,args=-high", appIDs: []string{"mygame"}, args: "-high"}})
+	if err != nil {
+		t.Fatalf("groupsUpdate() error = %v", err)
+	}
+	values, err := update.values([]string{"570", "730"})
+	if want := []steam.AppValue{{AppID: "730", Value: "-high"}}; err != nil || !reflect.DeepEqual(values, want) {
+		t.Errorf("groupsUpdate() values = %v, %v; want %v", values, err, want)
+	}
+}
+
+func TestLaunchOptionDiffs(t *testing.T) {
+	a := map[string]string{"570": "-novid", "730": "-high", "440": ""}
+	b := map[string]string{"570": "-novid", "730": "", "1245620": "-dx11"}
+
+	tests := []struct {
+		name string
+		apps []string
+		want []string
+	}{
+		{"all apps", nil, []string{"730", "1245620"}},
+		{"selected apps", []string{"1245620", "570", "1245620"}, []string{"1245620"}},
+		{"unknown app", []string{"10"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := launchOptionDiffs(a, b, tt.apps); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("launchOptionDiffs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCopiedLaunchOptions(t *testing.T) {
+	from := map[string]string{"570": "-novid", "730": "", "440": "-dx9"}
+	differing := []string{"440", "570", "730"}
+	owned := []string{"570", "730"}
+
+	tests := []struct {
+		name  string
+		named bool
+		want  []steam.AppValue
+	}{
+		{"all apps", false, []steam.AppValue{{AppID: "570", Value: "-novid"}}},
+		{"named apps", true, []steam.AppValue{{AppID: "570", Value: "-novid"}, {AppID: "730", Value: ""}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := copiedLaunchOptions(from, differing, owned, tt.named); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("copiedLaunchOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanMigration(t *testing.T) {
+	from := map[string]string{"570": "-novid", "730": "-high", "440": "", "10": "-old"}
+	to := map[string]string{"570": "-novid", "730": ""}
+	fromTools := map[string]string{"440": "proton_9", "730": "proton_8"}
+	toTools := map[string]string{"730": "proton_8"}
+	owned := []string{"570", "730", "440"}
+
+	tests := []struct {
+		name      string
+		fromTools map[string]string
+		apps      []string
+		want      migration
+	}{
+		{
+			name: "launch options only",
+			want: migration{
+				launchOptions: []steam.AppValue{{AppID: "730", Value: "-high"}},
+				missing:       []string{"10"},
+			},
+		},
+		{
+			name:      "with compat tools",
+			fromTools: fromTools,
+			want: migration{
+				launchOptions: []steam.AppValue{{AppID: "730", Value: "-high"}},
+				compatTools:   []steam.AppValue{{AppID: "440", Value: "proton_9"}},
+				missing:       []string{"10"},
+			},
+		},
+		{
+			name:      "selected apps",
+			fromTools: fromTools,
+			apps:      []string{"440"},
+			want: migration{
+				compatTools: []steam.AppValue{{AppID: "440", Value: "proton_9"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := planMigration(from, to, tt.fromTools, toTools, owned, tt.apps); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("planMigration() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KiB"},
+		{3 << 30, "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatSize(tt.bytes); got != tt.want {
+			t.Errorf("formatSize(%d) = %q, want %q", tt.bytes, got, tt.want)
+		}
+	}
+}
+
+func TestReviewAppValues(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{Games: []steamtest.Game{{AppID: "570", Name: "Dota 2"}}})
+	library, err := steam.LoadLibrary(install.Path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := []steam.AppValue{
+		{AppID: "570", Value: "-new"},
+		{AppID: "730", Value: "-new"},
+		{AppID: "440", Value: "-new"},
+		{AppID: "620", Value: "-new"},
+		{AppID: "10", Value: "-new"},
+	}
+	current := map[string]string{"440": "-new"}
+
+	tests := []struct {
+		name  string
+		input string
+		want  []steam.AppValue
+	}{
+		{
+			name:  "yes, edit, skip up to date, no, quit",
+			input: "y\ne\n-edited\nn\nq\n",
+			want:  []steam.AppValue{{AppID: "570", Value: "-new"}, {AppID: "730", Value: "-edited"}},
+		},
+		{
+			name:  "invalid answers are asked again",
+			input: "maybe\nyes\nn\nn\nn\n",
+			want:  []steam.AppValue{{AppID: "570", Value: "-new"}},
+		},
+		{
+			name:  "end of input quits",
+			input: "e\n\n",
+			want:  []steam.AppValue{{AppID: "570", Value: "-new"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdin = bufio.NewReader(strings.NewReader(tt.input))
+			defer func() { stdin = bufio.NewReader(os.Stdin) }()
+
+			got := reviewAppValues(values, current, library)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("reviewAppValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectMatches(t *testing.T) {
+	matches := make([]steam.GameInfo, 30)
+	for i := range matches {
+		matches[i] = steam.GameInfo{AppID: strconv.Itoa(i + 1), Name: fmt.Sprintf("Game %d", i+1)}
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		limit int
+		want  string
+	}{
+		{name: "first page", input: "3\n", limit: 10, want: "3"},
+		{name: "paging", input: "n\nN\np\n25\n", limit: 10, want: "25"},
+		{name: "beyond the page shown", input: "28-30\n", limit: 10, want: "28-30"},
+		{name: "no paging when everything is shown", input: "n\n", limit: 0, want: "n"},
+		{name: "end of input", input: "n\n", limit: 10, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdin = bufio.NewReader(strings.NewReader(tt.input))
+			defer func() { stdin = bufio.NewReader(os.Stdin) }()
+
+			if got := selectMatches(matches, tt.limit); got != tt.want {
+				t.Errorf("selectMatches() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if isTerminal(f) {
+		t.Error("isTerminal() of a regular file = true, want false")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = r.Close(); _ = w.Close() }()
+	if isTerminal(r) {
+		t.Error("isTerminal() of a pipe = true, want false")
+	}
+
+	if null, err := os.Open(os.DevNull); err == nil {
+		defer func() { _ = null.Close() }()
+		if isTerminal(null) {
+			t.Errorf("isTerminal() of %s = true, want false", os.DevNull)
+		}
+	}
+}
+
+func TestPrintProgressNotTerminal(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	stdout := os.Stdout
+	os.Stdout = f
+	defer func() { os.Stdout = stdout }()
+
+	progress := printProgress("Loading game library...")
+	for done := 1; done <= 3; done++ {
+		progress(done, 3)
+	}
+
+	got, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Loading game library... 3/3\n"; string(got) != want {
+		t.Errorf("printProgress() wrote %q, want %q", got, want)
+	}
+}
+
+func TestReadAnswer(t *testing.T) {
+	tests := []struct {
+		input    string
+		want     string
+		answered bool
+	}{
+		{"yes\n", "yes", true},
+		{"\n", "", true},
+		{"n", "n", true},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		stdin = bufio.NewReader(strings.NewReader(tt.input))
+		got, answered := readAnswer()
+		if got != tt.want || answered != tt.answered {
+			t.Errorf("readAnswer() of %q = %q, %v; want %q, %v", tt.input, got, answered, tt.want, tt.answered)
+		}
+	}
+	stdin = bufio.NewReader(os.Stdin)
+}
+
+func TestParseExpiry(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"7d", 7 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"0d", 0, true},
+		{"-1h", 0, true},
+		{"xd", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseExpiry(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("parseExpiry(%q) = %v, %v, want %v (error: %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestMergeLists(t *testing.T) {
+	lists := map[string][]string{
+		"a.txt": {"570", "Dota 2", "730"},
+		"b.txt": {"730", "Portal 2", "Unknown Game", "440"},
+	}
+	resolve := func(name string) (string, error) {
+		switch name {
+		case "Dota 2":
+			return "570", nil
+		case "Portal 2":
+			return "620", nil
+		}
+		return "", steam.ErrGameNotFound
+	}
+
+	merged, problems := mergeLists([]string{"a.txt", "b.txt"}, lists, resolve)
+
+	var got []string
+	for _, entry := range merged {
+		got = append(got, entry.appID+" "+strings.Join(entry.sources, ","))
+	}
+	want := []string{"570 a.txt", "730 a.txt,b.txt", "620 b.txt", "440 b.txt"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeLists() = %v, want %v", got, want)
+	}
+	if len(problems) != 1 || !strings.Contains(problems[0], "b.txt") {
+		t.Errorf("mergeLists() problems = %v, want the unknown game in b.txt", problems)
+	}
+}
+
+func TestListEditor(t *testing.T) {
+	lines := []string{"# header", "", "570", "730", "", "Dota 2", "440"}
+
+	annotated := []string{"# Created by gsca wizard", "# Portal 2", "620", "# Dota 2", "570", "", "# Counter-Strike 2", "730"}
+
+	tests := []struct {
+		name  string
+		lines []string
+		edit  func(e *listEditor) int
+		want  []string
+		n     int
+	}{
+		{
+			name: "remove",
+			edit: func(e *listEditor) int { return e.remove("1,3") },
+			want: []string{"# header", "", "730", "", "440"},
+			n:    2,
+		},
+		{
+			name: "comment out",
+			edit: func(e *listEditor) int { return e.comment("*,^2") },
+			want: []string{"# header", "", "#- 570", "730", "", "#- Dota 2", "#- 440"},
+			n:    3,
+		},
+		{
+			name: "move before an entry",
+			edit: func(e *listEditor) int { return e.move("4,3", 1) },
+			want: []string{"# header", "", "440", "Dota 2", "570", "730", ""},
+			n:    2,
+		},
+		{
+			name: "move to the end",
+			edit: func(e *listEditor) int { return e.move("1", 99) },
+			want: []string{"# header", "", "730", "", "Dota 2", "440", "570"},
+			n:    1,
+		},
+		{
+			name:  "remove with its comment",
+			lines: annotated,
+			edit:  func(e *listEditor) int { return e.remove("2") },
+			want:  []string{"# Created by gsca wizard", "# Portal 2", "620", "", "# Counter-Strike 2", "730"},
+			n:     1,
+		},
+		{
+			name:  "move with comments",
+			lines: annotated,
+			edit:  func(e *listEditor) int { return e.move("3", 1) },
+			want:  []string{"# Created by gsca wizard", "# Counter-Strike 2", "730", "# Portal 2", "620", "# Dota 2", "570", ""},
+			n:     1,
+		},
+		{
+			name:  "comment on the first line",
+			lines: []string{"# Portal 2", "620", "570"},
+			edit:  func(e *listEditor) int { return e.remove("1") },
+			want:  []string{"570"},
+			n:     1,
+		},
+		{
+			name:  "commented-out entries stay",
+			lines: []string{"# my games", "10", "#- 20", "30", "# 40", "50"},
+			edit:  func(e *listEditor) int { return e.remove("2,3") },
+			want:  []string{"# my games", "10", "#- 20", "# 40"},
+			n:     2,
+		},
+		{
+			name:  "move leaves commented-out entries",
+			lines: []string{"10", "#- 20", "30"},
+			edit:  func(e *listEditor) int { return e.move("2", 1) },
+			want:  []string{"30", "10", "#- 20"},
+			n:     1,
+		},
+		{
+			name: "invalid selection",
+			edit: func(e *listEditor) int { return e.remove("9") },
+			want: lines,
+			n:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := lines
+			if tt.lines != nil {
+				input = tt.lines
+			}
+			e := &listEditor{lines: append([]string(nil), input...)}
+			if n := tt.edit(e); n != tt.n || !reflect.DeepEqual(e.lines, tt.want) {
+				t.Errorf("edit = %d, %q, want %d, %q", n, e.lines, tt.n, tt.want)
+			}
+			if e.dirty != (tt.n > 0) {
+				t.Errorf("dirty = %v, want %v", e.dirty, tt.n > 0)
+			}
+		})
+	}
+}
+
+func TestSetupConsoleASCII(t *testing.T) {
+	defer func(b, r string) { bullet, ruleChar = b, r }(bullet, ruleChar)
+
+	t.Setenv("GSCA_ASCII", "1")
+	setupConsole()()
+	if bullet != "*" || rule() != strings.Repeat("-", 40) {
+		t.Errorf("with GSCA_ASCII, bullet = %q and rule() = %q, want ASCII", bullet, rule())
+	}
+}
+
+func TestResolveListEntry(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{Games: []steamtest.Game{
+		{AppID: "570", Name: "Dota 2"},
+		{AppID: "1", Name: "Doom"},
+		{AppID: "2", Name: "Doom"},
+	}})
+	library, err := steam.LoadLibrary(install.Path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	games := map[string]steam.GameInfo{
+		"570": {AppID: "570", Name: "Dota 2", Installed: true, LaunchOptions: "-novid"},
+		"440": {AppID: "440", Name: "440"},
+	}
+
+	tests := []struct {
+		entry string
+		want  listEntry
+	}{
+		{"570", listEntry{entry: "570", appID: "570", name: "Dota 2", status: listStatusInstalled, launchOptions: "-novid"}},
+		{"dota 2", listEntry{entry: "dota 2", appID: "570", name: "Dota 2", status: listStatusInstalled, launchOptions: "-novid"}},
+		{"440", listEntry{entry: "440", appID: "440", status: listStatusNotInstalled}},
+		{"620", listEntry{entry: "620", appID: "620", status: listStatusNotInLibrary}},
+		{"Portal", listEntry{entry: "Portal", status: listStatusNotFound}},
+		{"Doom", listEntry{entry: "Doom", status: listStatusAmbiguous, candidates: []string{"1", "2"}}},
+	}
+
+	for _, tt := range tests {
+		got := resolveListEntry(tt.entry, games, library)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("resolveListEntry(%q) = %+v, want %+v", tt.entry, got, tt.want)
+		}
+	}
+}
+
+func TestLintList(t *testing.T) {
+	lines := []string{"# shared list", "730", "Dota 2", "", "730", "cs", "Doom", "Portal", "620", "323180"}
+	alias := func(name string) (string, bool) { return map[string]string{"cs": "730"}[name], name == "cs" }
+	library := map[string]listEntry{
+		"Dota 2": {appID: "570", status: listStatusInstalled},
+		"Doom":   {status: listStatusAmbiguous, candidates: []string{"1", "2"}},
+		"Portal": {status: listStatusNotFound},
+		"730":    {appID: "730", status: listStatusInstalled},
+		"620":    {appID: "620", status: listStatusNotInLibrary},
+		"323180": {appID: "323180", status: listStatusInstalled, dlc: true, dlcParent: "620"},
+	}
+	resolve := func(entry string) listEntry { return library[entry] }
+
+	summarize := func(findings []listFinding) []string {
+		var got []string
+		for _, f := range findings {
+			got = append(got, fmt.Sprintf("%d %v %s", f.line, f.strictOnly, f.problem))
+		}
+		return got
+	}
+
+	want := []string{
+		"3 true game name; update only accepts app IDs (use 570)",
+		"5 true duplicate of line 2",
+		"6 true alias for app 730; other machines may not define it",
+		"6 true duplicate of line 2",
+		"7 false matches several games (1, 2)",
+		"8 false matches no game",
+		"9 true app 620 isn't in this Steam library",
+		"10 false DLC, which can't have launch options (use its game, 620)",
+	}
+	if got := summarize(lintList(lines, alias, resolve)); !reflect.DeepEqual(got, want) {
+		t.Errorf("lintList() = %q, want %q", got, want)
+	}
+
+	// Without a library, only the format is checked
+	want = []string{
+		"3 true not an app ID (update only accepts app IDs)",
+		"5 true duplicate of line 2",
+		"6 true alias for app 730; other machines may not define it",
+		"6 true duplicate of line 2",
+		"7 true not an app ID (update only accepts app IDs)",
+		"8 true not an app ID (update only accepts app IDs)",
+	}
+	if got := summarize(lintList(lines, alias, nil)); !reflect.DeepEqual(got, want) {
+		t.Errorf("lintList() without a library = %q, want %q", got, want)
+	}
+}
+
+func TestSubstituteDLC(t *testing.T) {
+	dlc := map[string]steam.AppInfo{
+		"323180": {AppID: "323180", Type: "DLC", Parent: "620"},
+		"323181": {AppID: "323181", Type: "DLC", Parent: "620"},
+		"999":    {AppID: "999", Type: "DLC"},
+	}
+	appIDs := []string{"570", "323180", "999", "323181", "730"}
+
+	tests := []struct {
+		substitute bool
+		want       []string
+	}{
+		{substitute: true, want: []string{"570", "620", "730"}},
+		{substitute: false, want: []string{"570", "730"}},
+	}
+	for _, tt := range tests {
+		if got := substituteDLC(appIDs, dlc, tt.substitute); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("substituteDLC(substitute=%v) = %v, want %v", tt.substitute, got, tt.want)
+		}
+	}
+}
+
+func TestWriteAllUsers(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{Users: []steamtest.User{
+		{ID: "111", Apps: []steamtest.App{{AppID: "570"}}},
+		{ID: "222", Apps: []steamtest.App{{AppID: "570", LaunchOptions: "-novid"}, {AppID: "730"}}},
+		{ID: "333", Apps: []steamtest.App{{AppID: "570"}}},
+	}})
+
+	var updates []*userUpdate
+	for _, id := range []string{"111", "222", "333"} {
+		u := &userUpdate{user: steam.User{ID: id}, localConfigPath: install.LocalConfigPath(id)}
+		fingerprint, err := steam.FingerprintFile(u.localConfigPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u.fingerprint = fingerprint
+		u.values = []steam.AppValue{{AppID: "570", Value: "-novid"}, {AppID: "730", Value: "-novid"}}
+		updates = append(updates, u)
+	}
+	// Changed after its games were selected
+	if err := os.WriteFile(updates[2].localConfigPath, []byte(`"UserLocalConfigStore" {}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	writeAllUsers(updates, steam.KeyLaunchOptions, steam.UpdateOptions{})
+
+	for i, want := range []int{2, 1} {
+		if u := updates[i]; u.err != nil || u.result.Count(steam.UpdateChanged) != want {
+			t.Errorf("account %s: err = %v, want %d changed", u.user.ID, u.err, want)
+		}
+	}
+	if !errors.Is(updates[2].err, steam.ErrConfigModified) {
+		t.Errorf("modified account error = %v, want ErrConfigModified", updates[2].err)
+	}
+	if err := allUsersError(updates); err == nil || !strings.Contains(err.Error(), "1 of 3") {
+		t.Errorf("allUsersError() = %v, want 1 of 3 failed", err)
+	}
+}
+
+func TestQueryMatcher(t *testing.T) {
+	games := []steam.GameInfo{
+		{AppID: "1091500", Name: "Cyberpunk 2077"},
+		{AppID: "489830", Name: "The Elder Scrolls V: Skyrim Special Edition"},
+		{AppID: "72850", Name: "The Elder Scrolls V: Skyrim"},
+		{AppID: "620", Name: "Portal 2"},
+		{AppID: "400", Name: "Portal"},
+		{AppID: "220", Name: "Pokémon"},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		regex bool
+		exact bool
+		want  string
+	}{
+		{name: "substring", query: "skyrim", want: "489830 72850"},
+		{name: "substring of name or app ID", query: "20", want: "1091500 620 220"},
+		{name: "exact name", query: "the elder scrolls v: skyrim", exact: true, want: "72850"},
+		{name: "exact ignores accents", query: "POKEMON", exact: true, want: "220"},
+		{name: "exact app ID", query: "620", exact: true, want: "620"},
+		{name: "regex", query: `^portal( \d)?$`, regex: true, want: "620 400"},
+		{name: "regex ignores case", query: `skyrim$`, regex: true, want: "72850"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryRegex, queryExact = tt.regex, tt.exact
+			defer func() { queryRegex, queryExact = false, false }()
+
+			match, err := queryMatcher(tt.query)
+			if err != nil {
+				t.Fatalf("queryMatcher() error = %v", err)
+			}
+			var got []string
+			for _, game := range games {
+				if match(game) {
+					got = append(got, game.AppID)
+				}
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("matches = %v, want %s", got, tt.want)
+			}
+		})
+	}
+
+	queryRegex = true
+	defer func() { queryRegex = false }()
+	if _, err := queryMatcher("portal("); err == nil {
+		t.Error("queryMatcher() of an invalid regex error = nil, want error")
+	}
+}
+
+func TestMatchesArgsFilter(t *testing.T) {
+	tests := []struct {
+		hasArgs, noArgs bool
+		launchOptions   string
+		want            bool
+	}{
+		{false, false, "", true},
+		{false, false, "-novid", true},
+		{true, false, "-novid", true},
+		{true, false, "", false},
+		{true, false, "  ", false},
+		{false, true, "", true},
+		{false, true, "gamemoderun %command%", false},
+	}
+
+	defer func() { queryHasArgs, queryNoArgs = false, false }()
+	for _, tt := range tests {
+		queryHasArgs, queryNoArgs = tt.hasArgs, tt.noArgs
+		if got := matchesArgsFilter(tt.launchOptions); got != tt.want {
+			t.Errorf("matchesArgsFilter(%q) with --has-args=%v --no-args=%v = %v, want %v", tt.launchOptions, tt.hasArgs, tt.noArgs, got, tt.want)
+		}
+	}
+}
+
+func TestAntiCheatWarnings(t *testing.T) {
+	games := map[string]anticheat.Game{
+		"100": {Name: "Kernel Game", AntiCheats: []string{"Easy Anti-Cheat"}},
+		"200": {Name: "VAC Game", AntiCheats: []string{"Valve Anti-Cheat"}},
+	}
+	risky := map[string][]string{
+		"mangohud %command%":    {"mangohud"},
+		"gamemoderun %command%": nil,
+	}
+	values := []steam.AppValue{
+		{AppID: "100", Value: "mangohud %command%"},
+		{AppID: "100", Value: "gamemoderun %command%"},
+		{AppID: "200", Value: "mangohud %command%"},
+		{AppID: "300", Value: "mangohud %command%"},
+	}
+
+	want := []string{"app 100 (Kernel Game) uses Easy Anti-Cheat; mangohud may be flagged as cheating"}
+	if got := antiCheatWarnings(values, risky, games); !reflect.DeepEqual(got, want) {
+		t.Errorf("antiCheatWarnings() = %q, want %q", got, want)
+	}
+	if got := antiCheatWarnings(values, risky, games); got != nil {
+		t.Errorf("antiCheatWarnings() repeated = %q, want nil", got)
+	}
+}
+
+func TestFilterByFamilySharing(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{Games: []steamtest.Game{
+		{AppID: "570", Name: "Dota 2", LastOwner: "76561197960265839"},
+		{AppID: "730", Name: "Counter-Strike 2", LastOwner: "76561197960265950"},
+	}})
+	library, err := steam.LoadLibrary(install.Path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(saved, savedUser string) { familyShared, userID = saved, savedUser }(familyShared, userID)
+	userID = "111"
+
+	appIDs := []string{"570", "730", "440"}
+	tests := map[string][]string{
+		familySharedInclude: {"570", "730", "440"},
+		familySharedExclude: {"570", "440"},
+		familySharedOnly:    {"730"},
+	}
+	for value, want := range tests {
+		familyShared = value
+		if got, err := filterByFamilySharing(appIDs, library); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("filterByFamilySharing() with %s = %v, %v, want %v", value, got, err, want)
+		}
+	}
+
+	familyShared = "borrowed"
+	if _, err := filterByFamilySharing(appIDs, library); err == nil {
+		t.Error("filterByFamilySharing() with an invalid value error = nil, want error")
+	}
+}
+
+func TestChangedAppValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to map[string]string
+		want     []string
+	}{
+		{name: "same", from: map[string]string{"570": "-novid"}, to: map[string]string{"570": "-novid"}},
+		{name: "changed and cleared", from: map[string]string{"570": "-novid", "730": "-high", "10": "x"}, to: map[string]string{"570": "-novid -high", "730": ""}, want: []string{"10", "570", "730"}},
+		{name: "added", from: map[string]string{}, to: map[string]string{"620": "-console", "440": ""}, want: []string{"620"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := changedAppValues(tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changedAppValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveSelection(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{Games: []steamtest.Game{{AppID: "570", Name: "Dota 2"}, {AppID: "730", Name: "Counter-Strike 2"}}})
+	library, err := steam.LoadLibrary(install.Path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "souls.txt")
+	if err := os.WriteFile(path, []byte("# mine\n570\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveSelection(path, "allow", []string{"570", "730"}, nil, library); err != nil {
+		t.Fatalf("saveSelection() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# mine\n570\n730\n"; string(data) != want {
+		t.Errorf("saveSelection() wrote %q, want %q", data, want)
+	}
+}
+
+func TestLocalConfigForRecordedRemotely(t *testing.T) {
+	mirrors, err := mirrorsDir()
+	if err != nil {
+		t.Skip(err)
+	}
+	local := filepath.Join(t.TempDir(), "userdata", "111", "config", "localconfig.vdf")
+	if got, err := localConfigFor(local); err != nil || got != local {
+		t.Errorf("localConfigFor(%q) = %q, %v; want it unchanged", local, got, err)
+	}
+	mirrored := filepath.Join(mirrors, "deck@steamdeck", "userdata", "111", "config", "localconfig.vdf")
+	if _, err := localConfigFor(mirrored); err == nil {
+		t.Errorf("localConfigFor(%q) without --remote succeeded, want it refused", mirrored)
+	}
+}
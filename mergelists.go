@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/steam"
+)
+
+var (
+	mergeOutput   string
+	mergeAnnotate bool
+)
+
+var mergeListsCmd = &cobra.Command{
+	Use:   "merge-lists <file>...",
+	Short: "Combine list files into one, without duplicates",
+	Long: `Combine allow or deny list files into one list of app IDs. Entries may be app IDs,
+aliases, or installed game names, as in 'gsca list'; each game is kept once, in the
+order it first appears.
+
+Names that don't match exactly one installed game are reported and stop the merge
+unless --ignore-missing is given. Without -o the merged list is printed to stdout, and
+any other output goes to stderr. Lists of app IDs and aliases don't need a Steam install
+unless --annotate is given.
+
+Example:
+  gsca merge-lists a.txt b.txt -o combined.txt --annotate`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runMergeLists,
+}
+
+func init() {
+	mergeListsCmd.Flags().StringVarP(&mergeOutput, "output", "o", "", "Write the merged list to this file (replacing it) instead of printing it")
+	mergeListsCmd.Flags().BoolVar(&mergeAnnotate, "annotate", false, "Add a comment with each game's name and source files")
+	mergeListsCmd.Flags().BoolVar(&ignoreMissing, "ignore-missing", false, "Leave out entries that don't match a game instead of failing")
+
+	rootCmd.AddCommand(mergeListsCmd)
+}
+
+// mergedEntry is a game in a merged list
+type mergedEntry struct {
+	appID   string
+	sources []string
+}
+
+// mergeLists unions the entries of lists (file name to entries, in files order) by app ID,
+// using resolve for entries that aren't app IDs. Returns the games in order of first
+// appearance, and the entries that couldn't be resolved
+func mergeLists(files []string, lists map[string][]string, resolve func(string) (string, error)) ([]*mergedEntry, []string) {
+	var merged []*mergedEntry
+	byID := make(map[string]*mergedEntry)
+	var problems []string
+
+	for _, file := range files {
+		for _, item := range lists[file] {
+			appID := item
+			if !isAppID(item) {
+				var err error
+				if appID, err = resolve(item); err != nil {
+					problems = append(problems, fmt.Sprintf("%s: %v", file, err))
+					continue
+				}
+			}
+
+			entry := byID[appID]
+			if entry == nil {
+				entry = &mergedEntry{appID: appID}
+				byID[appID] = entry
+				merged = append(merged, entry)
+			}
+			if len(entry.sources) == 0 || entry.sources[len(entry.sources)-1] != file {
+				entry.sources = append(entry.sources, file)
+			}
+		}
+	}
+	return merged, problems
+}
+
+// isAppID reports whether s is a numeric app ID
+func isAppID(s string) bool {
+	for _, ch := range s {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func runMergeLists(cmd *cobra.Command, args []string) error {
+	out := os.Stdout
+	if mergeOutput == "" {
+		// Only the list goes to stdout, so it can be redirected to a file
+		os.Stdout = os.Stderr
+		defer func() { os.Stdout = out }()
+	}
+
+	lists := make(map[string][]string, len(args))
+	total := 0
+	needLibrary := mergeAnnotate
+	for _, file := range args {
+		items, err := steam.LoadFilterList(file)
+		if err != nil {
+			return err
+		}
+		lists[file] = expandAliases(items)
+		total += len(items)
+		for _, item := range lists[file] {
+			needLibrary = needLibrary || !isAppID(item)
+		}
+	}
+
+	// Lists of app IDs merge without a Steam install, unless names are wanted
+	library := &steam.Library{}
+	if needLibrary {
+		var err error
+		if steamPath == "" {
+			if steamPath, err = detectSteamPath(); err != nil {
+				return fmt.Errorf("failed to detect Steam path: %w", err)
+			}
+		}
+		if library, err = steam.LoadLibrary(steamPath, nil); err != nil {
+			return fmt.Errorf("failed to load game library: %w", err)
+		}
+	}
+
+	merged, problems := mergeLists(args, lists, func(name string) (string, error) {
+		return resolveGame(name, library)
+	})
+	if len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "Entries that don't match a game:\n")
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stderr, "  - %s\n", problem)
+		}
+		if !ignoreMissing {
+			return fmt.Errorf("refusing to merge with %d unresolved entries (use --ignore-missing to leave them out)", len(problems))
+		}
+	}
+
+	names := libraryNames(library)
+	var buf bytes.Buffer
+	if mergeAnnotate {
+		fmt.Fprintf(&buf, "# Merged from %s by gsca merge-lists\n", strings.Join(args, ", "))
+	}
+	for _, entry := range merged {
+		if mergeAnnotate {
+			name := names[entry.appID]
+			if name == "" {
+				name = "(not installed)"
+			}
+			fmt.Fprintf(&buf, "# %s (%s)\n", name, strings.Join(entry.sources, ", "))
+		}
+		fmt.Fprintf(&buf, "%s\n", entry.appID)
+	}
+
+	if mergeOutput == "" {
+		fmt.Fprint(out, buf.String())
+		return nil
+	}
+	if err := os.WriteFile(mergeOutput, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", mergeOutput, err)
+	}
+	fmt.Printf("Merged %d entries from %d files into %d games (%d duplicates) in %s\n",
+		total, len(args), len(merged), total-len(problems)-len(merged), mergeOutput)
+	return nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/steam"
+)
+
+var (
+	migrateFromSteamPath string
+	migrateFromUser      string
+	migrateToUser        string
+	migrateCompatTools   bool
+	migrateApps          []string
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate --from-user A",
+	Short: "Copy launch options from another Steam install or user",
+	Long: `Copy launch options from one Steam install and user to another, e.g. from an old
+drive, a copy of another machine's Steam folder, or another account.
+
+The destination is the usual install (or --steam-path) and --to-user (default: the
+detected user). Only games with launch options at the source are copied; games the
+destination account doesn't have are skipped and listed.
+
+Example:
+  gsca migrate --from-steam-path /mnt/old/Steam --from-user 11111111
+  gsca migrate --from-user 11111111 --to-user 22222222 --compat-tools`,
+	Args: cobra.NoArgs,
+	RunE: runMigrate,
+}
+
+func init() {
+	migrateCmd.Flags().StringVar(&migrateFromSteamPath, "from-steam-path", "", "Steam install to copy from (default: the destination install)")
+	migrateCmd.Flags().StringVar(&migrateFromUser, "from-user", "", "Steam user ID to copy from")
+	migrateCmd.Flags().StringVar(&migrateToUser, "to-user", "", "Steam user ID to copy to (default: the detected user)")
+	migrateCmd.Flags().BoolVar(&migrateCompatTools, "compat-tools", false, "Also copy the compatibility tools forced for each game")
+	migrateCmd.Flags().StringSliceVar(&migrateApps, "apps", nil, "Only migrate these app IDs")
+	migrateCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be copied without modifying files")
+	migrateCmd.Flags().BoolVarP(&autoCloseSteam, "force", "f", false, "Automatically close Steam if running (no prompt)")
+	migrateCmd.Flags().BoolVar(&noBackup, "no-backup", false, "Skip creating backup file")
+	addRestartFlags(migrateCmd)
+	_ = migrateCmd.MarkFlagRequired("from-user")
+
+	rootCmd.AddCommand(migrateCmd)
+}
+
+func runMigrate(cmd *cobra.Command, args []string) error {
+	if migrateToUser != "" {
+		userID = migrateToUser
+	}
+	toPath, err := detectLocalConfigPath()
+	if err != nil {
+		return err
+	}
+
+	fromSteamPath := migrateFromSteamPath
+	if fromSteamPath == "" {
+		fromSteamPath = steamPath
+	}
+	fromPath := steam.GetLocalConfigPath(fromSteamPath, migrateFromUser)
+	if fromPath == toPath {
+		return fmt.Errorf("source and destination are the same (%s)", toPath)
+	}
+	if _, err := os.Stat(fromPath); err != nil {
+		return fmt.Errorf("no Steam config for user %s in %s: %w", migrateFromUser, fromSteamPath, err)
+	}
+
+	from, err := steam.GetAppValues(fromPath, steam.KeyLaunchOptions)
+	if err != nil {
+		return err
+	}
+	to, err := steam.GetAppValues(toPath, steam.KeyLaunchOptions)
+	if err != nil {
+		return err
+	}
+	owned, err := steam.GetAllGameIDs(toPath)
+	if err != nil {
+		return err
+	}
+	var fromTools map[string]string
+	if migrateCompatTools {
+		fromTools = steam.GetCompatTools(fromSteamPath)
+	}
+
+	plan := planMigration(from, to, fromTools, steam.GetCompatTools(steamPath), owned, migrateApps)
+
+	names := libraryNamesOrEmpty()
+
+	fmt.Printf("Migrating from %s to %s\n", fromPath, toPath)
+	if len(plan.missing) > 0 {
+		fmt.Printf("\nSkipping %d game(s) the destination account doesn't have:\n", len(plan.missing))
+		for _, appID := range plan.missing {
+			fmt.Printf("  - %s\n", gameLabel(appID, names))
+		}
+	}
+	if len(plan.launchOptions) == 0 && len(plan.compatTools) == 0 {
+		fmt.Println("\nNothing to migrate: the destination already matches")
+		return nil
+	}
+	fmt.Printf("\nWill copy launch options for %d games", len(plan.launchOptions))
+	if migrateCompatTools {
+		fmt.Printf(" and compatibility tools for %d games (they must also be installed at the destination)", len(plan.compatTools))
+	}
+	fmt.Println()
+
+	return writeCopiedValues(toPath, plan.launchOptions, plan.compatTools, names, "migrate")
+}
+
+// migration is what migrate will copy
+type migration struct {
+	launchOptions []steam.AppValue
+	compatTools   []steam.AppValue
+	// missing are source games with settings that the destination account doesn't have
+	missing []string
+}
+
+// planMigration picks the source launch options and compatibility tools (fromTools may be
+// nil) that differ at the destination, for games in owned (and apps, if given)
+func planMigration(from, to, fromTools, toTools map[string]string, owned, apps []string) migration {
+	ownedSet := make(map[string]bool, len(owned))
+	for _, appID := range owned {
+		ownedSet[appID] = true
+	}
+	selected := make(map[string]bool, len(apps))
+	for _, appID := range apps {
+		selected[appID] = true
+	}
+
+	// Games with settings at the source, in app ID order
+	var appIDs []string
+	for appID, value := range from {
+		if value != "" || fromTools[appID] != "" {
+			appIDs = append(appIDs, appID)
+		}
+	}
+	for appID := range fromTools {
+		if _, seen := from[appID]; !seen {
+			appIDs = append(appIDs, appID)
+		}
+	}
+	steam.SortAppIDs(appIDs)
+
+	var m migration
+	for _, appID := range appIDs {
+		if len(apps) > 0 && !selected[appID] {
+			continue
+		}
+		if !ownedSet[appID] {
+			m.missing = append(m.missing, appID)
+			continue
+		}
+		if value := from[appID]; value != "" && value != to[appID] {
+			m.launchOptions = append(m.launchOptions, steam.AppValue{AppID: appID, Value: value})
+		}
+		if tool := fromTools[appID]; tool != "" && tool != toTools[appID] {
+			m.compatTools = append(m.compatTools, steam.AppValue{AppID: appID, Value: tool})
+		}
+	}
+	return m
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/steam"
+)
+
+var applyNormalize bool
+
+var normalizeCmd = &cobra.Command{
+	Use:   "normalize",
+	Short: "Clean up launch options across the library",
+	Long: `Tidy up launch options left messy by manual editing: trim and collapse whitespace,
+drop duplicate %command% tokens, and remove repeated env vars, wrappers, and game flags.
+
+Only shows the changes unless --apply is given. All games are checked unless
+--allow, --deny, --apps, or --favorites narrows them down.`,
+	Args: cobra.NoArgs,
+	RunE: runNormalize,
+}
+
+func init() {
+	addUpdateFlags(normalizeCmd)
+	// Dry-run is the default here; --apply writes
+	_ = normalizeCmd.Flags().MarkHidden("dry-run")
+	normalizeCmd.Flags().BoolVar(&applyNormalize, "apply", false, "Write the cleaned-up launch options (default: only show them)")
+	rootCmd.AddCommand(normalizeCmd)
+}
+
+func runNormalize(cmd *cobra.Command, args []string) error {
+	dryRun = !applyNormalize
+	selectAllByDefault()
+
+	changed := 0
+	update := launchOptionsUpdate("")
+	update.values = func(appIDs []string) ([]steam.AppValue, error) {
+		current, err := steam.GetAppValues(steam.GetLocalConfigPath(steamPath, userID), steam.KeyLaunchOptions)
+		if err != nil {
+			return nil, err
+		}
+
+		var values []steam.AppValue
+		for _, appID := range appIDs {
+			value := current[appID]
+			if normalized := steam.ParseLaunchOptions(value).Normalize().String(); normalized != value {
+				fmt.Printf("  %s: %q -> %q\n", appID, value, normalized)
+				values = append(values, steam.AppValue{AppID: appID, Value: normalized})
+			}
+		}
+		changed = len(values)
+		return values, nil
+	}
+
+	if err := applyAppValue(update); err != nil {
+		return err
+	}
+	if dryRun && changed > 0 && planFile == "" {
+		fmt.Println("\nRun again with --apply to write these changes")
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zerkz/gsca/audit"
+	"github.com/zerkz/gsca/notify"
+	"github.com/zerkz/gsca/steam"
+)
+
+// notifyWebhook posts a summary of a change to the webhook in the config file, if any
+// result may be nil for changes that aren't per game. Failing to send only warns
+func notifyWebhook(event, key string, result *steam.UpdateResult, txID, detail string) {
+	cfg, err := loadConfig()
+	if err != nil || cfg.WebhookURL == "" {
+		return
+	}
+	if offlineMode {
+		fmt.Println("Skipping the webhook (--offline)")
+		return
+	}
+
+	host, _ := os.Hostname()
+	if remoteHost != nil {
+		host = remoteHost.Target
+	}
+	summary := notify.Summary{
+		Host:        host,
+		User:        audit.CurrentUser(),
+		Command:     strings.Join(os.Args, " "),
+		Event:       event,
+		Key:         key,
+		Transaction: txID,
+		Detail:      detail,
+	}
+	if result != nil {
+		summary.Changed = result.Count(steam.UpdateChanged)
+		summary.Unchanged = result.Count(steam.UpdateUnchanged)
+		summary.Failed = result.Count(steam.UpdateFailed)
+	}
+
+	if err := notify.Send(cfg.WebhookURL, cfg.WebhookFormat, summary); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
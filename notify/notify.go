@@ -0,0 +1,104 @@
+// Package notify posts a summary of each run to a webhook (Discord, Slack, or any
+// endpoint accepting JSON), so remote machines report what they changed
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Webhook formats
+const (
+	FormatJSON    = "json"
+	FormatDiscord = "discord"
+	FormatSlack   = "slack"
+)
+
+// timeout keeps a slow webhook from holding up gsca
+const timeout = 10 * time.Second
+
+// Summary describes what one run did
+type Summary struct {
+	Host    string `json:"host"`
+	User    string `json:"user"`
+	Command string `json:"command"`
+	// Event is what happened, e.g. "update" or "restore"
+	Event       string `json:"event"`
+	Key         string `json:"key,omitempty"`
+	Changed     int    `json:"changed"`
+	Unchanged   int    `json:"unchanged"`
+	Failed      int    `json:"failed"`
+	Transaction string `json:"transaction,omitempty"`
+	// Detail is extra text, e.g. the restored backup
+	Detail string `json:"detail,omitempty"`
+}
+
+// Text is the summary as one line of chat
+func (s Summary) Text() string {
+	text := fmt.Sprintf("gsca %s on %s (%s)", s.Event, s.Host, s.User)
+	if s.Key != "" {
+		text += fmt.Sprintf(": %s changed for %d game(s), %d unchanged, %d failed", s.Key, s.Changed, s.Unchanged, s.Failed)
+	}
+	if s.Detail != "" {
+		text += ": " + s.Detail
+	}
+	if s.Transaction != "" {
+		text += fmt.Sprintf(" [transaction %s]", s.Transaction)
+	}
+	return text
+}
+
+// DetectFormat guesses the format from a webhook URL, defaulting to plain JSON
+func DetectFormat(url string) string {
+	switch {
+	case strings.Contains(url, "discord.com/api/webhooks"), strings.Contains(url, "discordapp.com/api/webhooks"):
+		return FormatDiscord
+	case strings.Contains(url, "hooks.slack.com"):
+		return FormatSlack
+	default:
+		return FormatJSON
+	}
+}
+
+// requestBody builds the request body for a format
+func requestBody(format string, summary Summary) ([]byte, error) {
+	switch format {
+	case FormatDiscord:
+		return json.Marshal(map[string]string{"content": summary.Text()})
+	case FormatSlack:
+		return json.Marshal(map[string]string{"text": summary.Text()})
+	case FormatJSON, "":
+		return json.Marshal(summary)
+	default:
+		return nil, fmt.Errorf("unknown webhook format %q (use %s, %s, or %s)", format, FormatJSON, FormatDiscord, FormatSlack)
+	}
+}
+
+// Send posts a summary to url in format (detected from the URL if empty)
+func Send(url, format string, summary Summary) error {
+	if format == "" {
+		format = DetectFormat(url)
+	}
+	body, err := requestBody(format, summary)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://discord.com/api/webhooks/1/abc", FormatDiscord},
+		{"https://hooks.slack.com/services/T0/B0/x", FormatSlack},
+		{"https://ntfy.example.com/gsca", FormatJSON},
+	}
+
+	for _, tt := range tests {
+		if got := DetectFormat(tt.url); got != tt.want {
+			t.Errorf("DetectFormat(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	summary := Summary{Host: "deck", User: "deck", Event: "update", Key: "LaunchOptions", Changed: 3, Unchanged: 1}
+
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Errorf("webhook body %q: %v", data, err)
+		}
+	}))
+	defer server.Close()
+
+	if err := Send(server.URL, FormatSlack, summary); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got["text"] != summary.Text() {
+		t.Errorf("Slack body = %v, want text %q", got, summary.Text())
+	}
+
+	if err := Send(server.URL, "", summary); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got["event"] != "update" || got["changed"] != float64(3) {
+		t.Errorf("JSON body = %v, want the summary", got)
+	}
+
+	if err := Send(server.URL, "teams", summary); err == nil {
+		t.Error("Send() with an unknown format error = nil, want error")
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer failing.Close()
+	if err := Send(failing.URL, "", summary); err == nil {
+		t.Error("Send() to a failing webhook error = nil, want error")
+	}
+}
@@ -0,0 +1,173 @@
+// Package output renders command results as an aligned table, JSON, CSV, or YAML, so every
+// command that supports --format produces the same shapes
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Formats accepted by Write
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+	FormatYAML  = "yaml"
+)
+
+// Formats lists every format, for flag help and error messages
+var Formats = []string{FormatTable, FormatJSON, FormatCSV, FormatYAML}
+
+// Table is a command's result: named columns (snake_case, used as JSON and YAML keys) and
+// one row per item. Values are strings, numbers, bools, or nil for "not set"
+type Table struct {
+	Columns []string
+	Rows    [][]any
+}
+
+// Add appends a row with one value per column
+func (t *Table) Add(values ...any) {
+	t.Rows = append(t.Rows, values)
+}
+
+// Check returns an error if format isn't one of Formats
+func Check(format string) error {
+	for _, f := range Formats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown format %q (valid formats: %s)", format, strings.Join(Formats, ", "))
+}
+
+// Write renders t to w in format
+func Write(w io.Writer, format string, t Table) error {
+	switch format {
+	case FormatTable:
+		return writeTable(w, t)
+	case FormatJSON:
+		return writeJSON(w, t)
+	case FormatCSV:
+		return writeCSV(w, t)
+	case FormatYAML:
+		return writeYAML(w, t)
+	}
+	return Check(format)
+}
+
+// text renders a value for table and CSV cells
+func text(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// scalar renders a value as a JSON scalar, which is also a valid YAML scalar
+func scalar(v any) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	// Launch options are full of < > & that needn't be escaped
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func writeTable(w io.Writer, t Table) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := make([]string, len(t.Columns))
+	for i, column := range t.Columns {
+		header[i] = strings.ToUpper(column)
+	}
+	_, _ = fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range t.Rows {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			// Tabs and line breaks would break the alignment
+			cells[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(text(v))
+		}
+		_, _ = fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeJSON writes an array of objects, one per line, with keys in column order
+func writeJSON(w io.Writer, t Table) error {
+	if len(t.Rows) == 0 {
+		_, err := io.WriteString(w, "[]\n")
+		return err
+	}
+	var b strings.Builder
+	b.WriteString("[\n")
+	for r, row := range t.Rows {
+		b.WriteString("  {")
+		for i, v := range row {
+			value, err := scalar(v)
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "%q: %s", t.Columns[i], value)
+		}
+		b.WriteString("}")
+		if r < len(t.Rows)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("]\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeCSV(w io.Writer, t Table) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.Columns); err != nil {
+		return err
+	}
+	for _, row := range t.Rows {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			cells[i] = text(v)
+		}
+		if err := cw.Write(cells); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeYAML writes a sequence of mappings. Strings are double-quoted so values like "no",
+// "1.0", or launch options starting with "-" keep their type
+func writeYAML(w io.Writer, t Table) error {
+	if len(t.Rows) == 0 {
+		_, err := io.WriteString(w, "[]\n")
+		return err
+	}
+	var b strings.Builder
+	for _, row := range t.Rows {
+		for i, v := range row {
+			value, err := scalar(v)
+			if err != nil {
+				return err
+			}
+			prefix := "  "
+			if i == 0 {
+				prefix = "- "
+			}
+			fmt.Fprintf(&b, "%s%s: %s\n", prefix, t.Columns[i], value)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
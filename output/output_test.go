@@ -0,0 +1,94 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	table := Table{Columns: []string{"app_id", "name", "launch_options", "installed"}}
+	table.Add("730", "Counter-Strike 2", `-novid "+exec x.cfg"`, true)
+	table.Add("570", "Dota 2", nil, false)
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: FormatTable,
+			want: `APP_ID  NAME              LAUNCH_OPTIONS        INSTALLED
+730     Counter-Strike 2  -novid "+exec x.cfg"  true
+570     Dota 2                                  false
+`,
+		},
+		{
+			format: FormatJSON,
+			want: `[
+  {"app_id": "730", "name": "Counter-Strike 2", "launch_options": "-novid \"+exec x.cfg\"", "installed": true},
+  {"app_id": "570", "name": "Dota 2", "launch_options": null, "installed": false}
+]
+`,
+		},
+		{
+			format: FormatCSV,
+			want: `app_id,name,launch_options,installed
+730,Counter-Strike 2,"-novid ""+exec x.cfg""",true
+570,Dota 2,,false
+`,
+		},
+		{
+			format: FormatYAML,
+			want: `- app_id: "730"
+  name: "Counter-Strike 2"
+  launch_options: "-novid \"+exec x.cfg\""
+  installed: true
+- app_id: "570"
+  name: "Dota 2"
+  launch_options: null
+  installed: false
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, tt.format, table); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Write() =\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+
+	// JSON output parses back into the same values
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatJSON, table); err != nil {
+		t.Fatal(err)
+	}
+	var rows []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rows); err != nil || len(rows) != 2 || rows[0]["name"] != "Counter-Strike 2" {
+		t.Errorf("JSON output = %v, %v, want 2 rows", rows, err)
+	}
+}
+
+func TestWriteEmpty(t *testing.T) {
+	empty := Table{Columns: []string{"app_id"}}
+	for format, want := range map[string]string{FormatJSON: "[]\n", FormatYAML: "[]\n", FormatCSV: "app_id\n", FormatTable: "APP_ID\n"} {
+		var buf bytes.Buffer
+		if err := Write(&buf, format, empty); err != nil || buf.String() != want {
+			t.Errorf("Write(%s) of no rows = %q, %v, want %q", format, buf.String(), err, want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if err := Check("yaml"); err != nil {
+		t.Errorf("Check(yaml) error = %v", err)
+	}
+	if err := Check("xml"); err == nil {
+		t.Error("Check(xml) error = nil, want error")
+	}
+}
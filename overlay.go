@@ -0,0 +1,41 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/steam"
+)
+
+var overlayCmd = &cobra.Command{
+	Use:   "overlay enable|disable",
+	Short: "Enable or disable the Steam Overlay for selected games",
+	Long: `Turn the in-game Steam Overlay on or off per game, like the checkbox in each game's
+Steam properties.
+
+Games are selected with --all, --allow, or --deny, exactly like 'gsca update'.
+
+Example:
+  gsca overlay disable --allow competitive.txt`,
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: []string{"enable", "disable"},
+	RunE:      runOverlay,
+}
+
+func init() {
+	addUpdateFlags(overlayCmd)
+	rootCmd.AddCommand(overlayCmd)
+}
+
+func runOverlay(cmd *cobra.Command, args []string) error {
+	// Args are validated against ValidArgs, so anything but enable is disable
+	value := "0"
+	if args[0] == "enable" {
+		value = "1"
+	}
+
+	return applyAppValue(appValueUpdate{
+		key:        steam.KeyOverlayAppEnable,
+		value:      value,
+		label:      "the Steam Overlay setting",
+		valueLabel: "Overlay enabled",
+	})
+}
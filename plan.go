@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/steam"
+)
+
+var applyPlanCmd = &cobra.Command{
+	Use:   "apply-plan <plan.json>",
+	Short: "Apply a plan saved with --plan",
+	Long: `Apply the changes in a plan written by 'gsca update ... --plan plan.json'.
+
+The plan records a fingerprint of localconfig.vdf. If the file changed since the plan
+was made (e.g. Steam rewrote it), nothing is applied and a new plan has to be made.
+Make plans with Steam closed, since Steam rewrites localconfig.vdf when it exits.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runApplyPlan,
+}
+
+func init() {
+	applyPlanCmd.Flags().BoolVarP(&autoCloseSteam, "force", "f", false, "Automatically close Steam if running (no prompt)")
+	applyPlanCmd.Flags().BoolVar(&noBackup, "no-backup", false, "Skip creating backup file")
+	addRestartFlags(applyPlanCmd)
+
+	rootCmd.AddCommand(applyPlanCmd)
+}
+
+func runApplyPlan(cmd *cobra.Command, args []string) error {
+	plan, err := steam.LoadPlan(args[0])
+	if err != nil {
+		return err
+	}
+
+	env := plan.Environment
+	fmt.Printf("Plan made %s for %s\n", plan.CreatedAt.Local().Format("2006-01-02 15:04"), env.LocalConfigPath)
+	fmt.Printf("Will set %s for %d games\n", plan.Key, len(plan.Changes))
+	for _, change := range plan.Changes {
+		name := change.Name
+		if name == "" {
+			name = change.AppID
+		}
+		fmt.Printf("  %s: %q -> %q\n", name, change.OldValue, change.NewValue)
+	}
+
+	// Fail before touching Steam if the plan is already out of date
+	if err := steam.CheckPlan(plan); err != nil {
+		return planError(err)
+	}
+	if len(plan.Changes) == 0 {
+		fmt.Println("\nNothing to apply")
+		return nil
+	}
+
+	shouldRestartSteam, err := closeSteamBeforeWrite()
+	if err != nil {
+		return err
+	}
+
+	result, err := steam.ApplyPlan(plan, steam.UpdateOptions{SkipBackup: noBackup})
+	if err != nil {
+		if shouldRestartSteam {
+			restartSteam()
+		}
+		return planError(err)
+	}
+
+	for _, app := range result.Apps {
+		if app.Status == steam.UpdateFailed {
+			fmt.Printf("ERROR: Failed to update app %s: %v\n", app.AppID, app.Err)
+		}
+	}
+	printUpdateSummary(result)
+	names := make(map[string]string)
+	for _, change := range plan.Changes {
+		names[change.AppID] = change.Name
+	}
+	txID := recordTransaction(plan.Key, env.LocalConfigPath, result, names, "")
+	notifyWebhook("apply-plan", plan.Key, result, txID, "")
+
+	if shouldRestartSteam {
+		restartSteam()
+	}
+	return nil
+}
+
+// planError explains how to recover from a stale plan
+func planError(err error) error {
+	if errors.Is(err, steam.ErrPlanStale) {
+		return fmt.Errorf("%w - review a new plan (Steam may have rewritten the file when it closed)", err)
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/zerkz/gsca/steam"
+)
+
+// pluginPrefix is prepended to unknown subcommands when searching PATH for plugins
+const pluginPrefix = "gsca-"
+
+// findPlugin returns the path of a gsca-<name> executable for an unknown subcommand
+// Built-in commands and flags are never treated as plugins
+func findPlugin(args []string) (string, bool) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return "", false
+	}
+
+	if cmd, _, err := rootCmd.Find(args); err == nil && cmd != rootCmd {
+		return "", false
+	}
+
+	path, err := exec.LookPath(pluginPrefix + args[0])
+	if err != nil {
+		return "", false
+	}
+
+	return path, true
+}
+
+// runPlugin runs a plugin with the detected Steam details in its environment
+// Returns the exit code to exit gsca with
+func runPlugin(path string, args []string) int {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), pluginEnv()...)
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintf(os.Stderr, "failed to run plugin %s: %v\n", path, err)
+		return 1
+	}
+
+	return 0
+}
+
+// pluginEnv describes the detected Steam installation for plugins
+// Variables are omitted when detection fails so plugins that don't need Steam still run
+func pluginEnv() []string {
+	path := os.Getenv("GSCA_STEAM_PATH")
+	if path == "" {
+		detected, err := steam.GetSteamPath()
+		if err != nil {
+			return nil
+		}
+		path = detected
+	}
+	env := []string{"GSCA_STEAM_PATH=" + path}
+
+	user := os.Getenv("GSCA_USER_ID")
+	if user == "" {
+		detected, err := detectUserID(path)
+		if err != nil {
+			return env
+		}
+		user = detected
+	}
+
+	return append(env,
+		"GSCA_USER_ID="+user,
+		"GSCA_LOCALCONFIG_PATH="+steam.GetLocalConfigPath(path, user),
+	)
+}
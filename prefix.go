@@ -0,0 +1,191 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/steam"
+)
+
+var prefixCmd = &cobra.Command{
+	Use:   "prefix",
+	Short: "Find the Proton prefixes (compatdata) of games",
+	Long: `Games running through Proton keep their Windows environment in a prefix,
+steamapps/compatdata/<appid> in one of the library folders. These commands find them.`,
+}
+
+var prefixListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List Proton prefixes and the games they belong to",
+	Args:  cobra.NoArgs,
+	RunE:  runPrefixList,
+}
+
+var prefixSizeCmd = &cobra.Command{
+	Use:   "size [appid|name]",
+	Short: "Show the disk usage of one or all Proton prefixes",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runPrefixSize,
+}
+
+var prefixOpenCmd = &cobra.Command{
+	Use:   "open <appid|name>",
+	Short: "Open a game's Proton prefix in the file manager",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPrefixOpen,
+}
+
+func init() {
+	prefixCmd.AddCommand(prefixListCmd)
+	prefixCmd.AddCommand(prefixSizeCmd)
+	prefixCmd.AddCommand(prefixOpenCmd)
+	rootCmd.AddCommand(prefixCmd)
+}
+
+// loadPrefixes finds the Proton prefixes and the installed games to name them with
+func loadPrefixes() ([]steam.Prefix, *steam.Library, error) {
+	var err error
+	if steamPath == "" {
+		steamPath, err = detectSteamPath()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to detect Steam path: %w", err)
+		}
+	}
+
+	library, err := steam.LoadLibrary(steamPath, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load game library: %w", err)
+	}
+	warnOfflineLibraries()
+	prefixes, err := steam.FindPrefixes(steamPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return prefixes, library, nil
+}
+
+// resolveGame returns the app ID of a game given by app ID, alias, or installed game name
+func resolveGame(game string, library *steam.Library) (string, error) {
+	appID := expandAliases([]string{game})[0]
+	if _, err := strconv.ParseUint(appID, 10, 64); err == nil {
+		return appID, nil
+	}
+	entry, err := library.LookupByName(game)
+	var ambiguous *steam.AmbiguousNameError
+	if errors.As(err, &ambiguous) {
+		return "", fmt.Errorf("%w - use the app ID instead", err)
+	}
+	if err != nil {
+		return "", err
+	}
+	return entry.AppID, nil
+}
+
+// findPrefix returns the prefix of the game given by app ID or installed game name
+func findPrefix(game string, prefixes []steam.Prefix, library *steam.Library) (steam.Prefix, error) {
+	appID, err := resolveGame(game, library)
+	if err != nil {
+		return steam.Prefix{}, err
+	}
+
+	for _, prefix := range prefixes {
+		if prefix.AppID == appID {
+			return prefix, nil
+		}
+	}
+	return steam.Prefix{}, fmt.Errorf("%w for app %s (it is created the first time the game runs through Proton)", steam.ErrPrefixNotFound, appID)
+}
+
+// prefixGameName names the game a prefix belongs to
+func prefixGameName(prefix steam.Prefix, library *steam.Library) string {
+	if game, found := library.LookupByID(prefix.AppID); found {
+		return game.Name
+	}
+	return "(not installed or non-Steam game)"
+}
+
+func runPrefixList(cmd *cobra.Command, args []string) error {
+	prefixes, library, err := loadPrefixes()
+	if err != nil {
+		return err
+	}
+	if len(prefixes) == 0 {
+		fmt.Println("No Proton prefixes found")
+		return nil
+	}
+
+	for _, prefix := range prefixes {
+		fmt.Printf("%-10s %-40s %s\n", prefix.AppID, prefixGameName(prefix, library), prefix.Path)
+	}
+	fmt.Printf("\n%d prefix(es)\n", len(prefixes))
+	return nil
+}
+
+func runPrefixSize(cmd *cobra.Command, args []string) error {
+	prefixes, library, err := loadPrefixes()
+	if err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		prefix, err := findPrefix(args[0], prefixes, library)
+		if err != nil {
+			return err
+		}
+		prefixes = []steam.Prefix{prefix}
+	}
+
+	sizes := make(map[string]int64, len(prefixes))
+	var total int64
+	for _, prefix := range prefixes {
+		size, err := steam.DirSize(prefix.Path)
+		if err != nil {
+			fmt.Printf("Warning: Failed to read all of %s: %v\n", prefix.Path, err)
+		}
+		sizes[prefix.AppID] = size
+		total += size
+	}
+
+	// Largest first
+	sort.SliceStable(prefixes, func(i, j int) bool { return sizes[prefixes[i].AppID] > sizes[prefixes[j].AppID] })
+	for _, prefix := range prefixes {
+		fmt.Printf("%10s  %-10s %s\n", formatSize(sizes[prefix.AppID]), prefix.AppID, prefixGameName(prefix, library))
+	}
+	if len(prefixes) > 1 {
+		fmt.Printf("%10s  total\n", formatSize(total))
+	}
+	return nil
+}
+
+func runPrefixOpen(cmd *cobra.Command, args []string) error {
+	prefixes, library, err := loadPrefixes()
+	if err != nil {
+		return err
+	}
+	prefix, err := findPrefix(args[0], prefixes, library)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Opening %s\n", prefix.Path)
+	if err := steam.OpenFile(prefix.Path); err != nil {
+		return fmt.Errorf("failed to open %s: %w", prefix.Path, err)
+	}
+	return nil
+}
+
+// formatSize formats a byte count with binary units, e.g. 1.5 GiB
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
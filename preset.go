@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/httpcache"
+	"github.com/zerkz/gsca/preset"
+	"github.com/zerkz/gsca/steam"
+)
+
+var presetCmd = &cobra.Command{
+	Use:   "preset",
+	Short: "Import and apply shared launch option presets",
+	Long: `Presets are named launch options shared as JSON files, e.g. by Steam Deck communities.
+A file holds a single preset or a pack of them:
+
+  {"presets": [
+    {"name": "deck-fsr", "description": "FSR upscaling via gamescope",
+     "args": "gamescope -F fsr -- %command%", "when": "linux",
+     "conflicts": ["deck-native-res"]},
+    {"name": "nvapi", "args": "PROTON_ENABLE_NVAPI=1 %command%", "when": "proton"}
+  ]}
+
+"when" limits a preset to games running through Proton ("proton") or natively ("native"),
+or to machines running linux, windows, macos or a Steam Deck ("steamdeck"). Combine
+conditions with commas, e.g. "steamdeck,proton"; several OS conditions mean any of them.
+The older "platforms" list (linux, windows, darwin) is read as OS conditions.
+
+Imported presets are stored in your config directory and applied by name.`,
+}
+
+var presetImportCmd = &cobra.Command{
+	Use:   "import <file-or-url>",
+	Short: "Import presets from a file or URL",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPresetImport,
+}
+
+var presetListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List imported presets",
+	Args:  cobra.NoArgs,
+	RunE:  runPresetList,
+}
+
+var presetApplyCmd = &cobra.Command{
+	Use:   "apply <name>...",
+	Short: "Set launch options from one or more presets",
+	Long: `Set the launch options of selected games from imported presets.
+
+Several presets are combined into one launch option string: everything before
+%command% in each preset stays before it, everything after stays after it.
+Presets that conflict with each other or don't support this platform are rejected.
+Presets with a "when" condition only apply where it holds. Proton and native are decided
+per game from the compatibility tool set in Steam and whether the game has a Linux build.
+
+Games are selected with --all, --allow, or --deny, exactly like 'gsca update'.
+
+Example:
+  gsca preset apply deck-fsr mangohud --allow list.txt`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runPresetApply,
+}
+
+func init() {
+	addUpdateFlags(presetApplyCmd)
+
+	presetCmd.AddCommand(presetImportCmd)
+	presetCmd.AddCommand(presetListCmd)
+	presetCmd.AddCommand(presetApplyCmd)
+	rootCmd.AddCommand(presetCmd)
+}
+
+// presetStore returns the store for imported presets
+func presetStore() (preset.Store, error) {
+	dir, err := preset.DefaultDir()
+	if err != nil {
+		return preset.Store{}, fmt.Errorf("failed to locate preset directory: %w", err)
+	}
+	return preset.Store{Dir: dir}, nil
+}
+
+func runPresetImport(cmd *cobra.Command, args []string) error {
+	source := args[0]
+
+	var data []byte
+	var err error
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		// An explicit import should pick up the latest version, so only --offline uses the cache
+		client, clientErr := httpcache.NewDefault(true, offlineMode)
+		if clientErr != nil {
+			return clientErr
+		}
+		data, err = client.Get(source, 0)
+	} else {
+		data, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read presets: %w", err)
+	}
+
+	presets, err := preset.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	store, err := presetStore()
+	if err != nil {
+		return err
+	}
+	for _, p := range presets {
+		replaced, err := store.Save(p)
+		if err != nil {
+			return err
+		}
+		if replaced {
+			fmt.Printf("Updated preset %s\n", p.Name)
+		} else {
+			fmt.Printf("Imported preset %s\n", p.Name)
+		}
+	}
+
+	return nil
+}
+
+func runPresetList(cmd *cobra.Command, args []string) error {
+	store, err := presetStore()
+	if err != nil {
+		return err
+	}
+	presets, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	if len(presets) == 0 {
+		fmt.Println("No presets imported yet. Use 'gsca preset import <file-or-url>'.")
+		return nil
+	}
+
+	for _, p := range presets {
+		fmt.Printf("%s\n", p.Name)
+		if p.Description != "" {
+			fmt.Printf("    %s\n", p.Description)
+		}
+		fmt.Printf("    Args: %s\n", p.Args)
+		if p.When != "" {
+			fmt.Printf("    When: %s\n", p.When)
+		}
+		if len(p.Conflicts) > 0 {
+			fmt.Printf("    Conflicts: %s\n", strings.Join(p.Conflicts, ", "))
+		}
+	}
+
+	return nil
+}
+
+func runPresetApply(cmd *cobra.Command, args []string) error {
+	store, err := presetStore()
+	if err != nil {
+		return err
+	}
+
+	presets := make([]preset.Preset, 0, len(args))
+	for _, name := range args {
+		p, err := store.Load(name)
+		if err != nil {
+			return err
+		}
+		if !p.SupportsOS(preset.OSName(runtime.GOOS)) {
+			return fmt.Errorf("preset %q is only for %s", p.Name, strings.Join(p.OSes(), ", "))
+		}
+		presets = append(presets, p)
+	}
+
+	if !preset.Conditional(presets) {
+		value, err := preset.Combine(presets)
+		if err != nil {
+			return err
+		}
+		return applyAppValue(launchOptionsUpdate(value))
+	}
+
+	// Machine conditions are fixed, so only Proton and native variants remain. Resolve
+	// both up front so conflicts are reported before anything is selected
+	conditions := preset.Conditions{OS: preset.OSName(runtime.GOOS), SteamDeck: steam.IsSteamDeck()}
+	variants := make(map[bool]string, 2)
+	for _, proton := range []bool{false, true} {
+		conditions.Proton = proton
+		value, err := preset.Resolve(presets, conditions)
+		if err != nil {
+			return err
+		}
+		variants[proton] = value
+	}
+
+	update := launchOptionsUpdate("")
+	update.values = func(appIDs []string) ([]steam.AppValue, error) {
+		proton := steam.ProtonGames(steamPath, appIDs)
+
+		var values []steam.AppValue
+		skipped := 0
+		for _, appID := range appIDs {
+			value := variants[proton[appID]]
+			if value == "" {
+				skipped++
+				continue
+			}
+			values = append(values, steam.AppValue{AppID: appID, Value: value})
+		}
+		if skipped > 0 {
+			fmt.Printf("Skipping %d game(s) none of the presets apply to\n", skipped)
+		}
+		return values, nil
+	}
+	return applyAppValue(update)
+}
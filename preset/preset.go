@@ -0,0 +1,311 @@
+// Package preset reads and stores shareable launch option presets, so communities
+// can distribute curated option packs as a single JSON file
+package preset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/zerkz/gsca/jsonstore"
+	"github.com/zerkz/gsca/steam"
+)
+
+// Conditions a preset's "when" can require
+const (
+	WhenProton    = "proton"
+	WhenNative    = "native"
+	WhenLinux     = "linux"
+	WhenWindows   = "windows"
+	WhenMacOS     = "macos"
+	WhenSteamDeck = "steamdeck"
+)
+
+// validConditions lists the accepted "when" conditions
+var validConditions = map[string]bool{
+	WhenProton:    true,
+	WhenNative:    true,
+	WhenLinux:     true,
+	WhenWindows:   true,
+	WhenMacOS:     true,
+	WhenSteamDeck: true,
+}
+
+// Preset is a named set of launch options
+type Preset struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Args        string `json:"args"`
+	// Platforms is the old way of giving When's OS conditions, with Go's names (darwin for
+	// macOS). Parse and Load move it into When
+	Platforms []string `json:"platforms,omitempty"`
+	// Conflicts names presets that must not be applied together with this one
+	Conflicts []string `json:"conflicts,omitempty"`
+	// When limits the preset to matching games and machines: comma-separated conditions
+	// (proton, native, linux, windows, macos, steamdeck) that must all hold, except that
+	// several OS conditions mean any of them. Empty means always
+	When string `json:"when,omitempty"`
+}
+
+// Conditions describe the game and machine a preset's "when" is checked against
+type Conditions struct {
+	Proton bool
+	// OS is linux, windows or macos
+	OS        string
+	SteamDeck bool
+}
+
+// OSName returns the "when" condition name for a GOOS value
+func OSName(goos string) string {
+	if goos == "darwin" {
+		return WhenMacOS
+	}
+	return goos
+}
+
+// Parse reads a preset file, accepting a single preset object or a {"presets": [...]} pack
+func Parse(data []byte) ([]Preset, error) {
+	var file struct {
+		Presets []Preset `json:"presets"`
+		Preset
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid preset file: %w", err)
+	}
+
+	presets := file.Presets
+	if presets == nil {
+		presets = []Preset{file.Preset}
+	} else if file.Name != "" {
+		return nil, fmt.Errorf("invalid preset file: use either a single preset or a \"presets\" list, not both")
+	}
+	if len(presets) == 0 {
+		return nil, fmt.Errorf("invalid preset file: no presets defined")
+	}
+
+	seen := make(map[string]bool)
+	for i := range presets {
+		presets[i].migrate()
+		p := presets[i]
+		if err := p.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[p.Name] {
+			return nil, fmt.Errorf("preset %q is defined more than once", p.Name)
+		}
+		seen[p.Name] = true
+	}
+
+	return presets, nil
+}
+
+// Validate checks that a preset is complete and its fields are well-formed
+func (p Preset) Validate() error {
+	if !validName(p.Name) {
+		return fmt.Errorf("invalid preset name %q (use lowercase letters, digits, '.', '_' and '-')", p.Name)
+	}
+	if strings.TrimSpace(p.Args) == "" {
+		return fmt.Errorf("preset %q has no args", p.Name)
+	}
+	if strings.Count(p.Args, steam.CommandToken) > 1 {
+		return fmt.Errorf("preset %q uses %s more than once", p.Name, steam.CommandToken)
+	}
+	for _, condition := range p.conditions() {
+		if !validConditions[condition] {
+			return fmt.Errorf("preset %q has unknown condition %q (use proton, native, linux, windows, macos or steamdeck)", p.Name, condition)
+		}
+	}
+	return nil
+}
+
+// migrate moves the OS names in Platforms into When
+func (p *Preset) migrate() {
+	conditions := p.conditions()
+	for _, platform := range p.Platforms {
+		if condition := OSName(platform); !slices.Contains(conditions, condition) {
+			conditions = append(conditions, condition)
+		}
+	}
+	p.When = strings.Join(conditions, ",")
+	p.Platforms = nil
+}
+
+// conditions splits When into its conditions
+func (p Preset) conditions() []string {
+	var conditions []string
+	for _, condition := range strings.Split(p.When, ",") {
+		if condition = strings.TrimSpace(condition); condition != "" {
+			conditions = append(conditions, condition)
+		}
+	}
+	return conditions
+}
+
+// Applies reports whether the preset's "when" conditions hold
+func (p Preset) Applies(c Conditions) bool {
+	if !p.SupportsOS(c.OS) {
+		return false
+	}
+	for _, condition := range p.conditions() {
+		switch condition {
+		case WhenProton:
+			if !c.Proton {
+				return false
+			}
+		case WhenNative:
+			if c.Proton {
+				return false
+			}
+		case WhenSteamDeck:
+			if !c.SteamDeck {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// OSes returns the operating systems the preset is limited to, empty if it runs everywhere
+func (p Preset) OSes() []string {
+	var oses []string
+	for _, condition := range p.conditions() {
+		switch condition {
+		case WhenLinux, WhenWindows, WhenMacOS:
+			oses = append(oses, condition)
+		}
+	}
+	return oses
+}
+
+// SupportsOS reports whether the preset applies on os, a "when" name such as macos
+func (p Preset) SupportsOS(os string) bool {
+	oses := p.OSes()
+	return len(oses) == 0 || slices.Contains(oses, os)
+}
+
+// Conditional reports whether any preset has a "when" condition, so the
+// combined args can differ per game
+func Conditional(presets []Preset) bool {
+	for _, p := range presets {
+		if p.When != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve combines the presets whose conditions hold for a game and machine
+// It returns an empty string if none apply
+func Resolve(presets []Preset, c Conditions) (string, error) {
+	var applicable []Preset
+	for _, p := range presets {
+		if p.Applies(c) {
+			applicable = append(applicable, p)
+		}
+	}
+	return Combine(applicable)
+}
+
+// Combine merges presets into one launch option string: every preset's environment
+// variables first, then their wrapper commands, then %command% and the game arguments
+// (args without %command% are game arguments). Conflicting presets are rejected
+func Combine(presets []Preset) (string, error) {
+	names := make(map[string]bool, len(presets))
+	for _, p := range presets {
+		names[p.Name] = true
+	}
+	for _, p := range presets {
+		for _, conflict := range p.Conflicts {
+			if names[conflict] {
+				return "", fmt.Errorf("presets %q and %q conflict", p.Name, conflict)
+			}
+		}
+	}
+
+	// Without %command%, Steam passes the args to the game
+	combined := steam.ParseLaunchOptions("")
+	for _, p := range presets {
+		if strings.Contains(p.Args, steam.CommandToken) {
+			combined = steam.ParseLaunchOptions(steam.CommandToken)
+			break
+		}
+	}
+	for _, p := range presets {
+		opts := steam.ParseLaunchOptions(p.Args)
+		for _, env := range opts.Env {
+			combined.SetEnv(env.Name, env.Value)
+		}
+		combined.Wrappers = append(combined.Wrappers, opts.Wrappers...)
+		combined.Args = append(combined.Args, opts.Args...)
+	}
+	return combined.String(), nil
+}
+
+// Store keeps imported presets as one JSON file each in Dir
+type Store struct {
+	Dir string
+}
+
+// DefaultDir returns the preset directory under the user's config directory
+func DefaultDir() (string, error) {
+	return jsonstore.DefaultDir("presets")
+}
+
+// validName is a store name in lower case, as presets are named in files and on the command line
+func validName(name string) bool {
+	return jsonstore.ValidName(name) && name == strings.ToLower(name)
+}
+
+// Save writes a preset, replacing any existing preset with the same name
+// It reports whether a preset was replaced
+func (s Store) Save(p Preset) (bool, error) {
+	p.migrate()
+	if err := p.Validate(); err != nil {
+		return false, err
+	}
+
+	replaced, err := jsonstore.Dir(s.Dir).Save(p.Name, p)
+	if err != nil {
+		return false, fmt.Errorf("failed to save preset %q: %w", p.Name, err)
+	}
+	return replaced, nil
+}
+
+// Load reads a preset by name
+func (s Store) Load(name string) (Preset, error) {
+	if !validName(name) {
+		return Preset{}, fmt.Errorf("invalid preset name %q", name)
+	}
+
+	var p Preset
+	err := jsonstore.Dir(s.Dir).Load(name, &p)
+	if errors.Is(err, os.ErrNotExist) {
+		return Preset{}, fmt.Errorf("preset %q not found (import it with 'gsca preset import')", name)
+	}
+	if err != nil {
+		return Preset{}, fmt.Errorf("failed to read preset %q: %w", name, err)
+	}
+	p.migrate()
+	return p, p.Validate()
+}
+
+// List returns all stored presets sorted by name
+func (s Store) List() ([]Preset, error) {
+	names, err := jsonstore.Dir(s.Dir).Names()
+	if err != nil {
+		return nil, err
+	}
+
+	presets := make([]Preset, 0, len(names))
+	for _, name := range names {
+		p, err := s.Load(name)
+		if err != nil {
+			return nil, err
+		}
+		presets = append(presets, p)
+	}
+	return presets, nil
+}
@@ -0,0 +1,226 @@
+package preset
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantNames []string
+		wantErr   bool
+	}{
+		{
+			name:      "single preset",
+			data:      `{"name": "mangohud", "args": "mangohud %command%"}`,
+			wantNames: []string{"mangohud"},
+		},
+		{
+			name: "pack",
+			data: `{"presets": [
+				{"name": "deck-fsr", "args": "gamescope -F fsr -- %command%", "platforms": ["linux"]},
+				{"name": "no-intro", "args": "-novid"}
+			]}`,
+			wantNames: []string{"deck-fsr", "no-intro"},
+		},
+		{name: "invalid JSON", data: `{"name":`, wantErr: true},
+		{name: "empty pack", data: `{"presets": []}`, wantErr: true},
+		{name: "missing args", data: `{"name": "empty"}`, wantErr: true},
+		{name: "invalid name", data: `{"name": "../evil", "args": "-novid"}`, wantErr: true},
+		{name: "unknown platform", data: `{"name": "x", "args": "-novid", "platforms": ["steamos"]}`, wantErr: true},
+		{name: "command twice", data: `{"name": "x", "args": "%command% %command%"}`, wantErr: true},
+		{name: "unknown condition", data: `{"name": "x", "args": "-novid", "when": "wine"}`, wantErr: true},
+		{name: "unknown combined condition", data: `{"name": "x", "args": "-novid", "when": "steamdeck,darwin"}`, wantErr: true},
+		{
+			name:      "conditional",
+			data:      `{"name": "nvapi", "args": "PROTON_ENABLE_NVAPI=1 %command%", "when": "proton"}`,
+			wantNames: []string{"nvapi"},
+		},
+		{
+			name:    "duplicate names",
+			data:    `{"presets": [{"name": "x", "args": "-a"}, {"name": "x", "args": "-b"}]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			presets, err := Parse([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var names []string
+			for _, p := range presets {
+				names = append(names, p.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("Parse() names = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestCombine(t *testing.T) {
+	tests := []struct {
+		name    string
+		presets []Preset
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "single",
+			presets: []Preset{{Name: "a", Args: "mangohud %command%"}},
+			want:    "mangohud %command%",
+		},
+		{
+			name: "wrappers and game args",
+			presets: []Preset{
+				{Name: "a", Args: "PROTON_ENABLE_NVAPI=1 %command% -dx12"},
+				{Name: "b", Args: "mangohud %command%"},
+				{Name: "c", Args: "-novid"},
+			},
+			want: "PROTON_ENABLE_NVAPI=1 mangohud %command% -dx12 -novid",
+		},
+		{
+			name: "env after another preset's wrapper",
+			presets: []Preset{
+				{Name: "deck-fsr", Args: "gamescope -F fsr -- %command%"},
+				{Name: "nvapi", Args: "PROTON_ENABLE_NVAPI=1 %command%"},
+			},
+			want: "PROTON_ENABLE_NVAPI=1 gamescope -F fsr -- %command%",
+		},
+		{
+			name:    "game args only with command",
+			presets: []Preset{{Name: "a", Args: "%command% -novid"}, {Name: "b", Args: "-high"}},
+			want:    "%command% -novid -high",
+		},
+		{
+			name:    "no command placeholder",
+			presets: []Preset{{Name: "a", Args: "-novid"}, {Name: "b", Args: "-high"}},
+			want:    "-novid -high",
+		},
+		{
+			name:    "conflict",
+			presets: []Preset{{Name: "a", Args: "-a", Conflicts: []string{"b"}}, {Name: "b", Args: "-b"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Combine(tt.presets)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Combine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Combine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	presets := []Preset{
+		{Name: "nvapi", Args: "PROTON_ENABLE_NVAPI=1 %command%", When: WhenProton},
+		{Name: "gamemode", Args: "gamemoderun %command%", When: WhenNative},
+		{Name: "novid", Args: "-novid"},
+		{Name: "deck-fsr", Args: "gamescope -F fsr -- %command%", When: "steamdeck, proton"},
+		{Name: "dx12", Args: "-dx12", When: WhenWindows},
+	}
+	if !Conditional(presets) || Conditional(presets[2:3]) {
+		t.Error("Conditional() should only be true when a preset has a condition")
+	}
+
+	tests := []struct {
+		name       string
+		presets    []Preset
+		conditions Conditions
+		want       string
+	}{
+		{
+			name:       "proton",
+			presets:    presets,
+			conditions: Conditions{Proton: true, OS: WhenLinux},
+			want:       "PROTON_ENABLE_NVAPI=1 %command% -novid",
+		},
+		{
+			name:       "native",
+			presets:    presets,
+			conditions: Conditions{OS: WhenLinux},
+			want:       "gamemoderun %command% -novid",
+		},
+		{
+			name:       "steam deck proton",
+			presets:    presets,
+			conditions: Conditions{Proton: true, OS: WhenLinux, SteamDeck: true},
+			want:       "PROTON_ENABLE_NVAPI=1 gamescope -F fsr -- %command% -novid",
+		},
+		{
+			name:       "windows",
+			presets:    presets,
+			conditions: Conditions{OS: OSName("windows")},
+			want:       "gamemoderun %command% -novid -dx12",
+		},
+		{name: "nothing applies", presets: presets[:1], want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(tt.presets, tt.conditions)
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := Store{Dir: t.TempDir()}
+	p := Preset{Name: "deck-fsr", Args: "gamescope -F fsr -- %command%", When: WhenLinux}
+
+	if replaced, err := store.Save(p); err != nil || replaced {
+		t.Fatalf("Save() = %v, %v, want false, nil", replaced, err)
+	}
+	p.Description = "FSR upscaling"
+	if replaced, err := store.Save(p); err != nil || !replaced {
+		t.Fatalf("Save() of an existing preset = %v, %v, want true, nil", replaced, err)
+	}
+
+	got, err := store.Load("deck-fsr")
+	if err != nil || !reflect.DeepEqual(got, p) {
+		t.Errorf("Load() = %+v, %v, want %+v", got, err, p)
+	}
+	if _, err := store.Load("missing"); err == nil {
+		t.Error("Load() of a missing preset error = nil, want error")
+	}
+
+	presets, err := store.List()
+	if err != nil || len(presets) != 1 {
+		t.Errorf("List() = %v, %v, want 1 preset", presets, err)
+	}
+
+	if !p.SupportsOS(WhenLinux) || p.SupportsOS(WhenWindows) {
+		t.Error("SupportsOS() should only accept linux")
+	}
+
+	// Presets saved with the old platforms field load with the same conditions in when
+	legacy := `{"name": "old", "args": "-novid", "platforms": ["linux", "darwin"], "when": "proton"}`
+	if err := os.WriteFile(filepath.Join(store.Dir, "old.json"), []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old, err := store.Load("old")
+	if err != nil || old.When != "proton,linux,macos" || old.Platforms != nil {
+		t.Errorf("Load() of a legacy preset = %+v, %v, want when proton,linux,macos", old, err)
+	}
+	if !old.Applies(Conditions{Proton: true, OS: WhenMacOS}) || old.Applies(Conditions{Proton: true, OS: WhenWindows}) {
+		t.Error("Applies() should accept either OS condition and nothing else")
+	}
+}
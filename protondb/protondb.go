@@ -0,0 +1,144 @@
+// Package protondb looks up ProtonDB compatibility ratings and the launch options players
+// report for games, through the httpcache
+package protondb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zerkz/gsca/httpcache"
+)
+
+// Default endpoints. ProtonDB publishes summaries itself; individual reports come from a
+// community mirror of its data dumps
+const (
+	DefaultSummaryURL = "https://www.protondb.com/api/v1/reports/summaries/%s.json"
+	DefaultReportsURL = "https://protondb.max-p.me/games/%s/reports/"
+)
+
+// cacheTTL is how long lookups are reused; ratings and reports change slowly
+const cacheTTL = 7 * 24 * time.Hour
+
+// commandToken is Steam's placeholder for the game's executable
+const commandToken = "%command%"
+
+// Client looks up games on ProtonDB
+type Client struct {
+	HTTP *httpcache.Client
+	// SummaryURL and ReportsURL are formatted with the app ID
+	SummaryURL string
+	ReportsURL string
+}
+
+// New creates a client using the default endpoints
+func New(cache *httpcache.Client) *Client {
+	return &Client{HTTP: cache, SummaryURL: DefaultSummaryURL, ReportsURL: DefaultReportsURL}
+}
+
+// Summary is a game's overall rating
+type Summary struct {
+	// Tier is the rating: platinum, gold, silver, bronze, or borked
+	Tier       string `json:"tier"`
+	Confidence string `json:"confidence"`
+	Total      int    `json:"total"`
+}
+
+// Report is one player's report
+type Report struct {
+	Notes     string `json:"notes"`
+	Responses struct {
+		LaunchOptions string `json:"launchOptions"`
+	} `json:"responses"`
+}
+
+// Summary returns the rating of a game
+func (c *Client) Summary(appID string) (*Summary, error) {
+	var summary Summary
+	if err := c.getJSON(fmt.Sprintf(c.SummaryURL, appID), &summary); err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// Reports returns the reports for a game
+func (c *Client) Reports(appID string) ([]Report, error) {
+	var reports []Report
+	if err := c.getJSON(fmt.Sprintf(c.ReportsURL, appID), &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+func (c *Client) getJSON(url string, v any) error {
+	body, err := c.HTTP.Get(url, cacheTTL)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("unexpected response from %s: %w", url, err)
+	}
+	return nil
+}
+
+// LaunchOptions returns the launch strings in a report: its launch options field, and any
+// used in its notes
+func (r Report) LaunchOptions() []string {
+	var found []string
+	if value := strings.TrimSpace(r.Responses.LaunchOptions); value != "" {
+		found = append(found, value)
+	}
+	for _, line := range strings.Split(r.Notes, "\n") {
+		if value := extractLaunchOptions(line); value != "" {
+			found = append(found, value)
+		}
+	}
+	return found
+}
+
+// extractLaunchOptions picks a launch string out of free text: a quoted or backticked span
+// containing %command% if there is one, otherwise %command% with the environment variables
+// and programs right before it and the flags after it
+func extractLaunchOptions(line string) string {
+	idx := strings.Index(line, commandToken)
+	if idx < 0 {
+		return ""
+	}
+
+	for _, quote := range []string{"`", `"`} {
+		start := strings.LastIndex(line[:idx], quote)
+		end := strings.Index(line[idx:], quote)
+		if start >= 0 && end >= 0 {
+			return strings.TrimSpace(line[start+1 : idx+end])
+		}
+	}
+
+	before := strings.Fields(line[:idx])
+	first := len(before)
+	for first > 0 && isLaunchPrefix(before[first-1]) {
+		first--
+	}
+	parts := append(before[first:], commandToken)
+	for _, token := range strings.Fields(line[idx+len(commandToken):]) {
+		if !strings.HasPrefix(token, "-") && !strings.HasPrefix(token, "+") {
+			break
+		}
+		parts = append(parts, strings.TrimRight(token, ".,;)"))
+	}
+	return strings.Join(parts, " ")
+}
+
+// isLaunchPrefix reports whether a word of prose can be part of what goes before %command%:
+// an environment variable assignment or a lower-case program name
+func isLaunchPrefix(word string) bool {
+	if eq := strings.IndexByte(word, '='); eq > 0 {
+		return strings.ToUpper(word[:eq]) == word[:eq] || word[:eq] == "SteamDeck"
+	}
+	for _, r := range word {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '/' || r == '.') {
+			return false
+		}
+	}
+	return word != ""
+}
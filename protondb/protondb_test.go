@@ -0,0 +1,84 @@
+package protondb
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/zerkz/gsca/httpcache"
+)
+
+func TestClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/summaries/620.json":
+			fmt.Fprint(w, `{"tier": "platinum", "confidence": "strong", "total": 120}`)
+		case "/reports/620":
+			_, _ = w.Write([]byte(`[{"notes": "Runs great"}, {"responses": {"launchOptions": "gamemoderun %command%"}}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cache := httpcache.New(t.TempDir())
+	cache.MinInterval = 0
+	cache.MaxRetries = 0
+	client := &Client{HTTP: cache, SummaryURL: server.URL + "/summaries/%s.json", ReportsURL: server.URL + "/reports/%s"}
+
+	summary, err := client.Summary("620")
+	if err != nil || summary.Tier != "platinum" || summary.Total != 120 {
+		t.Errorf("Summary() = %+v, %v, want platinum with 120 reports", summary, err)
+	}
+	reports, err := client.Reports("620")
+	if err != nil || len(reports) != 2 || reports[1].Responses.LaunchOptions != "gamemoderun %command%" {
+		t.Errorf("Reports() = %+v, %v, want both reports", reports, err)
+	}
+	if _, err := client.Summary("1"); err == nil {
+		t.Error("Summary() of an unknown game error = nil, want error")
+	}
+}
+
+func TestLaunchOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		report Report
+		want   []string
+	}{
+		{
+			name:   "launch options field",
+			report: newReport("", "DXVK_ASYNC=1 %command%"),
+			want:   []string{"DXVK_ASYNC=1 %command%"},
+		},
+		{
+			name:   "backticks",
+			report: newReport("Crashes on start.\nFixed with `PROTON_USE_WINED3D=1 %command% -dx11` in the launch options", ""),
+			want:   []string{"PROTON_USE_WINED3D=1 %command% -dx11"},
+		},
+		{
+			name:   "prose",
+			report: newReport("Set PROTON_ENABLE_NVAPI=1 gamemoderun %command% -novid, then it works.", ""),
+			want:   []string{"PROTON_ENABLE_NVAPI=1 gamemoderun %command% -novid"},
+		},
+		{
+			name:   "no launch options",
+			report: newReport("Works out of the box", ""),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.report.LaunchOptions(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LaunchOptions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func newReport(notes, launchOptions string) Report {
+	r := Report{Notes: notes}
+	r.Responses.LaunchOptions = launchOptions
+	return r
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/config"
+	"github.com/zerkz/gsca/i18n"
+	"github.com/zerkz/gsca/remote"
+	"github.com/zerkz/gsca/steam"
+)
+
+// remoteLocal is the --remote value that ignores the config file's remote section
+const remoteLocal = "local"
+
+var (
+	remoteTarget string
+
+	// remoteHost is the machine whose Steam is managed, from --remote or the config file;
+	// nil when it's this one
+	remoteHost *remote.Host
+	// remoteMirror is the local copy of remoteHost's Steam files, fetched on first use
+	remoteMirror *remote.Mirror
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&remoteTarget, "remote", "", `Manage Steam on another machine over SSH, e.g. deck@steamdeck ("local" ignores the config file's remote section)`)
+	rootCmd.PersistentPreRunE = setupRemote
+}
+
+// setupRemote picks the machine to manage before any command runs. With a remote machine,
+// --steam-path is the install there
+func setupRemote(cmd *cobra.Command, args []string) error {
+	if remoteTarget == remoteLocal {
+		return nil
+	}
+	// A broken config file is reported by the steps that need it
+	cfg, err := loadConfig()
+	if err != nil {
+		cfg = &config.Config{}
+	}
+	host := &remote.Host{Target: remoteTarget, SteamPath: steamPath}
+	if cfg.Remote != nil {
+		if host.Target == "" {
+			host.Target = cfg.Remote.Host
+		}
+		if host.SteamPath == "" {
+			host.SteamPath = cfg.Remote.SteamPath
+		}
+		host.SSHArgs = cfg.Remote.SSHArgs
+		host.StartCommand = cfg.Remote.StartCommand
+		host.StopCommand = cfg.Remote.StopCommand
+	}
+	if host.Target == "" {
+		return nil
+	}
+	remoteHost = host
+	steamPath = ""
+	return nil
+}
+
+// mirrorsDir is where the copies of remote machines' Steam files are kept
+func mirrorsDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "gsca", "remote"), nil
+}
+
+// fetchRemote copies the remote machine's Steam files, returning the path of the copy
+func fetchRemote() (string, error) {
+	if remoteMirror != nil {
+		return remoteMirror.Dir, nil
+	}
+	mirrors, err := mirrorsDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(mirrors, mirrorDirName(remoteHost.Target))
+
+	fmt.Printf("Fetching Steam files from %s...\n", remoteHost.Target)
+	mirror, err := remote.Fetch(remoteHost, dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch Steam files from %s: %w", remoteHost.Target, err)
+	}
+	remoteMirror = mirror
+	fmt.Printf("Using %s:%s (copied to %s)\n", remoteHost.Target, remoteHost.SteamPath, dir)
+	return dir, nil
+}
+
+// refetchRemote copies the remote machine's Steam files again after Steam there exited,
+// as it saves its settings on the way out. A copy that already has changes is kept, and
+// pushing it then fails instead of overwriting what Steam saved
+func refetchRemote() error {
+	if remoteMirror == nil {
+		return nil
+	}
+	changed, err := remoteMirror.Changed()
+	if err != nil || len(changed) > 0 {
+		return err
+	}
+	remoteMirror = nil
+	_, err = fetchRemote()
+	return err
+}
+
+// localConfigFor returns where to find a localconfig.vdf recorded by an earlier run, such
+// as in a transaction or snapshot. With --remote that's in a fresh copy of the remote
+// machine's files; files recorded for another machine are refused either way
+func localConfigFor(localConfigPath string) (string, error) {
+	if remoteHost == nil {
+		if mirrors, err := mirrorsDir(); err == nil && isWithin(mirrors, localConfigPath) {
+			return "", fmt.Errorf("%s was recorded with --remote; run the command with --remote for the same machine", localConfigPath)
+		}
+		return localConfigPath, nil
+	}
+	dir, err := fetchRemote()
+	if err != nil {
+		return "", err
+	}
+	if !isWithin(dir, localConfigPath) {
+		return "", fmt.Errorf("%s isn't on %s; it was recorded for another machine", localConfigPath, remoteHost.Target)
+	}
+	return localConfigPath, nil
+}
+
+// isWithin reports whether file is inside dir
+func isWithin(dir, file string) bool {
+	rel, err := filepath.Rel(dir, file)
+	return err == nil && !filepath.IsAbs(rel) && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// forgetRemote sends pending changes to the remote machine and drops the copy, so the next
+// use fetches it again
+func forgetRemote() error {
+	err := pushRemote()
+	remoteMirror = nil
+	return err
+}
+
+// mirrorDirName turns an ssh destination into a directory name
+func mirrorDirName(target string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune(".-_@", r) {
+			return r
+		}
+		return '_'
+	}, target)
+}
+
+// pushRemote copies the files changed in the local copy back to the remote machine
+func pushRemote() error {
+	if remoteMirror == nil {
+		return nil
+	}
+	pushed, err := remoteMirror.Push()
+	if len(pushed) > 0 {
+		fmt.Printf("Copied %d file(s) back to %s\n", len(pushed), remoteHost.Target)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to copy changes back to %s: %w", remoteHost.Target, err)
+	}
+	return nil
+}
+
+// isSteamRunning checks for Steam on the machine being managed
+func isSteamRunning() (bool, error) {
+	if remoteHost != nil {
+		return remoteHost.SteamRunning()
+	}
+	return steam.IsSteamRunning()
+}
+
+// restartRemoteSteam sends the changes to the remote machine and starts Steam there again
+func restartRemoteSteam() {
+	if err := pushRemote(); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	args := strings.Fields(restartArgs)
+	if restartMinimized && !slices.Contains(args, "-silent") {
+		args = append(args, "-silent")
+	}
+	if restartBigPicture {
+		args = append(args, "steam://open/bigpicture")
+	}
+	if err := remoteHost.StartSteam(args); err != nil {
+		fmt.Println(i18n.T(i18n.SteamStartFailed, err))
+		fmt.Println(i18n.T(i18n.StartSteamManually))
+		return
+	}
+	fmt.Println(i18n.T(i18n.SteamStarted))
+	if restartWait > 0 {
+		fmt.Println("Not waiting for Steam to log in (--restart-wait only works on this machine)")
+	}
+}
@@ -0,0 +1,123 @@
+// Package remote manages Steam on another machine over SSH. It runs the system's ssh client,
+// so keys, agents and ~/.ssh/config work as usual, and needs nothing on the other machine
+// beyond a POSIX shell, tar, mktemp and Steam itself
+package remote
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// sshCommand is the ssh client to run; tests replace it
+var sshCommand = "ssh"
+
+// steamDirs are where Steam is usually installed on Linux, including SteamOS
+var steamDirs = []string{
+	"$HOME/.local/share/Steam",
+	"$HOME/.steam/steam",
+	"$HOME/.var/app/com.valvesoftware.Steam/.local/share/Steam",
+	"$HOME/snap/steam/common/.local/share/Steam",
+}
+
+// Host is a machine reached with ssh
+type Host struct {
+	// Target is the ssh destination, e.g. deck@steamdeck
+	Target string
+	// SSHArgs are extra options for ssh, e.g. -p 2222
+	SSHArgs []string
+	// SteamPath is the Steam install on the host; DetectSteamPath fills it in if empty
+	SteamPath string
+	// StartCommand and StopCommand are run instead of "steam" and "steam -shutdown"
+	StartCommand string
+	StopCommand  string
+}
+
+// Run runs a shell command on the host with stdin as its input, which may be nil, and
+// returns what it printed
+func (h *Host) Run(command string, stdin io.Reader) ([]byte, error) {
+	args := append(append([]string{}, h.SSHArgs...), h.Target, command)
+	cmd := exec.Command(sshCommand, args...)
+	cmd.Stdin = stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return out, fmt.Errorf("%s: %w: %s", h.Target, err, msg)
+		}
+		return out, fmt.Errorf("%s: %w", h.Target, err)
+	}
+	return out, nil
+}
+
+// DetectSteamPath finds the Steam install in the usual places on the host
+func (h *Host) DetectSteamPath() (string, error) {
+	if h.SteamPath != "" {
+		return h.SteamPath, nil
+	}
+	script := fmt.Sprintf(`for d in %s; do if [ -d "$d/steamapps" ]; then cd "$d" && pwd -P; exit 0; fi; done`, quoteDirs(steamDirs))
+	out, err := h.Run(script, nil)
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSpace(string(out))
+	if path == "" {
+		return "", fmt.Errorf("no Steam install found on %s (set steam_path in the config's remote section)", h.Target)
+	}
+	h.SteamPath = path
+	return path, nil
+}
+
+// SteamRunning reports whether Steam is running on the host
+func (h *Host) SteamRunning() (bool, error) {
+	out, err := h.Run(`if pgrep -x steam >/dev/null; then echo running; fi`, nil)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(out)) == "running", nil
+}
+
+// CloseSteam asks Steam on the host to shut down
+func (h *Host) CloseSteam() error {
+	command := h.StopCommand
+	if command == "" {
+		command = "steam -shutdown"
+	}
+	_, err := h.Run(withDisplay(command)+" >/dev/null 2>&1", nil)
+	return err
+}
+
+// StartSteam starts Steam on the host with args, detached from the ssh session
+func (h *Host) StartSteam(args []string) error {
+	command := h.StartCommand
+	if command == "" {
+		command = "steam"
+	}
+	for _, arg := range args {
+		command += " " + quote(arg)
+	}
+	_, err := h.Run(withDisplay("nohup "+command)+" >/dev/null 2>&1 &", nil)
+	return err
+}
+
+// withDisplay runs command on the host's display, as ssh sessions don't have one
+func withDisplay(command string) string {
+	return `DISPLAY="${DISPLAY:-:0}" ` + command
+}
+
+// quote quotes s for the host's shell
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// quoteDirs quotes directories that may start with $HOME, leaving the variable to the shell
+func quoteDirs(dirs []string) string {
+	quoted := make([]string, len(dirs))
+	for i, dir := range dirs {
+		quoted[i] = `"` + dir + `"`
+	}
+	return strings.Join(quoted, " ")
+}
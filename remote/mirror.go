@@ -0,0 +1,378 @@
+package remote
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zerkz/gsca/vdf"
+)
+
+// steamFiles are the files gsca reads or writes in a Steam install, as shell globs. The
+// appinfo cache is left out: it's large and only adds names and tags
+var steamFiles = []string{
+	"config/config.vdf",
+	"config/loginusers.vdf",
+	"config/libraryfolders.vdf",
+	"steamapps/libraryfolders.vdf",
+	"steamapps/appmanifest_*.acf",
+	"userdata/*/config/localconfig.vdf*",
+	"userdata/*/config/gsca-backups.sha256",
+	"userdata/*/7/remote/sharedconfig.vdf",
+}
+
+// libraryFiles are the files read from the host's other library folders
+var libraryFiles = []string{"steamapps/appmanifest_*.acf"}
+
+// Mirror is a local copy of the Steam files on a host. gsca works on the copy as if it were
+// a local install, and Push sends back what changed
+type Mirror struct {
+	Host *Host
+	// Dir holds the copy of the host's Steam install; other library folders are copied
+	// under Dir/libraries
+	Dir string
+
+	// dirs maps each local directory to the one on the host it copies
+	dirs map[string]string
+	// hashes are the contents of each file as fetched or last pushed
+	hashes map[string][sha256.Size]byte
+	// local are files rewritten to point at the copy, which must never be pushed
+	local map[string]bool
+}
+
+// Fetch copies the host's Steam files into dir, replacing an earlier copy
+func Fetch(host *Host, dir string) (*Mirror, error) {
+	steamPath, err := host.DetectSteamPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("failed to clear %s: %w", dir, err)
+	}
+	m := &Mirror{
+		Host:   host,
+		Dir:    dir,
+		dirs:   make(map[string]string),
+		hashes: make(map[string][sha256.Size]byte),
+		local:  make(map[string]bool),
+	}
+	if err := m.fetchDir(dir, steamPath, steamFiles); err != nil {
+		return nil, err
+	}
+	if err := m.fetchLibraries(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// fetchDir copies the files matching patterns from remoteDir on the host to localDir
+func (m *Mirror) fetchDir(localDir, remoteDir string, patterns []string) error {
+	out, err := m.Host.Run(tarScript(remoteDir, patterns), nil)
+	if err != nil {
+		return fmt.Errorf("failed to copy files from %s: %w", remoteDir, err)
+	}
+	if err := os.MkdirAll(localDir, 0755); err != nil {
+		return err
+	}
+	m.dirs[localDir] = remoteDir
+
+	files, err := extractTar(bytes.NewReader(out), localDir)
+	for _, file := range files {
+		if err := m.remember(file); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// tarScript is a shell command archiving the regular files in dir that match patterns,
+// which are globs or quoted names
+func tarScript(dir string, patterns []string) string {
+	return fmt.Sprintf(`cd %s || exit 1; set --; for f in %s; do if [ -f "$f" ]; then set -- "$@" "$f"; fi; done; if [ $# -gt 0 ]; then tar -cf - "$@"; fi`,
+		quote(dir), strings.Join(patterns, " "))
+}
+
+// fetchLibraries copies the appmanifests of the host's other library folders, and points the
+// copied libraryfolders.vdf files at them. A library that can't be read is left empty, so
+// it shows up as not mounted
+func (m *Mirror) fetchLibraries() error {
+	for _, name := range []string{filepath.Join("steamapps", "libraryfolders.vdf"), filepath.Join("config", "libraryfolders.vdf")} {
+		file := filepath.Join(m.Dir, name)
+		data, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		root, err := vdf.NewParser(bytes.NewReader(data)).Parse()
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+
+		libraries := vdf.FindNode(root, "libraryfolders")
+		if libraries == nil {
+			continue
+		}
+		for i, library := range libraries.Children {
+			for _, field := range library.Children {
+				if !vdf.KeyEqual(field.Key, "path") || field.IsObject {
+					continue
+				}
+				field.Value = m.libraryDir(field.Value, i)
+			}
+		}
+
+		var buf bytes.Buffer
+		if err := vdf.Write(&buf, root, 0); err != nil {
+			return err
+		}
+		if err := os.WriteFile(file, buf.Bytes(), 0644); err != nil {
+			return err
+		}
+		m.local[file] = true
+	}
+	return nil
+}
+
+// libraryDir returns the local copy of the library folder at remotePath, fetching it the
+// first time
+func (m *Mirror) libraryDir(remotePath string, index int) string {
+	if path.Clean(remotePath) == path.Clean(m.Host.SteamPath) {
+		return filepath.ToSlash(m.Dir)
+	}
+	for local, remote := range m.dirs {
+		if remote == remotePath {
+			return filepath.ToSlash(local)
+		}
+	}
+	local := filepath.Join(m.Dir, "libraries", strconv.Itoa(index))
+	if err := m.fetchDir(local, remotePath, libraryFiles); err != nil {
+		// Recorded anyway, so files there are never taken for the install's
+		m.dirs[local] = remotePath
+	}
+	return filepath.ToSlash(local)
+}
+
+// remember records a file's contents as matching the host's
+func (m *Mirror) remember(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	m.hashes[file] = sha256.Sum256(data)
+	return nil
+}
+
+// Changed returns the files that were changed or created since they were fetched or last
+// pushed, grouped by the local directory they belong to
+func (m *Mirror) Changed() (map[string][]string, error) {
+	changed := make(map[string][]string)
+	err := filepath.WalkDir(m.Dir, func(file string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || m.local[file] {
+			return err
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if hash, ok := m.hashes[file]; ok && hash == sha256.Sum256(data) {
+			return nil
+		}
+		if dir := m.owner(file); dir != "" {
+			changed[dir] = append(changed[dir], file)
+		}
+		return nil
+	})
+	return changed, err
+}
+
+// owner returns the deepest copied directory containing file
+func (m *Mirror) owner(file string) string {
+	owner := ""
+	for dir := range m.dirs {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(dir) > len(owner) {
+			owner = dir
+		}
+	}
+	return owner
+}
+
+// Push copies the files that changed in the mirror back to the host, and returns their
+// paths there. Files already pushed aren't sent again. Nothing is sent if any of them also
+// changed on the host since it was fetched, as Steam does when it exits
+func (m *Mirror) Push() ([]string, error) {
+	changed, err := m.Changed()
+	if err != nil {
+		return nil, err
+	}
+	dirs := make([]string, 0, len(changed))
+	for dir := range changed {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		if err := m.checkUnchanged(dir, changed[dir]); err != nil {
+			return nil, err
+		}
+	}
+
+	var pushed []string
+	for _, dir := range dirs {
+		files := changed[dir]
+		var archive bytes.Buffer
+		if err := writeTar(&archive, dir, files); err != nil {
+			return pushed, err
+		}
+		remoteDir := m.dirs[dir]
+		if _, err := m.Host.Run(replaceScript(remoteDir, dir, files), &archive); err != nil {
+			return pushed, fmt.Errorf("failed to copy files to %s: %w", remoteDir, err)
+		}
+		for _, file := range files {
+			if err := m.remember(file); err != nil {
+				return pushed, err
+			}
+			rel, _ := filepath.Rel(dir, file)
+			pushed = append(pushed, path.Join(remoteDir, filepath.ToSlash(rel)))
+		}
+	}
+	return pushed, nil
+}
+
+// replaceScript is a shell command unpacking an archive of files, in the local directory
+// dir, into a temporary directory next to them in remoteDir and renaming each over the
+// original, so a dropped connection never leaves a file half written
+func replaceScript(remoteDir, dir string, files []string) string {
+	rels := make([]string, len(files))
+	for i, file := range files {
+		rel, _ := filepath.Rel(dir, file)
+		rels[i] = quote(filepath.ToSlash(rel))
+	}
+	return fmt.Sprintf(`cd %s || exit 1; tmp=$(mktemp -d .gsca-push.XXXXXX) || exit 1; trap 'rm -rf "$tmp"' EXIT; `+
+		`tar -xf - -C "$tmp" || exit 1; for f in %s; do mkdir -p "$(dirname "$f")" && mv -f "$tmp/$f" "$f" || exit 1; done`,
+		quote(remoteDir), strings.Join(rels, " "))
+}
+
+// checkUnchanged fails if any of files, in the local directory dir, differs on the host from
+// the copy fetched or last pushed. Files new in the copy must not exist on the host either
+func (m *Mirror) checkUnchanged(dir string, files []string) error {
+	rels := make([]string, len(files))
+	for i, file := range files {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		rels[i] = quote(filepath.ToSlash(rel))
+	}
+	remoteDir := m.dirs[dir]
+	out, err := m.Host.Run(tarScript(remoteDir, rels), nil)
+	if err != nil {
+		return fmt.Errorf("failed to check files in %s: %w", remoteDir, err)
+	}
+
+	reader := tar.NewReader(bytes.NewReader(out))
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+		file := filepath.Join(dir, filepath.FromSlash(path.Clean(header.Name)))
+		if hash, ok := m.hashes[file]; !ok || hash != sha256.Sum256(data) {
+			return fmt.Errorf("%s changed on %s since it was copied; nothing was sent, run the command again",
+				path.Join(remoteDir, path.Clean(header.Name)), m.Host.Target)
+		}
+	}
+}
+
+// extractTar writes the regular files in a tar archive under dir, keeping their modification
+// times, and returns their paths. Entries that would land outside dir are refused
+func extractTar(r io.Reader, dir string) ([]string, error) {
+	var files []string
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return files, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := filepath.FromSlash(path.Clean(header.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return files, fmt.Errorf("refusing archive entry outside the copy: %s", header.Name)
+		}
+
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return files, err
+		}
+		out, err := os.Create(file)
+		if err != nil {
+			return files, err
+		}
+		_, err = io.Copy(out, reader)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return files, fmt.Errorf("failed to write %s: %w", file, err)
+		}
+		_ = os.Chtimes(file, header.ModTime, header.ModTime)
+		files = append(files, file)
+	}
+}
+
+// writeTar archives files, named relative to dir
+func writeTar(w io.Writer, dir string, files []string) error {
+	writer := tar.NewWriter(w)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		header := &tar.Header{
+			Name:    filepath.ToSlash(rel),
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: info.ModTime(),
+		}
+		if err := writer.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := writer.Write(data); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
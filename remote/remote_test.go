@@ -0,0 +1,147 @@
+package remote
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// fakeSSH makes Host.Run run commands with the local shell, so the "remote" machine is a
+// directory on this one
+func fakeSSH(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	if _, err := exec.LookPath("tar"); err != nil {
+		t.Skip("needs tar")
+	}
+	script := filepath.Join(t.TempDir(), "ssh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nfor last; do :; done\nexec sh -c \"$last\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	original := sshCommand
+	sshCommand = script
+	t.Cleanup(func() { sshCommand = original })
+}
+
+func writeFile(t *testing.T, file, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, file string) string {
+	t.Helper()
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestFetchAndPush(t *testing.T) {
+	fakeSSH(t)
+	steamPath := filepath.Join(t.TempDir(), "Steam")
+	sdCard := filepath.Join(t.TempDir(), "sdcard")
+	libraryFolders := "\"libraryfolders\"\n{\n\t\"0\"\n\t{\n\t\t\"path\"\t\t\"" + steamPath + "\"\n\t}\n" +
+		"\t\"1\"\n\t{\n\t\t\"path\"\t\t\"" + sdCard + "\"\n\t}\n}\n"
+	writeFile(t, filepath.Join(steamPath, "steamapps", "libraryfolders.vdf"), libraryFolders)
+	writeFile(t, filepath.Join(steamPath, "steamapps", "appmanifest_620.acf"), "\"AppState\"\n{\n}\n")
+	writeFile(t, filepath.Join(steamPath, "steamapps", "common", "Portal 2", "portal2.sh"), "game files")
+	writeFile(t, filepath.Join(steamPath, "userdata", "111", "config", "localconfig.vdf"), "before")
+	writeFile(t, filepath.Join(sdCard, "steamapps", "appmanifest_730.acf"), "\"AppState\"\n{\n}\n")
+
+	dir := filepath.Join(t.TempDir(), "mirror")
+	mirror, err := Fetch(&Host{Target: "deck@steamdeck", SteamPath: steamPath}, dir)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	localConfig := filepath.Join(dir, "userdata", "111", "config", "localconfig.vdf")
+	if got := readFile(t, localConfig); got != "before" {
+		t.Errorf("localconfig.vdf = %q, want the host's", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "steamapps", "common")); !os.IsNotExist(err) {
+		t.Errorf("game files were copied: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "libraries", "1", "steamapps", "appmanifest_730.acf")); err != nil {
+		t.Errorf("other library's appmanifest: %v", err)
+	}
+	mirrored := readFile(t, filepath.Join(dir, "steamapps", "libraryfolders.vdf"))
+	for _, want := range []string{filepath.ToSlash(dir), filepath.ToSlash(filepath.Join(dir, "libraries", "1"))} {
+		if !strings.Contains(mirrored, "\""+want+"\"") {
+			t.Errorf("libraryfolders.vdf doesn't point at %s:\n%s", want, mirrored)
+		}
+	}
+
+	if pushed, err := mirror.Push(); err != nil || len(pushed) != 0 {
+		t.Fatalf("Push before changes = %v, %v; want nothing", pushed, err)
+	}
+
+	writeFile(t, localConfig, "after")
+	writeFile(t, localConfig+".backup.20260101-120000", "before")
+	pushed, err := mirror.Push()
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	sort.Strings(pushed)
+	hostConfig := filepath.Join(steamPath, "userdata", "111", "config", "localconfig.vdf")
+	want := []string{hostConfig, hostConfig + ".backup.20260101-120000"}
+	if strings.Join(pushed, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Push = %v, want %v", pushed, want)
+	}
+	if got := readFile(t, hostConfig); got != "after" {
+		t.Errorf("host localconfig.vdf = %q, want %q", got, "after")
+	}
+	if leftover, _ := filepath.Glob(filepath.Join(steamPath, ".gsca-push.*")); len(leftover) > 0 {
+		t.Errorf("Push left its temporary directory behind: %v", leftover)
+	}
+	if got := readFile(t, filepath.Join(steamPath, "steamapps", "libraryfolders.vdf")); got != libraryFolders {
+		t.Errorf("host libraryfolders.vdf was changed:\n%s", got)
+	}
+
+	if pushed, err := mirror.Push(); err != nil || len(pushed) != 0 {
+		t.Errorf("second Push = %v, %v; want nothing", pushed, err)
+	}
+
+	// Steam saving its settings on the host after the fetch wins over the stale copy
+	writeFile(t, hostConfig, "saved by steam")
+	writeFile(t, localConfig, "edited")
+	if pushed, err := mirror.Push(); err == nil || len(pushed) != 0 {
+		t.Errorf("Push over a file changed on the host = %v, %v; want an error", pushed, err)
+	}
+	if got := readFile(t, hostConfig); got != "saved by steam" {
+		t.Errorf("host localconfig.vdf = %q, want it left alone", got)
+	}
+}
+
+func TestExtractTarRefusesEscapes(t *testing.T) {
+	for _, name := range []string{"../outside.vdf", "config/../../outside.vdf", "/etc/passwd"} {
+		var archive bytes.Buffer
+		writer := tar.NewWriter(&archive)
+		if err := writer.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: 1, Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = writer.Write([]byte("x"))
+		_ = writer.Close()
+
+		dir := t.TempDir()
+		if _, err := extractTar(&archive, dir); err == nil {
+			t.Errorf("extractTar accepted %q", name)
+		}
+		if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "outside.vdf")); err == nil {
+			t.Errorf("%q was written outside the directory", name)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zerkz/gsca/i18n"
+	"github.com/zerkz/gsca/steam"
+)
+
+// reviewAppValues walks through each planned change, letting the user accept, skip,
+// or edit it, or quit (skipping the rest). It returns the values to apply
+func reviewAppValues(values []steam.AppValue, current map[string]string, library *steam.Library) []steam.AppValue {
+	var accepted []steam.AppValue
+	upToDate := 0
+
+	for i, v := range values {
+		if current[v.AppID] == v.Value {
+			upToDate++
+			continue
+		}
+
+		name := v.AppID
+		if game, found := library.LookupByID(v.AppID); found {
+			name = fmt.Sprintf("%s (%s)", game.Name, v.AppID)
+		}
+		previous := current[v.AppID]
+		if previous == "" {
+			previous = "(none)"
+		}
+		fmt.Printf("\n[%d/%d] %s\n", i+1, len(values), name)
+		fmt.Printf("  Current:  %s\n", previous)
+		fmt.Printf("  Proposed: %s\n", v.Value)
+
+		switch promptReview() {
+		case "y":
+			accepted = append(accepted, v)
+		case "e":
+			fmt.Print("  " + i18n.T(i18n.PromptReviewEdit))
+			if edited := readLine(); edited != "" {
+				v.Value = edited
+			}
+			accepted = append(accepted, v)
+		case "q":
+			fmt.Printf("Skipping the remaining %d game(s)\n", len(values)-i)
+			return accepted
+		}
+	}
+
+	if upToDate > 0 {
+		fmt.Printf("\n%d game(s) already up to date\n", upToDate)
+	}
+	return accepted
+}
+
+// promptReview asks until it gets y, n, e, or q; end of input counts as q
+func promptReview() string {
+	for {
+		fmt.Print("  " + i18n.T(i18n.PromptReviewChange))
+		input, answered := readAnswer()
+		if !answered {
+			return "q"
+		}
+
+		switch answer := strings.ToLower(input); {
+		case answer == "n" || answer == "no":
+			return "n"
+		case answer == "e" || answer == "edit":
+			return "e"
+		case answer == "q" || answer == "quit":
+			return "q"
+		case i18n.IsYes(answer):
+			return "y"
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/snapshot"
+	"github.com/zerkz/gsca/steam"
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save and revert the launch options of all games",
+	Long: `Snapshots store only the launch options of every game (not the whole localconfig.vdf),
+as quick save-points around experiments.
+
+Example:
+  gsca snapshot save before-dxvk
+  gsca update --args "DXVK_HUD=fps %command%" --all
+  gsca snapshot revert before-dxvk`,
+}
+
+var snapshotSaveCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Save the current launch options of all games",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSnapshotSave,
+}
+
+var snapshotRevertCmd = &cobra.Command{
+	Use:   "revert <name>",
+	Short: "Restore the launch options saved in a snapshot",
+	Long: `Restore every game's launch options to what they were when the snapshot was saved.
+Games that had no launch options then have theirs cleared.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSnapshotRevert,
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved snapshots",
+	Args:  cobra.NoArgs,
+	RunE:  runSnapshotList,
+}
+
+var snapshotDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a snapshot",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSnapshotDelete,
+}
+
+func init() {
+	snapshotRevertCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be restored without modifying files")
+	snapshotRevertCmd.Flags().BoolVarP(&autoCloseSteam, "force", "f", false, "Automatically close Steam if running (no prompt)")
+	snapshotRevertCmd.Flags().BoolVar(&noBackup, "no-backup", false, "Skip creating backup file")
+	addRestartFlags(snapshotRevertCmd)
+
+	snapshotCmd.AddCommand(snapshotSaveCmd)
+	snapshotCmd.AddCommand(snapshotRevertCmd)
+	snapshotCmd.AddCommand(snapshotListCmd)
+	snapshotCmd.AddCommand(snapshotDeleteCmd)
+	rootCmd.AddCommand(snapshotCmd)
+}
+
+// snapshotStore returns the snapshots in the user's config directory
+func snapshotStore() (snapshot.Store, error) {
+	dir, err := snapshot.DefaultDir()
+	if err != nil {
+		return snapshot.Store{}, fmt.Errorf("failed to locate snapshot directory: %w", err)
+	}
+	return snapshot.Store{Dir: dir}, nil
+}
+
+func runSnapshotSave(cmd *cobra.Command, args []string) error {
+	localConfigPath, err := detectLocalConfigPath()
+	if err != nil {
+		return err
+	}
+	values, err := steam.GetAppValues(localConfigPath, steam.KeyLaunchOptions)
+	if err != nil {
+		return err
+	}
+
+	store, err := snapshotStore()
+	if err != nil {
+		return err
+	}
+	snap := &snapshot.Snapshot{
+		Name:            args[0],
+		CreatedAt:       time.Now().UTC(),
+		LocalConfigPath: localConfigPath,
+		LaunchOptions:   values,
+	}
+	replaced, err := store.Save(snap)
+	if err != nil {
+		return err
+	}
+
+	action := "Saved"
+	if replaced {
+		action = "Replaced"
+	}
+	fmt.Printf("%s snapshot %q with the launch options of %d games\n", action, snap.Name, len(values))
+	return nil
+}
+
+func runSnapshotRevert(cmd *cobra.Command, args []string) error {
+	store, err := snapshotStore()
+	if err != nil {
+		return err
+	}
+	snap, err := store.Load(args[0])
+	if err != nil {
+		return err
+	}
+
+	localConfigPath, err := localConfigFor(snap.LocalConfigPath)
+	if err != nil {
+		return err
+	}
+	current, err := steam.GetAppValues(localConfigPath, steam.KeyLaunchOptions)
+	if err != nil {
+		return err
+	}
+
+	// Restore saved values and clear ones set since
+	var values []steam.AppValue
+	for appID, value := range snap.LaunchOptions {
+		if current[appID] != value {
+			values = append(values, steam.AppValue{AppID: appID, Value: value})
+		}
+	}
+	for appID, value := range current {
+		if _, saved := snap.LaunchOptions[appID]; !saved && value != "" {
+			values = append(values, steam.AppValue{AppID: appID, Value: ""})
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].AppID < values[j].AppID })
+
+	fmt.Printf("Snapshot %q saved %s for %s\n", snap.Name, snap.CreatedAt.Local().Format("2006-01-02 15:04"), snap.LocalConfigPath)
+	if len(values) == 0 {
+		fmt.Println("Launch options already match the snapshot")
+		return nil
+	}
+	fmt.Printf("Will restore launch options for %d games\n", len(values))
+	if dryRun {
+		fmt.Println("\n[DRY RUN] Would restore the following values:")
+		for _, v := range values {
+			fmt.Printf("  - %s: %q -> %q\n", v.AppID, current[v.AppID], v.Value)
+		}
+		return nil
+	}
+
+	shouldRestartSteam, err := closeSteamBeforeWrite()
+	if err != nil {
+		return err
+	}
+
+	result, err := steam.SetAppValues(localConfigPath, steam.KeyLaunchOptions, values, steam.UpdateOptions{SkipBackup: noBackup})
+	if err != nil {
+		return fmt.Errorf("failed to revert to snapshot %q: %w", snap.Name, err)
+	}
+	printUpdateSummary(result)
+	txID := recordTransaction(steam.KeyLaunchOptions, localConfigPath, result, nil, "")
+	notifyWebhook("snapshot revert", steam.KeyLaunchOptions, result, txID, "reverted to snapshot "+snap.Name)
+
+	if shouldRestartSteam {
+		restartSteam()
+	}
+	return nil
+}
+
+func runSnapshotList(cmd *cobra.Command, args []string) error {
+	store, err := snapshotStore()
+	if err != nil {
+		return err
+	}
+	snaps, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	if len(snaps) == 0 {
+		fmt.Println("No snapshots saved yet. Save one with 'gsca snapshot save <name>'.")
+		return nil
+	}
+	for _, snap := range snaps {
+		fmt.Printf("%-24s %s  %4d games  %s\n",
+			snap.Name, snap.CreatedAt.Local().Format("2006-01-02 15:04"), len(snap.LaunchOptions), snap.LocalConfigPath)
+	}
+	return nil
+}
+
+func runSnapshotDelete(cmd *cobra.Command, args []string) error {
+	store, err := snapshotStore()
+	if err != nil {
+		return err
+	}
+	if err := store.Delete(args[0]); err != nil {
+		return err
+	}
+	fmt.Printf("Deleted snapshot %q\n", args[0])
+	return nil
+}
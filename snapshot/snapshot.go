@@ -0,0 +1,94 @@
+// Package snapshot saves the launch options of every game under a name, as a quick
+// save-point that can be restored without keeping a copy of the whole localconfig.vdf
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/zerkz/gsca/jsonstore"
+)
+
+// Snapshot is the launch options of every game in a localconfig.vdf at one point in time
+type Snapshot struct {
+	Name            string    `json:"name"`
+	CreatedAt       time.Time `json:"created_at"`
+	LocalConfigPath string    `json:"localconfig_path"`
+	// LaunchOptions maps app IDs to their launch options
+	LaunchOptions map[string]string `json:"launch_options"`
+}
+
+// Store keeps one JSON file per snapshot in Dir
+type Store struct {
+	Dir string
+}
+
+// DefaultDir returns the snapshot directory under the user's config directory
+func DefaultDir() (string, error) {
+	return jsonstore.DefaultDir("snapshots")
+}
+
+// Save stores a snapshot, replacing one with the same name. It reports whether one was replaced
+func (s Store) Save(snap *Snapshot) (bool, error) {
+	if !jsonstore.ValidName(snap.Name) {
+		return false, fmt.Errorf("invalid snapshot name %q (use letters, digits, '.', '_' and '-')", snap.Name)
+	}
+	replaced, err := jsonstore.Dir(s.Dir).Save(snap.Name, snap)
+	if err != nil {
+		return false, fmt.Errorf("failed to save snapshot %q: %w", snap.Name, err)
+	}
+	return replaced, nil
+}
+
+// Load reads a snapshot by name
+func (s Store) Load(name string) (*Snapshot, error) {
+	if !jsonstore.ValidName(name) {
+		return nil, fmt.Errorf("invalid snapshot name %q", name)
+	}
+
+	var snap Snapshot
+	err := jsonstore.Dir(s.Dir).Load(name, &snap)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("snapshot %q not found (see 'gsca snapshot list')", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %q: %w", name, err)
+	}
+	return &snap, nil
+}
+
+// List returns all snapshots, oldest first
+func (s Store) List() ([]*Snapshot, error) {
+	names, err := jsonstore.Dir(s.Dir).Names()
+	if err != nil {
+		return nil, err
+	}
+
+	var snaps []*Snapshot
+	for _, name := range names {
+		snap, err := s.Load(name)
+		if err != nil {
+			return nil, err
+		}
+		snaps = append(snaps, snap)
+	}
+	sort.SliceStable(snaps, func(i, j int) bool { return snaps[i].CreatedAt.Before(snaps[j].CreatedAt) })
+	return snaps, nil
+}
+
+// Delete removes a snapshot
+func (s Store) Delete(name string) error {
+	if !jsonstore.ValidName(name) {
+		return fmt.Errorf("invalid snapshot name %q", name)
+	}
+	if err := os.Remove(jsonstore.Dir(s.Dir).Path(name)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("snapshot %q not found", name)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	store := Store{Dir: t.TempDir()}
+	snap := &Snapshot{
+		Name:            "before-dxvk",
+		CreatedAt:       time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+		LocalConfigPath: "/steam/userdata/1/config/localconfig.vdf",
+		LaunchOptions:   map[string]string{"570": "-novid", "730": ""},
+	}
+
+	if replaced, err := store.Save(snap); err != nil || replaced {
+		t.Fatalf("Save() = %v, %v, want false, nil", replaced, err)
+	}
+	if replaced, err := store.Save(snap); err != nil || !replaced {
+		t.Fatalf("Save() of an existing snapshot = %v, %v, want true, nil", replaced, err)
+	}
+
+	got, err := store.Load("before-dxvk")
+	if err != nil || !reflect.DeepEqual(got, snap) {
+		t.Errorf("Load() = %+v, %v, want %+v", got, err, snap)
+	}
+
+	older := &Snapshot{Name: "older", CreatedAt: snap.CreatedAt.Add(-time.Hour)}
+	if _, err := store.Save(older); err != nil {
+		t.Fatal(err)
+	}
+	snaps, err := store.List()
+	if err != nil || len(snaps) != 2 || snaps[0].Name != "older" {
+		t.Errorf("List() = %v, %v, want older first", snaps, err)
+	}
+
+	if err := store.Delete("older"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	for _, name := range []string{"older", "../escape", ""} {
+		if _, err := store.Load(name); err == nil {
+			t.Errorf("Load(%q) error = nil, want error", name)
+		}
+	}
+	if _, err := store.Save(&Snapshot{Name: "a/b"}); err == nil {
+		t.Error("Save() with an invalid name error = nil, want error")
+	}
+}
@@ -0,0 +1,331 @@
+package steam
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/zerkz/gsca/vdf"
+)
+
+// appinfo.vdf magic numbers; v28 adds a binary hash per entry, v29 moves keys to a string table
+const (
+	appInfoMagicV27 = 0x07564427
+	appInfoMagicV28 = 0x07564428
+	appInfoMagicV29 = 0x07564429
+)
+
+// Store categories that mark VR titles
+const (
+	categoryVRSupport   = 31
+	categoryVRSupported = 53
+	categoryVROnly      = 54
+)
+
+// genreNames maps the store genre IDs in appinfo to their English names
+var genreNames = map[string]string{
+	"1":  "Action",
+	"2":  "Strategy",
+	"3":  "RPG",
+	"4":  "Casual",
+	"9":  "Racing",
+	"18": "Sports",
+	"23": "Indie",
+	"25": "Adventure",
+	"28": "Simulation",
+	"29": "Massively Multiplayer",
+	"37": "Free to Play",
+	"70": "Early Access",
+}
+
+// AppInfo is store metadata for an app from Steam's local appinfo cache
+type AppInfo struct {
+	AppID string
+	Name  string
+	Type  string
+	// Parent is the game a DLC belongs to
+	Parent string
+	// Categories are store category IDs (e.g. 54 for VR Only)
+	Categories []int
+
+	// VRSupport is set for apps that can be played in VR, VROnly for apps that require it
+	VRSupport bool
+	VROnly    bool
+
+	Developers []string
+	Publishers []string
+	// Genres are store genre names (unknown genre IDs are kept as numbers)
+	Genres []string
+	// OSList holds the platforms with native builds (windows, macos, linux)
+	OSList []string
+}
+
+// GetAppInfoPath returns the path to Steam's binary appinfo cache
+func GetAppInfoPath(steamPath string) string {
+	return filepath.Join(steamPath, "appcache", "appinfo.vdf")
+}
+
+// LoadAppInfo reads store metadata for the given apps from appinfo.vdf (all apps if appIDs is nil)
+// Apps missing from the cache are left out of the result
+func LoadAppInfo(path string, appIDs []string) (map[string]AppInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read appinfo cache: %w", err)
+	}
+
+	var wanted map[uint32]bool
+	if appIDs != nil {
+		wanted = make(map[uint32]bool, len(appIDs))
+		for _, appID := range appIDs {
+			if id, err := strconv.ParseUint(appID, 10, 32); err == nil {
+				wanted[uint32(id)] = true
+			}
+		}
+	}
+
+	entries, err := readAppInfoEntries(data, wanted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	infos := make(map[string]AppInfo, len(entries))
+	for appID, root := range entries {
+		infos[appID] = newAppInfo(appID, root)
+	}
+	return infos, nil
+}
+
+// readAppInfoEntries decodes the KeyValues of each wanted entry (all entries if wanted is nil)
+func readAppInfoEntries(data []byte, wanted map[uint32]bool) (map[string]*vdf.Node, error) {
+	if len(data) < 8 {
+		return nil, fmt.Errorf("file too short")
+	}
+
+	magic := binary.LittleEndian.Uint32(data)
+	pos := 8 // magic + universe
+
+	// Fixed-size fields between an entry's size and its KeyValues
+	headerSize := 4 + 4 + 8 + 20 + 4 // info state, last updated, PICS token, text SHA-1, change number
+	var keys []string
+
+	switch magic {
+	case appInfoMagicV27:
+	case appInfoMagicV28:
+		headerSize += 20 // binary SHA-1
+	case appInfoMagicV29:
+		headerSize += 20
+		if len(data) < 16 {
+			return nil, fmt.Errorf("file too short")
+		}
+		tableOffset := binary.LittleEndian.Uint64(data[8:])
+		pos = 16
+
+		var err error
+		if keys, err = readStringTable(data, tableOffset); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported appinfo version 0x%08x", magic)
+	}
+
+	entries := make(map[string]*vdf.Node)
+	for pos+8 <= len(data) {
+		appID := binary.LittleEndian.Uint32(data[pos:])
+		if appID == 0 {
+			break
+		}
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		start := pos + 8
+		end := start + size
+		if size < headerSize || end > len(data) {
+			return nil, fmt.Errorf("app %d: entry size %d out of range", appID, size)
+		}
+		pos = end
+
+		if wanted != nil && !wanted[appID] {
+			continue
+		}
+
+		root, err := vdf.ParseBinary(data[start+headerSize:end], keys)
+		if err != nil {
+			return nil, fmt.Errorf("app %d: %w", appID, err)
+		}
+		entries[strconv.FormatUint(uint64(appID), 10)] = root
+	}
+
+	return entries, nil
+}
+
+// readStringTable reads the key names appinfo.vdf v29 stores at the end of the file
+func readStringTable(data []byte, offset uint64) ([]string, error) {
+	if offset+4 > uint64(len(data)) {
+		return nil, fmt.Errorf("string table offset %d out of range", offset)
+	}
+	count := binary.LittleEndian.Uint32(data[offset:])
+	rest := data[offset+4:]
+
+	keys := make([]string, 0, count)
+	for i := uint32(0); i < count; i++ {
+		end := -1
+		for j, b := range rest {
+			if b == 0 {
+				end = j
+				break
+			}
+		}
+		if end == -1 {
+			return nil, fmt.Errorf("string table truncated after %d of %d entries", i, count)
+		}
+		keys = append(keys, string(rest[:end]))
+		rest = rest[end+1:]
+	}
+
+	return keys, nil
+}
+
+// newAppInfo extracts the fields gsca uses from an entry's KeyValues
+func newAppInfo(appID string, root *vdf.Node) AppInfo {
+	info := AppInfo{AppID: appID}
+
+	// Older entries only list developer/publisher in the extended section
+	if extended := vdf.FindNode(root, "appinfo/extended"); extended != nil {
+		for _, child := range extended.Children {
+			switch child.Key {
+			case "developer":
+				info.Developers = append(info.Developers, child.Value)
+			case "publisher":
+				info.Publishers = append(info.Publishers, child.Value)
+			}
+		}
+	}
+
+	common := vdf.FindNode(root, "appinfo/common")
+	if common == nil {
+		return info
+	}
+
+	for _, child := range common.Children {
+		switch child.Key {
+		case keyName:
+			info.Name = child.Value
+		case "type":
+			info.Type = child.Value
+		case "parent":
+			info.Parent = child.Value
+		case "oslist":
+			for _, platform := range strings.Split(child.Value, ",") {
+				if platform = strings.TrimSpace(platform); platform != "" {
+					info.OSList = append(info.OSList, platform)
+				}
+			}
+		case "category":
+			for _, category := range child.Children {
+				var id int
+				if _, err := fmt.Sscanf(category.Key, "category_%d", &id); err == nil && category.Value == "1" {
+					info.Categories = append(info.Categories, id)
+				}
+			}
+		case "genres":
+			for _, genre := range child.Children {
+				name, ok := genreNames[genre.Value]
+				if !ok {
+					name = genre.Value
+				}
+				info.Genres = append(info.Genres, name)
+			}
+		case "associations":
+			developers, publishers := readAssociations(child)
+			if len(developers) > 0 {
+				info.Developers = developers
+			}
+			if len(publishers) > 0 {
+				info.Publishers = publishers
+			}
+		case "onlyvrsupport":
+			info.VROnly = info.VROnly || child.Value == "1"
+		case "openvrsupport", "oculussupport", "openxrsupport":
+			info.VRSupport = info.VRSupport || child.Value == "1"
+		}
+	}
+
+	for _, id := range info.Categories {
+		switch id {
+		case categoryVROnly:
+			info.VROnly = true
+		case categoryVRSupport, categoryVRSupported:
+			info.VRSupport = true
+		}
+	}
+	if info.VROnly {
+		info.VRSupport = true
+	}
+
+	return info
+}
+
+// readAssociations lists the developers and publishers in common/associations
+func readAssociations(node *vdf.Node) ([]string, []string) {
+	var developers, publishers []string
+	for _, association := range node.Children {
+		var kind, name string
+		for _, field := range association.Children {
+			switch field.Key {
+			case "type":
+				kind = field.Value
+			case keyName:
+				name = field.Value
+			}
+		}
+		if name == "" {
+			continue
+		}
+
+		switch kind {
+		case "developer":
+			developers = append(developers, name)
+		case "publisher":
+			publishers = append(publishers, name)
+		}
+	}
+	return developers, publishers
+}
+
+// IsDLC reports whether the app is downloadable content for another game, which Steam
+// doesn't launch on its own
+func (i AppInfo) IsDLC() bool {
+	return strings.EqualFold(i.Type, "DLC")
+}
+
+// HasGenre reports whether the app is in a store genre (case-insensitive)
+func (i AppInfo) HasGenre(genre string) bool {
+	for _, g := range i.Genres {
+		if strings.EqualFold(g, genre) {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsOS reports whether the app has a native build for platform (windows, macos, linux)
+func (i AppInfo) SupportsOS(platform string) bool {
+	for _, p := range i.OSList {
+		if strings.EqualFold(p, platform) {
+			return true
+		}
+	}
+	return false
+}
+
+// MadeBy reports whether any of names contains name (ignoring case and accents), e.g.
+// "valve" matches "Valve Corporation"
+func MadeBy(names []string, name string) bool {
+	for _, n := range names {
+		if NameContains(n, name) {
+			return true
+		}
+	}
+	return false
+}
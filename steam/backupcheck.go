@@ -0,0 +1,128 @@
+package steam
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zerkz/gsca/vdf"
+)
+
+// backupChecksumFile holds the SHA-256 of each backup in its directory, in sha256sum format
+const backupChecksumFile = "gsca-backups.sha256"
+
+// Backup verification results
+const (
+	BackupOK         = "ok"
+	BackupUnverified = "unverified" // parses, but no checksum was recorded
+	BackupCorrupted  = "corrupted"  // changed since it was made
+	BackupDamaged    = "damaged"    // doesn't parse (e.g. truncated)
+)
+
+// BackupCheck is the result of verifying one backup
+type BackupCheck struct {
+	Backup BackupInfo
+	// Status is one of the Backup* result constants
+	Status string
+	Err    error
+}
+
+// createBackup copies path to its next backup path and records the copy's checksum
+func createBackup(path string) (string, error) {
+	backupPath := getNextBackupPath(path)
+	if err := copyFile(path, backupPath); err != nil {
+		return "", err
+	}
+	// The backup is usable without its checksum, so failing to record one isn't an error
+	_ = recordBackupChecksum(backupPath)
+	return backupPath, nil
+}
+
+// recordBackupChecksum appends a backup's checksum to the checksum file in its directory
+func recordBackupChecksum(backupPath string) error {
+	sum, err := fileSHA256(backupPath)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(filepath.Dir(backupPath), backupChecksumFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "%s  %s\n", sum, filepath.Base(backupPath))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// readBackupChecksums maps backup file names to their recorded checksums; a name
+// recorded more than once (a reused backup path) keeps its latest checksum
+func readBackupChecksums(dir string) map[string]string {
+	sums := make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(dir, backupChecksumFile))
+	if err != nil {
+		return sums
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if sum, name, found := strings.Cut(line, "  "); found {
+			sums[name] = sum
+		}
+	}
+	return sums
+}
+
+// VerifyBackups re-hashes and re-parses every backup of localConfigPath, newest first
+func VerifyBackups(localConfigPath string) ([]BackupCheck, error) {
+	backups, err := ListBackups(localConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	sums := readBackupChecksums(filepath.Dir(localConfigPath))
+	checks := make([]BackupCheck, len(backups))
+	for i, backup := range backups {
+		checks[i] = verifyBackup(backup, sums[backup.Name])
+	}
+	return checks, nil
+}
+
+// VerifyBackup checks a single backup against its recorded checksum and parses it
+func VerifyBackup(backup BackupInfo) BackupCheck {
+	return verifyBackup(backup, readBackupChecksums(filepath.Dir(backup.Path))[backup.Name])
+}
+
+// verifyBackup compares a backup to its recorded checksum (if any) and parses it
+func verifyBackup(backup BackupInfo, want string) BackupCheck {
+	sum, err := fileSHA256(backup.Path)
+	if err != nil {
+		return BackupCheck{Backup: backup, Status: BackupDamaged, Err: err}
+	}
+	if want != "" && sum != want {
+		return BackupCheck{Backup: backup, Status: BackupCorrupted, Err: errors.New("contents changed since the backup was made")}
+	}
+
+	if err := CheckLocalConfig(backup.Path); err != nil {
+		return BackupCheck{Backup: backup, Status: BackupDamaged, Err: err}
+	}
+	if root, err := readVDFFile(backup.Path); err != nil || vdf.FindNode(root, appsPath) == nil {
+		return BackupCheck{Backup: backup, Status: BackupDamaged, Err: errors.New("no per-game settings (apps section) found")}
+	}
+
+	if want == "" {
+		return BackupCheck{Backup: backup, Status: BackupUnverified}
+	}
+	return BackupCheck{Backup: backup, Status: BackupOK}
+}
+
+func fileSHA256(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,22 @@
+package steam
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BetaBranchPublic is the default branch; selecting it opts a game out of any beta
+const BetaBranchPublic = "public"
+
+const keyBetaKey = "BetaKey"
+
+// SetBetaBranch selects a beta branch for installed games by editing the UserConfig
+// section of their appmanifest files. Steam switches to the branch the next time it starts
+func SetBetaBranch(steamPath string, appIDs []string, branch string, opts UpdateOptions) (*UpdateResult, error) {
+	if branch == "" || strings.ContainsAny(branch, "\"\n") {
+		return nil, fmt.Errorf("invalid beta branch %q", branch)
+	}
+
+	// No key means the default branch
+	return setUserConfigValue(steamPath, appIDs, keyBetaKey, branch, BetaBranchPublic, opts)
+}
@@ -0,0 +1,94 @@
+package steam
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/zerkz/gsca/vdf"
+)
+
+// compatToolMappingPath is where config.vdf maps app IDs to forced compatibility tools
+const compatToolMappingPath = "InstallConfigStore/Software/Valve/Steam/CompatToolMapping"
+
+// compatToolPriority is the priority Steam gives tools the user forces for a game
+const compatToolPriority = "250"
+
+// ProtonGames reports which of the given apps run through Proton on this machine
+func ProtonGames(steamPath string, appIDs []string) map[string]bool {
+	return protonGames(steamPath, appIDs, runtime.GOOS)
+}
+
+// protonGames decides per app: a Proton tool forced in config.vdf means Proton, and
+// otherwise Steam uses Proton on Linux for games without a native Linux build (per appinfo)
+// Games missing from appinfo are assumed native
+func protonGames(steamPath string, appIDs []string, goos string) map[string]bool {
+	proton := make(map[string]bool, len(appIDs))
+	if goos != osLinux {
+		return proton
+	}
+
+	compatTools := getCompatToolMapping(steamPath)
+	// A missing or unreadable appinfo cache only loses the Windows-only fallback
+	infos, _ := LoadAppInfo(GetAppInfoPath(steamPath), appIDs)
+
+	for _, appID := range appIDs {
+		if tool, forced := compatTools[appID]; forced {
+			proton[appID] = strings.Contains(strings.ToLower(tool), "proton")
+			continue
+		}
+		if info, found := infos[appID]; found && len(info.OSList) > 0 {
+			proton[appID] = !info.SupportsOS(osLinux)
+		}
+	}
+
+	return proton
+}
+
+// GetCompatTools returns the compatibility tool forced for each app in config.vdf
+func GetCompatTools(steamPath string) map[string]string {
+	return getCompatToolMapping(steamPath)
+}
+
+// SetCompatTools forces a compatibility tool (e.g. "proton_9") for each app in config.vdf
+// config.vdf is backed up first unless opts.SkipBackup is set; opts.Surgical is ignored
+func SetCompatTools(steamPath string, tools []AppValue, opts UpdateOptions) (*UpdateResult, error) {
+	configPath := filepath.Join(steamPath, "config", "config.vdf")
+	root, err := readVDFFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config.vdf: %w", err)
+	}
+
+	result := &UpdateResult{}
+	changed := false
+	for _, tool := range tools {
+		app := AppUpdateResult{AppID: tool.AppID, NewValue: tool.Value, Status: UpdateUnchanged}
+		if node := vdf.FindNodePath(root, vdf.Path(compatToolMappingPath, tool.AppID, keyName)); node != nil {
+			app.PreviousValue = node.Value
+		}
+		if app.PreviousValue != tool.Value {
+			app.Status = UpdateChanged
+			changed = true
+			for _, kv := range [][2]string{{keyName, tool.Value}, {"config", ""}, {"priority", compatToolPriority}} {
+				if err := vdf.SetValuePath(root, vdf.Path(compatToolMappingPath, tool.AppID, kv[0]), kv[1]); err != nil {
+					return nil, err
+				}
+			}
+		}
+		result.Apps = append(result.Apps, app)
+	}
+	if !changed {
+		return result, nil
+	}
+
+	if !opts.SkipBackup {
+		if result.BackupPath, err = createBackup(configPath); err != nil {
+			return nil, fmt.Errorf("failed to create backup: %w", err)
+		}
+	}
+	if err := trackWrite(configPath, func() error { return writeVDFFile(configPath, root) }); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
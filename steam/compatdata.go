@@ -0,0 +1,80 @@
+package steam
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// ErrPrefixNotFound is returned when a game has no Proton prefix in any library folder
+var ErrPrefixNotFound = errors.New("no Proton prefix found")
+
+// Prefix is a game's Proton prefix, steamapps/compatdata/<appid> in one of the library folders
+type Prefix struct {
+	AppID   string
+	Path    string
+	Library string
+}
+
+// FindPrefixes returns the Proton prefixes in all library folders, sorted by app ID
+func FindPrefixes(steamPath string) ([]Prefix, error) {
+	folders, err := GetLibraryFolders(steamPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var prefixes []Prefix
+	seen := make(map[string]bool)
+	for _, folder := range folders {
+		if !libraryOnline(folder) {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(folder, "steamapps", "compatdata"))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if _, err := strconv.ParseUint(entry.Name(), 10, 64); err != nil || !entry.IsDir() || seen[entry.Name()] {
+				continue
+			}
+			seen[entry.Name()] = true
+			prefixes = append(prefixes, Prefix{
+				AppID:   entry.Name(),
+				Path:    filepath.Join(folder, "steamapps", "compatdata", entry.Name()),
+				Library: folder,
+			})
+		}
+	}
+
+	// Numeric order, like SortAppIDs
+	sort.Slice(prefixes, func(i, j int) bool {
+		a, b := prefixes[i].AppID, prefixes[j].AppID
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return a < b
+	})
+	return prefixes, nil
+}
+
+// DirSize returns the total size of the regular files under path, not following symlinks
+func DirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
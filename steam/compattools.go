@@ -0,0 +1,128 @@
+package steam
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/zerkz/gsca/vdf"
+)
+
+// CompatTool is a compatibility tool Steam can run games with
+type CompatTool struct {
+	// Name is the internal name config.vdf uses to force the tool, e.g. "proton_9"
+	Name        string
+	DisplayName string
+	// Custom is set for tools in a compatibilitytools.d directory, e.g. GE-Proton
+	Custom bool
+	Path   string
+}
+
+// systemCompatToolDirs are where Linux packages install compatibility tools for every user
+var systemCompatToolDirs = []string{
+	"/usr/share/steam/compatibilitytools.d",
+	"/usr/local/share/steam/compatibilitytools.d",
+}
+
+// protonVersionPattern matches the version in the name of an official Proton app, e.g. "9.0"
+var protonVersionPattern = regexp.MustCompile(`^(\d+)\.(\d+)`)
+
+// FindCompatTools returns the official Proton versions installed in any library folder of the
+// install at steamPath and the custom tools in its compatibilitytools.d, sorted by name. When several have the same name,
+// the first found is the one listed, as it is the one Steam uses
+func FindCompatTools(steamPath string) ([]CompatTool, error) {
+	manifests, err := scanAppManifests(steamPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var tools []CompatTool
+	for _, m := range manifests {
+		if name := protonToolName(m.name); name != "" {
+			tools = append(tools, CompatTool{Name: name, DisplayName: m.name, Path: m.installDir})
+		}
+	}
+	dirs := []string{filepath.Join(steamPath, "compatibilitytools.d")}
+	if runtime.GOOS == osLinux {
+		dirs = append(dirs, systemCompatToolDirs...)
+	}
+	for _, dir := range dirs {
+		tools = append(tools, customCompatTools(dir)...)
+	}
+
+	var unique []CompatTool
+	seen := make(map[string]bool)
+	for _, tool := range tools {
+		if !seen[tool.Name] {
+			seen[tool.Name] = true
+			unique = append(unique, tool)
+		}
+	}
+	sort.SliceStable(unique, func(i, j int) bool { return unique[i].Name < unique[j].Name })
+	return unique, nil
+}
+
+// protonToolName derives the internal name of an official Proton version from its app name:
+// "Proton 9.0" is proton_9, "Proton 5.13" is proton_513, and "Proton Experimental" is
+// proton_experimental. Returns "" for apps that aren't Proton versions, such as the runtimes
+func protonToolName(appName string) string {
+	rest, ok := strings.CutPrefix(appName, "Proton")
+	if !ok || strings.Contains(rest, "Runtime") {
+		return ""
+	}
+	rest = strings.Trim(rest, " -")
+	if m := protonVersionPattern.FindStringSubmatch(rest); m != nil {
+		if m[2] == "0" {
+			return "proton_" + m[1]
+		}
+		return "proton_" + m[1] + m[2]
+	}
+	if rest == "" {
+		return ""
+	}
+	return "proton_" + strings.ToLower(strings.Join(strings.Fields(rest), "_"))
+}
+
+// customCompatTools reads the compatibilitytool.vdf of each tool directory in dir. Missing
+// directories and unreadable manifests are skipped
+func customCompatTools(dir string) []CompatTool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var tools []CompatTool
+	for _, entry := range entries {
+		toolDir := filepath.Join(dir, entry.Name())
+		if !isDir(toolDir) {
+			continue
+		}
+		root, err := readVDFFile(filepath.Join(toolDir, "compatibilitytool.vdf"))
+		if err != nil {
+			continue
+		}
+		node := vdf.FindNode(root, "compatibilitytools/compat_tools")
+		if node == nil {
+			continue
+		}
+		for _, child := range node.Children {
+			tool := CompatTool{Name: child.Key, Custom: true, Path: toolDir}
+			for _, field := range child.Children {
+				switch {
+				case vdf.KeyEqual(field.Key, "display_name"):
+					tool.DisplayName = field.Value
+				case vdf.KeyEqual(field.Key, "install_path") && field.Value != "" && field.Value != ".":
+					tool.Path = field.Value
+					if !filepath.IsAbs(tool.Path) {
+						tool.Path = filepath.Join(toolDir, tool.Path)
+					}
+				}
+			}
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
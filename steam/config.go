@@ -2,6 +2,8 @@ package steam
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,56 +14,364 @@ import (
 	"github.com/zerkz/gsca/vdf"
 )
 
+// Update statuses reported per app in UpdateResult
+const (
+	UpdateChanged   = "changed"
+	UpdateUnchanged = "unchanged"
+	UpdateFailed    = "failed"
+)
+
+// AppUpdateResult describes what happened to a single app during an update
+type AppUpdateResult struct {
+	AppID         string
+	PreviousValue string
+	NewValue      string
+	Status        string
+	Err           error
+	// BackupPath is set when the app's own file (e.g. its appmanifest) was backed up
+	BackupPath string
+}
+
+// UpdateResult describes the outcome of UpdateLaunchOptions
+type UpdateResult struct {
+	BackupPath string
+	Apps       []AppUpdateResult
+}
+
+// Count returns the number of apps with the given status
+func (r *UpdateResult) Count(status string) int {
+	count := 0
+	for _, app := range r.Apps {
+		if app.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// maxUpdateAttempts limits how often an update is retried when localconfig changes underneath it
+const maxUpdateAttempts = 3
+
+// UpdateOptions controls how SetAppValue writes localconfig.vdf
+type UpdateOptions struct {
+	// SkipBackup disables the backup normally created before writing
+	SkipBackup bool
+	// Surgical rewrites only the changed lines instead of re-serializing
+	// the whole file, leaving every other byte identical
+	Surgical bool
+	// Progress (may be nil) is called after each app's value is set
+	Progress ProgressFunc
+}
+
 // UpdateLaunchOptions updates launch options for specified games
-func UpdateLaunchOptions(localConfigPath string, appIDs []string, launchArgs string, skipBackup bool) (string, error) {
-	// Read the original file
+func UpdateLaunchOptions(localConfigPath string, appIDs []string, launchArgs string, opts UpdateOptions) (*UpdateResult, error) {
+	return SetAppValue(localConfigPath, appIDs, KeyLaunchOptions, launchArgs, opts)
+}
+
+// GetLaunchOptions returns the launch options of one app ("" if it has none)
+func GetLaunchOptions(localConfigPath, appID string) (string, error) {
 	f, err := os.Open(localConfigPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to open localconfig.vdf: %w", err)
 	}
+	defer func() { _ = f.Close() }()
 
-	parser := vdf.NewParser(f)
-	root, err := parser.Parse()
-	_ = f.Close()
-
+	appPath := appsPath + "/" + appID
+	root, err := vdf.NewPathParser(f, appPath).Parse()
 	if err != nil {
 		return "", fmt.Errorf("failed to parse localconfig.vdf: %w", err)
 	}
+	if node := vdf.FindNodePath(root, vdf.Path(appsPath, appID, KeyLaunchOptions)); node != nil && !node.IsObject {
+		return node.Value, nil
+	}
+	return "", nil
+}
+
+// SetLaunchOptions sets the launch options of one app: the value is checked, localconfig.vdf
+// is backed up (unless opts.SkipBackup is set) and rewritten, and the value is read back to
+// verify it. The result has a single app, which is unchanged if it already had the value
+func SetLaunchOptions(localConfigPath, appID, value string, opts UpdateOptions) (*UpdateResult, error) {
+	if _, err := CheckLaunchOptions(value); err != nil {
+		return nil, err
+	}
 
-	// Update launch options for each app ID
-	for _, appID := range appIDs {
-		path := fmt.Sprintf("UserLocalConfigStore/Software/Valve/Steam/apps/%s/LaunchOptions", appID)
-		if setErr := vdf.SetValue(root, path, launchArgs); setErr != nil {
-			return "", fmt.Errorf("failed to set launch options for app %s: %w", appID, setErr)
+	result, err := SetAppValues(localConfigPath, KeyLaunchOptions, []AppValue{{AppID: appID, Value: value}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if app := result.Apps[0]; app.Status == UpdateFailed {
+		return result, fmt.Errorf("failed to set launch options for app %s: %w", appID, app.Err)
+	}
+
+	written, err := GetLaunchOptions(localConfigPath, appID)
+	if err != nil {
+		return result, err
+	}
+	if written != value {
+		return result, fmt.Errorf("app %s: %w (found launch options %q)", appID, ErrWriteNotVerified, written)
+	}
+	return result, nil
+}
+
+// SetAppValue sets key to value under each app's node in localconfig.vdf
+// The file is only written (and backed up) if at least one app changed
+// If the file is modified between reading and writing, the update is retried from a
+// fresh read; ErrConfigModified is returned if it keeps changing
+func SetAppValue(localConfigPath string, appIDs []string, key, value string, opts UpdateOptions) (*UpdateResult, error) {
+	values := make([]AppValue, len(appIDs))
+	for i, appID := range appIDs {
+		values[i] = AppValue{AppID: appID, Value: value}
+	}
+	return SetAppValues(localConfigPath, key, values, opts)
+}
+
+// AppValue is the value to set for one app
+type AppValue struct {
+	AppID string
+	Value string
+}
+
+// SetAppValues is SetAppValue with a separate value for each app, written in a single pass
+func SetAppValues(localConfigPath, key string, values []AppValue, opts UpdateOptions) (*UpdateResult, error) {
+	if err := validateAppKey(key); err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		result, err := setAppValuesOnce(localConfigPath, key, values, opts)
+		if !errors.Is(err, ErrConfigModified) || attempt == maxUpdateAttempts {
+			return result, err
 		}
 	}
+}
+
+// validateAppKey rejects keys that can't be stored as a single VDF key
+func validateAppKey(key string) error {
+	if key == "" || strings.ContainsAny(key, "/\"\n") {
+		return fmt.Errorf("invalid app config key %q", key)
+	}
+	return nil
+}
+
+func setAppValuesOnce(localConfigPath, key string, values []AppValue, opts UpdateOptions) (*UpdateResult, error) {
+	edit, err := editAppValues(localConfigPath, key, values, opts)
+	if err != nil {
+		return nil, err
+	}
+	return commitAppValues(localConfigPath, edit, opts)
+}
+
+// commitAppValues backs up localconfig.vdf and writes an edit, unless nothing changed
+// ErrConfigModified is returned if the file changed since the edit read it
+func commitAppValues(localConfigPath string, edit *appValuesEdit, opts UpdateOptions) (*UpdateResult, error) {
+	result := edit.result
+	if result.Count(UpdateChanged) == 0 {
+		return result, nil
+	}
+
+	backupPath, err := writeLocalConfigData(localConfigPath, edit.updated, WriteOptions{
+		SkipBackup: opts.SkipBackup,
+		Original:   &edit.original,
+	})
+	result.BackupPath = backupPath
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// appValuesEdit is localconfig.vdf with new app values applied in memory
+type appValuesEdit struct {
+	result *UpdateResult
+	// original identifies the file the edit is based on
+	original Fingerprint
+	// current and updated are the file contents before and after (updated is nil if nothing changed)
+	current []byte
+	updated []byte
+}
 
-	// Create backup (unless skipped)
-	var backupPath string
-	if !skipBackup {
-		backupPath = getNextBackupPath(localConfigPath)
-		if copyErr := copyFile(localConfigPath, backupPath); copyErr != nil {
-			return "", fmt.Errorf("failed to create backup: %w", copyErr)
+// editAppValues reads localconfig.vdf and computes the contents with values applied
+func editAppValues(localConfigPath, key string, values []AppValue, opts UpdateOptions) (*appValuesEdit, error) {
+	// Read the original file, remembering its state to detect concurrent writes
+	data, err := os.ReadFile(localConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open localconfig.vdf: %w", err)
+	}
+	info, err := os.Stat(localConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open localconfig.vdf: %w", err)
+	}
+	edit := &appValuesEdit{
+		result:   &UpdateResult{},
+		original: fingerprintData(data, info),
+		current:  data,
+	}
+
+	// Splicing relies on line positions, which are meaningless in a damaged file
+	if opts.Surgical {
+		if err := vdf.Validate(bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("surgical mode requires a well-formed localconfig.vdf (try 'gsca doctor'): %w", err)
 		}
 	}
 
-	// Write the updated config
-	outFile, err := os.Create(localConfigPath)
+	parser := vdf.NewParser(bytes.NewReader(data))
+	root, err := parser.Parse()
 	if err != nil {
-		return "", fmt.Errorf("failed to create output file: %w", err)
+		return nil, fmt.Errorf("failed to parse localconfig.vdf: %w", err)
+	}
+
+	// Set the value for each app ID
+	result := edit.result
+	for i, v := range values {
+		path := vdf.Path(appsPath, v.AppID, key)
+
+		// A damaged or hand-edited file can list an app or key twice; every copy is set so
+		// Steam sees the new value whichever one it reads
+		app := AppUpdateResult{AppID: v.AppID, NewValue: v.Value, Status: UpdateUnchanged}
+		matches := vdf.FindNodesPath(root, path)
+		if len(matches) > 0 {
+			app.PreviousValue = matches[0].Node.Value
+		}
+		for _, match := range matches {
+			if match.Node.Value != v.Value {
+				match.Node.Value = v.Value
+				app.Status = UpdateChanged
+			}
+		}
+
+		if len(matches) == 0 && v.Value != "" {
+			if setErr := vdf.SetValuePath(root, path, v.Value); setErr != nil {
+				app.Status = UpdateFailed
+				app.Err = setErr
+			} else {
+				app.Status = UpdateChanged
+			}
+		}
+
+		result.Apps = append(result.Apps, app)
+		opts.Progress.report(i+1, len(values))
+	}
+
+	if result.Count(UpdateChanged) == 0 {
+		return edit, nil
+	}
+
+	if opts.Surgical {
+		var changed []string
+		for _, app := range result.Apps {
+			if app.Status == UpdateChanged {
+				changed = append(changed, app.AppID)
+			}
+		}
+
+		if edit.updated, err = spliceAppValues(data, root, key, changed); err != nil {
+			return nil, fmt.Errorf("failed to edit localconfig.vdf in place: %w", err)
+		}
+		return edit, nil
+	}
+
+	var buf bytes.Buffer
+	if err := vdf.Write(&buf, root, 0); err != nil {
+		return nil, fmt.Errorf("failed to write VDF: %w", err)
+	}
+	edit.updated = buf.Bytes()
+	return edit, nil
+}
+
+// AppValuesPreview is what SetAppValues would do, computed without writing anything
+type AppValuesPreview struct {
+	Result *UpdateResult
+	// Fingerprint identifies the localconfig.vdf the preview is based on
+	Fingerprint Fingerprint
+	// Before and After are the file contents (equal if nothing would change)
+	Before []byte
+	After  []byte
+}
+
+// PreviewAppValues computes the result of SetAppValues with the same arguments
+// without writing or backing up anything
+func PreviewAppValues(localConfigPath, key string, values []AppValue, opts UpdateOptions) (*AppValuesPreview, error) {
+	if err := validateAppKey(key); err != nil {
+		return nil, err
+	}
+
+	edit, err := editAppValues(localConfigPath, key, values, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &AppValuesPreview{
+		Result:      edit.result,
+		Fingerprint: edit.original,
+		Before:      edit.current,
+		After:       edit.updated,
+	}
+	if preview.After == nil {
+		preview.After = edit.current
+	}
+	return preview, nil
+}
+
+// GetAppValues returns the value of key for every app in localconfig.vdf that has it
+func GetAppValues(localConfigPath, key string) (map[string]string, error) {
+	f, err := os.Open(localConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open localconfig.vdf: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	root, err := vdf.NewPathParser(f, appsPath).Parse()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse localconfig.vdf: %w", err)
+	}
+
+	values := make(map[string]string)
+	appsNode := vdf.FindNode(root, appsPath)
+	if appsNode == nil {
+		return values, nil
+	}
+
+	for _, appNode := range appsNode.Children {
+		for _, child := range appNode.Children {
+			if vdf.KeyEqual(child.Key, key) && !child.IsObject {
+				values[appNode.Key] = child.Value
+				break
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// readVDFFile parses a VDF file
+func readVDFFile(path string) (*vdf.Node, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	return vdf.NewParser(f).Parse()
+}
+
+// writeVDFFile serializes a VDF tree to path, replacing its contents
+func writeVDFFile(path string, root *vdf.Node) error {
+	outFile, err := os.Create(path)
+	if err != nil {
+		return classifyWriteError(path, err)
 	}
 	defer func() { _ = outFile.Close() }()
 
 	writer := bufio.NewWriter(outFile)
 	if err := vdf.Write(writer, root, 0); err != nil {
-		return "", fmt.Errorf("failed to write VDF: %w", err)
+		return fmt.Errorf("failed to write VDF: %w", err)
 	}
 
 	if err := writer.Flush(); err != nil {
-		return "", fmt.Errorf("failed to flush writer: %w", err)
+		return classifyWriteError(path, err)
 	}
 
-	return backupPath, nil
+	return nil
 }
 
 // LoadFilterList loads a list of game names or IDs from a file
@@ -93,7 +403,7 @@ func LoadFilterList(filename string) ([]string, error) {
 
 // ResolveGameIDs validates that items are numeric app IDs
 // Game names are no longer supported - use query/list modes to get IDs
-func ResolveGameIDs(items []string, mapping map[string]string) ([]string, []string) {
+func ResolveGameIDs(items []string, lib *Library) ([]string, []string) {
 	var resolved []string
 	var notFound []string
 
@@ -163,15 +473,18 @@ func copyFile(src, dst string) error {
 		return err
 	}
 
-	return os.WriteFile(dst, input, 0644)
+	return classifyWriteError(dst, os.WriteFile(dst, input, 0644))
 }
 
 // getNextBackupPath finds the next available backup filename
 // Returns: localconfig.vdf.backup, localconfig.vdf.backup.1, localconfig.vdf.backup.2, etc.
 func getNextBackupPath(originalPath string) string {
-	basePath := originalPath + ".backup"
+	return getNextSuffixedPath(originalPath + ".backup")
+}
 
-	// Check if base backup exists
+// getNextSuffixedPath returns basePath if it's free, otherwise basePath.1, basePath.2, etc.
+func getNextSuffixedPath(basePath string) string {
+	// Check if base path exists
 	if _, err := os.Stat(basePath); os.IsNotExist(err) {
 		return basePath
 	}
@@ -235,7 +548,12 @@ func ListBackups(localConfigPath string) ([]BackupInfo, error) {
 	return backups, nil
 }
 
-// RestoreBackup copies a backup file back to the original config location
-func RestoreBackup(backupPath, localConfigPath string) error {
-	return copyFile(backupPath, localConfigPath)
+// RestoreBackup copies a backup file back to the original config location, backing up the
+// file it replaces first. Returns the path of that backup (empty if there was no file)
+func RestoreBackup(backupPath, localConfigPath string) (string, error) {
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read backup: %w", err)
+	}
+	return writeLocalConfigData(localConfigPath, data, WriteOptions{})
 }
@@ -0,0 +1,35 @@
+package steam
+
+import "sync"
+
+// FileChange is a Steam file gsca modified, with the SHA-256 of its contents before and
+// after ("" if it didn't exist)
+type FileChange struct {
+	Path   string
+	Before string
+	After  string
+}
+
+// OnFileChange, if set, is called after each Steam file gsca modifies (localconfig.vdf,
+// appmanifests), but not for the backups gsca creates
+var OnFileChange func(FileChange)
+
+var fileChangeMu sync.Mutex
+
+// trackWrite runs write, which modifies path, and reports the change to OnFileChange
+// Files whose contents didn't change (e.g. a failed write) aren't reported
+func trackWrite(path string, write func() error) error {
+	if OnFileChange == nil {
+		return write()
+	}
+
+	before, _ := fileSHA256(path)
+	err := write()
+	after, _ := fileSHA256(path)
+	if before != after {
+		fileChangeMu.Lock()
+		OnFileChange(FileChange{Path: path, Before: before, After: after})
+		fileChangeMu.Unlock()
+	}
+	return err
+}
@@ -0,0 +1,48 @@
+package steam
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"time"
+)
+
+// ErrConfigModified is returned when localconfig.vdf changes while gsca is editing it
+var ErrConfigModified = errors.New("localconfig.vdf was modified by another program while gsca was editing it")
+
+// Fingerprint identifies the contents of a file at a point in time
+type Fingerprint struct {
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+	Hash    string    `json:"sha256"`
+}
+
+// FingerprintFile records the modification time, size, and SHA-256 hash of a file
+func FingerprintFile(path string) (Fingerprint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Fingerprint{}, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return Fingerprint{}, err
+	}
+
+	return fingerprintData(data, info), nil
+}
+
+// Equal reports whether two fingerprints describe the same file contents
+func (f Fingerprint) Equal(other Fingerprint) bool {
+	return f.ModTime.Equal(other.ModTime) && f.Size == other.Size && f.Hash == other.Hash
+}
+
+func fingerprintData(data []byte, info os.FileInfo) Fingerprint {
+	sum := sha256.Sum256(data)
+	return Fingerprint{
+		ModTime: info.ModTime(),
+		Size:    info.Size(),
+		Hash:    hex.EncodeToString(sum[:]),
+	}
+}
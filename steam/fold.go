@@ -0,0 +1,51 @@
+package steam
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// foldTable maps what's left for FoldName after decomposition: letters that don't decompose
+// into a plain letter and a mark, and typographic punctuation
+var foldTable = map[rune]string{
+	'æ': "ae", 'đ': "d", 'ð': "d", 'ħ': "h", 'ı': "i", 'ł': "l", 'ø': "o", 'œ': "oe",
+	'ß': "ss", 'þ': "th",
+	'‘': "'", '’': "'", 'ʼ': "'", '′': "'",
+	'“': "\"", '”': "\"", '″': "\"",
+	'‐': "-", '‑': "-", '‒': "-", '–': "-", '—': "-",
+}
+
+// trademarks are dropped before decomposition, which would turn ™ into "TM"; they're
+// decoration, not part of the name
+var trademarks = strings.NewReplacer("™", "", "℠", "", "®", "", "©", "")
+
+// FoldName normalizes a game name or search term for matching: lower case, without
+// diacritics, with full-width, half-width and compatibility characters replaced by their
+// plain forms, so that "pokemon" matches "Pokémon", "ＮＩＥＲ" matches "NieR" and "ii"
+// matches "Ⅱ"
+func FoldName(s string) string {
+	s = norm.NFKD.String(width.Fold.String(trademarks.Replace(s)))
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		r = unicode.ToLower(r)
+		if plain, ok := foldTable[r]; ok {
+			b.WriteString(plain)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// NameContains reports whether the game name contains the search term, ignoring case,
+// diacritics and character width
+func NameContains(name, term string) bool {
+	return strings.Contains(FoldName(name), FoldName(term))
+}
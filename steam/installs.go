@@ -0,0 +1,226 @@
+package steam
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Steam install flavors
+const (
+	FlavorNative  = "native"
+	FlavorFlatpak = "flatpak"
+	FlavorSnap    = "snap"
+	// FlavorChina is the Steam China client on Windows
+	FlavorChina = "china"
+	// FlavorCustom is an install at one of the extra search paths
+	FlavorCustom = "custom"
+)
+
+// Flavors lists the valid install flavors
+var Flavors = []string{FlavorNative, FlavorFlatpak, FlavorSnap, FlavorChina, FlavorCustom}
+
+// Install is a Steam installation found on this machine
+type Install struct {
+	Flavor string
+	Path   string
+	// Users is the number of Steam users with data in the install
+	Users int
+	// LastUsed is when a user's data was last modified (zero without users)
+	LastUsed time.Time
+}
+
+// FindInstalls returns every Steam installation in extraPaths and the usual locations,
+// most recently used first
+func FindInstalls(extraPaths []string) ([]Install, error) {
+	var candidates []installCandidate
+	for _, path := range extraPaths {
+		candidates = append(candidates, installCandidate{FlavorCustom, path})
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	switch runtime.GOOS {
+	case osLinux, osDarwin:
+		candidates = append(candidates, installCandidates(runtime.GOOS, homeDir, nil)...)
+	case osWindows:
+		// The client records where it's installed
+		if output, err := exec.Command("reg", "query", `HKCU\Software\Valve\Steam`, "/v", "SteamPath").Output(); err == nil {
+			if path := parseRegSZ(string(output)); path != "" {
+				candidates = append(candidates, installCandidate{FlavorNative, filepath.Clean(path)})
+			}
+		}
+		// Steam is usually installed on C:, but may be on any drive
+		var drives []string
+		for letter := 'C'; letter <= 'Z'; letter++ {
+			drives = append(drives, string(letter)+`:\`)
+		}
+		candidates = append(candidates, installCandidates(osWindows, homeDir, drives)...)
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+	}
+	return findInstalls(candidates), nil
+}
+
+// installCandidate is a path where a Steam install of a flavor may be
+type installCandidate struct {
+	flavor string
+	path   string
+}
+
+// installCandidates lists the paths to check on goos, in order of preference
+func installCandidates(goos, homeDir string, drives []string) []installCandidate {
+	switch goos {
+	case osLinux:
+		flatpak := filepath.Join(homeDir, ".var", "app", "com.valvesoftware.Steam")
+		return []installCandidate{
+			{FlavorNative, filepath.Join(homeDir, ".steam", "root")},
+			{FlavorNative, filepath.Join(homeDir, ".steam", "steam")},
+			{FlavorNative, filepath.Join(homeDir, ".local", "share", "Steam")},
+			{FlavorFlatpak, filepath.Join(flatpak, ".local", "share", "Steam")},
+			{FlavorFlatpak, filepath.Join(flatpak, "data", "Steam")},
+			{FlavorSnap, filepath.Join(homeDir, "snap", "steam", "common", ".local", "share", "Steam")},
+		}
+	case osDarwin:
+		return []installCandidate{{FlavorNative, filepath.Join(homeDir, "Library", "Application Support", "Steam")}}
+	case osWindows:
+		// Scoop installs per user or globally; Chocolatey uses the default location
+		candidates := []installCandidate{{FlavorNative, filepath.Join(homeDir, "scoop", "apps", "steam", "current")}}
+		for _, drive := range drives {
+			candidates = append(candidates,
+				installCandidate{FlavorNative, filepath.Join(drive, "Program Files (x86)", "Steam")},
+				installCandidate{FlavorNative, filepath.Join(drive, "Program Files", "Steam")},
+				installCandidate{FlavorNative, filepath.Join(drive, "Steam")},
+				installCandidate{FlavorNative, filepath.Join(drive, "Games", "Steam")},
+				installCandidate{FlavorNative, filepath.Join(drive, "ProgramData", "scoop", "apps", "steam", "current")},
+				installCandidate{FlavorChina, filepath.Join(drive, "Program Files (x86)", "SteamChina")},
+				installCandidate{FlavorChina, filepath.Join(drive, "Program Files", "SteamChina")},
+				installCandidate{FlavorChina, filepath.Join(drive, "SteamChina")},
+			)
+		}
+		return candidates
+	}
+	return nil
+}
+
+// findInstalls keeps the candidates that are Steam installs (they have a config or userdata
+// directory, unlike game libraries), listing each real path once
+func findInstalls(candidates []installCandidate) []Install {
+	var installs []Install
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		path, err := filepath.EvalSymlinks(candidate.path)
+		if err != nil || seen[path] {
+			continue
+		}
+		if !isDir(filepath.Join(path, "config")) && !isDir(filepath.Join(path, "userdata")) {
+			continue
+		}
+		seen[path] = true
+
+		install := Install{Flavor: candidate.flavor, Path: path}
+		entries, _ := os.ReadDir(filepath.Join(path, "userdata"))
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil || !entry.IsDir() || entry.Name() == "0" {
+				continue
+			}
+			install.Users++
+			if info.ModTime().After(install.LastUsed) {
+				install.LastUsed = info.ModTime()
+			}
+		}
+		installs = append(installs, install)
+	}
+
+	// Most recently used first; preference order breaks ties
+	sort.SliceStable(installs, func(i, j int) bool { return installs[i].LastUsed.After(installs[j].LastUsed) })
+	return installs
+}
+
+// SteamPathFromProcess finds the install of a running Steam client from its executable and
+// working directory, for installs outside the usual locations. Returns "" if none is found
+func SteamPathFromProcess() string {
+	var paths []string
+	switch runtime.GOOS {
+	case osLinux:
+		paths = procSteamPaths("/proc")
+	case osDarwin:
+		output, err := exec.Command("ps", "-axo", "comm=").Output()
+		if err != nil {
+			return ""
+		}
+		for _, line := range strings.Split(string(output), "\n") {
+			if line = strings.TrimSpace(line); filepath.Base(line) == "steam_osx" {
+				paths = append(paths, line)
+			}
+		}
+	case osWindows:
+		output, err := exec.Command("powershell", "-NoProfile", "-Command",
+			"(Get-Process steam,steamchina -ErrorAction SilentlyContinue).Path").Output()
+		if err != nil {
+			return ""
+		}
+		// One executable path per line
+		for _, line := range strings.Split(string(output), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				paths = append(paths, line)
+			}
+		}
+	}
+
+	for _, path := range paths {
+		if root := steamRootOf(path); root != "" {
+			return root
+		}
+	}
+	return ""
+}
+
+// procSteamPaths returns the executables and working directories of the steam processes
+// in a Linux /proc
+func procSteamPaths(procRoot string) []string {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		comm, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "comm"))
+		if err != nil || strings.TrimSpace(string(comm)) != "steam" {
+			continue
+		}
+		for _, link := range []string{"exe", "cwd"} {
+			if path, err := os.Readlink(filepath.Join(procRoot, entry.Name(), link)); err == nil {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+// steamRootOf returns the Steam install containing path (the closest parent with steamapps
+// and a config or userdata directory), or "" if there is none
+func steamRootOf(path string) string {
+	for dir := filepath.Clean(path); ; dir = filepath.Dir(dir) {
+		if isDir(filepath.Join(dir, "steamapps")) && (isDir(filepath.Join(dir, "config")) || isDir(filepath.Join(dir, "userdata"))) {
+			return dir
+		}
+		if filepath.Dir(dir) == dir {
+			return ""
+		}
+	}
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,70 @@
+package steam
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Install states of games with an appmanifest, from its StateFlags
+const (
+	InstallStateInstalled     = "installed"
+	InstallStateUpdating      = "updating"
+	InstallStatePreallocating = "preallocating"
+	// InstallStateUnavailable is for games in a library folder that is offline
+	InstallStateUnavailable = "unavailable"
+)
+
+// InstallStates lists the valid install states
+var InstallStates = []string{InstallStateInstalled, InstallStateUpdating, InstallStatePreallocating, InstallStateUnavailable}
+
+// appmanifest StateFlags bits (Steam's EAppState)
+const (
+	stateUpdateRequired = 1 << 1
+	stateFullyInstalled = 1 << 2
+	stateUpdateRunning  = 1 << 8
+	stateUpdatePaused   = 1 << 9
+	stateUpdateStarted  = 1 << 10
+	stateReconfiguring  = 1 << 16
+	stateValidating     = 1 << 17
+	stateAddingFiles    = 1 << 18
+	statePreallocating  = 1 << 19
+	stateDownloading    = 1 << 20
+	stateStaging        = 1 << 21
+	stateCommitting     = 1 << 22
+
+	stateUpdating = stateUpdateRequired | stateUpdateRunning | stateUpdatePaused | stateUpdateStarted |
+		stateReconfiguring | stateValidating | stateAddingFiles | stateDownloading | stateStaging | stateCommitting
+)
+
+// installStateFromFlags maps appmanifest StateFlags to an install state. Manifests without
+// StateFlags are treated as installed. Anything short of fully installed that isn't
+// preallocating (partial downloads, missing files) counts as updating
+func installStateFromFlags(flags int64) string {
+	switch {
+	case flags == 0:
+		return InstallStateInstalled
+	case flags&statePreallocating != 0:
+		return InstallStatePreallocating
+	case flags&stateUpdating != 0 || flags&stateFullyInstalled == 0:
+		return InstallStateUpdating
+	default:
+		return InstallStateInstalled
+	}
+}
+
+// ParseInstallStates parses a comma-separated list of install states
+func ParseInstallStates(s string) (map[string]bool, error) {
+	states := make(map[string]bool)
+	for _, state := range strings.Split(s, ",") {
+		state = strings.ToLower(strings.TrimSpace(state))
+		valid := false
+		for _, known := range InstallStates {
+			valid = valid || state == known
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown install state %q (use %s)", state, strings.Join(InstallStates, ", "))
+		}
+		states[state] = true
+	}
+	return states, nil
+}
@@ -0,0 +1,78 @@
+package steam
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const keyLanguage = "language"
+
+// steamLanguages maps language codes to the names Steam uses in its config files
+var steamLanguages = map[string]string{
+	"ar":     "arabic",
+	"bg":     "bulgarian",
+	"cs":     "czech",
+	"da":     "danish",
+	"de":     "german",
+	"el":     "greek",
+	"en":     "english",
+	"es":     "spanish",
+	"es-419": "latam",
+	"fi":     "finnish",
+	"fr":     "french",
+	"hu":     "hungarian",
+	"id":     "indonesian",
+	"it":     "italian",
+	"ja":     "japanese",
+	"ko":     "koreana",
+	"nl":     "dutch",
+	"no":     "norwegian",
+	"pl":     "polish",
+	"pt":     "portuguese",
+	"pt-br":  "brazilian",
+	"ro":     "romanian",
+	"ru":     "russian",
+	"sv":     "swedish",
+	"th":     "thai",
+	"tr":     "turkish",
+	"uk":     "ukrainian",
+	"vi":     "vietnamese",
+	"zh-cn":  "schinese",
+	"zh-tw":  "tchinese",
+}
+
+// SteamLanguage converts a language code (e.g. "ja", "pt-BR") or Steam language name
+// (e.g. "japanese") to the name Steam stores in its config
+func SteamLanguage(language string) (string, bool) {
+	lang := strings.ToLower(strings.ReplaceAll(language, "_", "-"))
+	if name, ok := steamLanguages[lang]; ok {
+		return name, true
+	}
+	for _, name := range steamLanguages {
+		if name == lang {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// SteamLanguages returns the language codes accepted by SteamLanguage, sorted
+func SteamLanguages() []string {
+	codes := make([]string, 0, len(steamLanguages))
+	for code := range steamLanguages {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// SetGameLanguage overrides the client language for installed games, in their appmanifests
+func SetGameLanguage(steamPath string, appIDs []string, language string, opts UpdateOptions) (*UpdateResult, error) {
+	name, ok := SteamLanguage(language)
+	if !ok {
+		return nil, fmt.Errorf("unknown language %q (supported: %s)", language, strings.Join(SteamLanguages(), ", "))
+	}
+
+	return setUserConfigValue(steamPath, appIDs, keyLanguage, name, "", opts)
+}
@@ -0,0 +1,327 @@
+package steam
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CommandToken is the placeholder Steam replaces with the game's executable
+const CommandToken = "%command%"
+
+// MaxLaunchOptionsLength is a practical limit for launch options; some games and wrappers
+// truncate or misparse longer command lines
+const MaxLaunchOptionsLength = 1024
+
+// CheckLaunchOptions returns warnings about a launch string that may not work as intended,
+// and an error if Steam can't round-trip it: its launch options field is a single line, so
+// line breaks and tabs can't be shown or edited there
+func CheckLaunchOptions(s string) ([]string, error) {
+	if strings.ContainsAny(s, "\n\r\t") {
+		return nil, fmt.Errorf("launch options %q contain line breaks or tabs, which Steam's single-line launch options field can't hold", s)
+	}
+
+	var warnings []string
+	if len(s) > MaxLaunchOptionsLength {
+		warnings = append(warnings, fmt.Sprintf("launch options are %d characters long; some games and wrappers truncate or misparse strings over %d", len(s), MaxLaunchOptionsLength))
+	}
+	return warnings, nil
+}
+
+// EnvVar is an environment variable assignment at the start of a launch string
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// LaunchOptions is a parsed launch string of the form "ENV=1 wrapper %command% -game -flags"
+// Without %command%, Steam appends the whole string to the game's arguments, so every token is a game flag
+type LaunchOptions struct {
+	Env      []EnvVar
+	Wrappers []string
+	Args     []string
+
+	hasCommand bool
+}
+
+// ParseLaunchOptions splits a launch options string into env vars, wrappers, and game flags
+// Quoted tokens are kept intact (including their quotes) so String() round-trips them
+func ParseLaunchOptions(s string) *LaunchOptions {
+	tokens := SplitLaunchOptions(s)
+	opts := &LaunchOptions{}
+
+	commandIdx := -1
+	for i, token := range tokens {
+		if token == CommandToken {
+			commandIdx = i
+			break
+		}
+	}
+
+	if commandIdx == -1 {
+		opts.Args = tokens
+		return opts
+	}
+
+	opts.hasCommand = true
+	inEnv := true
+	for _, token := range tokens[:commandIdx] {
+		if inEnv {
+			if name, value, ok := SplitEnvVar(token); ok {
+				opts.Env = append(opts.Env, EnvVar{Name: name, Value: value})
+				continue
+			}
+			inEnv = false
+		}
+		opts.Wrappers = append(opts.Wrappers, token)
+	}
+
+	for _, token := range tokens[commandIdx+1:] {
+		// Duplicate %command% tokens are dropped
+		if token != CommandToken {
+			opts.Args = append(opts.Args, token)
+		}
+	}
+
+	return opts
+}
+
+// HasCommand reports whether the launch string uses %command%
+func (o *LaunchOptions) HasCommand() bool {
+	return o.hasCommand || len(o.Env) > 0 || len(o.Wrappers) > 0
+}
+
+// GetEnv returns the value of an environment variable and whether it is set
+func (o *LaunchOptions) GetEnv(name string) (string, bool) {
+	for _, env := range o.Env {
+		if env.Name == name {
+			return env.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetEnv sets an environment variable, replacing any existing value
+func (o *LaunchOptions) SetEnv(name, value string) *LaunchOptions {
+	for i := range o.Env {
+		if o.Env[i].Name == name {
+			o.Env[i].Value = value
+			return o
+		}
+	}
+	o.Env = append(o.Env, EnvVar{Name: name, Value: value})
+	return o
+}
+
+// UnsetEnv removes an environment variable
+func (o *LaunchOptions) UnsetEnv(name string) *LaunchOptions {
+	var kept []EnvVar
+	for _, env := range o.Env {
+		if env.Name != name {
+			kept = append(kept, env)
+		}
+	}
+	o.Env = kept
+	return o
+}
+
+// AddWrapper appends a wrapper command (e.g. "gamemoderun") before %command%
+// Wrappers may include their own arguments; nothing is added if the wrapper's
+// program is already present
+func (o *LaunchOptions) AddWrapper(wrapper string) *LaunchOptions {
+	tokens := SplitLaunchOptions(wrapper)
+	if len(tokens) == 0 || containsToken(o.Wrappers, tokens[0]) {
+		return o
+	}
+	o.Wrappers = append(o.Wrappers, tokens...)
+	return o
+}
+
+// AddArg appends game flags after %command%, skipping each flag that is already present with
+// the same values (flags are compared with their values, as in Normalize)
+func (o *LaunchOptions) AddArg(arg string) *LaunchOptions {
+	present := make(map[string]bool)
+	for _, unit := range flagUnits(o.Args) {
+		present[strings.Join(unit, " ")] = true
+	}
+	for _, unit := range flagUnits(SplitLaunchOptions(arg)) {
+		key := strings.Join(unit, " ")
+		if !present[key] {
+			present[key] = true
+			o.Args = append(o.Args, unit...)
+		}
+	}
+	return o
+}
+
+// HasToken reports whether a wrapper or game flag token is present
+func (o *LaunchOptions) HasToken(token string) bool {
+	return containsToken(o.Wrappers, token) || containsToken(o.Args, token)
+}
+
+// RemoveToken removes every wrapper or game flag token equal to token
+// Env vars are matched against their "NAME=value" form
+func (o *LaunchOptions) RemoveToken(token string) *LaunchOptions {
+	var env []EnvVar
+	for _, e := range o.Env {
+		if e.Name+"="+e.Value != token {
+			env = append(env, e)
+		}
+	}
+	o.Env = env
+	o.Wrappers = removeToken(o.Wrappers, token)
+	o.Args = removeToken(o.Args, token)
+	return o
+}
+
+// Normalize removes repeated settings: env vars set more than once keep their last value
+// (as in the shell), a wrapper repeated right after itself is dropped, and repeated game
+// flags are dropped along with their values (e.g. the second "-w 1920" in "-w 1920 -w 1920",
+// but not "+set b 1" after "+set a 1"). Whitespace and duplicate %command% are cleaned up
+// by parsing already
+func (o *LaunchOptions) Normalize() *LaunchOptions {
+	var env []EnvVar
+	index := make(map[string]int)
+	for _, e := range o.Env {
+		if i, seen := index[e.Name]; seen {
+			env[i].Value = e.Value
+			continue
+		}
+		index[e.Name] = len(env)
+		env = append(env, e)
+	}
+	o.Env = env
+
+	var wrappers []string
+	for i, token := range o.Wrappers {
+		if i == 0 || token != o.Wrappers[i-1] {
+			wrappers = append(wrappers, token)
+		}
+	}
+	o.Wrappers = wrappers
+
+	var args []string
+	seen := make(map[string]bool)
+	for _, unit := range flagUnits(o.Args) {
+		key := strings.Join(unit, " ")
+		if !seen[key] {
+			seen[key] = true
+			args = append(args, unit...)
+		}
+	}
+	o.Args = args
+	return o
+}
+
+// flagUnits groups game flags with the values that follow them, e.g. [-w 1920] [-novid]
+// Tokens before the first flag each form their own unit
+func flagUnits(tokens []string) [][]string {
+	var units [][]string
+	for _, token := range tokens {
+		if isFlagToken(token) || len(units) == 0 || !isFlagToken(units[len(units)-1][0]) {
+			units = append(units, []string{token})
+			continue
+		}
+		units[len(units)-1] = append(units[len(units)-1], token)
+	}
+	return units
+}
+
+func isFlagToken(token string) bool {
+	return strings.HasPrefix(token, "-") || strings.HasPrefix(token, "+")
+}
+
+// String renders the launch options back into a launch string
+func (o *LaunchOptions) String() string {
+	if len(o.Env) == 0 && len(o.Wrappers) == 0 && len(o.Args) == 0 {
+		return ""
+	}
+
+	if !o.HasCommand() {
+		return strings.Join(o.Args, " ")
+	}
+
+	parts := make([]string, 0, len(o.Env)+len(o.Wrappers)+len(o.Args)+1)
+	for _, env := range o.Env {
+		parts = append(parts, env.Name+"="+env.Value)
+	}
+	parts = append(parts, o.Wrappers...)
+	parts = append(parts, CommandToken)
+	parts = append(parts, o.Args...)
+
+	return strings.Join(parts, " ")
+}
+
+// SplitLaunchOptions splits a launch string into tokens on whitespace outside of single or
+// double quotes, keeping the quotes
+func SplitLaunchOptions(s string) []string {
+	var tokens []string
+	var current strings.Builder
+	var quote byte
+
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+
+		switch {
+		case quote != 0:
+			current.WriteByte(ch)
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			current.WriteByte(ch)
+			quote = ch
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteByte(ch)
+		}
+	}
+
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens
+}
+
+// SplitEnvVar splits a NAME=value token, returning false if token isn't an assignment
+func SplitEnvVar(token string) (string, string, bool) {
+	eq := strings.IndexByte(token, '=')
+	if eq <= 0 {
+		return "", "", false
+	}
+
+	name := token[:eq]
+	for i := 0; i < len(name); i++ {
+		ch := name[i]
+		isLetter := (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') || ch == '_'
+		isDigit := ch >= '0' && ch <= '9'
+		if !isLetter && !(isDigit && i > 0) {
+			return "", "", false
+		}
+	}
+
+	return name, token[eq+1:], true
+}
+
+func containsToken(tokens []string, token string) bool {
+	for _, t := range tokens {
+		if t == token {
+			return true
+		}
+	}
+	return false
+}
+
+func removeToken(tokens []string, token string) []string {
+	var kept []string
+	for _, t := range tokens {
+		if t != token {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
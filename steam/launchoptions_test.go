@@ -0,0 +1,189 @@
+package steam
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLaunchOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		wantEnv      []EnvVar
+		wantWrappers []string
+		wantArgs     []string
+	}{
+		{
+			name:  "empty",
+			input: "",
+		},
+		{
+			name:     "game flags only",
+			input:    "-novid -high",
+			wantArgs: []string{"-novid", "-high"},
+		},
+		{
+			name:         "env, wrappers and flags",
+			input:        "PROTON_LOG=1 DXVK_HUD=fps gamemoderun mangohud %command% -novid",
+			wantEnv:      []EnvVar{{Name: "PROTON_LOG", Value: "1"}, {Name: "DXVK_HUD", Value: "fps"}},
+			wantWrappers: []string{"gamemoderun", "mangohud"},
+			wantArgs:     []string{"-novid"},
+		},
+		{
+			name:         "quoted argument kept intact",
+			input:        `gamescope -W 1920 -- %command% -name "My Server"`,
+			wantWrappers: []string{"gamescope", "-W", "1920", "--"},
+			wantArgs:     []string{"-name", `"My Server"`},
+		},
+		{
+			name:     "duplicate command dropped",
+			input:    "%command% -novid %command%",
+			wantArgs: []string{"-novid"},
+		},
+		{
+			name:         "assignment after wrapper is not env",
+			input:        "env FOO=1 %command%",
+			wantWrappers: []string{"env", "FOO=1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseLaunchOptions(tt.input)
+
+			if !reflect.DeepEqual(got.Env, tt.wantEnv) {
+				t.Errorf("ParseLaunchOptions() Env = %v, want %v", got.Env, tt.wantEnv)
+			}
+			if !reflect.DeepEqual(got.Wrappers, tt.wantWrappers) {
+				t.Errorf("ParseLaunchOptions() Wrappers = %v, want %v", got.Wrappers, tt.wantWrappers)
+			}
+			if !reflect.DeepEqual(got.Args, tt.wantArgs) {
+				t.Errorf("ParseLaunchOptions() Args = %v, want %v", got.Args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestLaunchOptionsEdit(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		edit  func(o *LaunchOptions) *LaunchOptions
+		want  string
+	}{
+		{
+			name:  "round trip",
+			input: `A=1 gamemoderun %command% -name "My Server"`,
+			edit:  func(o *LaunchOptions) *LaunchOptions { return o },
+			want:  `A=1 gamemoderun %command% -name "My Server"`,
+		},
+		{
+			name:  "add wrapper to flags-only string",
+			input: "-novid",
+			edit:  func(o *LaunchOptions) *LaunchOptions { return o.AddWrapper("gamemoderun") },
+			want:  "gamemoderun %command% -novid",
+		},
+		{
+			name:  "add existing wrapper is a no-op",
+			input: "gamemoderun %command%",
+			edit:  func(o *LaunchOptions) *LaunchOptions { return o.AddWrapper("gamemoderun") },
+			want:  "gamemoderun %command%",
+		},
+		{
+			name:  "set env replaces value",
+			input: "DXVK_HUD=fps %command%",
+			edit: func(o *LaunchOptions) *LaunchOptions {
+				return o.SetEnv("DXVK_HUD", "full").SetEnv("PROTON_LOG", "1")
+			},
+			want: "DXVK_HUD=full PROTON_LOG=1 %command%",
+		},
+		{
+			name:  "unset env",
+			input: "DXVK_HUD=fps mangohud %command%",
+			edit:  func(o *LaunchOptions) *LaunchOptions { return o.UnsetEnv("DXVK_HUD") },
+			want:  "mangohud %command%",
+		},
+		{
+			name:  "remove token everywhere",
+			input: "mangohud %command% -novid mangohud",
+			edit:  func(o *LaunchOptions) *LaunchOptions { return o.RemoveToken("mangohud") },
+			want:  "%command% -novid",
+		},
+		{
+			name:  "remove last token empties string",
+			input: "gamemoderun %command%",
+			edit:  func(o *LaunchOptions) *LaunchOptions { return o.RemoveToken("gamemoderun") },
+			want:  "",
+		},
+		{
+			name:  "add arg skips duplicates",
+			input: "%command% -novid",
+			edit:  func(o *LaunchOptions) *LaunchOptions { return o.AddArg("-novid -high") },
+			want:  "%command% -novid -high",
+		},
+		{
+			name:  "add arg keeps flag values",
+			input: "%command% -h 1920",
+			edit:  func(o *LaunchOptions) *LaunchOptions { return o.AddArg("-w 1920") },
+			want:  "%command% -h 1920 -w 1920",
+		},
+		{
+			name:  "add arg skips flag with same value",
+			input: "%command% -w 1920 -novid",
+			edit:  func(o *LaunchOptions) *LaunchOptions { return o.AddArg("-w 1920 -high") },
+			want:  "%command% -w 1920 -novid -high",
+		},
+		{
+			name:  "normalize whitespace and duplicate command",
+			input: "  gamemoderun   %command%  -novid %command%\t",
+			edit:  (*LaunchOptions).Normalize,
+			want:  "gamemoderun %command% -novid",
+		},
+		{
+			name:  "normalize repeated env, wrappers, and flags",
+			input: "DXVK_HUD=fps PROTON_LOG=1 DXVK_HUD=full mangohud mangohud %command% -w 1920 -novid -w 1920 -novid",
+			edit:  (*LaunchOptions).Normalize,
+			want:  "DXVK_HUD=full PROTON_LOG=1 mangohud %command% -w 1920 -novid",
+		},
+		{
+			name:  "normalize keeps repeated flags with different values",
+			input: `-novid +set a 1 +set b 1 -name "x  y" -novid`,
+			edit:  (*LaunchOptions).Normalize,
+			want:  `-novid +set a 1 +set b 1 -name "x  y"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.edit(ParseLaunchOptions(tt.input)).String()
+			if got != tt.want {
+				t.Errorf("LaunchOptions.String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckLaunchOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		wantWarnings int
+		wantErr      bool
+	}{
+		{name: "typical", input: "gamemoderun %command% -novid"},
+		{name: "empty", input: ""},
+		{name: "too long", input: "%command% " + strings.Repeat("-x ", MaxLaunchOptionsLength/3+1), wantWarnings: 1},
+		{name: "newline", input: "%command%\n-novid", wantErr: true},
+		{name: "tab", input: "%command%\t-novid", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := CheckLaunchOptions(tt.input)
+			if (err != nil) != tt.wantErr || len(warnings) != tt.wantWarnings {
+				t.Errorf("CheckLaunchOptions() = %v, %v, want %d warning(s), wantErr %v", warnings, err, tt.wantWarnings, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package steam
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrGameNotFound is returned when a name or app ID doesn't match any installed game
+var ErrGameNotFound = errors.New("game not found")
+
+// AmbiguousNameError is returned when a game name matches more than one app
+// (e.g. a game and its demo or a regional release)
+type AmbiguousNameError struct {
+	Name   string
+	AppIDs []string
+}
+
+func (e *AmbiguousNameError) Error() string {
+	return fmt.Sprintf("%q matches multiple games (app IDs %s)", e.Name, strings.Join(e.AppIDs, ", "))
+}
+
+// LibraryEntry is an installed game known to a Library
+type LibraryEntry struct {
+	AppID string
+	Name  string
+	// State is the game's install state (one of the InstallState constants)
+	State string
+	// LastOwner is the SteamID64 of the account whose license the install uses; see
+	// FamilySharedBy
+	LastOwner string
+}
+
+// Library indexes the installed games of a Steam installation by name and app ID
+type Library struct {
+	byID   map[string]LibraryEntry
+	byName map[string][]string
+}
+
+// LoadLibrary reads every appmanifest in all library folders
+// progress (may be nil) is called after each appmanifest is read
+func LoadLibrary(steamPath string, progress ProgressFunc) (*Library, error) {
+	session := NewSession(steamPath, "")
+	session.Progress = progress
+	return session.Library()
+}
+
+// libraryFromManifests indexes the games of scanned appmanifests
+func libraryFromManifests(manifests []appManifest) *Library {
+	lib := newLibrary()
+	for _, m := range manifests {
+		lib.add(LibraryEntry{AppID: m.appID, Name: m.name, State: installStateFromFlags(m.stateFlags), LastOwner: m.lastOwner})
+	}
+	return lib
+}
+
+func newLibrary() *Library {
+	return &Library{
+		byID:   make(map[string]LibraryEntry),
+		byName: make(map[string][]string),
+	}
+}
+
+// add indexes an entry; the first manifest seen for an app ID wins
+func (l *Library) add(entry LibraryEntry) {
+	if _, exists := l.byID[entry.AppID]; exists {
+		return
+	}
+	l.byID[entry.AppID] = entry
+
+	// Names are matched ignoring case and accents and keep all of their app IDs
+	key := FoldName(entry.Name)
+	l.byName[key] = append(l.byName[key], entry.AppID)
+	SortAppIDs(l.byName[key])
+}
+
+// AddInstalledGameIDs returns appIDs, the apps of a localconfig.vdf, followed by the
+// installed games missing from them: Steam only adds a game to localconfig.vdf once it is
+// launched, and writing a value creates its entry. Tools (Proton, runtimes) are left out
+func AddInstalledGameIDs(appIDs []string, library *Library) []string {
+	listed := make(map[string]bool, len(appIDs))
+	for _, appID := range appIDs {
+		listed[appID] = true
+	}
+
+	var missing []string
+	for appID, entry := range library.byID {
+		if !listed[appID] && !IsToolName(entry.Name) {
+			missing = append(missing, appID)
+		}
+	}
+	SortAppIDs(missing)
+	return append(append([]string{}, appIDs...), missing...)
+}
+
+// LookupByName finds the game with the given name (ignoring case and accents)
+// Returns ErrGameNotFound or an *AmbiguousNameError if there isn't exactly one match
+func (l *Library) LookupByName(name string) (LibraryEntry, error) {
+	appIDs := l.byName[FoldName(name)]
+	switch len(appIDs) {
+	case 0:
+		return LibraryEntry{}, fmt.Errorf("%q: %w", name, ErrGameNotFound)
+	case 1:
+		return l.byID[appIDs[0]], nil
+	default:
+		return LibraryEntry{}, &AmbiguousNameError{Name: name, AppIDs: append([]string(nil), appIDs...)}
+	}
+}
+
+// LookupByID finds the game with the given app ID
+func (l *Library) LookupByID(appID string) (LibraryEntry, bool) {
+	entry, ok := l.byID[appID]
+	return entry, ok
+}
+
+// All returns every game in the library, sorted by app ID
+func (l *Library) All() []LibraryEntry {
+	appIDs := make([]string, 0, len(l.byID))
+	for appID := range l.byID {
+		appIDs = append(appIDs, appID)
+	}
+	SortAppIDs(appIDs)
+
+	entries := make([]LibraryEntry, len(appIDs))
+	for i, appID := range appIDs {
+		entries[i] = l.byID[appID]
+	}
+	return entries
+}
+
+// SortAppIDs sorts numeric app IDs in ascending numeric order
+func SortAppIDs(appIDs []string) {
+	sort.Slice(appIDs, func(i, j int) bool {
+		if len(appIDs[i]) != len(appIDs[j]) {
+			return len(appIDs[i]) < len(appIDs[j])
+		}
+		return appIDs[i] < appIDs[j]
+	})
+}
@@ -0,0 +1,121 @@
+package steam
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/zerkz/gsca/vdf"
+)
+
+// ErrWriteNotVerified is returned when localconfig.vdf doesn't hold the written contents afterwards
+var ErrWriteNotVerified = errors.New("localconfig.vdf doesn't match what was written")
+
+// WriteOptions controls WriteLocalConfig
+type WriteOptions struct {
+	// SkipBackup disables the backup normally created before writing
+	SkipBackup bool
+	// Original, if set, is the file the new contents were computed from; the write fails with
+	// ErrConfigModified if localconfig.vdf changed since
+	Original *Fingerprint
+}
+
+// WriteLocalConfig replaces localConfigPath with root. Every write to localconfig.vdf goes
+// through the same steps: the contents are checked to be well-formed VDF, the file is checked
+// against opts.Original, backed up, replaced atomically keeping its permissions and owner, and
+// read back to verify. Returns the backup path ("" if skipped)
+func WriteLocalConfig(localConfigPath string, root *vdf.Node, opts WriteOptions) (string, error) {
+	var buf bytes.Buffer
+	if err := vdf.Write(&buf, root, 0); err != nil {
+		return "", fmt.Errorf("failed to write VDF: %w", err)
+	}
+	return writeLocalConfigData(localConfigPath, buf.Bytes(), opts)
+}
+
+// writeLocalConfigData is WriteLocalConfig for contents that are already serialized
+// (surgical edits, restored backups)
+func writeLocalConfigData(localConfigPath string, data []byte, opts WriteOptions) (string, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return "", errors.New("refusing to write an empty localconfig.vdf")
+	}
+	if err := vdf.Validate(bytes.NewReader(data)); err != nil {
+		return "", fmt.Errorf("refusing to write a malformed localconfig.vdf: %w", err)
+	}
+
+	// Make sure nothing (e.g. Steam) rewrote the file since it was parsed
+	if opts.Original != nil {
+		current, err := FingerprintFile(localConfigPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to re-check localconfig.vdf: %w", err)
+		}
+		if !current.Equal(*opts.Original) {
+			return "", ErrConfigModified
+		}
+	}
+
+	// A missing file (e.g. restoring a deleted localconfig.vdf) is simply created
+	info, err := os.Stat(localConfigPath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to open localconfig.vdf: %w", err)
+	}
+
+	var backupPath string
+	if info != nil && !opts.SkipBackup {
+		if backupPath, err = createBackup(localConfigPath); err != nil {
+			return "", fmt.Errorf("failed to create backup: %w", err)
+		}
+	}
+
+	if err := trackWrite(localConfigPath, func() error {
+		return writeFileAtomic(localConfigPath, data, info)
+	}); err != nil {
+		return backupPath, classifyWriteError(localConfigPath, err)
+	}
+
+	written, err := os.ReadFile(localConfigPath)
+	if err != nil {
+		return backupPath, fmt.Errorf("failed to verify localconfig.vdf: %w", err)
+	}
+	if !bytes.Equal(written, data) {
+		return backupPath, ErrWriteNotVerified
+	}
+	return backupPath, nil
+}
+
+// writeFileAtomic replaces path with data by writing a temporary file next to it and renaming
+// it over the original, so a crash or full disk never leaves a truncated file behind. The
+// temporary file gets the mode and (where possible) owner of the original, described by info
+// (nil for a new file)
+func writeFileAtomic(path string, data []byte, info os.FileInfo) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	// Removing fails harmlessly once the rename succeeded
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	perm := os.FileMode(0644)
+	if info != nil {
+		perm = info.Mode().Perm()
+		// Running as root (e.g. sudo) mustn't leave Steam unable to write its own file
+		copyOwner(tmpPath, info)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
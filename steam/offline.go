@@ -0,0 +1,57 @@
+package steam
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// LibraryProbeTimeout is how long a library folder may take to respond before it's treated as offline
+var LibraryProbeTimeout = 2 * time.Second
+
+// libraryProbes caches libraryOnline results, so an offline drive only costs one timeout
+var libraryProbes sync.Map
+
+// OfflineLibrary is a library folder that is unmounted or not responding
+type OfflineLibrary struct {
+	Path string
+	// AppIDs are the games libraryfolders.vdf lists in it
+	AppIDs []string
+}
+
+// OfflineLibraries returns the library folders in libraryfolders.vdf that can't be read
+func OfflineLibraries(steamPath string) []OfflineLibrary {
+	var offline []OfflineLibrary
+	seen := make(map[string]bool)
+	for _, folder := range readLibraryFolders(steamPath) {
+		if seen[folder.path] || libraryOnline(folder.path) {
+			continue
+		}
+		seen[folder.path] = true
+		offline = append(offline, OfflineLibrary{Path: folder.path, AppIDs: folder.appIDs})
+	}
+	return offline
+}
+
+// libraryOnline reports whether a library folder's steamapps directory can be read within
+// LibraryProbeTimeout. A hung stat (e.g. a dead network mount) is left running in the background
+func libraryOnline(path string) bool {
+	if online, ok := libraryProbes.Load(path); ok {
+		return online.(bool)
+	}
+
+	done := make(chan bool, 1)
+	go func() {
+		info, err := os.Stat(filepath.Join(path, "steamapps"))
+		done <- err == nil && info.IsDir()
+	}()
+
+	var online bool
+	select {
+	case online = <-done:
+	case <-time.After(LibraryProbeTimeout):
+	}
+	libraryProbes.Store(path, online)
+	return online
+}
@@ -0,0 +1,34 @@
+//go:build !windows
+
+package steam
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// ownedByOtherUser reports whether path (or its directory, if path doesn't exist) belongs to
+// a user other than the current one, and that user's ID
+func ownedByOtherUser(path string) (int, bool) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		info, err = os.Stat(filepath.Dir(path))
+	}
+	if err != nil {
+		return 0, false
+	}
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return int(stat.Uid), int(stat.Uid) != os.Getuid()
+}
+
+// copyOwner gives path the owner and group described by info, if allowed (e.g. as root)
+func copyOwner(path string, info os.FileInfo) {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		_ = os.Chown(path, int(stat.Uid), int(stat.Gid))
+	}
+}
@@ -0,0 +1,11 @@
+package steam
+
+import "os"
+
+// ownedByOtherUser is not checked on Windows, where access is controlled by ACLs instead
+func ownedByOtherUser(path string) (int, bool) {
+	return 0, false
+}
+
+// copyOwner does nothing on Windows, where new files inherit the folder's ACLs
+func copyOwner(path string, info os.FileInfo) {}
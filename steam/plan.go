@@ -0,0 +1,118 @@
+package steam
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// PlanFormatVersion is the version of the plan file format written by WritePlan
+const PlanFormatVersion = 1
+
+// ErrPlanStale is returned when localconfig.vdf no longer matches the state a plan was made against
+var ErrPlanStale = errors.New("localconfig.vdf changed since the plan was made")
+
+// Plan is a reviewed set of localconfig.vdf changes, saved to be applied later
+type Plan struct {
+	FormatVersion int         `json:"format_version"`
+	CreatedAt     time.Time   `json:"created_at"`
+	Environment   Environment `json:"environment"`
+	// Key is the per-app localconfig key being set (e.g. LaunchOptions)
+	Key      string       `json:"key"`
+	Surgical bool         `json:"surgical,omitempty"`
+	Changes  []PlanChange `json:"changes"`
+}
+
+// Environment identifies the Steam install and config state a plan was made against
+type Environment struct {
+	OS              string      `json:"os"`
+	GscaVersion     string      `json:"gsca_version,omitempty"`
+	SteamPath       string      `json:"steam_path"`
+	UserID          string      `json:"user_id"`
+	LocalConfigPath string      `json:"localconfig_path"`
+	LocalConfig     Fingerprint `json:"localconfig"`
+}
+
+// PlanChange is one app's planned value change
+type PlanChange struct {
+	AppID    string `json:"app_id"`
+	Name     string `json:"name,omitempty"`
+	OldValue string `json:"old_value"`
+	NewValue string `json:"new_value"`
+}
+
+// WritePlan saves a plan as indented JSON
+func WritePlan(path string, plan *Plan) error {
+	plan.FormatVersion = PlanFormatVersion
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+	return nil
+}
+
+// LoadPlan reads a plan saved by WritePlan
+func LoadPlan(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("invalid plan %s: %w", path, err)
+	}
+	if plan.FormatVersion != PlanFormatVersion {
+		return nil, fmt.Errorf("unsupported plan format version %d (expected %d)", plan.FormatVersion, PlanFormatVersion)
+	}
+	if plan.Environment.LocalConfigPath == "" || plan.Key == "" {
+		return nil, fmt.Errorf("invalid plan %s: missing localconfig path or key", path)
+	}
+	return &plan, nil
+}
+
+// CheckPlan returns ErrPlanStale if localconfig.vdf changed since the plan was made
+func CheckPlan(plan *Plan) error {
+	current, err := FingerprintFile(plan.Environment.LocalConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read localconfig.vdf: %w", err)
+	}
+	if !current.Equal(plan.Environment.LocalConfig) {
+		return ErrPlanStale
+	}
+	return nil
+}
+
+// ApplyPlan writes a plan's changes, refusing with ErrPlanStale if localconfig.vdf
+// is no longer exactly the file the plan was made against. opts.Surgical is taken from the plan
+func ApplyPlan(plan *Plan, opts UpdateOptions) (*UpdateResult, error) {
+	if err := validateAppKey(plan.Key); err != nil {
+		return nil, err
+	}
+
+	values := make([]AppValue, len(plan.Changes))
+	for i, change := range plan.Changes {
+		values[i] = AppValue{AppID: change.AppID, Value: change.NewValue}
+	}
+
+	opts.Surgical = plan.Surgical
+	path := plan.Environment.LocalConfigPath
+	edit, err := editAppValues(path, plan.Key, values, opts)
+	if err != nil {
+		return nil, err
+	}
+	if !edit.original.Equal(plan.Environment.LocalConfig) {
+		return nil, ErrPlanStale
+	}
+
+	result, err := commitAppValues(path, edit, opts)
+	if errors.Is(err, ErrConfigModified) {
+		return nil, ErrPlanStale
+	}
+	return result, err
+}
@@ -0,0 +1,93 @@
+package steam
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// steamDeckProducts are the DMI product names of Steam Deck models (LCD and OLED)
+var steamDeckProducts = map[string]bool{
+	"Jupiter": true,
+	"Galileo": true,
+}
+
+// IsSteamDeck reports whether gsca is running on a Steam Deck
+func IsSteamDeck() bool {
+	return runtime.GOOS == osLinux && isSteamDeck("/")
+}
+
+// isSteamDeck checks the DMI vendor and product, falling back to SteamOS in os-release
+// (covers Decks running in a container without /sys). root is the filesystem root
+func isSteamDeck(root string) bool {
+	vendor, vendorErr := os.ReadFile(filepath.Join(root, "sys", "devices", "virtual", "dmi", "id", "board_vendor"))
+	product, productErr := os.ReadFile(filepath.Join(root, "sys", "devices", "virtual", "dmi", "id", "product_name"))
+	if vendorErr == nil && productErr == nil {
+		return strings.TrimSpace(string(vendor)) == "Valve" && steamDeckProducts[strings.TrimSpace(string(product))]
+	}
+
+	osRelease, err := os.ReadFile(filepath.Join(root, "etc", "os-release"))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(osRelease), "\n") {
+		if key, value, found := strings.Cut(strings.TrimSpace(line), "="); found && key == "ID" {
+			return strings.Trim(value, `"`) == "steamos"
+		}
+	}
+	return false
+}
+
+// Sandboxes gsca can detect itself running in
+const (
+	SandboxFlatpak   = "flatpak"
+	SandboxSnap      = "snap"
+	SandboxContainer = "container"
+)
+
+// DetectSandbox returns the kind of sandbox gsca is running in, or "" if none was detected
+func DetectSandbox() string {
+	if runtime.GOOS != osLinux {
+		return ""
+	}
+	return detectSandbox("/", os.Getenv)
+}
+
+// detectSandbox checks the marker files and environment variables Flatpak, Snap, and
+// container runtimes (Docker, Podman, systemd-nspawn, Toolbox) set up. root is the filesystem root
+func detectSandbox(root string, getenv func(string) string) string {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(root, name))
+		return err == nil
+	}
+
+	switch {
+	case getenv("FLATPAK_ID") != "" || exists(".flatpak-info"):
+		return SandboxFlatpak
+	case getenv("SNAP_NAME") != "":
+		return SandboxSnap
+	case getenv("container") != "" || exists(".dockerenv") || exists(filepath.Join("run", ".containerenv")):
+		return SandboxContainer
+	}
+	return ""
+}
+
+// sandboxError explains how to give gsca running in sandbox access to steamPath
+func sandboxError(sandbox, steamPath string) error {
+	var hint string
+	switch sandbox {
+	case SandboxFlatpak:
+		app := os.Getenv("FLATPAK_ID")
+		if app == "" {
+			app = "<app-id>"
+		}
+		hint = fmt.Sprintf("grant access with 'flatpak override --user --filesystem=%s %s', or run the gsca binary outside the sandbox", steamPath, app)
+	case SandboxSnap:
+		hint = "snaps can't read hidden directories in your home folder; run the gsca release binary directly instead"
+	default:
+		hint = "mount the host's Steam directory into the container (e.g. 'docker run -v ~/.local/share/Steam:/steam ...') and pass --steam-path, or run gsca on the host"
+	}
+	return fmt.Errorf("steam installation not found at %s: gsca is running inside a %s sandbox without access to it - %s", steamPath, sandbox, hint)
+}
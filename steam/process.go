@@ -1,12 +1,18 @@
 package steam
 
 import (
+	"encoding/csv"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 )
 
+// windowsClients are the Steam client executables on Windows: the global client and Steam China's
+var windowsClients = []string{"steam.exe", "steamchina.exe"}
+
 // IsSteamRunning checks if Steam is currently running
 func IsSteamRunning() (bool, error) {
 	var cmd *exec.Cmd
@@ -17,7 +23,8 @@ func IsSteamRunning() (bool, error) {
 	case osDarwin:
 		cmd = exec.Command("pgrep", "-x", "steam_osx")
 	case osWindows:
-		cmd = exec.Command("tasklist", "/FI", "IMAGENAME eq steam.exe", "/NH")
+		running, err := runningWindowsClients()
+		return len(running) > 0, err
 	default:
 		return false, fmt.Errorf("unsupported platform: %s", runtime.GOOS)
 	}
@@ -33,25 +40,68 @@ func IsSteamRunning() (bool, error) {
 		return false, err
 	}
 
-	// Check output
-	outputStr := strings.TrimSpace(string(output))
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// runningWindowsClients returns the Steam client executables that are running
+func runningWindowsClients() ([]string, error) {
+	var running []string
+	for _, client := range windowsClients {
+		output, err := exec.Command("tasklist", "/FO", "CSV", "/NH", "/FI", "IMAGENAME eq "+client).Output()
+		if err != nil {
+			return nil, err
+		}
+		if tasklistHasImage(string(output), client) {
+			running = append(running, client)
+		}
+	}
+	return running, nil
+}
 
-	// On Windows, tasklist returns "INFO: No tasks..." when process not found
-	if runtime.GOOS == osWindows {
-		return !strings.Contains(outputStr, "No tasks"), nil
+// tasklistHasImage reports whether CSV output of tasklist lists a process of image. The
+// message tasklist prints when nothing matches is localized, so rows are matched by name
+func tasklistHasImage(output, image string) bool {
+	r := csv.NewReader(strings.NewReader(output))
+	r.FieldsPerRecord = -1
+	records, _ := r.ReadAll()
+	for _, record := range records {
+		if len(record) > 1 && strings.EqualFold(record[0], image) {
+			return true
+		}
 	}
+	return false
+}
 
-	return outputStr != "", nil
+// StopOptions controls how Steam is closed
+type StopOptions struct {
+	// Executable is the Steam client to shut down instead of the platform's usual one:
+	// "steam" on Linux or the running steam.exe on Windows. It is ignored on macOS
+	Executable string
+	// Command replaces the command that closes Steam
+	Command []string
 }
 
 // CloseSteam attempts to gracefully close Steam
 func CloseSteam() error {
+	return CloseSteamWith(StopOptions{})
+}
+
+// CloseSteamWith attempts to gracefully close Steam with opts
+func CloseSteamWith(opts StopOptions) error {
+	if len(opts.Command) > 0 {
+		return exec.Command(opts.Command[0], opts.Command[1:]...).Run()
+	}
+
 	var cmd *exec.Cmd
+	executable := "steam"
+	if opts.Executable != "" {
+		executable = opts.Executable
+	}
 
 	switch runtime.GOOS {
 	case osLinux:
 		// Use steam's own shutdown command
-		cmd = exec.Command("steam", "-shutdown")
+		cmd = exec.Command(executable, "-shutdown")
 	case osDarwin:
 		// macOS: Use AppleScript to quit gracefully
 		// Note: osascript may return exit code 1 even when quit succeeds,
@@ -61,7 +111,18 @@ func CloseSteam() error {
 		return nil
 	case osWindows:
 		// Windows: Force kill Steam - graceful shutdown doesn't work reliably
-		cmd = exec.Command("taskkill", "/F", "/IM", "steam.exe")
+		running, err := runningWindowsClients()
+		if err != nil || len(running) == 0 {
+			running = windowsClients[:1]
+		}
+		if opts.Executable != "" {
+			running = []string{filepath.Base(opts.Executable)}
+		}
+		args := []string{"/F"}
+		for _, client := range running {
+			args = append(args, "/IM", client)
+		}
+		cmd = exec.Command("taskkill", args...)
 	default:
 		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
 	}
@@ -69,25 +130,127 @@ func CloseSteam() error {
 	return cmd.Run()
 }
 
+// StartOptions controls how Steam is started
+type StartOptions struct {
+	// BigPicture opens Steam in Big Picture mode
+	BigPicture bool
+	// Minimized starts Steam in the tray without opening or focusing its window
+	Minimized bool
+	// Args are extra command line arguments for Steam
+	Args []string
+	// SteamPath is the install to run when Steam has to be started directly
+	// (Windows with Args); detected if empty
+	SteamPath string
+	// Executable is the Steam client to run instead of the platform's usual one: "steam" on
+	// Linux, the Steam app on macOS, or steam.exe in the install on Windows
+	Executable string
+	// Command replaces the command that starts Steam; Args and the Big Picture URL are
+	// appended to it
+	Command []string
+}
+
+// args returns Args plus -silent when starting minimized
+func (o StartOptions) args() []string {
+	if o.Minimized && !containsToken(o.Args, "-silent") {
+		return append(append([]string{}, o.Args...), "-silent")
+	}
+	return o.Args
+}
+
 // StartSteam attempts to start Steam
 func StartSteam() error {
-	var cmd *exec.Cmd
+	return StartSteamWith(StartOptions{})
+}
 
-	switch runtime.GOOS {
+// StartSteamWith attempts to start Steam with opts
+func StartSteamWith(opts StartOptions) error {
+	steamPath := opts.SteamPath
+	if runtime.GOOS == osWindows && len(opts.args()) > 0 && steamPath == "" && opts.Executable == "" && len(opts.Command) == 0 {
+		// Arguments can't be passed through the steam:// protocol, so run steam.exe itself
+		var err error
+		if steamPath, err = GetSteamPath(); err != nil {
+			return err
+		}
+	}
+
+	command, err := startCommand(runtime.GOOS, steamPath, opts)
+	if err != nil {
+		return err
+	}
+	return exec.Command(command[0], command[1:]...).Start()
+}
+
+// startCommand returns the command line that starts Steam on goos
+func startCommand(goos, steamPath string, opts StartOptions) ([]string, error) {
+	var command []string
+	args := opts.args()
+	url := "steam://open/main"
+	if opts.BigPicture {
+		url = "steam://open/bigpicture"
+	}
+
+	if len(opts.Command) > 0 {
+		command = append(append(command, opts.Command...), args...)
+		if opts.BigPicture {
+			command = append(command, url)
+		}
+		return command, nil
+	}
+	executable := opts.Executable
+
+	switch goos {
 	case osLinux:
-		cmd = exec.Command("steam")
+		if executable == "" {
+			executable = "steam"
+		}
+		command = append([]string{executable}, args...)
+		if opts.BigPicture {
+			command = append(command, url)
+		}
 	case osDarwin:
-		// macOS: Use open command
-		cmd = exec.Command("open", "-a", "Steam")
+		// macOS: Use open command, -g keeps Steam in the background
+		if executable == "" {
+			executable = "Steam"
+		}
+		command = []string{"open", "-a", executable}
+		if opts.Minimized {
+			command = []string{"open", "-g", "-a", executable}
+		}
+		if opts.BigPicture {
+			command = append(command, url)
+		}
+		if len(args) > 0 {
+			command = append(append(command, "--args"), args...)
+		}
 	case osWindows:
 		// Windows: Use steam:// protocol which works regardless of install location
 		// The empty string "" is needed as the window title parameter for start command
-		cmd = exec.Command("cmd", "/C", "start", "", "steam://open/main")
+		if len(args) == 0 && executable == "" {
+			return []string{"cmd", "/C", "start", "", url}, nil
+		}
+		if executable == "" {
+			executable = windowsClientPath(steamPath)
+		}
+		command = append([]string{"cmd", "/C", "start", "", executable}, args...)
+		if opts.BigPicture {
+			command = append(command, url)
+		}
 	default:
-		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+		return nil, fmt.Errorf("unsupported platform: %s", goos)
 	}
 
-	return cmd.Start()
+	return command, nil
+}
+
+// windowsClientPath returns the client executable of a Windows install, steamchina.exe for Steam China
+func windowsClientPath(steamPath string) string {
+	for _, client := range windowsClients[1:] {
+		path := filepath.Join(steamPath, client)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(steamPath, windowsClients[0])
 }
 
 // OpenFile opens a file with the default system application
@@ -0,0 +1,12 @@
+package steam
+
+// ProgressFunc is called with the number of items processed so far and the total
+// Pass nil to functions accepting a ProgressFunc to disable progress reporting
+type ProgressFunc func(done, total int)
+
+// report calls the progress function if one was provided
+func (p ProgressFunc) report(done, total int) {
+	if p != nil {
+		p(done, total)
+	}
+}
@@ -0,0 +1,117 @@
+package steam
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zerkz/gsca/vdf"
+)
+
+// ErrSteamNotReady is returned when Steam doesn't finish starting in time
+var ErrSteamNotReady = errors.New("steam did not finish starting")
+
+// activeProcessPath is where Steam records its running client and logged in user
+const activeProcessPath = "Registry/HKCU/Software/Valve/Steam/ActiveProcess"
+
+// IsSteamReady reports whether the Steam client is running with a user logged in, which is
+// when it starts accepting steam:// requests. Steam records the user in its registry
+// (registry.vdf on Linux and macOS) once login completes
+func IsSteamReady() (bool, error) {
+	running, err := IsSteamRunning()
+	if err != nil || !running {
+		return false, err
+	}
+
+	var user string
+	switch runtime.GOOS {
+	case osWindows:
+		output, err := exec.Command("reg", "query", `HKCU\Software\Valve\Steam\ActiveProcess`, "/v", "ActiveUser").Output()
+		if err != nil {
+			return false, nil
+		}
+		user = parseRegDWORD(string(output))
+	default:
+		for _, path := range registryVDFPaths() {
+			if user, err = activeUserFromRegistry(path); err == nil {
+				break
+			}
+		}
+	}
+	return user != "" && user != "0", nil
+}
+
+// WaitForSteam polls IsSteamReady every interval until it reports true or timeout passes
+func WaitForSteam(timeout, interval time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		if ready, _ := IsSteamReady(); ready {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w within %s", ErrSteamNotReady, timeout)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// registryVDFPaths lists where Steam keeps registry.vdf, native install first
+func registryVDFPaths() []string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	if runtime.GOOS == osDarwin {
+		return []string{filepath.Join(homeDir, "Library", "Application Support", "Steam", "registry.vdf")}
+	}
+	return []string{
+		filepath.Join(homeDir, ".steam", "registry.vdf"),
+		filepath.Join(homeDir, ".var", "app", "com.valvesoftware.Steam", ".steam", "registry.vdf"),
+	}
+}
+
+// activeUserFromRegistry returns the ActiveUser Steam recorded in a registry.vdf
+func activeUserFromRegistry(path string) (string, error) {
+	root, err := readVDFFile(path)
+	if err != nil {
+		return "", err
+	}
+	node := vdf.FindNode(root, activeProcessPath+"/ActiveUser")
+	if node == nil {
+		return "", nil
+	}
+	return node.Value, nil
+}
+
+// parseRegSZ extracts the string from 'reg query' output such as
+// "    SteamPath    REG_SZ    c:/program files (x86)/steam"
+func parseRegSZ(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if _, value, found := strings.Cut(line, " REG_SZ "); found {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// parseRegDWORD extracts the value from 'reg query' output such as
+// "    ActiveUser    REG_DWORD    0x1a2b3c", returning it in decimal
+func parseRegDWORD(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[1] == "REG_DWORD" {
+			value, err := strconv.ParseUint(strings.TrimPrefix(fields[2], "0x"), 16, 32)
+			if err != nil {
+				return ""
+			}
+			return strconv.FormatUint(value, 10)
+		}
+	}
+	return ""
+}
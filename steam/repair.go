@@ -0,0 +1,99 @@
+package steam
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/zerkz/gsca/vdf"
+)
+
+// CheckLocalConfig validates a localconfig.vdf file
+// Returns a *vdf.SyntaxError describing the damaged region, or nil if the file is intact
+func CheckLocalConfig(localConfigPath string) error {
+	f, err := os.Open(localConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to open localconfig.vdf: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	return vdf.Validate(f)
+}
+
+// NewestValidBackup returns the most recent backup that validates and contains an apps subtree
+func NewestValidBackup(localConfigPath string) (BackupInfo, bool) {
+	backups, err := ListBackups(localConfigPath)
+	if err != nil {
+		return BackupInfo{}, false
+	}
+
+	for _, backup := range backups {
+		if CheckLocalConfig(backup.Path) != nil {
+			continue
+		}
+		if root, err := readVDFFile(backup.Path); err == nil && vdf.FindNode(root, appsPath) != nil {
+			return backup, true
+		}
+	}
+
+	return BackupInfo{}, false
+}
+
+// RepairResult describes what RepairLocalConfig changed
+type RepairResult struct {
+	// DamagedCopy is where the original damaged file was saved
+	DamagedCopy string
+	// AppsRestoredFrom is the backup the apps subtree was rebuilt from (empty if not rebuilt)
+	AppsRestoredFrom string
+	// AppCount is the number of apps in the repaired file
+	AppCount int
+}
+
+// RepairLocalConfig rewrites a damaged localconfig.vdf from everything that could still be parsed
+// If appsBackupPath is set, the apps subtree is replaced with the one from that backup
+// while the rest of the salvaged file is kept. The damaged file is preserved alongside it
+func RepairLocalConfig(localConfigPath, appsBackupPath string) (*RepairResult, error) {
+	data, err := os.ReadFile(localConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read localconfig.vdf: %w", err)
+	}
+
+	// The parser stops at the damage, keeping everything before it
+	root, err := vdf.NewParser(bytes.NewReader(data)).ParsePartial()
+	var syntaxErr *vdf.SyntaxError
+	if err != nil && !errors.As(err, &syntaxErr) {
+		return nil, fmt.Errorf("failed to salvage localconfig.vdf: %w", err)
+	}
+
+	result := &RepairResult{}
+
+	if appsBackupPath != "" {
+		backupRoot, err := readVDFFile(appsBackupPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup: %w", err)
+		}
+		backupApps := vdf.FindNode(backupRoot, appsPath)
+		if backupApps == nil {
+			return nil, fmt.Errorf("backup has no apps section: %s", appsBackupPath)
+		}
+		vdf.ReplaceNode(root, appsPath, backupApps)
+		result.AppsRestoredFrom = appsBackupPath
+	}
+
+	if apps := vdf.FindNode(root, appsPath); apps != nil {
+		result.AppCount = len(apps.Children)
+	}
+
+	result.DamagedCopy = getNextSuffixedPath(localConfigPath + ".damaged")
+	if err := os.WriteFile(result.DamagedCopy, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to save damaged copy: %w", err)
+	}
+
+	// The damaged copy is the backup
+	if _, err := WriteLocalConfig(localConfigPath, root, WriteOptions{SkipBackup: true}); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,121 @@
+package steam
+
+import "github.com/zerkz/gsca/vdf"
+
+// Session reads a Steam install's files and an account's localconfig.vdf on first use and
+// keeps what it parsed, so the steps of one command share a single parse of localconfig.vdf
+// and a single scan of the appmanifests. It doesn't notice changes made to the files after
+// reading them, so writes still check the file's Fingerprint
+type Session struct {
+	SteamPath       string
+	LocalConfigPath string
+	// Progress reports the appmanifest scan and, for Games, the localconfig apps; it may be nil
+	Progress ProgressFunc
+
+	install      *installFiles
+	apps         cached[*vdf.Node]
+	sharedConfig cached[*SharedConfig]
+	games        cached[[]GameInfo]
+}
+
+// installFiles is what a session read from the install, shared with sessions for its other
+// accounts
+type installFiles struct {
+	manifests cached[[]appManifest]
+	// manifestCount is the number of appmanifests found by the scan
+	manifestCount int
+	library       cached[*Library]
+}
+
+// cached is a value read on first use
+type cached[T any] struct {
+	read  bool
+	value T
+	err   error
+}
+
+func (c *cached[T]) get(read func() (T, error)) (T, error) {
+	if !c.read {
+		c.read = true
+		c.value, c.err = read()
+	}
+	return c.value, c.err
+}
+
+// NewSession starts a session for the install at steamPath and the account whose settings are
+// in localConfigPath. Either may be empty if only the other's files are used
+func NewSession(steamPath, localConfigPath string) *Session {
+	return &Session{SteamPath: steamPath, LocalConfigPath: localConfigPath, install: &installFiles{}}
+}
+
+// ForAccount returns a session for another account of the same install, sharing the
+// appmanifests already read
+func (s *Session) ForAccount(localConfigPath string) *Session {
+	return &Session{SteamPath: s.SteamPath, LocalConfigPath: localConfigPath, Progress: s.Progress, install: s.install}
+}
+
+// Library returns the installed games
+func (s *Session) Library() (*Library, error) {
+	return s.install.library.get(func() (*Library, error) {
+		manifests, err := s.appManifests(0)
+		if err != nil {
+			return nil, err
+		}
+		return libraryFromManifests(manifests), nil
+	})
+}
+
+// GameIDs returns the app IDs in localconfig.vdf
+func (s *Session) GameIDs() ([]string, error) {
+	appsNode, err := s.appsNode()
+	if err != nil {
+		return nil, err
+	}
+	appIDs := make([]string, 0, len(appsNode.Children))
+	for _, child := range appsNode.Children {
+		appIDs = append(appIDs, child.Key)
+	}
+	return appIDs, nil
+}
+
+// SharedConfig returns the account's collections and hidden games
+func (s *Session) SharedConfig() (*SharedConfig, error) {
+	return s.sharedConfig.get(func() (*SharedConfig, error) {
+		return LoadSharedConfig(sharedConfigPathFor(s.LocalConfigPath))
+	})
+}
+
+// Games returns the account's games with their settings and install details; see GetAllGames
+func (s *Session) Games() ([]GameInfo, error) {
+	return s.games.get(func() ([]GameInfo, error) {
+		appsNode, err := s.appsNode()
+		if err != nil {
+			return nil, err
+		}
+		manifests, err := s.appManifests(len(appsNode.Children))
+		if err != nil {
+			return nil, err
+		}
+		sharedConfig, err := s.SharedConfig()
+		if err != nil {
+			return nil, err
+		}
+		return buildGames(s.SteamPath, s.LocalConfigPath, appsNode, manifests, s.install.manifestCount, sharedConfig, s.Progress), nil
+	})
+}
+
+func (s *Session) appsNode() (*vdf.Node, error) {
+	return s.apps.get(func() (*vdf.Node, error) {
+		return readLocalConfigApps(s.LocalConfigPath)
+	})
+}
+
+// appManifests scans the appmanifests once; progress counts extra more steps to come
+func (s *Session) appManifests(extra int) ([]appManifest, error) {
+	return s.install.manifests.get(func() ([]appManifest, error) {
+		return scanAppManifests(s.SteamPath, func(done, total int) {
+			s.install.manifestCount = total
+			s.Progress.report(done, total+extra)
+		})
+	})
+}
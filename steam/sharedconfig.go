@@ -0,0 +1,182 @@
+package steam
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/zerkz/gsca/vdf"
+)
+
+// Tags Steam stores in sharedconfig.vdf for the built-in collections
+const (
+	TagFavorite = "favorite"
+	TagHidden   = "hidden"
+)
+
+// sharedConfigSteamPath is the parent of the per-app node in sharedconfig.vdf
+const sharedConfigSteamPath = "UserRoamingConfigStore/Software/Valve/Steam"
+
+// GetSharedConfigPath returns the path to a user's sharedconfig.vdf (collections, favorites, hidden games)
+func GetSharedConfigPath(steamPath, userID string) string {
+	return filepath.Join(steamPath, "userdata", userID, "7", "remote", "sharedconfig.vdf")
+}
+
+// sharedConfigPathFor returns the sharedconfig.vdf belonging to the same user as localConfigPath
+func sharedConfigPathFor(localConfigPath string) string {
+	userDir := filepath.Dir(filepath.Dir(localConfigPath))
+	return filepath.Join(userDir, "7", "remote", "sharedconfig.vdf")
+}
+
+// SharedConfig holds the per-app collection tags from sharedconfig.vdf
+type SharedConfig struct {
+	tags   map[string][]string
+	hidden map[string]bool
+}
+
+// LoadSharedConfig reads the collection tags from sharedconfig.vdf
+// A missing file (e.g. a user who never made a collection) yields an empty config
+func LoadSharedConfig(path string) (*SharedConfig, error) {
+	config := &SharedConfig{
+		tags:   make(map[string][]string),
+		hidden: make(map[string]bool),
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sharedconfig.vdf: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	root, err := vdf.NewParser(f).Parse()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sharedconfig.vdf: %w", err)
+	}
+
+	// Older clients capitalize the apps node, which the case-insensitive lookup covers
+	appsNode := vdf.FindNode(root, sharedConfigSteamPath+"/apps")
+	if appsNode == nil {
+		return config, nil
+	}
+
+	for _, appNode := range appsNode.Children {
+		for _, child := range appNode.Children {
+			switch {
+			case vdf.KeyEqual(child.Key, "tags"):
+				for _, tag := range child.Children {
+					config.tags[appNode.Key] = append(config.tags[appNode.Key], tag.Value)
+				}
+			case vdf.KeyEqual(child.Key, "Hidden"):
+				config.hidden[appNode.Key] = child.Value == "1"
+			}
+		}
+	}
+
+	return config, nil
+}
+
+// Tags returns the collection tags of an app
+func (c *SharedConfig) Tags(appID string) []string {
+	return c.tags[appID]
+}
+
+// HasTag reports whether an app is in a collection (case-insensitive)
+func (c *SharedConfig) HasTag(appID, tag string) bool {
+	for _, t := range c.tags[appID] {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsHidden reports whether an app is hidden from the Steam library
+func (c *SharedConfig) IsHidden(appID string) bool {
+	return c.hidden[appID] || c.HasTag(appID, TagHidden)
+}
+
+// AppsWithTag returns the app IDs in a collection, in ascending order
+func (c *SharedConfig) AppsWithTag(tag string) []string {
+	var appIDs []string
+	for appID := range c.tags {
+		if c.HasTag(appID, tag) {
+			appIDs = append(appIDs, appID)
+		}
+	}
+	SortAppIDs(appIDs)
+	return appIDs
+}
+
+// TagApps adds apps to the collection tag in sharedconfig.vdf, creating the file if the user
+// has none. The file is backed up first unless opts.SkipBackup is set; opts.Surgical is ignored
+func TagApps(path, tag string, appIDs []string, opts UpdateOptions) (*UpdateResult, error) {
+	root, err := readVDFFile(path)
+	exists := err == nil
+	if os.IsNotExist(err) {
+		root = &vdf.Node{IsObject: true}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read sharedconfig.vdf: %w", err)
+	}
+
+	// Keys match case-insensitively, so an existing "Apps" node of older clients is reused
+	appsPath := sharedConfigSteamPath + "/apps"
+
+	result := &UpdateResult{}
+	changed := false
+	for _, appID := range appIDs {
+		app := AppUpdateResult{AppID: appID, NewValue: tag, Status: UpdateUnchanged}
+		tagsPath := vdf.Path(appsPath, appID, "tags")
+		tags := vdf.FindNodePath(root, tagsPath)
+		if !hasTagNode(tags, tag) {
+			// Tags are a list keyed "0", "1", ...; append after the highest index
+			next := 0
+			if tags != nil {
+				for _, child := range tags.Children {
+					if n, err := strconv.Atoi(child.Key); err == nil && n >= next {
+						next = n + 1
+					}
+				}
+			}
+			if err := vdf.SetValuePath(root, append(tagsPath, strconv.Itoa(next)), tag); err != nil {
+				return nil, err
+			}
+			app.Status = UpdateChanged
+			changed = true
+		}
+		result.Apps = append(result.Apps, app)
+	}
+	if !changed {
+		return result, nil
+	}
+
+	if exists && !opts.SkipBackup {
+		if result.BackupPath, err = createBackup(path); err != nil {
+			return nil, fmt.Errorf("failed to create backup: %w", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, classifyWriteError(path, err)
+	}
+	if err := trackWrite(path, func() error { return writeVDFFile(path, root) }); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// hasTagNode reports whether a tags node (may be nil) contains tag (case-insensitive)
+func hasTagNode(tags *vdf.Node, tag string) bool {
+	if tags == nil {
+		return false
+	}
+	for _, child := range tags.Children {
+		if strings.EqualFold(child.Value, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -5,7 +5,9 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/zerkz/gsca/vdf"
 )
@@ -17,6 +19,16 @@ const (
 	osDarwin    = "darwin"
 	keyAppID    = "appid"
 	keyName     = "name"
+
+	keyLastPlayed = "LastPlayed"
+
+	// KeyLaunchOptions is the localconfig key holding an app's launch options
+	KeyLaunchOptions = "LaunchOptions"
+	// KeyOverlayAppEnable is the localconfig key for the per-app Steam Overlay setting ("0" disables it)
+	KeyOverlayAppEnable = "OverlayAppEnable"
+
+	// appsPath is the location of per-app settings inside localconfig.vdf
+	appsPath = "UserLocalConfigStore/Software/Valve/Steam/apps"
 )
 
 // GetSteamPath returns the Steam installation path for the current platform
@@ -29,7 +41,7 @@ func GetSteamPath() (string, error) {
 		if err != nil {
 			return "", err
 		}
-		steamPath = filepath.Join(homeDir, ".local", "share", "Steam")
+		steamPath = linuxSteamPath(homeDir)
 
 	case osWindows:
 		steamPath = `C:\Program Files (x86)\Steam`
@@ -46,14 +58,35 @@ func GetSteamPath() (string, error) {
 		return "", fmt.Errorf("unsupported platform: %s", runtime.GOOS)
 	}
 
-	// Verify the path exists
-	if _, err := os.Stat(steamPath); os.IsNotExist(err) {
-		return "", fmt.Errorf("steam installation not found at %s", steamPath)
+	// Verify the path exists; a sandbox may hide it even when it does
+	if _, err := os.Stat(steamPath); err != nil {
+		if sandbox := DetectSandbox(); sandbox != "" {
+			return "", sandboxError(sandbox, steamPath)
+		}
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("steam installation not found at %s", steamPath)
+		}
 	}
 
 	return steamPath, nil
 }
 
+// linuxSteamPath returns the first Steam install found through the ~/.steam/root and
+// ~/.steam/steam symlinks (which Steam points at the active install, wherever the distro
+// put it) or at ~/.local/share/Steam, with symlinks resolved. If none exists it returns
+// ~/.local/share/Steam
+func linuxSteamPath(homeDir string) string {
+	for _, candidate := range installCandidates(osLinux, homeDir, nil) {
+		if candidate.flavor != FlavorNative {
+			continue
+		}
+		if resolved, err := filepath.EvalSymlinks(candidate.path); err == nil && isDir(resolved) {
+			return resolved
+		}
+	}
+	return filepath.Join(homeDir, ".local", "share", "Steam")
+}
+
 // GetUserID returns the most recently used Steam user ID
 func GetUserID(steamPath string) (string, error) {
 	userdataPath := filepath.Join(steamPath, "userdata")
@@ -101,277 +134,431 @@ func GetLocalConfigPath(steamPath, userID string) string {
 	return filepath.Join(steamPath, "userdata", userID, "config", "localconfig.vdf")
 }
 
+// App types reported in GameInfo.AppType
+const (
+	AppTypeGame = "game"
+	AppTypeTool = "tool"
+)
+
 // GameInfo represents information about a Steam game
 type GameInfo struct {
 	AppID         string
 	Name          string
 	LaunchOptions string
 	Installed     bool
-}
-
-// GetGameMapping returns a map of game names (lowercase) to app IDs
-func GetGameMapping(steamPath string) (map[string]string, error) {
-	mapping := make(map[string]string)
-
-	// Get all library folders
-	libraryFolders, err := GetLibraryFolders(steamPath)
-	if err != nil {
-		return nil, err
-	}
-
-	// Scan each library folder
-	for _, libraryPath := range libraryFolders {
-		steamappsPath := filepath.Join(libraryPath, "steamapps")
-
-		// Read all appmanifest files in this library
-		files, err := filepath.Glob(filepath.Join(steamappsPath, "appmanifest_*.acf"))
-		if err != nil {
-			continue // Skip this library if glob fails
-		}
-
-		for _, file := range files {
-			f, err := os.Open(file)
-			if err != nil {
-				continue
-			}
-
-			parser := vdf.NewParser(f)
-			root, err := parser.Parse()
-			_ = f.Close()
-
-			if err != nil {
-				continue
-			}
-
-			// Find AppState node
-			var appState *vdf.Node
-			for _, child := range root.Children {
-				if child.Key == appStateKey {
-					appState = child
-					break
-				}
-			}
-
-			if appState == nil {
-				continue
-			}
-
-			var appID, name string
-			for _, child := range appState.Children {
-				switch child.Key {
-				case keyAppID:
-					appID = child.Value
-				case keyName:
-					name = child.Value
-				}
-			}
 
-			if appID != "" && name != "" {
-				// Store with lowercase name for case-insensitive matching
-				mapping[strings.ToLower(name)] = appID
-				// Also store with the app ID as key for direct ID lookup
-				mapping[appID] = appID
-			}
-		}
-	}
+	// Populated from the appmanifest (installed games only)
+	// InstallState is one of the InstallState constants; games in offline
+	// libraries are InstallStateUnavailable but not Installed
+	InstallState  string
+	InstallDir    string
+	LibraryFolder string
+	SizeOnDisk    int64
+	BuildID       string
+
+	// Populated from localconfig.vdf, falling back to the appmanifest
+	LastPlayed      time.Time
+	PlaytimeMinutes int
+
+	// AppType is AppTypeTool for Proton, runtimes, etc. and AppTypeGame otherwise
+	AppType string
+
+	// CompatTool is the compatibility tool forced for this app in config.vdf (empty if none)
+	CompatTool string
+	// Hidden is set for games hidden from the Steam library (sharedconfig.vdf)
+	Hidden bool
+	// SharedBy is the account ID of the owner of a game borrowed through Family Sharing
+	// (installed games only; empty for the user's own games)
+	SharedBy string
+}
 
-	return mapping, nil
+// appManifest holds the fields read from an appmanifest_*.acf file
+type appManifest struct {
+	path          string
+	appID         string
+	name          string
+	installDir    string
+	libraryFolder string
+	sizeOnDisk    int64
+	buildID       string
+	lastPlayed    time.Time
+	stateFlags    int64
+	// lastOwner is the SteamID64 of the account whose license the install uses
+	lastOwner string
 }
 
 // GetAllGameIDs returns all app IDs from the localconfig.vdf
 func GetAllGameIDs(localConfigPath string) ([]string, error) {
+	return NewSession("", localConfigPath).GameIDs()
+}
+
+// readLocalConfigApps parses the apps node of a localconfig.vdf
+func readLocalConfigApps(localConfigPath string) (*vdf.Node, error) {
 	f, err := os.Open(localConfigPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open localconfig.vdf: %w", err)
 	}
 	defer func() { _ = f.Close() }()
 
-	parser := vdf.NewParser(f)
+	// Only the apps subtree is needed, so skip friends, news, etc.
+	parser := vdf.NewPathParser(f, appsPath)
 	root, err := parser.Parse()
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse localconfig.vdf: %w", err)
 	}
 
 	// Navigate to Software/Valve/Steam/apps
-	appsNode := vdf.FindNode(root, "UserLocalConfigStore/Software/Valve/Steam/apps")
+	appsNode := vdf.FindNode(root, appsPath)
 	if appsNode == nil {
 		return nil, fmt.Errorf("apps node not found in localconfig.vdf")
 	}
+	return appsNode, nil
+}
 
-	var appIDs []string
-	for _, child := range appsNode.Children {
-		appIDs = append(appIDs, child.Key)
+// GetLibraryFolders returns all Steam library folder paths
+func GetLibraryFolders(steamPath string) ([]string, error) {
+	folders := readLibraryFolders(steamPath)
+	if len(folders) == 0 {
+		return []string{steamPath}, nil
+	}
+
+	// Libraries reached through symlinks are listed once, by their real path.
+	// Offline libraries aren't resolved, since that could hang on a dead mount
+	var paths []string
+	seen := make(map[string]bool)
+	for _, folder := range folders {
+		path := folder.path
+		if libraryOnline(path) {
+			if resolved, err := filepath.EvalSymlinks(path); err == nil {
+				path = resolved
+			}
+		}
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
 	}
 
-	return appIDs, nil
+	return paths, nil
 }
 
-// GetLibraryFolders returns all Steam library folder paths
-func GetLibraryFolders(steamPath string) ([]string, error) {
-	libraryFoldersPath := filepath.Join(steamPath, "steamapps", "libraryfolders.vdf")
+// libraryFolder is an entry of libraryfolders.vdf
+type libraryFolder struct {
+	path   string
+	appIDs []string
+}
 
-	f, err := os.Open(libraryFoldersPath)
+// readLibraryFolders parses libraryfolders.vdf, returning nil if it's missing or invalid
+func readLibraryFolders(steamPath string) []libraryFolder {
+	f, err := os.Open(filepath.Join(steamPath, "steamapps", "libraryfolders.vdf"))
 	if err != nil {
-		// If libraryfolders.vdf doesn't exist, just return default path
-		return []string{steamPath}, nil
+		return nil
 	}
 	defer func() { _ = f.Close() }()
 
 	parser := vdf.NewParser(f)
 	root, err := parser.Parse()
 	if err != nil {
-		return []string{steamPath}, nil
-	}
-
-	// Navigate to libraryfolders node
-	var libraryNode *vdf.Node
-	for _, child := range root.Children {
-		if child.Key == "libraryfolders" {
-			libraryNode = child
-			break
-		}
+		return nil
 	}
 
+	libraryNode := vdf.FindNode(root, "libraryfolders")
 	if libraryNode == nil {
-		return []string{steamPath}, nil
+		return nil
 	}
 
-	var paths []string
+	var folders []libraryFolder
 	for _, child := range libraryNode.Children {
 		// Each child is a library entry
+		var folder libraryFolder
 		for _, field := range child.Children {
-			if field.Key == "path" {
-				paths = append(paths, field.Value)
-				break
+			switch field.Key {
+			case "path":
+				folder.path = field.Value
+			case "apps":
+				for _, app := range field.Children {
+					folder.appIDs = append(folder.appIDs, app.Key)
+				}
 			}
 		}
+		if folder.path != "" {
+			folders = append(folders, folder)
+		}
 	}
-
-	if len(paths) == 0 {
-		return []string{steamPath}, nil
-	}
-
-	return paths, nil
+	return folders
 }
 
-// getInstalledGameNames returns a map of app IDs to game names (with original casing)
-func getInstalledGameNames(steamPath string) (map[string]string, error) {
-	appIDToName := make(map[string]string)
-
+// scanAppManifests parses the appmanifest files in every library folder
+func scanAppManifests(steamPath string, progress ProgressFunc) ([]appManifest, error) {
 	// Get all library folders
 	libraryFolders, err := GetLibraryFolders(steamPath)
 	if err != nil {
 		return nil, err
 	}
 
-	// Scan each library folder
+	// Collect manifest files up front so progress has a known total
+	type manifestFile struct {
+		path    string
+		library string
+	}
+	var files []manifestFile
+
 	for _, libraryPath := range libraryFolders {
+		if !libraryOnline(libraryPath) {
+			continue // Reported by OfflineLibraries
+		}
 		steamappsPath := filepath.Join(libraryPath, "steamapps")
 
 		// Read all appmanifest files in this library
-		files, err := filepath.Glob(filepath.Join(steamappsPath, "appmanifest_*.acf"))
+		matches, err := filepath.Glob(filepath.Join(steamappsPath, "appmanifest_*.acf"))
 		if err != nil {
 			continue // Skip this library if glob fails
 		}
 
-		for _, file := range files {
-			f, err := os.Open(file)
-			if err != nil {
-				continue
+		for _, match := range matches {
+			files = append(files, manifestFile{path: match, library: libraryPath})
+		}
+	}
+
+	var manifests []appManifest
+	for i, file := range files {
+		m, ok := readAppManifest(file.path)
+		if ok {
+			m.path = file.path
+			m.libraryFolder = file.library
+			if m.installDir != "" {
+				m.installDir = filepath.Join(file.library, "steamapps", "common", m.installDir)
 			}
+			manifests = append(manifests, m)
+		}
+		progress.report(i+1, len(files))
+	}
 
-			parser := vdf.NewParser(f)
-			root, err := parser.Parse()
-			_ = f.Close()
+	return manifests, nil
+}
 
-			if err != nil {
-				continue
-			}
+// readAppManifest parses a single appmanifest file
+// Returns false if the file can't be read or lacks an app ID or name
+func readAppManifest(file string) (appManifest, bool) {
+	f, err := os.Open(file)
+	if err != nil {
+		return appManifest{}, false
+	}
 
-			// Find AppState node
-			var appState *vdf.Node
-			for _, child := range root.Children {
-				if child.Key == appStateKey {
-					appState = child
-					break
-				}
-			}
+	parser := vdf.NewParser(f)
+	root, err := parser.Parse()
+	_ = f.Close()
 
-			if appState == nil {
-				continue
-			}
+	if err != nil {
+		return appManifest{}, false
+	}
 
-			var appID, name string
-			for _, child := range appState.Children {
-				switch child.Key {
-				case keyAppID:
-					appID = child.Value
-				case keyName:
-					name = child.Value
-				}
-			}
+	// Find AppState node
+	var appState *vdf.Node
+	for _, child := range root.Children {
+		if vdf.KeyEqual(child.Key, appStateKey) {
+			appState = child
+			break
+		}
+	}
 
-			if appID != "" && name != "" {
-				appIDToName[appID] = name
-			}
+	if appState == nil {
+		return appManifest{}, false
+	}
+
+	var m appManifest
+	for _, child := range appState.Children {
+		switch child.Key {
+		case keyAppID:
+			m.appID = child.Value
+		case keyName:
+			m.name = child.Value
+		case "installdir":
+			m.installDir = child.Value
+		case "SizeOnDisk":
+			m.sizeOnDisk, _ = strconv.ParseInt(child.Value, 10, 64)
+		case "buildid":
+			m.buildID = child.Value
+		case keyLastPlayed:
+			m.lastPlayed = parseUnixTime(child.Value)
+		case "StateFlags":
+			m.stateFlags, _ = strconv.ParseInt(child.Value, 10, 64)
+		case "LastOwner":
+			m.lastOwner = child.Value
 		}
 	}
 
-	return appIDToName, nil
+	if m.appID == "" || m.name == "" {
+		return appManifest{}, false
+	}
+
+	return m, true
 }
 
-// GetAllGames returns all games from localconfig with their names and launch options
-func GetAllGames(steamPath, localConfigPath string) ([]GameInfo, error) {
-	// Get installed game names with original casing
-	installedNames, err := getInstalledGameNames(steamPath)
-	if err != nil {
-		return nil, err
-	}
+// getCompatToolMapping returns a map of app IDs to the compatibility tool set in config.vdf
+// Missing or unreadable config files result in an empty map
+func getCompatToolMapping(steamPath string) map[string]string {
+	mapping := make(map[string]string)
 
-	// Get all games from localconfig
-	f, err := os.Open(localConfigPath)
+	f, err := os.Open(filepath.Join(steamPath, "config", "config.vdf"))
 	if err != nil {
-		return nil, fmt.Errorf("failed to open localconfig.vdf: %w", err)
+		return mapping
 	}
 	defer func() { _ = f.Close() }()
 
 	parser := vdf.NewParser(f)
 	root, err := parser.Parse()
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse localconfig.vdf: %w", err)
+		return mapping
 	}
 
-	// Navigate to Software/Valve/Steam/apps
-	appsNode := vdf.FindNode(root, "UserLocalConfigStore/Software/Valve/Steam/apps")
-	if appsNode == nil {
-		return nil, fmt.Errorf("apps node not found in localconfig.vdf")
+	toolsNode := vdf.FindNode(root, "InstallConfigStore/Software/Valve/Steam/CompatToolMapping")
+	if toolsNode == nil {
+		return mapping
+	}
+
+	for _, appNode := range toolsNode.Children {
+		// "0" holds the global default, not a per-app setting
+		if appNode.Key == "0" {
+			continue
+		}
+		if nameNode := vdf.FindNode(appNode, keyName); nameNode != nil && nameNode.Value != "" {
+			mapping[appNode.Key] = nameNode.Value
+		}
+	}
+
+	return mapping
+}
+
+// FamilySharedBy returns the account ID of the owner of an install borrowed through Family
+// Sharing, given its appmanifest's LastOwner (a SteamID64), or "" if userID owns it or the
+// owner isn't recorded
+func FamilySharedBy(lastOwner, userID string) string {
+	steamID, err := strconv.ParseUint(lastOwner, 10, 64)
+	if err != nil || steamID <= steamID64Base {
+		return ""
+	}
+	if owner := strconv.FormatUint(steamID-steamID64Base, 10); owner != userID {
+		return owner
+	}
+	return ""
+}
+
+// IsToolName reports whether a name looks like a Steam tool (Proton, Runtime, etc.)
+func IsToolName(name string) bool {
+	return strings.Contains(name, "Proton") || strings.Contains(name, "Runtime")
+}
+
+// parseUnixTime converts a VDF timestamp string to a time.Time (zero if unset or invalid)
+func parseUnixTime(value string) time.Time {
+	secs, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || secs <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(secs, 0)
+}
+
+// GetAllGames returns all games from localconfig with their names and launch options
+// progress (may be nil) covers both the appmanifest scan and the localconfig apps
+func GetAllGames(steamPath, localConfigPath string, progress ProgressFunc) ([]GameInfo, error) {
+	session := NewSession(steamPath, localConfigPath)
+	session.Progress = progress
+	return session.Games()
+}
+
+// buildGames combines the apps of a localconfig.vdf with the install's appmanifests, config.vdf,
+// and the account's sharedconfig.vdf. progress continues from the appmanifest scan, which
+// counted manifestCount manifests
+func buildGames(steamPath, localConfigPath string, appsNode *vdf.Node, manifests []appManifest, manifestCount int, sharedConfig *SharedConfig, progress ProgressFunc) []GameInfo {
+	installedGames := make(map[string]appManifest, len(manifests))
+	for _, m := range manifests {
+		installedGames[m.appID] = m
+	}
+
+	compatTools := getCompatToolMapping(steamPath)
+
+	// Games in offline libraries have no readable appmanifest
+	offline := make(map[string]bool)
+	for _, library := range OfflineLibraries(steamPath) {
+		for _, appID := range library.AppIDs {
+			offline[appID] = true
+		}
+	}
+
+	// localconfig.vdf is in userdata/<account ID>/config
+	userID := filepath.Base(filepath.Dir(filepath.Dir(localConfigPath)))
+
+	// Games installed but never launched aren't in localconfig.vdf yet; they are listed after
+	// the others, as apps without settings
+	appNodes := append([]*vdf.Node{}, appsNode.Children...)
+	inConfig := make(map[string]bool, len(appNodes))
+	for _, appNode := range appNodes {
+		inConfig[appNode.Key] = true
+	}
+	var notLaunched []string
+	for appID := range installedGames {
+		if !inConfig[appID] {
+			notLaunched = append(notLaunched, appID)
+		}
+	}
+	SortAppIDs(notLaunched)
+	for _, appID := range notLaunched {
+		appNodes = append(appNodes, &vdf.Node{Key: appID})
 	}
 
 	var games []GameInfo
-	for _, appNode := range appsNode.Children {
+	for i, appNode := range appNodes {
 		appID := appNode.Key
 
 		// Get launch options if they exist
 		var launchOptions string
-		launchNode := vdf.FindNode(appNode, "LaunchOptions")
+		launchNode := vdf.FindNode(appNode, KeyLaunchOptions)
 		if launchNode != nil {
 			launchOptions = launchNode.Value
 		}
 
-		// Check if game is installed and get name
-		name, installed := installedNames[appID]
-		if !installed {
-			// Not installed, use app ID as name
-			name = appID
-		}
-
-		games = append(games, GameInfo{
+		game := GameInfo{
 			AppID:         appID,
-			Name:          name,
-			Installed:     installed,
+			Name:          appID, // Not installed, use app ID as name
 			LaunchOptions: launchOptions,
-		})
+			CompatTool:    compatTools[appID],
+			Hidden:        sharedConfig.IsHidden(appID),
+		}
+
+		// Fill in appmanifest data if the game is installed
+		if offline[appID] {
+			game.InstallState = InstallStateUnavailable
+		}
+		if m, installed := installedGames[appID]; installed {
+			game.Name = m.name
+			game.Installed = true
+			game.InstallState = installStateFromFlags(m.stateFlags)
+			game.InstallDir = m.installDir
+			game.LibraryFolder = m.libraryFolder
+			game.SizeOnDisk = m.sizeOnDisk
+			game.BuildID = m.buildID
+			game.LastPlayed = m.lastPlayed
+			game.SharedBy = FamilySharedBy(m.lastOwner, userID)
+		}
+
+		// localconfig tracks play history for installed and uninstalled games
+		if node := vdf.FindNode(appNode, keyLastPlayed); node != nil {
+			if t := parseUnixTime(node.Value); !t.IsZero() {
+				game.LastPlayed = t
+			}
+		}
+		if node := vdf.FindNode(appNode, "Playtime"); node != nil {
+			game.PlaytimeMinutes, _ = strconv.Atoi(node.Value)
+		}
+
+		game.AppType = AppTypeGame
+		if IsToolName(game.Name) {
+			game.AppType = AppTypeTool
+		}
+
+		games = append(games, game)
+		progress.report(manifestCount+i+1, manifestCount+len(appNodes))
 	}
 
-	return games, nil
+	return games
 }
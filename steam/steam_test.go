@@ -1,9 +1,20 @@
 package steam
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
+
+	"github.com/zerkz/gsca/steam/steamtest"
+	"github.com/zerkz/gsca/vdf"
 )
 
 func TestFilterGameIDs(t *testing.T) {
@@ -145,52 +156,39 @@ Dota 2
 }
 
 func TestResolveGameIDs(t *testing.T) {
-	mapping := map[string]string{
-		"counter-strike 2": "730",
-		"dota 2":           "570",
-		"730":              "730", // Direct ID mapping
-		"570":              "570",
-	}
-
 	tests := []struct {
 		name       string
 		list       []string
-		mapping    map[string]string
 		wantIDs    []string
 		wantMissed []string
 	}{
 		{
 			name:       "numeric IDs only",
 			list:       []string{"730", "570"},
-			mapping:    mapping,
 			wantIDs:    []string{"730", "570"},
 			wantMissed: []string{},
 		},
 		{
 			name:       "game names rejected",
 			list:       []string{"Counter-Strike 2", "Dota 2"},
-			mapping:    mapping,
 			wantIDs:    []string{},
 			wantMissed: []string{"Counter-Strike 2", "Dota 2"},
 		},
 		{
 			name:       "mixed IDs and names",
 			list:       []string{"730", "Counter-Strike 2"},
-			mapping:    mapping,
 			wantIDs:    []string{"730"},
 			wantMissed: []string{"Counter-Strike 2"},
 		},
 		{
 			name:       "invalid numeric ID",
 			list:       []string{"730", "999999"},
-			mapping:    mapping,
 			wantIDs:    []string{"730", "999999"},
 			wantMissed: []string{},
 		},
 		{
 			name:       "non-alphanumeric rejected",
 			list:       []string{"730", "test-game"},
-			mapping:    mapping,
 			wantIDs:    []string{"730"},
 			wantMissed: []string{"test-game"},
 		},
@@ -198,7 +196,7 @@ func TestResolveGameIDs(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotIDs, gotMissed := ResolveGameIDs(tt.list, tt.mapping)
+			gotIDs, gotMissed := ResolveGameIDs(tt.list, nil)
 
 			if len(gotIDs) != len(tt.wantIDs) {
 				t.Errorf("ResolveGameIDs() IDs length = %v, want %v", len(gotIDs), len(tt.wantIDs))
@@ -223,6 +221,114 @@ func TestResolveGameIDs(t *testing.T) {
 	}
 }
 
+func TestLoadLibrary(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{
+		Games: []steamtest.Game{
+			{AppID: "1245620", Name: "ELDEN RING"},
+			{AppID: "570", Name: "Dota 2"},
+			{AppID: "2050650", Name: "Pokémon Café ReMix"},
+		},
+		Libraries: [][]steamtest.Game{{
+			// Regional release sharing a name with the main game
+			{AppID: "1245621", Name: "Elden Ring"},
+		}},
+	})
+
+	lib, err := LoadLibrary(install.Path, nil)
+	if err != nil {
+		t.Fatalf("LoadLibrary() error = %v", err)
+	}
+
+	var all []string
+	for _, entry := range lib.All() {
+		all = append(all, entry.AppID)
+	}
+	if want := "[570 1245620 1245621 2050650]"; fmt.Sprint(all) != want {
+		t.Errorf("All() = %v, want %v", all, want)
+	}
+
+	if entry, ok := lib.LookupByID("1245621"); !ok || entry.Name != "Elden Ring" {
+		t.Errorf("LookupByID() = %+v, %v, want Elden Ring", entry, ok)
+	}
+	if _, ok := lib.LookupByID("999"); ok {
+		t.Error("LookupByID() of unknown app = true, want false")
+	}
+
+	tests := []struct {
+		name          string
+		entry         string
+		wantID        string
+		wantAmbiguous []string
+		wantNotFound  bool
+	}{
+		{name: "unique name", entry: "DOTA 2", wantID: "570"},
+		{name: "name without accents", entry: "pokemon cafe remix", wantID: "2050650"},
+		{name: "duplicate name", entry: "elden ring", wantAmbiguous: []string{"1245620", "1245621"}},
+		{name: "unknown name", entry: "Half-Life 3", wantNotFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lib.LookupByName(tt.entry)
+
+			var ambiguous *AmbiguousNameError
+			switch {
+			case tt.wantAmbiguous != nil:
+				if !errors.As(err, &ambiguous) {
+					t.Fatalf("LookupByName() error = %v, want *AmbiguousNameError", err)
+				}
+				if fmt.Sprint(ambiguous.AppIDs) != fmt.Sprint(tt.wantAmbiguous) {
+					t.Errorf("LookupByName() AppIDs = %v, want %v", ambiguous.AppIDs, tt.wantAmbiguous)
+				}
+			case tt.wantNotFound:
+				if !errors.Is(err, ErrGameNotFound) {
+					t.Errorf("LookupByName() error = %v, want ErrGameNotFound", err)
+				}
+			default:
+				if err != nil || got.AppID != tt.wantID {
+					t.Errorf("LookupByName() = %+v, %v, want %q", got, err, tt.wantID)
+				}
+			}
+		})
+	}
+}
+
+func TestFoldName(t *testing.T) {
+	tests := []struct {
+		name string
+		term string
+		want bool
+	}{
+		{"Pokémon Café ReMix", "pokemon cafe", true},
+		{"Senua’s Saga: Hellblade II", "senua's saga", true},
+		{"ＮＩＥＲ：Ａｕｔｏｍａｔａ", "nier:automata", true},
+		{"Ōkami HD", "okami", true},
+		{"Pokémon Café ReMix", "Pokémon", true},
+		{"Baldur's Gate 3", "baldurs", false},
+		{"Ёлки", "елки", true},
+		{"Άλφα", "αλφα", true},
+		{"ＰＯＫＥＭＯＮ", "pokemon", true},
+		{"ｶﾀｶﾅ", "カタカナ", true},
+		{"Hellblade Ⅱ", "hellblade ii", true},
+		{"Portal™ 2", "portal 2", true},
+		{"DOOM® Eternal", "doom eternal", true},
+		{"Ø\ufb01re", "ofire", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NameContains(tt.name, tt.term); got != tt.want {
+				t.Errorf("NameContains(%q, %q) = %v, want %v (folded %q)", tt.name, tt.term, got, tt.want, FoldName(tt.name))
+			}
+		})
+	}
+
+	// Decomposed input folds like the precomposed form
+	if got := FoldName("Poke\u0301mon"); got != "pokemon" {
+		t.Errorf("FoldName() of decomposed text = %q, want %q", got, "pokemon")
+	}
+}
+
 func TestGetLibraryFolders(t *testing.T) {
 	// Create a temporary directory structure
 	tmpDir := t.TempDir()
@@ -286,3 +392,1911 @@ func TestGetLibraryFolders(t *testing.T) {
 		})
 	}
 }
+
+func TestOfflineLibraries(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{
+		Users: []steamtest.User{{ID: "1", Apps: []steamtest.App{{AppID: "570"}, {AppID: "620"}}}},
+		Games: []steamtest.Game{{AppID: "620", Name: "Portal 2", InstallDir: "Portal 2"}},
+		Libraries: [][]steamtest.Game{
+			{{AppID: "570", Name: "Dota 2", InstallDir: "dota 2 beta"}},
+		},
+	})
+
+	// Unmount the extra library
+	offlinePath := install.LibraryPath(0)
+	if err := os.RemoveAll(offlinePath); err != nil {
+		t.Fatal(err)
+	}
+
+	offline := OfflineLibraries(install.Path)
+	if len(offline) != 1 || offline[0].Path != offlinePath || !reflect.DeepEqual(offline[0].AppIDs, []string{"570"}) {
+		t.Fatalf("OfflineLibraries() = %+v, want %s with app 570", offline, offlinePath)
+	}
+
+	games, err := GetAllGames(install.Path, install.LocalConfigPath("1"), nil)
+	if err != nil {
+		t.Fatalf("GetAllGames() error = %v", err)
+	}
+	states := make(map[string]string)
+	for _, game := range games {
+		states[game.AppID] = game.InstallState
+		if game.AppID == "570" && game.Installed {
+			t.Error("GetAllGames() marked a game in an offline library as installed")
+		}
+	}
+	if states["570"] != InstallStateUnavailable || states["620"] != InstallStateInstalled {
+		t.Errorf("GetAllGames() install states = %v, want 570 unavailable and 620 installed", states)
+	}
+}
+
+func TestLinuxSteamPath(t *testing.T) {
+	// The temp dir may itself be behind a symlink (e.g. /var on macOS)
+	home, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fallback := filepath.Join(home, ".local", "share", "Steam")
+	if got := linuxSteamPath(home); got != fallback {
+		t.Errorf("linuxSteamPath() without an install = %q, want %q", got, fallback)
+	}
+
+	// Debian-style install, reached only through ~/.steam/steam
+	real := filepath.Join(home, ".steam", "debian-installation")
+	if err := os.MkdirAll(real, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(real, filepath.Join(home, ".steam", "steam")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if got := linuxSteamPath(home); got != real {
+		t.Errorf("linuxSteamPath() = %q, want %q", got, real)
+	}
+
+	// A library listed through a symlink and by its real path is only returned once
+	steamapps := filepath.Join(real, "steamapps")
+	if err := os.MkdirAll(steamapps, 0755); err != nil {
+		t.Fatal(err)
+	}
+	libraries := fmt.Sprintf("\"libraryfolders\"\n{\n\t\"0\"\n\t{\n\t\t\"path\"\t\t%q\n\t}\n\t\"1\"\n\t{\n\t\t\"path\"\t\t%q\n\t}\n}\n",
+		filepath.Join(home, ".steam", "steam"), real)
+	if err := os.WriteFile(filepath.Join(steamapps, "libraryfolders.vdf"), []byte(libraries), 0644); err != nil {
+		t.Fatal(err)
+	}
+	paths, err := GetLibraryFolders(real)
+	if err != nil || !reflect.DeepEqual(paths, []string{real}) {
+		t.Errorf("GetLibraryFolders() = %v, %v, want [%s]", paths, err, real)
+	}
+}
+
+func TestFindInstalls(t *testing.T) {
+	home, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	native := filepath.Join(home, ".local", "share", "Steam")
+	flatpak := filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam")
+	for _, dir := range []string{
+		filepath.Join(native, "config"),
+		filepath.Join(flatpak, "userdata", "111"),
+		// A game library, not an install
+		filepath.Join(home, "snap", "steam", "common", ".local", "share", "Steam", "steamapps"),
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(home, ".steam"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// The same install through a symlink is only listed once
+	if err := os.Symlink(native, filepath.Join(home, ".steam", "steam")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	installs := findInstalls(installCandidates(osLinux, home, nil))
+	if len(installs) != 2 {
+		t.Fatalf("findInstalls() = %+v, want 2 installs", installs)
+	}
+	// The install with user data was used most recently
+	if installs[0].Flavor != FlavorFlatpak || installs[0].Path != flatpak || installs[0].Users != 1 {
+		t.Errorf("installs[0] = %+v, want the flatpak install with 1 user", installs[0])
+	}
+	if installs[1].Flavor != FlavorNative || installs[1].Path != native || installs[1].Users != 0 {
+		t.Errorf("installs[1] = %+v, want the native install without users", installs[1])
+	}
+
+	// Steam China installs next to the global client on Windows
+	drive := filepath.Join(home, "D")
+	china := filepath.Join(drive, "Program Files (x86)", "SteamChina")
+	if err := os.MkdirAll(filepath.Join(china, "config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	scoop := filepath.Join(home, "scoop", "apps", "steam", "current")
+	if err := os.MkdirAll(filepath.Join(scoop, "config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	installs = findInstalls(installCandidates(osWindows, home, []string{drive}))
+	if len(installs) != 2 || installs[0].Path != scoop || installs[1].Flavor != FlavorChina || installs[1].Path != china {
+		t.Errorf("findInstalls() on Windows = %+v, want the Scoop and Steam China installs", installs)
+	}
+}
+
+func TestSteamPathFromProc(t *testing.T) {
+	root := t.TempDir()
+	install := filepath.Join(root, "games", "Steam")
+	proc := filepath.Join(root, "proc")
+	for _, dir := range []string{
+		filepath.Join(install, "steamapps"),
+		filepath.Join(install, "userdata"),
+		filepath.Join(install, "ubuntu12_32"),
+		filepath.Join(proc, "100"),
+		filepath.Join(proc, "200"),
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 100 is another program, 200 is the Steam client
+	for pid, comm := range map[string]string{"100": "bash\n", "200": "steam\n"} {
+		if err := os.WriteFile(filepath.Join(proc, pid, "comm"), []byte(comm), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.Join(install, "ubuntu12_32", "steam"), filepath.Join(proc, pid, "exe")); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	paths := procSteamPaths(proc)
+	if len(paths) != 1 {
+		t.Fatalf("procSteamPaths() = %q, want the steam process executable", paths)
+	}
+	if got := steamRootOf(paths[0]); got != install {
+		t.Errorf("steamRootOf(%q) = %q, want %q", paths[0], got, install)
+	}
+	if got := steamRootOf(filepath.Join(root, "elsewhere")); got != "" {
+		t.Errorf("steamRootOf() outside an install = %q, want \"\"", got)
+	}
+}
+
+func TestFindPrefixes(t *testing.T) {
+	root := t.TempDir()
+	games := filepath.Join(root, "games")
+	for _, dir := range []string{
+		filepath.Join(root, "steamapps", "compatdata", "1245620", "pfx"),
+		filepath.Join(root, "steamapps", "compatdata", "0"),
+		filepath.Join(root, "steamapps", "compatdata", "not-an-app"),
+		filepath.Join(games, "steamapps", "compatdata", "730"),
+		// Duplicate prefixes in a second library are ignored
+		filepath.Join(games, "steamapps", "compatdata", "1245620"),
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "steamapps", "compatdata", "1245620", "pfx", "system.reg"), make([]byte, 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	libraries := fmt.Sprintf("\"libraryfolders\"\n{\n\t\"0\"\n\t{\n\t\t\"path\"\t\t%q\n\t}\n\t\"1\"\n\t{\n\t\t\"path\"\t\t%q\n\t}\n}\n", root, games)
+	if err := os.WriteFile(filepath.Join(root, "steamapps", "libraryfolders.vdf"), []byte(libraries), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	prefixes, err := FindPrefixes(root)
+	if err != nil {
+		t.Fatalf("FindPrefixes() error = %v", err)
+	}
+	var got []string
+	for _, prefix := range prefixes {
+		got = append(got, prefix.AppID+"@"+prefix.Library)
+	}
+	want := []string{"0@" + root, "730@" + games, "1245620@" + root}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindPrefixes() = %v, want %v", got, want)
+	}
+
+	if size, err := DirSize(prefixes[2].Path); err != nil || size != 100 {
+		t.Errorf("DirSize() = %d, %v, want 100", size, err)
+	}
+}
+
+func TestGetAllGames(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{
+		Users: []steamtest.User{{
+			ID: "12345",
+			Apps: []steamtest.App{
+				{AppID: "570", LastPlayed: 1700000000, PlaytimeMinutes: 90, LaunchOptions: "gamemoderun %command%"},
+				{AppID: "1493710"},
+				{AppID: "730", PlaytimeMinutes: 15, Hidden: true},
+				{AppID: "440"},
+			},
+		}},
+		Games: []steamtest.Game{
+			{AppID: "1493710", Name: "Proton Experimental", InstallDir: "Proton - Experimental"},
+		},
+		Libraries: [][]steamtest.Game{{
+			{AppID: "570", Name: "Dota 2", InstallDir: "dota 2 beta", SizeOnDisk: 123456, BuildID: "42", StateFlags: 4},
+			{AppID: "440", Name: "Team Fortress 2", StateFlags: 6},
+		}},
+		CompatTools: map[string]string{"0": "proton_experimental", "570": "proton_9"},
+	})
+
+	var lastDone, lastTotal int
+	progress := func(done, total int) {
+		if done < lastDone {
+			t.Errorf("GetAllGames() progress went backwards: %d after %d", done, lastDone)
+		}
+		lastDone, lastTotal = done, total
+	}
+
+	games, err := GetAllGames(install.Path, install.LocalConfigPath("12345"), progress)
+	if err != nil {
+		t.Fatalf("GetAllGames() error = %v", err)
+	}
+
+	// 3 appmanifests + 4 localconfig apps
+	if lastDone != 7 || lastTotal != 7 {
+		t.Errorf("GetAllGames() final progress = %d/%d, want 7/7", lastDone, lastTotal)
+	}
+
+	byID := make(map[string]GameInfo)
+	for _, game := range games {
+		byID[game.AppID] = game
+	}
+
+	dota := byID["570"]
+	if !dota.Installed || dota.Name != "Dota 2" {
+		t.Errorf("GetAllGames() 570 = %+v, want installed Dota 2", dota)
+	}
+	if dota.InstallDir != filepath.Join(install.LibraryPath(0), "steamapps", "common", "dota 2 beta") {
+		t.Errorf("GetAllGames() InstallDir = %v", dota.InstallDir)
+	}
+	if dota.LibraryFolder != install.LibraryPath(0) {
+		t.Errorf("GetAllGames() LibraryFolder = %v, want %v", dota.LibraryFolder, install.LibraryPath(0))
+	}
+	if dota.InstallState != InstallStateInstalled || byID["440"].InstallState != InstallStateUpdating {
+		t.Errorf("GetAllGames() InstallState = %q, %q, want installed, updating", dota.InstallState, byID["440"].InstallState)
+	}
+	if dota.SizeOnDisk != 123456 || dota.BuildID != "42" {
+		t.Errorf("GetAllGames() SizeOnDisk = %v, BuildID = %v", dota.SizeOnDisk, dota.BuildID)
+	}
+	if dota.LastPlayed.Unix() != 1700000000 {
+		t.Errorf("GetAllGames() LastPlayed = %v, want 1700000000", dota.LastPlayed.Unix())
+	}
+	if dota.PlaytimeMinutes != 90 {
+		t.Errorf("GetAllGames() PlaytimeMinutes = %v, want 90", dota.PlaytimeMinutes)
+	}
+	if dota.CompatTool != "proton_9" {
+		t.Errorf("GetAllGames() CompatTool = %v, want proton_9", dota.CompatTool)
+	}
+	if dota.AppType != AppTypeGame {
+		t.Errorf("GetAllGames() AppType = %v, want %v", dota.AppType, AppTypeGame)
+	}
+
+	if proton := byID["1493710"]; proton.AppType != AppTypeTool || proton.CompatTool != "" {
+		t.Errorf("GetAllGames() 1493710 = %+v, want tool without compat tool", proton)
+	}
+
+	cs := byID["730"]
+	if cs.Installed || cs.Name != "730" || cs.PlaytimeMinutes != 15 || !cs.Hidden {
+		t.Errorf("GetAllGames() 730 = %+v, want uninstalled, hidden, with playtime", cs)
+	}
+	if dota.Hidden {
+		t.Error("GetAllGames() 570 Hidden = true, want false")
+	}
+}
+
+func TestInstallStateFromFlags(t *testing.T) {
+	tests := []struct {
+		flags int64
+		want  string
+	}{
+		{0, InstallStateInstalled},
+		{4, InstallStateInstalled},
+		{6, InstallStateUpdating},           // update required
+		{1026, InstallStateUpdating},        // update started
+		{1, InstallStateUpdating},           // partial download
+		{525312, InstallStatePreallocating}, // preallocating, update started
+	}
+
+	for _, tt := range tests {
+		if got := installStateFromFlags(tt.flags); got != tt.want {
+			t.Errorf("installStateFromFlags(%d) = %q, want %q", tt.flags, got, tt.want)
+		}
+	}
+
+	if states, err := ParseInstallStates("updating, Preallocating"); err != nil || !states[InstallStateUpdating] || !states[InstallStatePreallocating] || states[InstallStateInstalled] {
+		t.Errorf("ParseInstallStates() = %v, %v", states, err)
+	}
+	if _, err := ParseInstallStates("broken"); err == nil {
+		t.Error("ParseInstallStates() of an unknown state error = nil, want error")
+	}
+}
+
+func TestLoadSharedConfig(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{
+		Users: []steamtest.User{{
+			ID: "1",
+			Apps: []steamtest.App{
+				{AppID: "570", Tags: []string{"favorite", "MOBA"}},
+				{AppID: "730", Tags: []string{"Favorite"}},
+				{AppID: "440", Hidden: true},
+				{AppID: "10", Tags: []string{"hidden"}},
+				{AppID: "20"},
+			},
+		}},
+	})
+
+	config, err := LoadSharedConfig(install.SharedConfigPath("1"))
+	if err != nil {
+		t.Fatalf("LoadSharedConfig() error = %v", err)
+	}
+
+	if got := fmt.Sprint(config.AppsWithTag(TagFavorite)); got != "[570 730]" {
+		t.Errorf("AppsWithTag(favorite) = %s, want [570 730]", got)
+	}
+	if !config.HasTag("570", "moba") {
+		t.Error("HasTag(570, moba) = false, want true")
+	}
+	for appID, want := range map[string]bool{"440": true, "10": true, "570": false, "20": false} {
+		if got := config.IsHidden(appID); got != want {
+			t.Errorf("IsHidden(%s) = %v, want %v", appID, got, want)
+		}
+	}
+
+	// Users without collections have no sharedconfig.vdf
+	empty, err := LoadSharedConfig(filepath.Join(t.TempDir(), "missing.vdf"))
+	if err != nil {
+		t.Fatalf("LoadSharedConfig() on missing file error = %v", err)
+	}
+	if empty.IsHidden("440") || len(empty.AppsWithTag(TagFavorite)) != 0 {
+		t.Error("LoadSharedConfig() on missing file returned tags, want none")
+	}
+}
+
+func TestListUsers(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{
+		Users: []steamtest.User{{ID: "111"}, {ID: "222"}, {ID: "333"}},
+	})
+	now := time.Now()
+	for i, id := range []string{"111", "222", "333"} {
+		// 222 was used last, then 111
+		modTime := now.Add(-time.Duration([]int{1, 0, 2}[i]) * time.Hour)
+		if err := os.Chtimes(filepath.Join(install.Path, "userdata", id), modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	loginUsers := `"users"
+{
+	"76561197960265839"
+	{
+		"AccountName"		"alice"
+		"PersonaName"		"Alice"
+	}
+	"76561197960265950"
+	{
+		"AccountName"		"bob"
+		"PersonaName"		"Bob"
+		"MostRecent"		"1"
+	}
+	"not-a-steam-id"
+	{
+		"AccountName"		"ignored"
+	}
+}
+`
+	if err := os.MkdirAll(filepath.Join(install.Path, "config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(GetLoginUsersPath(install.Path), []byte(loginUsers), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := ListUsers(install.Path)
+	if err != nil {
+		t.Fatalf("ListUsers() error = %v", err)
+	}
+	want := []User{
+		{ID: "222", AccountName: "bob", PersonaName: "Bob", MostRecent: true},
+		{ID: "111", AccountName: "alice", PersonaName: "Alice"},
+		{ID: "333"},
+	}
+	if !reflect.DeepEqual(users, want) {
+		t.Errorf("ListUsers() = %+v, want %+v", users, want)
+	}
+	if got := users[0].Label(); got != "Bob (bob, 222)" {
+		t.Errorf("Label() = %q, want %q", got, "Bob (bob, 222)")
+	}
+	if got := users[2].Label(); got != "333" {
+		t.Errorf("Label() without a login = %q, want %q", got, "333")
+	}
+
+	if _, err := ListUsers(t.TempDir()); err == nil {
+		t.Error("ListUsers() without userdata error = nil, want error")
+	}
+}
+
+func TestTagApps(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{
+		Users: []steamtest.User{{
+			ID: "1",
+			Apps: []steamtest.App{
+				{AppID: "570", Tags: []string{"favorite", "gsca"}},
+				{AppID: "730", Tags: []string{"favorite"}},
+				{AppID: "440"},
+			},
+		}},
+	})
+	path := install.SharedConfigPath("1")
+
+	result, err := TagApps(path, "gsca", []string{"570", "730", "440"}, UpdateOptions{})
+	if err != nil {
+		t.Fatalf("TagApps() error = %v", err)
+	}
+	if result.Count(UpdateChanged) != 2 || result.Count(UpdateUnchanged) != 1 || result.BackupPath == "" {
+		t.Errorf("TagApps() = %+v, want 2 changed, 1 unchanged, and a backup", result)
+	}
+
+	config, err := LoadSharedConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(config.AppsWithTag("gsca")); got != "[440 570 730]" {
+		t.Errorf("AppsWithTag(gsca) = %s, want [440 570 730]", got)
+	}
+	if got := fmt.Sprint(config.Tags("730")); got != "[favorite gsca]" {
+		t.Errorf("Tags(730) = %s, want [favorite gsca]", got)
+	}
+
+	// Users without collections get a new sharedconfig.vdf
+	missing := filepath.Join(t.TempDir(), "7", "remote", "sharedconfig.vdf")
+	if _, err := TagApps(missing, "gsca", []string{"570"}, UpdateOptions{}); err != nil {
+		t.Fatalf("TagApps() on missing file error = %v", err)
+	}
+	if config, err := LoadSharedConfig(missing); err != nil || !config.HasTag("570", "gsca") {
+		t.Errorf("TagApps() on missing file didn't tag 570 (error %v)", err)
+	}
+}
+
+func TestLoadAppInfo(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{
+		AppInfo: []steamtest.AppInfo{
+			{AppID: "620", Common: map[string]string{
+				"name":                "Portal 2",
+				"type":                "Game",
+				"associations/0/type": "developer",
+				"associations/0/name": "Valve",
+				"associations/1/type": "publisher",
+				"associations/1/name": "Valve Corporation",
+				"genres/0":            "1",
+				"genres/1":            "9999",
+			}},
+			{AppID: "546560", Common: map[string]string{"name": "Half-Life: Alyx", "category/category_54": "1"}},
+			{AppID: "275850", Common: map[string]string{"name": "No Man's Sky", "category/category_53": "1", "category/category_2": "1"}},
+			{AppID: "450390", Common: map[string]string{"name": "The Lab", "onlyvrsupport": "1"}},
+			{AppID: "323180", Common: map[string]string{"name": "Portal 2 - Soundtrack", "type": "DLC", "parent": "620"}},
+		},
+	})
+
+	infos, err := LoadAppInfo(GetAppInfoPath(install.Path), []string{"620", "546560", "275850", "450390", "323180", "999"})
+	if err != nil {
+		t.Fatalf("LoadAppInfo() error = %v", err)
+	}
+
+	tests := []struct {
+		appID         string
+		wantName      string
+		wantVRSupport bool
+		wantVROnly    bool
+	}{
+		{appID: "620", wantName: "Portal 2"},
+		{appID: "546560", wantName: "Half-Life: Alyx", wantVRSupport: true, wantVROnly: true},
+		{appID: "275850", wantName: "No Man's Sky", wantVRSupport: true},
+		{appID: "450390", wantName: "The Lab", wantVRSupport: true, wantVROnly: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.appID, func(t *testing.T) {
+			info, ok := infos[tt.appID]
+			if !ok {
+				t.Fatalf("LoadAppInfo() missing %s", tt.appID)
+			}
+			if info.Name != tt.wantName || info.VRSupport != tt.wantVRSupport || info.VROnly != tt.wantVROnly {
+				t.Errorf("LoadAppInfo() %s = %+v, want name %q, VRSupport %v, VROnly %v",
+					tt.appID, info, tt.wantName, tt.wantVRSupport, tt.wantVROnly)
+			}
+		})
+	}
+
+	if _, ok := infos["999"]; ok {
+		t.Error("LoadAppInfo() returned an app that isn't in the cache")
+	}
+
+	portal := infos["620"]
+	if fmt.Sprint(portal.Developers) != "[Valve]" || fmt.Sprint(portal.Publishers) != "[Valve Corporation]" {
+		t.Errorf("LoadAppInfo() 620 developers = %v, publishers = %v", portal.Developers, portal.Publishers)
+	}
+	if fmt.Sprint(portal.Genres) != "[Action 9999]" || !portal.HasGenre("action") || portal.HasGenre("Racing") {
+		t.Errorf("LoadAppInfo() 620 genres = %v, want [Action 9999]", portal.Genres)
+	}
+	if portal.IsDLC() {
+		t.Error("IsDLC() = true for a game")
+	}
+	if dlc := infos["323180"]; !dlc.IsDLC() || dlc.Parent != "620" {
+		t.Errorf("LoadAppInfo() 323180 = %+v, want DLC with parent 620", dlc)
+	}
+	if !MadeBy(portal.Publishers, "valve") || MadeBy(portal.Publishers, "FromSoftware") {
+		t.Error("MadeBy() should match publisher names case-insensitively by substring")
+	}
+
+	// Without a filter every app is read
+	all, err := LoadAppInfo(GetAppInfoPath(install.Path), nil)
+	if err != nil {
+		t.Fatalf("LoadAppInfo() error = %v", err)
+	}
+	if len(all) != 5 {
+		t.Errorf("LoadAppInfo(nil) returned %d apps, want 5", len(all))
+	}
+}
+
+func TestProtonGames(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{
+		CompatTools: map[string]string{"0": "proton_experimental", "570": "proton_9", "1091500": "luxtorpeda"},
+		AppInfo: []steamtest.AppInfo{
+			{AppID: "570", Common: map[string]string{"oslist": "windows,macos,linux"}},
+			{AppID: "1245620", Common: map[string]string{"oslist": "windows"}},
+			{AppID: "730", Common: map[string]string{"oslist": "windows, linux"}},
+			{AppID: "1091500", Common: map[string]string{"oslist": "windows"}},
+		},
+	})
+	appIDs := []string{"570", "1245620", "730", "1091500", "999"}
+
+	got := protonGames(install.Path, appIDs, "linux")
+	want := map[string]bool{"570": true, "1245620": true, "730": false, "1091500": false, "999": false}
+	for appID, wantProton := range want {
+		if got[appID] != wantProton {
+			t.Errorf("protonGames() %s = %v, want %v", appID, got[appID], wantProton)
+		}
+	}
+
+	if got := protonGames(install.Path, appIDs, "windows"); len(got) != 0 {
+		t.Errorf("protonGames() on windows = %v, want none", got)
+	}
+}
+
+func TestSetCompatTools(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{CompatTools: map[string]string{"0": "proton_experimental", "570": "proton_9"}})
+
+	result, err := SetCompatTools(install.Path, []AppValue{{AppID: "570", Value: "proton_9"}, {AppID: "1245620", Value: "GE-Proton9-20"}}, UpdateOptions{})
+	if err != nil {
+		t.Fatalf("SetCompatTools() error = %v", err)
+	}
+	if result.Count(UpdateChanged) != 1 || result.Count(UpdateUnchanged) != 1 || result.BackupPath == "" {
+		t.Errorf("SetCompatTools() = %+v, want 1 changed, 1 unchanged, and a backup", result)
+	}
+
+	want := map[string]string{"570": "proton_9", "1245620": "GE-Proton9-20"}
+	if got := GetCompatTools(install.Path); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetCompatTools() = %v, want %v", got, want)
+	}
+}
+
+func TestIsSteamDeck(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  bool
+	}{
+		{
+			name: "Deck OLED",
+			files: map[string]string{
+				"sys/devices/virtual/dmi/id/board_vendor": "Valve\n",
+				"sys/devices/virtual/dmi/id/product_name": "Galileo\n",
+			},
+			want: true,
+		},
+		{
+			name: "other hardware running SteamOS",
+			files: map[string]string{
+				"sys/devices/virtual/dmi/id/board_vendor": "ASUSTeK COMPUTER INC.\n",
+				"sys/devices/virtual/dmi/id/product_name": "ROG Ally RC71L\n",
+				"etc/os-release": "ID=steamos\n",
+			},
+		},
+		{
+			name:  "SteamOS without DMI",
+			files: map[string]string{"etc/os-release": "NAME=\"SteamOS\"\nID=\"steamos\"\n"},
+			want:  true,
+		},
+		{name: "desktop", files: map[string]string{"etc/os-release": "ID=arch\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(root, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := isSteamDeck(root); got != tt.want {
+				t.Errorf("isSteamDeck() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectSandbox(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		env   map[string]string
+		want  string
+	}{
+		{name: "host"},
+		{name: "flatpak", files: []string{".flatpak-info"}, want: SandboxFlatpak},
+		{name: "flatpak env", env: map[string]string{"FLATPAK_ID": "io.github.gsca"}, want: SandboxFlatpak},
+		{name: "snap", env: map[string]string{"SNAP_NAME": "gsca"}, want: SandboxSnap},
+		{name: "docker", files: []string{".dockerenv"}, want: SandboxContainer},
+		{name: "podman", files: []string{"run/.containerenv"}, want: SandboxContainer},
+		{name: "systemd-nspawn", env: map[string]string{"container": "systemd-nspawn"}, want: SandboxContainer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for _, name := range tt.files {
+				path := filepath.Join(root, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			getenv := func(key string) string { return tt.env[key] }
+			if got := detectSandbox(root, getenv); got != tt.want {
+				t.Errorf("detectSandbox() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyWriteError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "localconfig.vdf")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want WriteErrorKind
+	}{
+		{name: "read-only filesystem", err: &os.PathError{Op: "open", Path: path, Err: syscall.EROFS}, want: WriteErrorReadOnlyFS},
+		{name: "disk full", err: &os.PathError{Op: "write", Path: path, Err: syscall.ENOSPC}, want: WriteErrorDiskFull},
+		{name: "permission denied", err: &os.PathError{Op: "open", Path: path, Err: os.ErrPermission}, want: WriteErrorPermission},
+		{name: "other", err: errors.New("I/O error"), want: WriteErrorOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyWriteError(path, tt.err)
+			var writeErr *WriteError
+			if !errors.As(err, &writeErr) || writeErr.Kind != tt.want {
+				t.Fatalf("classifyWriteError() = %v, want kind %d", err, tt.want)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Error("classifyWriteError() should wrap the original error")
+			}
+			if (tt.want != WriteErrorOther) != strings.Contains(err.Error(), "To fix this") {
+				t.Errorf("classifyWriteError() steps missing or unexpected: %v", err)
+			}
+		})
+	}
+
+	if err := classifyWriteError(path, nil); err != nil {
+		t.Errorf("classifyWriteError(nil) = %v, want nil", err)
+	}
+}
+
+func TestSteamReadyState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.vdf")
+	registry := `"Registry"
+{
+	"HKCU"
+	{
+		"Software"
+		{
+			"Valve"
+			{
+				"Steam"
+				{
+					"ActiveProcess"
+					{
+						"pid"		"4242"
+						"ActiveUser"		"12345"
+					}
+				}
+			}
+		}
+	}
+}
+`
+	if err := os.WriteFile(path, []byte(registry), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if user, err := activeUserFromRegistry(path); err != nil || user != "12345" {
+		t.Errorf("activeUserFromRegistry() = %q, %v, want 12345", user, err)
+	}
+
+	output := "\r\nHKEY_CURRENT_USER\\Software\\Valve\\Steam\\ActiveProcess\r\n    ActiveUser    REG_DWORD    0x3039\r\n\r\n"
+	if got := parseRegDWORD(output); got != "12345" {
+		t.Errorf("parseRegDWORD() = %q, want 12345", got)
+	}
+	if got := parseRegDWORD("ERROR: The system was unable to find the specified registry key or value."); got != "" {
+		t.Errorf("parseRegDWORD() of an error = %q, want empty", got)
+	}
+	output = "\r\nHKEY_CURRENT_USER\\Software\\Valve\\Steam\r\n    SteamPath    REG_SZ    d:/program files (x86)/steam\r\n\r\n"
+	if got := parseRegSZ(output); got != "d:/program files (x86)/steam" {
+		t.Errorf("parseRegSZ() = %q, want the path", got)
+	}
+}
+
+func TestTasklistHasImage(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		image  string
+		want   bool
+	}{
+		{name: "running", output: "\"steam.exe\",\"4242\",\"Console\",\"1\",\"120,512 K\"\r\n", image: "steam.exe", want: true},
+		{name: "different case", output: "\"Steam.exe\",\"4242\",\"Console\",\"1\",\"120,512 K\"\r\n", image: "steam.exe", want: true},
+		{name: "english no tasks", output: "INFO: No tasks are running which match the specified criteria.\r\n", image: "steam.exe"},
+		{name: "german no tasks", output: "INFORMATION: Es werden keine Aufgaben mit den angegebenen Kriterien ausgeführt.\r\n", image: "steam.exe"},
+		{name: "other image", output: "\"steamwebhelper.exe\",\"17\",\"Console\",\"1\",\"80 K\"\r\n", image: "steam.exe"},
+		{name: "empty", output: "", image: "steam.exe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tasklistHasImage(tt.output, tt.image); got != tt.want {
+				t.Errorf("tasklistHasImage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		goos string
+		opts StartOptions
+		want []string
+	}{
+		{name: "linux", goos: osLinux, want: []string{"steam"}},
+		{
+			name: "linux big picture with args",
+			goos: osLinux,
+			opts: StartOptions{BigPicture: true, Args: []string{"-silent"}},
+			want: []string{"steam", "-silent", "steam://open/bigpicture"},
+		},
+		{name: "darwin", goos: osDarwin, want: []string{"open", "-a", "Steam"}},
+		{
+			name: "darwin with args",
+			goos: osDarwin,
+			opts: StartOptions{Args: []string{"-silent"}},
+			want: []string{"open", "-a", "Steam", "--args", "-silent"},
+		},
+		{
+			name: "darwin minimized",
+			goos: osDarwin,
+			opts: StartOptions{Minimized: true},
+			want: []string{"open", "-g", "-a", "Steam", "--args", "-silent"},
+		},
+		{
+			name: "windows minimized",
+			goos: osWindows,
+			opts: StartOptions{Minimized: true, Args: []string{"-silent"}},
+			want: []string{"cmd", "/C", "start", "", filepath.Join("steam", "steam.exe"), "-silent"},
+		},
+		{
+			name: "windows big picture",
+			goos: osWindows,
+			opts: StartOptions{BigPicture: true},
+			want: []string{"cmd", "/C", "start", "", "steam://open/bigpicture"},
+		},
+		{
+			name: "windows with args",
+			goos: osWindows,
+			opts: StartOptions{Args: []string{"-silent"}},
+			want: []string{"cmd", "/C", "start", "", filepath.Join("steam", "steam.exe"), "-silent"},
+		},
+		{
+			name: "linux executable",
+			goos: osLinux,
+			opts: StartOptions{Executable: "/opt/steam/steam.sh", Args: []string{"-silent"}},
+			want: []string{"/opt/steam/steam.sh", "-silent"},
+		},
+		{
+			name: "darwin executable",
+			goos: osDarwin,
+			opts: StartOptions{Executable: "/Applications/Games/Steam.app"},
+			want: []string{"open", "-a", "/Applications/Games/Steam.app"},
+		},
+		{
+			name: "windows executable",
+			goos: osWindows,
+			opts: StartOptions{Executable: `D:\Steam\steam.exe`},
+			want: []string{"cmd", "/C", "start", "", `D:\Steam\steam.exe`},
+		},
+		{
+			name: "custom command",
+			goos: osLinux,
+			opts: StartOptions{Command: []string{"flatpak", "run", "com.valvesoftware.Steam"}, BigPicture: true, Executable: "ignored"},
+			want: []string{"flatpak", "run", "com.valvesoftware.Steam", "steam://open/bigpicture"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := startCommand(tt.goos, "steam", tt.opts)
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("startCommand() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	// Steam China is started through its own client
+	china := t.TempDir()
+	if err := os.WriteFile(filepath.Join(china, "steamchina.exe"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"cmd", "/C", "start", "", filepath.Join(china, "steamchina.exe"), "-silent"}
+	if got, err := startCommand(osWindows, china, StartOptions{Minimized: true}); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("startCommand() for Steam China = %q, %v, want %q", got, err, want)
+	}
+
+	if _, err := startCommand("plan9", "", StartOptions{}); err == nil {
+		t.Error("startCommand() on an unsupported platform error = nil, want error")
+	}
+}
+
+func TestUpdateLaunchOptions(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "localconfig.vdf")
+	content := `"UserLocalConfigStore"
+{
+	"Software"
+	{
+		"Valve"
+		{
+			"Steam"
+			{
+				"apps"
+				{
+					"570"
+					{
+						"LaunchOptions"		"gamemoderun %command%"
+					}
+					"730"
+					{
+						"LaunchOptions"		"-novid"
+					}
+				}
+			}
+		}
+	}
+}`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write localconfig.vdf: %v", err)
+	}
+
+	result, err := UpdateLaunchOptions(configPath, []string{"570", "730"}, "gamemoderun %command%", UpdateOptions{})
+	if err != nil {
+		t.Fatalf("UpdateLaunchOptions() error = %v", err)
+	}
+
+	want := []AppUpdateResult{
+		{AppID: "570", PreviousValue: "gamemoderun %command%", NewValue: "gamemoderun %command%", Status: UpdateUnchanged},
+		{AppID: "730", PreviousValue: "-novid", NewValue: "gamemoderun %command%", Status: UpdateChanged},
+	}
+	if len(result.Apps) != len(want) {
+		t.Fatalf("UpdateLaunchOptions() returned %d results, want %d", len(result.Apps), len(want))
+	}
+	for i, app := range result.Apps {
+		if app != want[i] {
+			t.Errorf("UpdateLaunchOptions() result[%d] = %+v, want %+v", i, app, want[i])
+		}
+	}
+
+	if result.BackupPath == "" {
+		t.Error("UpdateLaunchOptions() did not create a backup")
+	}
+
+	// A second run changes nothing, so no file is written or backed up
+	result, err = UpdateLaunchOptions(configPath, []string{"570", "730"}, "gamemoderun %command%", UpdateOptions{})
+	if err != nil {
+		t.Fatalf("UpdateLaunchOptions() second run error = %v", err)
+	}
+	if result.Count(UpdateUnchanged) != 2 || result.BackupPath != "" {
+		t.Errorf("UpdateLaunchOptions() second run = %+v, want 2 unchanged and no backup", result)
+	}
+}
+
+func TestVerifyBackups(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{Users: []steamtest.User{{ID: "1", Apps: []steamtest.App{{AppID: "570"}}}}})
+	configPath := install.LocalConfigPath("1")
+
+	var backups []string
+	for _, args := range []string{"-novid", "-high", "-dx11"} {
+		result, err := UpdateLaunchOptions(configPath, []string{"570"}, args, UpdateOptions{})
+		if err != nil {
+			t.Fatalf("UpdateLaunchOptions() error = %v", err)
+		}
+		backups = append(backups, result.BackupPath)
+	}
+
+	// One backup was edited, one truncated, and one copied by hand without a checksum
+	if err := os.WriteFile(backups[0], []byte(strings.Replace(mustRead(t, backups[0]), "570", "571", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(backups[1], []byte(mustRead(t, backups[1])[:40]), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manual := configPath + ".backup.manual"
+	if err := os.WriteFile(manual, []byte(mustRead(t, backups[2])), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	checks, err := VerifyBackups(configPath)
+	if err != nil {
+		t.Fatalf("VerifyBackups() error = %v", err)
+	}
+	got := make(map[string]string)
+	for _, check := range checks {
+		got[check.Backup.Path] = check.Status
+	}
+	want := map[string]string{
+		backups[0]: BackupCorrupted,
+		backups[1]: BackupCorrupted,
+		backups[2]: BackupOK,
+		manual:     BackupUnverified,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("VerifyBackups() = %v, want %v", got, want)
+	}
+
+	// Without a checksum, a truncated backup is caught by parsing it
+	if err := os.WriteFile(manual, []byte(mustRead(t, backups[2])[:40]), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if check := VerifyBackup(BackupInfo{Path: manual, Name: filepath.Base(manual)}); check.Status != BackupDamaged || check.Err == nil {
+		t.Errorf("VerifyBackup() of a truncated backup = %+v, want damaged", check)
+	}
+}
+
+func mustRead(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestOnFileChange(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{Users: []steamtest.User{{ID: "1", Apps: []steamtest.App{{AppID: "570"}}}}})
+	configPath := install.LocalConfigPath("1")
+	before := fmt.Sprintf("%x", sha256.Sum256([]byte(mustRead(t, configPath))))
+
+	var changes []FileChange
+	OnFileChange = func(change FileChange) { changes = append(changes, change) }
+	defer func() { OnFileChange = nil }()
+
+	// Unchanged values don't write, so only the first run is reported
+	for i := 0; i < 2; i++ {
+		if _, err := UpdateLaunchOptions(configPath, []string{"570"}, "-novid", UpdateOptions{}); err != nil {
+			t.Fatalf("UpdateLaunchOptions() error = %v", err)
+		}
+	}
+
+	after := fmt.Sprintf("%x", sha256.Sum256([]byte(mustRead(t, configPath))))
+	want := []FileChange{{Path: configPath, Before: before, After: after}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("OnFileChange calls = %+v, want %+v", changes, want)
+	}
+}
+
+func TestSetAppValue(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{
+		Users: []steamtest.User{{ID: "1", Apps: []steamtest.App{
+			{AppID: "570", LaunchOptions: "-novid"},
+			{AppID: "730"},
+		}}},
+	})
+	configPath := install.LocalConfigPath("1")
+
+	result, err := SetAppValue(configPath, []string{"570", "730"}, "DisableLaunchInVR", "1", UpdateOptions{SkipBackup: true})
+	if err != nil {
+		t.Fatalf("SetAppValue() error = %v", err)
+	}
+	if got := result.Count(UpdateChanged); got != 2 {
+		t.Errorf("Count(UpdateChanged) = %d, want 2", got)
+	}
+
+	values, err := GetAppValues(configPath, "DisableLaunchInVR")
+	if err != nil {
+		t.Fatalf("GetAppValues() error = %v", err)
+	}
+	if fmt.Sprint(values) != "map[570:1 730:1]" {
+		t.Errorf("GetAppValues() = %v, want both apps set to 1", values)
+	}
+
+	// Other keys are left alone
+	launchOptions, err := GetAppValues(configPath, KeyLaunchOptions)
+	if err != nil {
+		t.Fatalf("GetAppValues() error = %v", err)
+	}
+	if fmt.Sprint(launchOptions) != "map[570:-novid]" {
+		t.Errorf("GetAppValues(LaunchOptions) = %v, want only 570", launchOptions)
+	}
+
+	if _, err := SetAppValue(configPath, []string{"570"}, "bad/key", "1", UpdateOptions{SkipBackup: true}); err == nil {
+		t.Error("SetAppValue() with a path separator in the key error = nil, want error")
+	}
+}
+
+func TestSetLaunchOptions(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{
+		Users: []steamtest.User{{ID: "1", Apps: []steamtest.App{
+			{AppID: "570", LaunchOptions: "-novid"},
+			{AppID: "730"},
+		}}},
+	})
+	configPath := install.LocalConfigPath("1")
+
+	if got, err := GetLaunchOptions(configPath, "570"); err != nil || got != "-novid" {
+		t.Errorf("GetLaunchOptions(570) = %q, %v, want -novid", got, err)
+	}
+	if got, err := GetLaunchOptions(configPath, "10"); err != nil || got != "" {
+		t.Errorf("GetLaunchOptions() of an unknown app = %q, %v, want empty", got, err)
+	}
+
+	result, err := SetLaunchOptions(configPath, "730", "mangohud %command%", UpdateOptions{})
+	if err != nil {
+		t.Fatalf("SetLaunchOptions() error = %v", err)
+	}
+	if result.Count(UpdateChanged) != 1 || result.BackupPath == "" {
+		t.Errorf("SetLaunchOptions() = %+v, want 730 changed with a backup", result)
+	}
+	if got, _ := GetLaunchOptions(configPath, "730"); got != "mangohud %command%" {
+		t.Errorf("GetLaunchOptions(730) = %q after SetLaunchOptions()", got)
+	}
+
+	// Setting the same value again leaves the file alone
+	result, err = SetLaunchOptions(configPath, "730", "mangohud %command%", UpdateOptions{})
+	if err != nil || result.Count(UpdateUnchanged) != 1 || result.BackupPath != "" {
+		t.Errorf("SetLaunchOptions() with the current value = %+v, %v, want unchanged without a backup", result, err)
+	}
+
+	if _, err := SetLaunchOptions(configPath, "570", "-novid\n-console", UpdateOptions{}); err == nil {
+		t.Error("SetLaunchOptions() with a line break error = nil, want error")
+	}
+
+	// No temporary files are left next to localconfig.vdf
+	entries, err := os.ReadDir(filepath.Dir(configPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp") {
+			t.Errorf("temporary file %s left behind", entry.Name())
+		}
+	}
+}
+
+func TestPreviewAppValues(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{
+		Users: []steamtest.User{{ID: "1", Apps: []steamtest.App{
+			{AppID: "570", LaunchOptions: "-novid"},
+			{AppID: "730", LaunchOptions: "-high"},
+		}}},
+	})
+	configPath := install.LocalConfigPath("1")
+	original, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := []AppValue{{AppID: "570", Value: "-console"}, {AppID: "730", Value: "-high"}}
+	preview, err := PreviewAppValues(configPath, KeyLaunchOptions, values, UpdateOptions{Surgical: true})
+	if err != nil {
+		t.Fatalf("PreviewAppValues() error = %v", err)
+	}
+
+	if preview.Result.Count(UpdateChanged) != 1 || preview.Result.Count(UpdateUnchanged) != 1 {
+		t.Errorf("PreviewAppValues() result = %+v, want 1 changed and 1 unchanged", preview.Result.Apps)
+	}
+	want := bytes.Replace(original, []byte(`"-novid"`), []byte(`"-console"`), 1)
+	if !bytes.Equal(preview.Before, original) || !bytes.Equal(preview.After, want) {
+		t.Errorf("PreviewAppValues() After =\n%s\nwant\n%s", preview.After, want)
+	}
+	if current, _ := FingerprintFile(configPath); !current.Equal(preview.Fingerprint) {
+		t.Error("PreviewAppValues() fingerprint doesn't match the file")
+	}
+
+	// Nothing is written
+	if data, _ := os.ReadFile(configPath); !bytes.Equal(data, original) {
+		t.Error("PreviewAppValues() modified localconfig.vdf")
+	}
+}
+
+func TestApplyPlan(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{
+		Users: []steamtest.User{{ID: "1", Apps: []steamtest.App{{AppID: "570", LaunchOptions: "-novid"}}}},
+	})
+	configPath := install.LocalConfigPath("1")
+
+	fingerprint, err := FingerprintFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	planPath := filepath.Join(t.TempDir(), "plan.json")
+	err = WritePlan(planPath, &Plan{
+		Environment: Environment{LocalConfigPath: configPath, LocalConfig: fingerprint},
+		Key:         KeyLaunchOptions,
+		Changes:     []PlanChange{{AppID: "570", OldValue: "-novid", NewValue: "-console"}},
+	})
+	if err != nil {
+		t.Fatalf("WritePlan() error = %v", err)
+	}
+
+	plan, err := LoadPlan(planPath)
+	if err != nil {
+		t.Fatalf("LoadPlan() error = %v", err)
+	}
+	if err := CheckPlan(plan); err != nil {
+		t.Fatalf("CheckPlan() error = %v", err)
+	}
+
+	result, err := ApplyPlan(plan, UpdateOptions{SkipBackup: true})
+	if err != nil {
+		t.Fatalf("ApplyPlan() error = %v", err)
+	}
+	if result.Count(UpdateChanged) != 1 {
+		t.Errorf("ApplyPlan() changed %d apps, want 1", result.Count(UpdateChanged))
+	}
+	values, _ := GetAppValues(configPath, KeyLaunchOptions)
+	if values["570"] != "-console" {
+		t.Errorf("LaunchOptions after ApplyPlan() = %q, want -console", values["570"])
+	}
+
+	// The file the plan was made against is gone now
+	if err := CheckPlan(plan); !errors.Is(err, ErrPlanStale) {
+		t.Errorf("CheckPlan() after applying error = %v, want ErrPlanStale", err)
+	}
+	if _, err := ApplyPlan(plan, UpdateOptions{SkipBackup: true}); !errors.Is(err, ErrPlanStale) {
+		t.Errorf("ApplyPlan() of a stale plan error = %v, want ErrPlanStale", err)
+	}
+}
+
+func TestUpdateLaunchOptionsConcurrentModification(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{
+		Users: []steamtest.User{{ID: "1", Apps: []steamtest.App{{AppID: "570"}, {AppID: "730"}}}},
+	})
+	configPath := install.LocalConfigPath("1")
+
+	// Simulate Steam rewriting the file mid-update, adding a new app once
+	rewrites := 0
+	rewrite := func(done, total int) {
+		if done != total || rewrites > 0 {
+			return
+		}
+		rewrites++
+		if err := steamtest.Build(install.Path, steamtest.Spec{
+			Users: []steamtest.User{{ID: "1", Apps: []steamtest.App{{AppID: "570"}, {AppID: "730"}, {AppID: "440"}}}},
+		}); err != nil {
+			t.Fatalf("Failed to rewrite config: %v", err)
+		}
+	}
+
+	if _, err := UpdateLaunchOptions(configPath, []string{"570"}, "-novid", UpdateOptions{SkipBackup: true, Progress: rewrite}); err != nil {
+		t.Fatalf("UpdateLaunchOptions() error = %v", err)
+	}
+
+	// The retry must keep the concurrent change instead of overwriting it
+	ids, err := GetAllGameIDs(configPath)
+	if err != nil {
+		t.Fatalf("GetAllGameIDs() error = %v", err)
+	}
+	if len(ids) != 3 {
+		t.Errorf("GetAllGameIDs() = %v, want the concurrently added app kept", ids)
+	}
+
+	// A file that never stops changing is reported instead of overwritten
+	attempts := 0
+	alwaysRewrite := func(done, total int) {
+		if done != total {
+			return
+		}
+		attempts++
+		content := fmt.Sprintf("\"UserLocalConfigStore\"\n{\n\t\"attempt\"\t\t\"%d\"\n}\n", attempts)
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to rewrite config: %v", err)
+		}
+	}
+
+	_, err = UpdateLaunchOptions(configPath, []string{"570"}, "-high", UpdateOptions{SkipBackup: true, Progress: alwaysRewrite})
+	if !errors.Is(err, ErrConfigModified) {
+		t.Errorf("UpdateLaunchOptions() error = %v, want ErrConfigModified", err)
+	}
+}
+
+func TestUpdateLaunchOptionsDuplicateApps(t *testing.T) {
+	// An app listed twice, as left behind by some hand edits and sync tools
+	original := `"UserLocalConfigStore"
+{
+	"Software"
+	{
+		"Valve"
+		{
+			"Steam"
+			{
+				"apps"
+				{
+					"570"
+					{
+						"LaunchOptions"		"-novid"
+					}
+					"570"
+					{
+						"LaunchOptions"		"-console"
+					}
+				}
+			}
+		}
+	}
+}
+`
+
+	for _, surgical := range []bool{false, true} {
+		t.Run(fmt.Sprintf("surgical=%v", surgical), func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "localconfig.vdf")
+			if err := os.WriteFile(configPath, []byte(original), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := UpdateLaunchOptions(configPath, []string{"570"}, "-high", UpdateOptions{SkipBackup: true, Surgical: surgical})
+			if err != nil {
+				t.Fatalf("UpdateLaunchOptions() error = %v", err)
+			}
+			if app := result.Apps[0]; app.Status != UpdateChanged || app.PreviousValue != "-novid" {
+				t.Errorf("UpdateLaunchOptions() app = %+v, want changed from -novid", app)
+			}
+
+			data := mustRead(t, configPath)
+			if strings.Count(data, `"-high"`) != 2 || strings.Contains(data, "-novid") || strings.Contains(data, "-console") {
+				t.Errorf("UpdateLaunchOptions() didn't set every copy of the app:\n%s", data)
+			}
+		})
+	}
+}
+
+func TestUpdateLaunchOptionsSurgical(t *testing.T) {
+	// Hand-formatted file the serializer would normalize (spaces, comments, CRLF)
+	original := strings.Join([]string{
+		`"UserLocalConfigStore"`,
+		`{`,
+		`  // keep me`,
+		`  "Software"`,
+		`  {`,
+		`    "Valve"`,
+		`    {`,
+		`      "Steam"`,
+		`      {`,
+		`        "apps"`,
+		`        {`,
+		`          "570"`,
+		`          {`,
+		`            "LaunchOptions"  "-novid"`,
+		`            "LastPlayed"  "1700000000"`,
+		`          }`,
+		`          "730"`,
+		`          {`,
+		`            "LastPlayed"  "1700000001"`,
+		`          }`,
+		`        }`,
+		`      }`,
+		`    }`,
+		`  }`,
+		`}`,
+		``,
+	}, "\r\n")
+
+	configPath := filepath.Join(t.TempDir(), "localconfig.vdf")
+	if err := os.WriteFile(configPath, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+
+	result, err := UpdateLaunchOptions(configPath, []string{"570", "730", "440"}, "-high", UpdateOptions{SkipBackup: true, Surgical: true})
+	if err != nil {
+		t.Fatalf("UpdateLaunchOptions() error = %v", err)
+	}
+	if got := result.Count(UpdateChanged); got != 3 {
+		t.Fatalf("Count(UpdateChanged) = %d, want 3", got)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	want := strings.Replace(original, `"LaunchOptions"  "-novid"`, `"LaunchOptions"  "-high"`, 1)
+	want = strings.Replace(want,
+		"\"1700000001\"\r\n",
+		"\"1700000001\"\r\n            \"LaunchOptions\"\t\t\"-high\"\r\n", 1)
+	want = strings.Replace(want,
+		"          }\r\n        }",
+		"          }\r\n          \"440\"\r\n          {\r\n            \"LaunchOptions\"\t\t\"-high\"\r\n          }\r\n        }", 1)
+	if string(data) != want {
+		t.Errorf("surgical write =\n%s\nwant\n%s", data, want)
+	}
+
+	// Quotes in the new value are escaped as Steam writes them
+	if err := os.WriteFile(configPath, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if _, err := UpdateLaunchOptions(configPath, []string{"570"}, `%command% -name "My Server"`, UpdateOptions{SkipBackup: true, Surgical: true}); err != nil {
+		t.Fatalf("UpdateLaunchOptions() with quotes error = %v", err)
+	}
+	if data := mustRead(t, configPath); !strings.Contains(data, `"LaunchOptions"  "%command% -name \"My Server\""`) {
+		t.Errorf("surgical write didn't escape the quotes:\n%s", data)
+	}
+
+	// Values sharing a line with other tokens are replaced on their own
+	sharedLines := []struct{ line, want string }{
+		{`"570" { "LaunchOptions" "-old" }`, `"570" { "LaunchOptions" "-high" }`},
+		{"\"570\"\n{\n\"LaunchOptions\" \"-old\" \"Playtime\" \"5\"\n}", "\"570\"\n{\n\"LaunchOptions\" \"-high\" \"Playtime\" \"5\"\n}"},
+	}
+	for _, tt := range sharedLines {
+		wrap := func(app string) string {
+			return "\"UserLocalConfigStore\"\n{\n\"Software\"\n{\n\"Valve\"\n{\n\"Steam\"\n{\n\"apps\"\n{\n" + app + "\n}\n}\n}\n}\n}\n"
+		}
+		if err := os.WriteFile(configPath, []byte(wrap(tt.line)), 0644); err != nil {
+			t.Fatalf("Failed to create config: %v", err)
+		}
+		if _, err := UpdateLaunchOptions(configPath, []string{"570"}, "-high", UpdateOptions{SkipBackup: true, Surgical: true}); err != nil {
+			t.Fatalf("UpdateLaunchOptions() on %q error = %v", tt.line, err)
+		}
+		if data := mustRead(t, configPath); data != wrap(tt.want) {
+			t.Errorf("surgical write of %q =\n%s\nwant\n%s", tt.line, data, wrap(tt.want))
+		}
+	}
+
+	// A damaged file is refused rather than spliced
+	if err := os.WriteFile(configPath, []byte(original[:len(original)/2]), 0644); err != nil {
+		t.Fatalf("Failed to truncate config: %v", err)
+	}
+	if _, err := UpdateLaunchOptions(configPath, []string{"570"}, "-novid", UpdateOptions{SkipBackup: true, Surgical: true}); err == nil {
+		t.Error("UpdateLaunchOptions() on damaged file error = nil, want error")
+	}
+
+	// So is adding a key to a one-line object, which has no line to insert it before
+	oneLine := strings.Replace(original, "\"730\"\r\n          {\r\n            \"LastPlayed\"  \"1700000001\"\r\n          }", `"730" {}`, 1)
+	if err := os.WriteFile(configPath, []byte(oneLine), 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if _, err := UpdateLaunchOptions(configPath, []string{"730"}, "-high", UpdateOptions{SkipBackup: true, Surgical: true}); err == nil {
+		t.Error("UpdateLaunchOptions() into a one-line object error = nil, want error")
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != oneLine {
+		t.Error("UpdateLaunchOptions() changed the file after failing")
+	}
+}
+
+func TestSetBetaBranch(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{
+		Games: []steamtest.Game{
+			{AppID: "570", Name: "Dota 2"},
+			{AppID: "730", Name: "Counter-Strike 2"},
+		},
+	})
+	manifestPath := filepath.Join(install.Path, "steamapps", "appmanifest_570.acf")
+
+	result, err := SetBetaBranch(install.Path, []string{"570", "999"}, "beta", UpdateOptions{})
+	if err != nil {
+		t.Fatalf("SetBetaBranch() error = %v", err)
+	}
+	if result.Apps[0].Status != UpdateChanged || result.Apps[0].PreviousValue != BetaBranchPublic {
+		t.Errorf("SetBetaBranch() app 570 = %+v, want changed from public", result.Apps[0])
+	}
+	if result.Apps[1].Status != UpdateFailed {
+		t.Errorf("SetBetaBranch() app 999 status = %s, want failed (not installed)", result.Apps[1].Status)
+	}
+	if _, err := os.Stat(result.Apps[0].BackupPath); err != nil {
+		t.Errorf("SetBetaBranch() backup missing: %v", err)
+	}
+
+	root, err := readVDFFile(manifestPath)
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	if node := vdf.FindNode(root, "AppState/UserConfig/BetaKey"); node == nil || node.Value != "beta" {
+		t.Errorf("BetaKey = %v, want beta", node)
+	}
+
+	// Switching back to public removes the key
+	result, err = SetBetaBranch(install.Path, []string{"570", "730"}, BetaBranchPublic, UpdateOptions{SkipBackup: true})
+	if err != nil {
+		t.Fatalf("SetBetaBranch() error = %v", err)
+	}
+	if result.Count(UpdateChanged) != 1 || result.Count(UpdateUnchanged) != 1 {
+		t.Errorf("SetBetaBranch() to public = %+v, want 1 changed and 1 unchanged", result.Apps)
+	}
+	if root, err = readVDFFile(manifestPath); err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	if node := vdf.FindNode(root, "AppState/UserConfig/BetaKey"); node != nil {
+		t.Errorf("BetaKey = %q after switching to public, want removed", node.Value)
+	}
+}
+
+func TestSetGameLanguage(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{
+		Games: []steamtest.Game{{AppID: "1245620", Name: "ELDEN RING"}},
+	})
+
+	tests := []struct {
+		language string
+		want     string
+		wantErr  bool
+	}{
+		{language: "ja", want: "japanese"},
+		{language: "pt_BR", want: "brazilian"},
+		{language: "schinese", want: "schinese"},
+		{language: "klingon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			result, err := SetGameLanguage(install.Path, []string{"1245620"}, tt.language, UpdateOptions{SkipBackup: true})
+			if tt.wantErr {
+				if err == nil {
+					t.Error("SetGameLanguage() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetGameLanguage() error = %v", err)
+			}
+			if result.Apps[0].NewValue != tt.want {
+				t.Errorf("SetGameLanguage() value = %q, want %q", result.Apps[0].NewValue, tt.want)
+			}
+
+			root, err := readVDFFile(filepath.Join(install.Path, "steamapps", "appmanifest_1245620.acf"))
+			if err != nil {
+				t.Fatalf("Failed to read manifest: %v", err)
+			}
+			if node := vdf.FindNode(root, "AppState/UserConfig/language"); node == nil || node.Value != tt.want {
+				t.Errorf("language = %v, want %q", node, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteLocalConfig(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{
+		Users: []steamtest.User{{ID: "1", Apps: []steamtest.App{{AppID: "570", LaunchOptions: "-novid"}}}},
+	})
+	configPath := install.LocalConfigPath("1")
+	if err := os.Chmod(configPath, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := readVDFFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vdf.SetValue(root, appsPath+"/570/"+KeyLaunchOptions, "-console"); err != nil {
+		t.Fatal(err)
+	}
+	backupPath, err := WriteLocalConfig(configPath, root, WriteOptions{})
+	if err != nil {
+		t.Fatalf("WriteLocalConfig() error = %v", err)
+	}
+	if backupPath == "" || !strings.Contains(mustRead(t, backupPath), "-novid") {
+		t.Errorf("WriteLocalConfig() backup = %q, want a backup of the previous file", backupPath)
+	}
+	if got, _ := GetLaunchOptions(configPath, "570"); got != "-console" {
+		t.Errorf("launch options after WriteLocalConfig() = %q, want -console", got)
+	}
+	if info, err := os.Stat(configPath); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("WriteLocalConfig() changed the file mode to %v (error %v), want 0600", info.Mode().Perm(), err)
+	}
+
+	// A stale fingerprint means the file changed underneath the caller
+	stale := Fingerprint{Hash: "stale"}
+	if _, err := WriteLocalConfig(configPath, root, WriteOptions{Original: &stale}); !errors.Is(err, ErrConfigModified) {
+		t.Errorf("WriteLocalConfig() with a stale fingerprint error = %v, want ErrConfigModified", err)
+	}
+
+	// Malformed contents are never written, e.g. when restoring a truncated backup
+	truncated := filepath.Join(t.TempDir(), "localconfig.vdf.backup")
+	if err := os.WriteFile(truncated, []byte("\"UserLocalConfigStore\"\n{\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	before := mustRead(t, configPath)
+	if _, err := RestoreBackup(truncated, configPath); err == nil {
+		t.Error("RestoreBackup() of a truncated backup error = nil, want error")
+	}
+	if mustRead(t, configPath) != before {
+		t.Error("RestoreBackup() of a truncated backup modified localconfig.vdf")
+	}
+
+	// The file a restore replaces is backed up first
+	replaced, err := RestoreBackup(backupPath, configPath)
+	if err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+	if replaced == "" || mustRead(t, replaced) != before {
+		t.Errorf("RestoreBackup() backup = %q, want a backup of the replaced file", replaced)
+	}
+
+	// Restoring works even if localconfig.vdf was deleted
+	if err := os.Remove(configPath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RestoreBackup(backupPath, configPath); err != nil {
+		t.Fatalf("RestoreBackup() of a deleted file error = %v", err)
+	}
+	if got, _ := GetLaunchOptions(configPath, "570"); got != "-novid" {
+		t.Errorf("launch options after RestoreBackup() = %q, want -novid", got)
+	}
+}
+
+func TestRepairLocalConfig(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{
+		Users: []steamtest.User{{ID: "1", Apps: []steamtest.App{
+			{AppID: "570", LaunchOptions: "-novid"},
+			{AppID: "730", LaunchOptions: "-high"},
+		}}},
+	})
+	configPath := install.LocalConfigPath("1")
+
+	// Keep a good backup, then truncate the config mid-way through the apps section
+	if _, err := UpdateLaunchOptions(configPath, []string{"570"}, "gamemoderun %command%", UpdateOptions{}); err != nil {
+		t.Fatalf("UpdateLaunchOptions() error = %v", err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	damaged := data[:bytes.Index(data, []byte(`"730"`))]
+	if err := os.WriteFile(configPath, damaged, 0644); err != nil {
+		t.Fatalf("Failed to write damaged config: %v", err)
+	}
+
+	if err := CheckLocalConfig(configPath); err == nil {
+		t.Fatal("CheckLocalConfig() = nil, want error for truncated file")
+	}
+
+	backup, ok := NewestValidBackup(configPath)
+	if !ok {
+		t.Fatal("NewestValidBackup() found no backup")
+	}
+
+	result, err := RepairLocalConfig(configPath, backup.Path)
+	if err != nil {
+		t.Fatalf("RepairLocalConfig() error = %v", err)
+	}
+	if result.AppCount != 2 {
+		t.Errorf("RepairLocalConfig() AppCount = %d, want 2", result.AppCount)
+	}
+
+	if err := CheckLocalConfig(configPath); err != nil {
+		t.Errorf("CheckLocalConfig() after repair = %v", err)
+	}
+	if saved, err := os.ReadFile(result.DamagedCopy); err != nil || !bytes.Equal(saved, damaged) {
+		t.Errorf("RepairLocalConfig() damaged copy not preserved (err = %v)", err)
+	}
+
+	ids, err := GetAllGameIDs(configPath)
+	if err != nil || len(ids) != 2 {
+		t.Errorf("GetAllGameIDs() after repair = %v, %v, want 2 apps", ids, err)
+	}
+}
+
+func TestRepairLocalConfigTruncatedInValue(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{
+		Users: []steamtest.User{{ID: "1", Apps: []steamtest.App{
+			{AppID: "570", LaunchOptions: "-novid"},
+			{AppID: "730", LaunchOptions: "-high"},
+		}}},
+	})
+	configPath := install.LocalConfigPath("1")
+
+	// Cut the file off inside 730's quoted launch options, as a crash mid-write would
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	damaged := data[:bytes.Index(data, []byte(`"-high"`))+3]
+	if err := os.WriteFile(configPath, damaged, 0644); err != nil {
+		t.Fatalf("Failed to write damaged config: %v", err)
+	}
+
+	result, err := RepairLocalConfig(configPath, "")
+	if err != nil {
+		t.Fatalf("RepairLocalConfig() error = %v", err)
+	}
+	if err := CheckLocalConfig(configPath); err != nil {
+		t.Errorf("CheckLocalConfig() after repair = %v", err)
+	}
+	values, err := GetAppValues(configPath, KeyLaunchOptions)
+	if err != nil {
+		t.Fatalf("GetAppValues() error = %v", err)
+	}
+	if values["570"] != "-novid" || values["730"] != "" || result.AppCount != 2 {
+		t.Errorf("RepairLocalConfig() kept %v (%d apps), want 570's options and 730 without its cut-off value", values, result.AppCount)
+	}
+}
+
+func TestProtonToolName(t *testing.T) {
+	tests := map[string]string{
+		"Proton 9.0":                   "proton_9",
+		"Proton 5.13":                  "proton_513",
+		"Proton 6.3":                   "proton_63",
+		"Proton Experimental":          "proton_experimental",
+		"Proton - Experimental":        "proton_experimental",
+		"Proton Hotfix":                "proton_hotfix",
+		"Proton EasyAntiCheat Runtime": "",
+		"Steam Linux Runtime 3.0":      "",
+		"Counter-Strike 2":             "",
+	}
+	for name, want := range tests {
+		if got := protonToolName(name); got != want {
+			t.Errorf("protonToolName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestFindCompatTools(t *testing.T) {
+	saved := systemCompatToolDirs
+	systemCompatToolDirs = nil
+	defer func() { systemCompatToolDirs = saved }()
+
+	install := steamtest.New(t, steamtest.Spec{
+		Games: []steamtest.Game{
+			{AppID: "2805730", Name: "Proton 9.0", InstallDir: "Proton 9.0"},
+			{AppID: "1826330", Name: "Proton EasyAntiCheat Runtime", InstallDir: "Proton EasyAntiCheat Runtime"},
+			{AppID: "730", Name: "Counter-Strike 2", InstallDir: "Counter-Strike Global Offensive"},
+		},
+		Libraries: [][]steamtest.Game{{
+			{AppID: "1493710", Name: "Proton - Experimental", InstallDir: "Proton - Experimental"},
+		}},
+	})
+	toolDir := filepath.Join(install.Path, "compatibilitytools.d", "GE-Proton9-20")
+	if err := os.MkdirAll(toolDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := "\"compatibilitytools\"\n{\n\t\"compat_tools\"\n\t{\n\t\t\"GE-Proton9-20\"\n\t\t{\n\t\t\t\"install_path\"\t\".\"\n\t\t\t\"display_name\"\t\"GE-Proton9-20\"\n\t\t}\n\t}\n}\n"
+	if err := os.WriteFile(filepath.Join(toolDir, "compatibilitytool.vdf"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tools, err := FindCompatTools(install.Path)
+	if err != nil {
+		t.Fatalf("FindCompatTools() error = %v", err)
+	}
+	want := []CompatTool{
+		{Name: "GE-Proton9-20", DisplayName: "GE-Proton9-20", Custom: true, Path: toolDir},
+		{Name: "proton_9", DisplayName: "Proton 9.0", Path: filepath.Join(install.Path, "steamapps", "common", "Proton 9.0")},
+		{Name: "proton_experimental", DisplayName: "Proton - Experimental", Path: filepath.Join(install.LibraryPath(0), "steamapps", "common", "Proton - Experimental")},
+	}
+	if !reflect.DeepEqual(tools, want) {
+		t.Errorf("FindCompatTools() = %+v, want %+v", tools, want)
+	}
+}
+
+func TestUpdaterRun(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{Users: []steamtest.User{
+		{ID: "111", Apps: []steamtest.App{{AppID: "570"}, {AppID: "730", LaunchOptions: "-high"}}},
+	}})
+
+	for _, dryRun := range []bool{false, true} {
+		closed, restarted := 0, 0
+		updater := &Updater{
+			SteamPath: install.Path,
+			UserID:    "111",
+			Key:       KeyLaunchOptions,
+			DryRun:    dryRun,
+			Options:   UpdateOptions{SkipBackup: true},
+			Select: func(p *PreparedUpdate) ([]AppValue, error) {
+				return []AppValue{{AppID: "570", Value: "-novid"}}, nil
+			},
+			CloseSteam:   func() (bool, error) { closed++; return true, nil },
+			RestartSteam: func() { restarted++ },
+		}
+
+		p, result, err := updater.Run()
+		if err != nil {
+			t.Fatalf("Run(dry run %v) error = %v", dryRun, err)
+		}
+		if p.LocalConfigPath != install.LocalConfigPath("111") || len(p.Values) != 1 {
+			t.Errorf("Run(dry run %v) prepared %+v", dryRun, p)
+		}
+		values, err := GetAppValues(p.LocalConfigPath, KeyLaunchOptions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dryRun {
+			if result != nil || closed != 0 || restarted != 0 {
+				t.Errorf("dry run: result %v, closed %d, restarted %d, want nothing done", result, closed, restarted)
+			}
+			continue
+		}
+		if result == nil || result.Count(UpdateChanged) != 1 || values["570"] != "-novid" || values["730"] != "-high" {
+			t.Errorf("Run() result %+v, values %v, want 570 changed", result, values)
+		}
+		if closed != 1 || restarted != 1 {
+			t.Errorf("Run() closed Steam %d time(s) and restarted it %d, want once each", closed, restarted)
+		}
+	}
+
+	// Apply replaces the localconfig write, and Steam is restarted when it fails
+	restarted := 0
+	var applied []AppValue
+	updater := &Updater{
+		SteamPath: install.Path,
+		UserID:    "111",
+		Select: func(p *PreparedUpdate) ([]AppValue, error) {
+			return []AppValue{{AppID: "730", Value: "beta"}}, nil
+		},
+		Apply: func(values []AppValue) (*UpdateResult, error) {
+			applied = values
+			return nil, errors.New("appmanifest is read-only")
+		},
+		CloseSteam:   func() (bool, error) { return true, nil },
+		RestartSteam: func() { restarted++ },
+	}
+	if _, _, err := updater.Run(); err == nil {
+		t.Error("Run() with a failing Apply succeeded")
+	}
+	values, err := GetAppValues(install.LocalConfigPath("111"), KeyLaunchOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 1 || applied[0].AppID != "730" || values["730"] != "-high" || restarted != 1 {
+		t.Errorf("Run() with Apply: applied %v, localconfig values %v, restarted %d", applied, values, restarted)
+	}
+}
+
+func TestFamilySharedBy(t *testing.T) {
+	tests := []struct {
+		lastOwner, userID, want string
+	}{
+		{"76561197960265839", "111", ""},
+		{"76561197960265950", "111", "222"},
+		{"0", "111", ""},
+		{"", "111", ""},
+	}
+	for _, tt := range tests {
+		if got := FamilySharedBy(tt.lastOwner, tt.userID); got != tt.want {
+			t.Errorf("FamilySharedBy(%q, %q) = %q, want %q", tt.lastOwner, tt.userID, got, tt.want)
+		}
+	}
+
+	install := steamtest.New(t, steamtest.Spec{
+		Users: []steamtest.User{{ID: "111", Apps: []steamtest.App{{AppID: "570"}, {AppID: "730"}}}},
+		Games: []steamtest.Game{
+			{AppID: "570", Name: "Dota 2", LastOwner: "76561197960265839"},
+			{AppID: "730", Name: "Counter-Strike 2", LastOwner: "76561197960265950"},
+		},
+	})
+	games, err := GetAllGames(install.Path, install.LocalConfigPath("111"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, game := range games {
+		if want := map[string]string{"730": "222"}[game.AppID]; game.SharedBy != want {
+			t.Errorf("GetAllGames() %s shared by %q, want %q", game.AppID, game.SharedBy, want)
+		}
+	}
+}
+
+func TestInstalledGamesMissingFromLocalConfig(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{
+		Users: []steamtest.User{{ID: "111", Apps: []steamtest.App{{AppID: "570", LaunchOptions: "-novid"}, {AppID: "440"}}}},
+		Games: []steamtest.Game{
+			{AppID: "570", Name: "Dota 2"},
+			{AppID: "730", Name: "Counter-Strike 2"},
+			{AppID: "1493710", Name: "Proton Experimental"},
+		},
+	})
+	library, err := LoadLibrary(install.Path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	localConfigIDs, err := GetAllGameIDs(install.LocalConfigPath("111"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := AddInstalledGameIDs(localConfigIDs, library)
+	if want := []string{"570", "440", "730"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AddInstalledGameIDs() = %v, want %v", got, want)
+	}
+
+	games, err := GetAllGames(install.Path, install.LocalConfigPath("111"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, game := range games {
+		names = append(names, game.Name)
+	}
+	if want := []string{"Dota 2", "440", "Counter-Strike 2", "Proton Experimental"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("GetAllGames() = %v, want %v", names, want)
+	}
+
+	// Writing a value creates the game's entry
+	if _, err := SetAppValues(install.LocalConfigPath("111"), KeyLaunchOptions, []AppValue{{AppID: "730", Value: "-high"}}, UpdateOptions{SkipBackup: true, Surgical: true}); err != nil {
+		t.Fatalf("SetAppValues() error = %v", err)
+	}
+	values, err := GetAppValues(install.LocalConfigPath("111"), KeyLaunchOptions)
+	if err != nil || values["730"] != "-high" || values["570"] != "-novid" {
+		t.Errorf("GetAppValues() = %v, %v, want 730 set", values, err)
+	}
+}
+
+func TestSessionReadsFilesOnce(t *testing.T) {
+	install := steamtest.New(t, steamtest.Spec{
+		Users: []steamtest.User{
+			{ID: "111", Apps: []steamtest.App{{AppID: "570", LaunchOptions: "-novid"}}},
+			{ID: "222", Apps: []steamtest.App{{AppID: "730"}}},
+		},
+		Games: []steamtest.Game{{AppID: "570", Name: "Dota 2"}},
+	})
+
+	scans := 0
+	session := NewSession(install.Path, install.LocalConfigPath("111"))
+	session.Progress = func(done, total int) {
+		if done == 1 {
+			scans++
+		}
+	}
+	library, err := session.Library()
+	if err != nil {
+		t.Fatal(err)
+	}
+	games, err := session.Games()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := session.ForAccount(install.LocalConfigPath("222"))
+	otherLibrary, err := other.Library()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scans != 1 || otherLibrary != library {
+		t.Errorf("Session scanned the appmanifests %d times, want once for both accounts", scans)
+	}
+	if len(games) != 1 || games[0].Name != "Dota 2" || games[0].LaunchOptions != "-novid" {
+		t.Errorf("Session.Games() = %+v, want Dota 2 with its launch options", games)
+	}
+
+	// Later steps get what was parsed, even if the file is gone
+	if err := os.Remove(install.LocalConfigPath("111")); err != nil {
+		t.Fatal(err)
+	}
+	if ids, err := session.GameIDs(); err != nil || !reflect.DeepEqual(ids, []string{"570"}) {
+		t.Errorf("Session.GameIDs() = %v, %v, want [570]", ids, err)
+	}
+	if ids, err := other.GameIDs(); err != nil || !reflect.DeepEqual(ids, []string{"730"}) {
+		t.Errorf("ForAccount().GameIDs() = %v, %v, want [730]", ids, err)
+	}
+}
@@ -0,0 +1,329 @@
+// Package steamtest builds fake Steam installations for tests
+package steamtest
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/zerkz/gsca/vdf"
+)
+
+// Spec declares the contents of a fake Steam installation
+type Spec struct {
+	// Users each get a userdata/<id>/config/localconfig.vdf
+	Users []User
+	// Games are installed in the main library (<steam path>/steamapps)
+	Games []Game
+	// Libraries are additional library folders, created under <steam path>/libraries/<n>
+	Libraries [][]Game
+	// CompatTools maps app IDs to compatibility tool names in config/config.vdf
+	CompatTools map[string]string
+	// AppInfo entries are written to appcache/appinfo.vdf
+	AppInfo []AppInfo
+}
+
+// User is a Steam account with per-app entries in localconfig.vdf and sharedconfig.vdf
+type User struct {
+	ID   string
+	Apps []App
+}
+
+// App is an entry under the apps node of localconfig.vdf
+type App struct {
+	AppID           string
+	LaunchOptions   string
+	LastPlayed      int64
+	PlaytimeMinutes int
+
+	// Tags are collection tags written to sharedconfig.vdf (e.g. "favorite")
+	Tags []string
+	// Hidden marks the app as hidden in sharedconfig.vdf
+	Hidden bool
+}
+
+// AppInfo is an entry in the binary appinfo cache
+type AppInfo struct {
+	AppID string
+	// Common holds key/values for the entry's "common" section; nested objects use "/"
+	// in the key (e.g. "category/category_54": "1")
+	Common map[string]string
+}
+
+// Game is an installed game with an appmanifest file
+type Game struct {
+	AppID      string
+	Name       string
+	InstallDir string
+	SizeOnDisk int64
+	BuildID    string
+	// StateFlags is written to the manifest if non-zero (4 is fully installed)
+	StateFlags int
+	// LastOwner is the SteamID64 of the account whose license the install uses
+	LastOwner string
+}
+
+// Install is a fake Steam installation on disk
+type Install struct {
+	Path string
+}
+
+// New builds spec in a temporary directory that is removed when the test ends
+func New(t testing.TB, spec Spec) *Install {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := Build(dir, spec); err != nil {
+		t.Fatalf("steamtest: %v", err)
+	}
+
+	return &Install{Path: dir}
+}
+
+// LocalConfigPath returns the path to a user's localconfig.vdf
+func (i *Install) LocalConfigPath(userID string) string {
+	return filepath.Join(i.Path, "userdata", userID, "config", "localconfig.vdf")
+}
+
+// SharedConfigPath returns the path to a user's sharedconfig.vdf
+func (i *Install) SharedConfigPath(userID string) string {
+	return filepath.Join(i.Path, "userdata", userID, "7", "remote", "sharedconfig.vdf")
+}
+
+// LibraryPath returns the path of the nth entry in Spec.Libraries
+func (i *Install) LibraryPath(n int) string {
+	return filepath.Join(i.Path, "libraries", strconv.Itoa(n))
+}
+
+// Build creates the installation described by spec under steamPath
+func Build(steamPath string, spec Spec) error {
+	install := &Install{Path: steamPath}
+
+	// Main library first, then any extra libraries
+	libraries := []string{steamPath}
+	if err := writeManifests(steamPath, spec.Games); err != nil {
+		return err
+	}
+	for n, games := range spec.Libraries {
+		libraryPath := install.LibraryPath(n)
+		if err := writeManifests(libraryPath, games); err != nil {
+			return err
+		}
+		libraries = append(libraries, libraryPath)
+	}
+
+	folders := obj("libraryfolders")
+	gamesByLibrary := append([][]Game{spec.Games}, spec.Libraries...)
+	for n, libraryPath := range libraries {
+		apps := obj("apps")
+		for _, game := range gamesByLibrary[n] {
+			apps.Children = append(apps.Children, kv(game.AppID, strconv.FormatInt(game.SizeOnDisk, 10)))
+		}
+		folders.Children = append(folders.Children, obj(strconv.Itoa(n), kv("path", libraryPath), apps))
+	}
+	if err := writeVDF(filepath.Join(steamPath, "steamapps", "libraryfolders.vdf"), folders); err != nil {
+		return err
+	}
+
+	if len(spec.CompatTools) > 0 {
+		appIDs := make([]string, 0, len(spec.CompatTools))
+		for appID := range spec.CompatTools {
+			appIDs = append(appIDs, appID)
+		}
+		sort.Strings(appIDs)
+
+		mapping := obj("CompatToolMapping")
+		for _, appID := range appIDs {
+			mapping.Children = append(mapping.Children, obj(appID, kv("name", spec.CompatTools[appID])))
+		}
+		root := obj("InstallConfigStore", obj("Software", obj("Valve", obj("Steam", mapping))))
+		if err := writeVDF(filepath.Join(steamPath, "config", "config.vdf"), root); err != nil {
+			return err
+		}
+	}
+
+	if len(spec.AppInfo) > 0 {
+		if err := writeAppInfo(filepath.Join(steamPath, "appcache", "appinfo.vdf"), spec.AppInfo); err != nil {
+			return err
+		}
+	}
+
+	for _, user := range spec.Users {
+		apps := obj("apps")
+		for _, app := range user.Apps {
+			appNode := obj(app.AppID)
+			if app.LastPlayed != 0 {
+				appNode.Children = append(appNode.Children, kv("LastPlayed", strconv.FormatInt(app.LastPlayed, 10)))
+			}
+			if app.PlaytimeMinutes != 0 {
+				appNode.Children = append(appNode.Children, kv("Playtime", strconv.Itoa(app.PlaytimeMinutes)))
+			}
+			if app.LaunchOptions != "" {
+				appNode.Children = append(appNode.Children, kv("LaunchOptions", app.LaunchOptions))
+			}
+			apps.Children = append(apps.Children, appNode)
+		}
+		root := obj("UserLocalConfigStore", obj("Software", obj("Valve", obj("Steam", apps))))
+		if err := writeVDF(install.LocalConfigPath(user.ID), root); err != nil {
+			return err
+		}
+
+		if err := writeSharedConfig(install.SharedConfigPath(user.ID), user.Apps); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeSharedConfig writes collection tags and hidden flags, skipping the file if there are none
+func writeSharedConfig(path string, apps []App) error {
+	sharedApps := obj("apps")
+	for _, app := range apps {
+		if len(app.Tags) == 0 && !app.Hidden {
+			continue
+		}
+
+		appNode := obj(app.AppID)
+		if len(app.Tags) > 0 {
+			tags := obj("tags")
+			for n, tag := range app.Tags {
+				tags.Children = append(tags.Children, kv(strconv.Itoa(n), tag))
+			}
+			appNode.Children = append(appNode.Children, tags)
+		}
+		if app.Hidden {
+			appNode.Children = append(appNode.Children, kv("Hidden", "1"))
+		}
+		sharedApps.Children = append(sharedApps.Children, appNode)
+	}
+
+	if len(sharedApps.Children) == 0 {
+		return nil
+	}
+
+	root := obj("UserRoamingConfigStore", obj("Software", obj("Valve", obj("Steam", sharedApps))))
+	return writeVDF(path, root)
+}
+
+// writeAppInfo writes entries in the appinfo.vdf v29 format (keys in a trailing string table)
+func writeAppInfo(path string, entries []AppInfo) error {
+	const magicV29 = 0x07564429
+	const entryHeaderSize = 4 + 4 + 8 + 20 + 4 + 20
+
+	buf := binary.LittleEndian.AppendUint32(nil, magicV29)
+	buf = binary.LittleEndian.AppendUint32(buf, 1) // universe
+	buf = binary.LittleEndian.AppendUint64(buf, 0) // string table offset, patched below
+
+	var keys []string
+	for _, entry := range entries {
+		root := &vdf.Node{IsObject: true}
+		if err := vdf.SetValue(root, "appinfo/appid", entry.AppID); err != nil {
+			return err
+		}
+
+		commonKeys := make([]string, 0, len(entry.Common))
+		for key := range entry.Common {
+			commonKeys = append(commonKeys, key)
+		}
+		sort.Strings(commonKeys)
+		for _, key := range commonKeys {
+			if err := vdf.SetValue(root, "appinfo/common/"+key, entry.Common[key]); err != nil {
+				return err
+			}
+		}
+
+		appID, err := strconv.ParseUint(entry.AppID, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid app ID %q: %w", entry.AppID, err)
+		}
+		kv := vdf.AppendBinary(nil, root, &keys)
+
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(appID))
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(entryHeaderSize+len(kv)))
+		buf = append(buf, make([]byte, entryHeaderSize)...)
+		buf = append(buf, kv...)
+	}
+	buf = binary.LittleEndian.AppendUint32(buf, 0)
+
+	binary.LittleEndian.PutUint64(buf[8:], uint64(len(buf)))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(keys)))
+	for _, key := range keys {
+		buf = append(append(buf, key...), 0)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf, 0644)
+}
+
+// writeManifests writes an appmanifest file for each game in a library
+func writeManifests(libraryPath string, games []Game) error {
+	steamappsPath := filepath.Join(libraryPath, "steamapps")
+	if err := os.MkdirAll(steamappsPath, 0755); err != nil {
+		return err
+	}
+
+	for _, game := range games {
+		appState := obj("AppState",
+			kv("appid", game.AppID),
+			kv("name", game.Name),
+		)
+		if game.InstallDir != "" {
+			appState.Children = append(appState.Children, kv("installdir", game.InstallDir))
+		}
+		if game.SizeOnDisk != 0 {
+			appState.Children = append(appState.Children, kv("SizeOnDisk", strconv.FormatInt(game.SizeOnDisk, 10)))
+		}
+		if game.BuildID != "" {
+			appState.Children = append(appState.Children, kv("buildid", game.BuildID))
+		}
+		if game.StateFlags != 0 {
+			appState.Children = append(appState.Children, kv("StateFlags", strconv.Itoa(game.StateFlags)))
+		}
+		if game.LastOwner != "" {
+			appState.Children = append(appState.Children, kv("LastOwner", game.LastOwner))
+		}
+
+		path := filepath.Join(steamappsPath, fmt.Sprintf("appmanifest_%s.acf", game.AppID))
+		if err := writeVDF(path, appState); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeVDF writes a single top-level node to path, creating parent directories
+func writeVDF(path string, node *vdf.Node) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	writer := bufio.NewWriter(f)
+	if err := vdf.Write(writer, &vdf.Node{IsObject: true, Children: []*vdf.Node{node}}, 0); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return writer.Flush()
+}
+
+func obj(key string, children ...*vdf.Node) *vdf.Node {
+	return &vdf.Node{Key: key, IsObject: true, Children: children}
+}
+
+func kv(key, value string) *vdf.Node {
+	return &vdf.Node{Key: key, Value: value}
+}
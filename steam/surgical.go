@@ -0,0 +1,141 @@
+package steam
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zerkz/gsca/vdf"
+)
+
+// spliceAppValues rewrites only the values of key for the given apps in data, leaving every
+// other byte untouched. root must be the tree parsed from data, with the new values already
+// applied via vdf.SetValuePath. Missing entries are inserted as new lines
+func spliceAppValues(data []byte, root *vdf.Node, key string, appIDs []string) ([]byte, error) {
+	appsNode := vdf.FindNode(root, appsPath)
+	if appsNode == nil || appsNode.Line == 0 || appsNode.EndLine == 0 {
+		return nil, fmt.Errorf("apps node not found in localconfig.vdf")
+	}
+
+	newline := "\n"
+	if bytes.Contains(data, []byte("\r\n")) {
+		newline = "\r\n"
+	}
+
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	var edits []splice
+
+	// Every copy of an app is spliced, as it was updated in the tree
+	for _, match := range appMatches(appsNode, appIDs) {
+		appID, appNode := match.Node.Key, match.Node
+
+		// App didn't exist before - add a whole block before the apps closing brace
+		if appNode.Line == 0 {
+			at, err := lineBeforeBrace(data, appsNode)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: can't add apps: %w", appsNode.EndLine, err)
+			}
+			indent := childIndent(lines, appsNode)
+			step := strings.TrimPrefix(indent, leadingWhitespace(lines[appsNode.EndLine-1]))
+			if step == "" || step == indent {
+				step = "\t"
+			}
+			block := indent + quote(appID) + newline + indent + "{" + newline
+			for _, child := range appNode.Children {
+				block += indent + step + quote(child.Key) + "\t\t" + quote(child.Value) + newline
+			}
+			block += indent + "}" + newline
+			edits = append(edits, splice{start: at, end: at, text: block})
+			continue
+		}
+
+		for _, child := range appNode.Children {
+			if !vdf.KeyEqual(child.Key, key) {
+				continue
+			}
+
+			if child.Line > 0 {
+				// Existing value: swap just its token, wherever it is on the line
+				if child.ValueEnd == 0 {
+					return nil, fmt.Errorf("line %d: %s has no value to replace", child.Line, child.Key)
+				}
+				edits = append(edits, splice{start: child.ValueStart, end: child.ValueEnd, text: quote(child.Value)})
+			} else {
+				// New key in an existing app - add it before the app's closing brace
+				at, err := lineBeforeBrace(data, appNode)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: can't add %s: %w", appNode.Line, key, err)
+				}
+				indent := childIndent(lines, appNode)
+				edits = append(edits, splice{start: at, end: at, text: indent + quote(child.Key) + "\t\t" + quote(child.Value) + newline})
+			}
+		}
+	}
+
+	// Stable, so blocks inserted at the same place keep their order
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	var out bytes.Buffer
+	out.Grow(len(data))
+	last := 0
+	for _, e := range edits {
+		out.Write(data[last:e.start])
+		out.WriteString(e.text)
+		last = e.end
+	}
+	out.Write(data[last:])
+
+	return out.Bytes(), nil
+}
+
+// splice replaces data[start:end] with text
+type splice struct {
+	start int
+	end   int
+	text  string
+}
+
+// lineBeforeBrace returns the offset of the start of the line holding an object's closing
+// brace, where new children can be inserted. The brace must be alone on its line: anything
+// before it (a one-line object, or the object's last value) leaves no line to insert before
+func lineBeforeBrace(data []byte, node *vdf.Node) (int, error) {
+	if node.EndOffset == 0 || node.EndLine == node.Line {
+		return 0, fmt.Errorf("it's a one-line object")
+	}
+	lineStart := bytes.LastIndexByte(data[:node.EndOffset], '\n') + 1
+	if len(bytes.TrimSpace(data[lineStart:node.EndOffset])) > 0 {
+		return 0, fmt.Errorf("its closing brace shares a line with other text")
+	}
+	return lineStart, nil
+}
+
+// appMatches returns every node under apps for the given app IDs
+func appMatches(appsNode *vdf.Node, appIDs []string) []vdf.Match {
+	var matches []vdf.Match
+	for _, appID := range appIDs {
+		matches = append(matches, vdf.FindNodesPath(appsNode, []string{appID})...)
+	}
+	return matches
+}
+
+// childIndent returns the indentation used by an object's existing children,
+// falling back to one tab deeper than its closing brace
+func childIndent(lines [][]byte, node *vdf.Node) string {
+	for _, child := range node.Children {
+		if child.Line > 0 {
+			return leadingWhitespace(lines[child.Line-1])
+		}
+	}
+	return leadingWhitespace(lines[node.EndLine-1]) + "\t"
+}
+
+// leadingWhitespace returns the indentation at the start of a line
+func leadingWhitespace(line []byte) string {
+	text := string(line)
+	return text[:len(text)-len(strings.TrimLeft(text, " \t"))]
+}
+
+// quote writes s as a quoted VDF string
+func quote(s string) string {
+	return `"` + vdf.Escape(s) + `"`
+}
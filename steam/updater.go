@@ -0,0 +1,202 @@
+package steam
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Updater runs the workflow that sets a localconfig.vdf value for a selection of games: make
+// sure Steam is closed, find the install and account, load the library, select the games,
+// then write their values and restart Steam if it was closed. gsca's update commands use it,
+// and front-ends can drive the same workflow through its options and hooks.
+//
+// Run does it all at once. Callers that review or report the changes between the steps call
+// Start, Prepare, Write, and Finish themselves
+type Updater struct {
+	// SteamPath and UserID are detected when empty, and set once known
+	SteamPath string
+	UserID    string
+	// Key is the localconfig.vdf key to set, e.g. KeyLaunchOptions
+	Key string
+	// Select picks the games to update and their values
+	Select func(p *PreparedUpdate) ([]AppValue, error)
+	// DryRun leaves Steam running and files unchanged; Run stops after Prepare
+	DryRun  bool
+	Options UpdateOptions
+	// Apply writes the values instead of setting Key in localconfig.vdf, for settings stored
+	// elsewhere such as appmanifests. Write's check for changes to localconfig.vdf is skipped
+	Apply func(values []AppValue) (*UpdateResult, error)
+
+	// DetectSteamPath and DetectUserID find the install and account when SteamPath and
+	// UserID are empty; GetSteamPath and GetUserID are used if they are nil
+	DetectSteamPath func() (string, error)
+	DetectUserID    func(steamPath string) (string, error)
+	// CloseSteam closes Steam if it's running (e.g. after asking), reporting whether it did;
+	// nil closes a running Steam without asking
+	CloseSteam func() (bool, error)
+	// RestartSteam starts Steam again after a write that closed it; nil starts it with
+	// StartSteam
+	RestartSteam func()
+	// Progress reports progress loading the library; it may be nil
+	Progress ProgressFunc
+	// Log prints a line of progress; nil discards it
+	Log func(format string, args ...any)
+
+	closedSteam bool
+}
+
+// PreparedUpdate is what Prepare found: where the account's settings are, the library, and
+// the values to write
+type PreparedUpdate struct {
+	LocalConfigPath string
+	Library         *Library
+	// Session holds the install and account files already read, for Select to reuse
+	Session *Session
+	Values  []AppValue
+	// Fingerprint is localconfig.vdf's state before selection, to detect changes made
+	// by other programs before the write
+	Fingerprint Fingerprint
+}
+
+// Run updates the selected games: Start, Prepare, Write, and Finish. It returns no result
+// for dry runs
+func (u *Updater) Run() (*PreparedUpdate, *UpdateResult, error) {
+	if err := u.Start(); err != nil {
+		return nil, nil, err
+	}
+	p, err := u.Prepare()
+	if err != nil || u.DryRun {
+		u.Finish()
+		return p, nil, err
+	}
+	result, err := u.Write(p)
+	u.Finish()
+	return p, result, err
+}
+
+// Start closes Steam, unless this is a dry run, and finds the Steam install
+func (u *Updater) Start() error {
+	if !u.DryRun {
+		closeSteam := u.CloseSteam
+		if closeSteam == nil {
+			closeSteam = closeRunningSteam
+		}
+		closed, err := closeSteam()
+		if err != nil {
+			return err
+		}
+		u.closedSteam = closed
+	}
+
+	if u.SteamPath == "" {
+		detect := u.DetectSteamPath
+		if detect == nil {
+			detect = GetSteamPath
+		}
+		path, err := detect()
+		if err != nil {
+			u.Finish()
+			return fmt.Errorf("failed to detect Steam path: %w", err)
+		}
+		u.SteamPath = path
+	}
+	u.log("Steam path: %s", u.SteamPath)
+	return nil
+}
+
+// Prepare finds the account, loads the library, and selects the games to update
+func (u *Updater) Prepare() (*PreparedUpdate, error) {
+	if u.UserID == "" {
+		detect := u.DetectUserID
+		if detect == nil {
+			detect = GetUserID
+		}
+		id, err := detect(u.SteamPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect user ID: %w", err)
+		}
+		u.UserID = id
+	}
+	u.log("User ID: %s", u.UserID)
+
+	p := &PreparedUpdate{LocalConfigPath: GetLocalConfigPath(u.SteamPath, u.UserID)}
+	u.log("Local config: %s", p.LocalConfigPath)
+	p.Session = NewSession(u.SteamPath, p.LocalConfigPath)
+	p.Session.Progress = u.Progress
+
+	u.log("Loading game library...")
+	var err error
+	if p.Library, err = p.Session.Library(); err != nil {
+		return nil, fmt.Errorf("failed to load game library: %w", err)
+	}
+	u.log("Found %d games", len(p.Library.All()))
+	for _, offline := range OfflineLibraries(u.SteamPath) {
+		u.log("Warning: Skipped library %s (not mounted or not responding); its %d game(s) are unavailable", offline.Path, len(offline.AppIDs))
+	}
+
+	if p.Fingerprint, err = FingerprintFile(p.LocalConfigPath); err != nil {
+		return nil, fmt.Errorf("failed to read localconfig.vdf: %w", err)
+	}
+	if u.Select == nil {
+		return nil, errors.New("no games selected")
+	}
+	if p.Values, err = u.Select(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Preview returns the changes writing p's values would make, without writing them
+func (u *Updater) Preview(p *PreparedUpdate) (*AppValuesPreview, error) {
+	return PreviewAppValues(p.LocalConfigPath, u.Key, p.Values, u.Options)
+}
+
+// Write sets p's values, unless localconfig.vdf changed since Prepare read it
+func (u *Updater) Write(p *PreparedUpdate) (*UpdateResult, error) {
+	if u.Apply != nil {
+		return u.Apply(p.Values)
+	}
+	if current, err := FingerprintFile(p.LocalConfigPath); err == nil && !current.Equal(p.Fingerprint) {
+		return nil, fmt.Errorf("%w - make sure Steam is closed and run the command again", ErrConfigModified)
+	}
+	return SetAppValues(p.LocalConfigPath, u.Key, p.Values, u.Options)
+}
+
+// Finish restarts Steam if Start closed it. It only does so once
+func (u *Updater) Finish() {
+	if !u.closedSteam {
+		return
+	}
+	u.closedSteam = false
+	if u.RestartSteam != nil {
+		u.RestartSteam()
+		return
+	}
+	if err := StartSteamWith(StartOptions{SteamPath: u.SteamPath}); err != nil {
+		u.log("Failed to start Steam: %v", err)
+	}
+}
+
+func (u *Updater) log(format string, args ...any) {
+	if u.Log != nil {
+		u.Log(format, args...)
+	}
+}
+
+// closeRunningSteam closes Steam if it's running and waits up to 10 seconds for it to exit
+func closeRunningSteam() (bool, error) {
+	if running, err := IsSteamRunning(); err != nil || !running {
+		return false, nil
+	}
+	if err := CloseSteam(); err != nil {
+		return false, fmt.Errorf("failed to close Steam: %w", err)
+	}
+	for i := 0; i < 10; i++ {
+		time.Sleep(time.Second)
+		if running, _ := IsSteamRunning(); !running {
+			return true, nil
+		}
+	}
+	return true, errors.New("timed out waiting for Steam to exit")
+}
@@ -0,0 +1,106 @@
+package steam
+
+import (
+	"fmt"
+
+	"github.com/zerkz/gsca/vdf"
+)
+
+const keyUserConfig = "UserConfig"
+
+// setUserConfigValue sets key in the UserConfig section of each game's appmanifest
+// Setting removeValue (if non-empty) deletes the key instead, restoring Steam's default
+// Each changed manifest is backed up next to itself unless opts.SkipBackup is set
+// Games that aren't installed are reported as failed; opts.Surgical is ignored
+func setUserConfigValue(steamPath string, appIDs []string, key, value, removeValue string, opts UpdateOptions) (*UpdateResult, error) {
+	manifests, err := scanAppManifests(steamPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	manifestPaths := make(map[string]string)
+	for _, m := range manifests {
+		if _, exists := manifestPaths[m.appID]; !exists {
+			manifestPaths[m.appID] = m.path
+		}
+	}
+
+	result := &UpdateResult{}
+	for i, appID := range appIDs {
+		app := AppUpdateResult{AppID: appID, NewValue: value}
+
+		if path, ok := manifestPaths[appID]; !ok {
+			app.Status = UpdateFailed
+			app.Err = fmt.Errorf("app %s is not installed", appID)
+		} else if err := setManifestUserConfig(path, key, value, removeValue, opts.SkipBackup, &app); err != nil {
+			app.Status = UpdateFailed
+			app.Err = err
+		}
+
+		result.Apps = append(result.Apps, app)
+		opts.Progress.report(i+1, len(appIDs))
+	}
+
+	return result, nil
+}
+
+// setManifestUserConfig sets UserConfig/<key> in one appmanifest, filling in app's
+// previous value, status, and backup path. A missing key reads as removeValue
+func setManifestUserConfig(path, key, value, removeValue string, skipBackup bool, app *AppUpdateResult) error {
+	root, err := readVDFFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	appState := vdf.FindNode(root, appStateKey)
+	if appState == nil {
+		return fmt.Errorf("%s has no %s section", path, appStateKey)
+	}
+
+	userConfig := vdf.FindNode(appState, keyUserConfig)
+	if userConfig == nil {
+		userConfig = &vdf.Node{Key: keyUserConfig, IsObject: true}
+		appState.Children = append(appState.Children, userConfig)
+	}
+
+	// Key case varies between manifest versions (e.g. "betakey" vs "BetaKey")
+	idx := -1
+	for i, child := range userConfig.Children {
+		if vdf.KeyEqual(child.Key, key) {
+			idx = i
+			break
+		}
+	}
+
+	app.PreviousValue = removeValue
+	if idx >= 0 && userConfig.Children[idx].Value != "" {
+		app.PreviousValue = userConfig.Children[idx].Value
+	}
+	if app.PreviousValue == value {
+		app.Status = UpdateUnchanged
+		return nil
+	}
+
+	switch {
+	case removeValue != "" && value == removeValue:
+		userConfig.Children = append(userConfig.Children[:idx], userConfig.Children[idx+1:]...)
+	case idx >= 0:
+		userConfig.Children[idx].Value = value
+	default:
+		userConfig.Children = append(userConfig.Children, &vdf.Node{Key: key, Value: value})
+	}
+
+	if !skipBackup {
+		backupPath, err := createBackup(path)
+		if err != nil {
+			return fmt.Errorf("failed to create backup: %w", err)
+		}
+		app.BackupPath = backupPath
+	}
+
+	if err := trackWrite(path, func() error { return writeVDFFile(path, root) }); err != nil {
+		return err
+	}
+
+	app.Status = UpdateChanged
+	return nil
+}
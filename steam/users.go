@@ -0,0 +1,115 @@
+package steam
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/zerkz/gsca/vdf"
+)
+
+// steamID64Base is the SteamID64 of account ID 0; userdata directories are named by account ID
+const steamID64Base = 76561197960265728
+
+// User is a Steam account with a userdata directory
+type User struct {
+	// ID is the account ID, the name of the userdata directory
+	ID string
+	// AccountName and PersonaName come from loginusers.vdf and are empty for accounts that
+	// aren't remembered there
+	AccountName string
+	PersonaName string
+	// MostRecent is set for the account that signed in last
+	MostRecent bool
+}
+
+// Label returns a readable name for the account, e.g. "Gabe (gaben, 22202)"
+func (u User) Label() string {
+	switch {
+	case u.PersonaName != "" && u.AccountName != "":
+		return fmt.Sprintf("%s (%s, %s)", u.PersonaName, u.AccountName, u.ID)
+	case u.PersonaName != "" || u.AccountName != "":
+		return fmt.Sprintf("%s (%s)", u.PersonaName+u.AccountName, u.ID)
+	}
+	return u.ID
+}
+
+// GetLoginUsersPath returns the path to loginusers.vdf, the accounts remembered by Steam
+func GetLoginUsersPath(steamPath string) string {
+	return filepath.Join(steamPath, "config", "loginusers.vdf")
+}
+
+// ListUsers returns the accounts with a userdata directory, the most recently modified first
+// (the one GetUserID picks). Names are filled in from loginusers.vdf where it knows them
+func ListUsers(steamPath string) ([]User, error) {
+	entries, err := os.ReadDir(filepath.Join(steamPath, "userdata"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read userdata directory: %w", err)
+	}
+
+	logins := loadLoginUsers(steamPath)
+	var users []User
+	modTimes := make(map[string]int64)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := strconv.ParseUint(entry.Name(), 10, 32); err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		modTimes[entry.Name()] = info.ModTime().UnixNano()
+
+		user := User{ID: entry.Name()}
+		if login, ok := logins[user.ID]; ok {
+			user = login
+		}
+		users = append(users, user)
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("no valid user ID found in userdata directory")
+	}
+
+	sort.SliceStable(users, func(i, j int) bool {
+		return modTimes[users[i].ID] > modTimes[users[j].ID]
+	})
+	return users, nil
+}
+
+// loadLoginUsers reads loginusers.vdf into users by account ID. A missing or unreadable
+// file gives no names
+func loadLoginUsers(steamPath string) map[string]User {
+	users := make(map[string]User)
+	root, err := readVDFFile(GetLoginUsersPath(steamPath))
+	if err != nil {
+		return users
+	}
+	node := vdf.FindNode(root, "users")
+	if node == nil {
+		return users
+	}
+	for _, child := range node.Children {
+		steamID, err := strconv.ParseUint(child.Key, 10, 64)
+		if err != nil || steamID < steamID64Base {
+			continue
+		}
+		user := User{ID: strconv.FormatUint(steamID-steamID64Base, 10)}
+		for _, field := range child.Children {
+			switch {
+			case vdf.KeyEqual(field.Key, "AccountName"):
+				user.AccountName = field.Value
+			case vdf.KeyEqual(field.Key, "PersonaName"):
+				user.PersonaName = field.Value
+			case vdf.KeyEqual(field.Key, "MostRecent"):
+				user.MostRecent = field.Value == "1"
+			}
+		}
+		users[user.ID] = user
+	}
+	return users
+}
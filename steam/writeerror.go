@@ -0,0 +1,102 @@
+package steam
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+// WriteErrorKind classifies why writing a Steam file failed
+type WriteErrorKind int
+
+const (
+	WriteErrorOther WriteErrorKind = iota
+	WriteErrorReadOnlyFS
+	WriteErrorPermission
+	// WriteErrorOwner is a permission error on a file owned by another user
+	WriteErrorOwner
+	WriteErrorDiskFull
+)
+
+// WriteError is a failed write with steps the user can take to fix it
+type WriteError struct {
+	Path string
+	Kind WriteErrorKind
+	Err  error
+	// Steps are the remediation steps for this platform
+	Steps []string
+}
+
+func (e *WriteError) Error() string {
+	msg := fmt.Sprintf("failed to write %s: %v", e.Path, e.Err)
+	if len(e.Steps) > 0 {
+		msg += "\nTo fix this:\n  - " + strings.Join(e.Steps, "\n  - ")
+	}
+	return msg
+}
+
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
+
+// classifyWriteError wraps an error writing path in a WriteError, or returns nil for nil
+func classifyWriteError(path string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	e := &WriteError{Path: path, Err: err}
+	switch {
+	case errors.Is(err, syscall.EROFS):
+		e.Kind = WriteErrorReadOnlyFS
+	case errors.Is(err, syscall.ENOSPC):
+		e.Kind = WriteErrorDiskFull
+	case errors.Is(err, fs.ErrPermission):
+		e.Kind = WriteErrorPermission
+		if uid, other := ownedByOtherUser(path); other {
+			e.Kind = WriteErrorOwner
+			e.Steps = []string{fmt.Sprintf("%s is owned by another user (uid %d), usually after running Steam or gsca with sudo. Take it back with 'sudo chown -R $USER: %s'", path, uid, filepath.Dir(path))}
+			return e
+		}
+	default:
+		return e
+	}
+	e.Steps = writeErrorSteps(e.Kind, path, runtime.GOOS)
+	return e
+}
+
+// writeErrorSteps returns the remediation steps for kind on goos
+func writeErrorSteps(kind WriteErrorKind, path, goos string) []string {
+	switch kind {
+	case WriteErrorReadOnlyFS:
+		if goos == osWindows {
+			return []string{"The drive holding " + path + " is read-only (write-protected or a read-only share); make it writable or move Steam to another drive"}
+		}
+		return []string{
+			"The filesystem holding " + path + " is mounted read-only",
+			"Check with 'findmnt -T " + path + "' and remount it read-write, or pass --steam-path if this is a copy of the real install",
+		}
+	case WriteErrorDiskFull:
+		return []string{"The drive holding " + path + " is full; free up some space and try again"}
+	case WriteErrorPermission:
+		switch goos {
+		case osWindows:
+			return []string{
+				"Controlled Folder Access may be blocking gsca: allow it in Windows Security > Virus & threat protection > Ransomware protection > Allow an app through Controlled folder access",
+				"Clear the file's read-only attribute with 'attrib -r \"" + path + "\"'",
+				"If Steam is installed under Program Files, run gsca from a terminal opened as administrator",
+			}
+		case osDarwin:
+			return []string{
+				"Make the file and its folder writable with 'chmod u+w \"" + path + "\"'",
+				"Give your terminal Full Disk Access in System Settings > Privacy & Security",
+			}
+		}
+		return []string{"Check the permissions with 'ls -l " + path + "' and make the file and its directory writable with 'chmod u+w'"}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/httpcache"
+	"github.com/zerkz/gsca/i18n"
+	"github.com/zerkz/gsca/protondb"
+	"github.com/zerkz/gsca/steam"
+	"github.com/zerkz/gsca/suggest"
+)
+
+var suggestNoProtonDB bool
+
+var suggestCmd = &cobra.Command{
+	Use:   "suggest <game>",
+	Short: "Suggest launch options for a game",
+	Long: `Propose launch options for a game (app ID, alias, or installed name), combining:
+
+  - launch options players report on ProtonDB (for games running through Proton)
+  - what you already use for similar games (same developer, or same genres)
+  - the built-in knowledge base of 'gsca args explain', which drops options that don't
+    apply to the game, e.g. Proton variables for native games
+
+The game's current launch options are kept. Each addition is explained, and you can
+accept, edit, or skip the result before anything is written. Outside a terminal the
+suggestion is only printed, unless --yes is given.
+
+Example:
+  gsca suggest "ELDEN RING"
+  gsca suggest 1245620 --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSuggest,
+}
+
+func init() {
+	suggestCmd.Flags().BoolVar(&suggestNoProtonDB, "no-protondb", false, "Don't look up ProtonDB reports")
+	suggestCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be changed without actually modifying files")
+	suggestCmd.Flags().BoolVarP(&autoCloseSteam, "force", "f", false, "Automatically close Steam if running (no prompt)")
+	suggestCmd.Flags().BoolVar(&noBackup, "no-backup", false, "Skip creating backup file")
+	addRestartFlags(suggestCmd)
+
+	rootCmd.AddCommand(suggestCmd)
+}
+
+func runSuggest(cmd *cobra.Command, args []string) error {
+	localConfigPath, err := detectLocalConfigPath()
+	if err != nil {
+		return err
+	}
+	library, err := steam.LoadLibrary(steamPath, nil)
+	if err != nil {
+		return fmt.Errorf("failed to load game library: %w", err)
+	}
+	appID, err := resolveGame(args[0], library)
+	if err != nil {
+		return err
+	}
+	name := gameLabel(appID, libraryNames(library))
+
+	current, err := steam.GetAppValues(localConfigPath, steam.KeyLaunchOptions)
+	if err != nil {
+		return err
+	}
+
+	db, err := loadArgDB()
+	if err != nil {
+		return err
+	}
+
+	// Games with launch options are the only ones worth comparing against
+	appIDs := []string{appID}
+	for other, value := range current {
+		if value != "" && other != appID {
+			appIDs = append(appIDs, other)
+		}
+	}
+	proton := steam.ProtonGames(steamPath, appIDs)
+	in := suggest.Input{
+		Current: current[appID],
+		Proton:  proton[appID],
+		GOOS:    runtime.GOOS,
+		Similar: make(map[string]string),
+	}
+
+	infos, err := steam.LoadAppInfo(steam.GetAppInfoPath(steamPath), appIDs)
+	if target, found := infos[appID]; err == nil && found {
+		games := make([]steam.AppInfo, 0, len(infos))
+		for _, info := range infos {
+			games = append(games, info)
+		}
+		for _, similar := range suggest.Similar(target, in.Proton, games, proton) {
+			in.Similar[similar] = current[similar]
+		}
+	} else {
+		fmt.Println("Warning: No store metadata for this game in Steam's appinfo cache; not comparing with similar games")
+	}
+
+	if in.Proton && !suggestNoProtonDB {
+		in.Reports = protonDBLaunchOptions(appID)
+	}
+
+	result := suggest.Suggest(db, in)
+	fmt.Printf("\n%s: %d ProtonDB report(s) with launch options, %d similar game(s) with launch options\n", name, len(in.Reports), len(in.Similar))
+	if len(result.Added) == 0 {
+		fmt.Println("No suggestions")
+		return nil
+	}
+
+	fmt.Println("\nSuggested additions:")
+	for _, reason := range result.Added {
+		fmt.Printf("  %s\n", reason.Text)
+		if reason.Entry != nil {
+			fmt.Printf("    %s\n", reason.Entry.Description)
+		}
+		fmt.Printf("    %s\n", suggestionSources(reason, libraryNames(library)))
+	}
+
+	previous := in.Current
+	if previous == "" {
+		previous = "(none)"
+	}
+	fmt.Printf("\nCurrent:  %s\n", previous)
+	fmt.Printf("Proposed: %s\n", result.Value)
+	warnAntiCheat(db, []steam.AppValue{{AppID: appID, Value: result.Value}})
+
+	value := result.Value
+	switch {
+	case assumeYes:
+		fmt.Println("Applying (--yes)")
+	case !canPrompt():
+		fmt.Println("\nNot a terminal; nothing applied (use --yes to apply the suggestion)")
+		return nil
+	default:
+		switch promptReview() {
+		case "e":
+			fmt.Print("  " + i18n.T(i18n.PromptReviewEdit))
+			if edited := readLine(); edited != "" {
+				value = edited
+			}
+		case "n", "q":
+			fmt.Println("Skipped")
+			return nil
+		}
+	}
+
+	appIDList = []string{appID}
+	return applyAppValue(launchOptionsUpdate(value))
+}
+
+// protonDBLaunchOptions returns the launch strings in a game's ProtonDB reports; lookup
+// failures are reported and leave ProtonDB out
+func protonDBLaunchOptions(appID string) []string {
+	cache, err := httpcache.NewDefault(refreshMetadata, offlineMode)
+	if err != nil {
+		fmt.Printf("Warning: %v; skipping ProtonDB\n", err)
+		return nil
+	}
+	client := protondb.New(cache)
+
+	if summary, err := client.Summary(appID); err == nil {
+		fmt.Printf("ProtonDB rating: %s (%d reports, %s confidence)\n", summary.Tier, summary.Total, summary.Confidence)
+	}
+	reports, err := client.Reports(appID)
+	if err != nil {
+		fmt.Printf("Warning: Failed to get ProtonDB reports: %v\n", err)
+		return nil
+	}
+
+	var launchOptions []string
+	for _, report := range reports {
+		launchOptions = append(launchOptions, report.LaunchOptions()...)
+	}
+	return launchOptions
+}
+
+// suggestionSources describes where a suggested token comes from
+func suggestionSources(reason suggest.Reason, names map[string]string) string {
+	var sources []string
+	if reason.Reports > 0 {
+		sources = append(sources, fmt.Sprintf("%d ProtonDB report(s)", reason.Reports))
+	}
+	if len(reason.Games) > 0 {
+		games := make([]string, len(reason.Games))
+		for i, appID := range reason.Games {
+			games[i] = gameLabel(appID, names)
+		}
+		sources = append(sources, "used for "+strings.Join(games, ", "))
+	}
+	return "From " + strings.Join(sources, "; ")
+}
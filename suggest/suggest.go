@@ -0,0 +1,253 @@
+// Package suggest proposes launch options for a game from what ProtonDB players report and
+// what the user already runs similar games with, vetted against the argdb knowledge base
+package suggest
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/zerkz/gsca/argdb"
+	"github.com/zerkz/gsca/steam"
+)
+
+// minSupport is how many reports or games must use a token the knowledge base doesn't know
+// before it is suggested
+const minSupport = 2
+
+// Input is what a suggestion is based on
+type Input struct {
+	// Current is the game's launch options, which are kept
+	Current string
+	// Proton is set if the game runs through Proton
+	Proton bool
+	// GOOS is the platform the game runs on
+	GOOS string
+	// Reports are launch strings from ProtonDB reports
+	Reports []string
+	// Similar maps similar games to their launch options
+	Similar map[string]string
+}
+
+// Reason explains one suggested token
+type Reason struct {
+	// Text is the token with its arguments, e.g. PROTON_LOG=1 or "-w 1920"
+	Text string
+	Kind string
+	// Entry is the knowledge base entry, if any
+	Entry *argdb.Entry
+	// Reports is how many ProtonDB reports use it
+	Reports int
+	// Games are the similar games that use it
+	Games []string
+}
+
+// Result is a suggested launch string
+type Result struct {
+	Value string
+	// Added explains the tokens added to the current launch options
+	Added []Reason
+}
+
+// unit is a token with its arguments: an environment variable, a wrapper with its
+// arguments, or a game flag with its value
+type unit struct {
+	kind string
+	// key identifies the unit regardless of its value (variable name, program, flag)
+	key   string
+	text  string
+	entry *argdb.Entry
+}
+
+// candidate collects the uses of one unit key across sources
+type candidate struct {
+	kind    string
+	entry   *argdb.Entry
+	texts   map[string]int
+	order   int
+	reports int
+	games   []string
+}
+
+// Suggest combines the reports and similar games into a launch string for the game.
+// Tokens the knowledge base knows are suggested if any source uses them and they apply to
+// the game (e.g. Proton variables only for Proton games); unknown ones need minSupport
+// sources. Unknown wrappers are never taken from reports, whose notes are free text
+func Suggest(db *argdb.DB, in Input) Result {
+	candidates := make(map[string]*candidate)
+	add := func(launchOptions string, fromReport bool, game string) {
+		seen := make(map[string]bool)
+		for _, u := range units(db, launchOptions) {
+			id := u.kind + ":" + u.key
+			if seen[id] || fromReport && u.kind == argdb.KindWrapper && u.entry == nil {
+				continue
+			}
+			seen[id] = true
+			c := candidates[id]
+			if c == nil {
+				c = &candidate{kind: u.kind, entry: u.entry, texts: make(map[string]int), order: len(candidates)}
+				candidates[id] = c
+			}
+			c.texts[u.text]++
+			if fromReport {
+				c.reports++
+			} else {
+				c.games = append(c.games, game)
+			}
+		}
+	}
+	for _, report := range in.Reports {
+		add(report, true, "")
+	}
+	games := make([]string, 0, len(in.Similar))
+	for appID := range in.Similar {
+		games = append(games, appID)
+	}
+	steam.SortAppIDs(games)
+	for _, appID := range games {
+		add(in.Similar[appID], false, appID)
+	}
+
+	current := make(map[string]bool)
+	for _, u := range units(db, in.Current) {
+		current[u.kind+":"+u.key] = true
+	}
+
+	ids := make([]string, 0, len(candidates))
+	for id, c := range candidates {
+		if !current[id] && applies(c, in) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := candidates[ids[i]], candidates[ids[j]]
+		if support(a) != support(b) {
+			return support(a) > support(b)
+		}
+		return a.order < b.order
+	})
+
+	opts := steam.ParseLaunchOptions(in.Current)
+	var result Result
+	for _, id := range ids {
+		c := candidates[id]
+		text := mostCommon(c.texts)
+		switch c.kind {
+		case argdb.KindEnv:
+			name, value, _ := steam.SplitEnvVar(text)
+			opts.SetEnv(name, value)
+		case argdb.KindWrapper:
+			opts.AddWrapper(text)
+		default:
+			opts.AddArg(text)
+		}
+		result.Added = append(result.Added, Reason{Text: text, Kind: c.kind, Entry: c.entry, Reports: c.reports, Games: c.games})
+	}
+	result.Value = opts.String()
+	return result
+}
+
+func support(c *candidate) int {
+	return c.reports + len(c.games)
+}
+
+// applies reports whether a candidate should be suggested for the game
+func applies(c *candidate, in Input) bool {
+	if c.entry == nil {
+		return support(c) >= minSupport
+	}
+	if c.entry.Proton && !in.Proton {
+		return false
+	}
+	if len(c.entry.Platforms) > 0 {
+		supported := false
+		for _, platform := range c.entry.Platforms {
+			supported = supported || platform == in.GOOS
+		}
+		return supported
+	}
+	return true
+}
+
+// mostCommon returns the most used variant of a unit, e.g. the most common value of a
+// variable; ties go to the alphabetically first
+func mostCommon(texts map[string]int) string {
+	best := ""
+	for text, n := range texts {
+		if best == "" || n > texts[best] || n == texts[best] && text < best {
+			best = text
+		}
+	}
+	return best
+}
+
+// units groups the tokens of a launch string into units
+func units(db *argdb.DB, launchOptions string) []unit {
+	var result []unit
+	for _, ex := range db.Explain(launchOptions) {
+		switch ex.Kind {
+		case argdb.KindCommand:
+			continue
+		case argdb.KindEnv:
+			name, _, _ := steam.SplitEnvVar(ex.Token)
+			result = append(result, unit{kind: ex.Kind, key: name, text: ex.Token, entry: ex.Entry})
+			continue
+		case argdb.KindWrapper, argdb.KindFlag:
+			key := strings.ToLower(ex.Token)
+			if ex.Kind == argdb.KindWrapper {
+				key = strings.ToLower(programName(ex.Token))
+			}
+			result = append(result, unit{kind: ex.Kind, key: key, text: ex.Token, entry: ex.Entry})
+			continue
+		}
+		// Values and arguments belong to the unit before them; stray words are dropped
+		if len(result) > 0 && ex.Kind != "" {
+			last := &result[len(result)-1]
+			last.text += " " + ex.Token
+		}
+	}
+	return result
+}
+
+// programName strips the directory from a wrapper, e.g. /usr/bin/mangohud
+func programName(wrapper string) string {
+	wrapper = strings.ReplaceAll(wrapper, `\`, "/")
+	return wrapper[strings.LastIndex(wrapper, "/")+1:]
+}
+
+// Similar returns the games that are likely to need the same launch options as target:
+// ones from the same developer, or sharing at least two store genres and running through
+// Proton (or not) like it
+func Similar(target steam.AppInfo, targetProton bool, games []steam.AppInfo, proton map[string]bool) []string {
+	var similar []string
+	for _, game := range games {
+		if game.AppID == target.AppID {
+			continue
+		}
+		if sameDeveloper(target, game) || proton[game.AppID] == targetProton && sharedGenres(target, game) >= 2 {
+			similar = append(similar, game.AppID)
+		}
+	}
+	steam.SortAppIDs(similar)
+	return similar
+}
+
+func sameDeveloper(a, b steam.AppInfo) bool {
+	for _, developer := range a.Developers {
+		for _, other := range b.Developers {
+			if steam.FoldName(developer) == steam.FoldName(other) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sharedGenres(a, b steam.AppInfo) int {
+	shared := 0
+	for _, genre := range a.Genres {
+		if b.HasGenre(genre) {
+			shared++
+		}
+	}
+	return shared
+}
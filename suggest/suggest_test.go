@@ -0,0 +1,99 @@
+package suggest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zerkz/gsca/argdb"
+	"github.com/zerkz/gsca/steam"
+)
+
+func TestSuggest(t *testing.T) {
+	db := argdb.Default()
+
+	tests := []struct {
+		name      string
+		in        Input
+		wantValue string
+	}{
+		{
+			name: "reports and similar games",
+			in: Input{
+				Proton:  true,
+				GOOS:    "linux",
+				Reports: []string{"PROTON_ENABLE_NVAPI=1 %command%", "PROTON_ENABLE_NVAPI=1 gamemoderun %command%"},
+				Similar: map[string]string{"10": "gamemoderun %command% -novid"},
+			},
+			wantValue: "PROTON_ENABLE_NVAPI=1 gamemoderun %command% -novid",
+		},
+		{
+			name: "keeps current options",
+			in: Input{
+				Current: "PROTON_ENABLE_NVAPI=0 %command% -w 1280",
+				Proton:  true,
+				GOOS:    "linux",
+				Reports: []string{"PROTON_ENABLE_NVAPI=1 %command% -w 1920"},
+			},
+			wantValue: "PROTON_ENABLE_NVAPI=0 %command% -w 1280",
+		},
+		{
+			name: "Proton only tokens skipped for native games",
+			in: Input{
+				GOOS:    "linux",
+				Reports: []string{"PROTON_LOG=1 mangohud %command%"},
+			},
+			wantValue: "mangohud %command%",
+		},
+		{
+			name: "Linux only tokens skipped on Windows",
+			in: Input{
+				GOOS:    "windows",
+				Similar: map[string]string{"10": "gamemoderun %command% -novid"},
+			},
+			wantValue: "-novid",
+		},
+		{
+			name: "unknown tokens need two sources",
+			in: Input{
+				GOOS:    "linux",
+				Reports: []string{"used FOO=1 %command% -bar", "MY_FIX=1 %command%"},
+				Similar: map[string]string{"10": "MY_FIX=2 %command%"},
+			},
+			wantValue: "MY_FIX=1 %command%",
+		},
+		{
+			name: "most common value wins",
+			in: Input{
+				GOOS:    "linux",
+				Reports: []string{"DXVK_FRAME_RATE=60 %command%"},
+				Similar: map[string]string{"10": "MANGOHUD_CONFIG=fps_limit=30 %command%", "20": "MANGOHUD_CONFIG=fps_limit=60 %command%", "30": "MANGOHUD_CONFIG=fps_limit=60 %command%"},
+			},
+			wantValue: "MANGOHUD_CONFIG=fps_limit=60 %command%",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Suggest(db, tt.in); got.Value != tt.wantValue {
+				t.Errorf("Suggest() = %q, want %q", got.Value, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestSimilar(t *testing.T) {
+	target := steam.AppInfo{AppID: "1", Developers: []string{"FromSoftware, Inc."}, Genres: []string{"Action", "RPG"}}
+	games := []steam.AppInfo{
+		target,
+		{AppID: "2", Developers: []string{"FROMSOFTWARE, INC."}},
+		{AppID: "3", Developers: []string{"Other"}, Genres: []string{"RPG", "Action", "Indie"}},
+		{AppID: "4", Developers: []string{"Other"}, Genres: []string{"RPG", "Action"}},
+		{AppID: "5", Developers: []string{"Other"}, Genres: []string{"RPG"}},
+	}
+	proton := map[string]bool{"1": true, "3": true}
+
+	want := []string{"2", "3"}
+	if got := Similar(target, true, games, proton); !reflect.DeepEqual(got, want) {
+		t.Errorf("Similar() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,171 @@
+package vdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Type tags in binary KeyValues (as used by appinfo.vdf and shortcuts.vdf)
+const (
+	binaryMap     byte = 0x00
+	binaryString  byte = 0x01
+	binaryInt32   byte = 0x02
+	binaryFloat32 byte = 0x03
+	binaryPointer byte = 0x04
+	binaryColor   byte = 0x06
+	binaryUint64  byte = 0x07
+	binaryEnd     byte = 0x08
+	binaryInt64   byte = 0x0A
+	binaryEndAlt  byte = 0x0B
+)
+
+// ParseBinary decodes a binary KeyValues document into a tree; numbers become decimal strings
+// If keys is non-nil, key names are read as uint32 indexes into it (appinfo.vdf v29+)
+// instead of inline strings
+func ParseBinary(data []byte, keys []string) (*Node, error) {
+	d := &binaryDecoder{data: data, keys: keys}
+	children, err := d.parseMap()
+	if err != nil {
+		return nil, err
+	}
+	return &Node{IsObject: true, Children: children}, nil
+}
+
+type binaryDecoder struct {
+	data []byte
+	pos  int
+	keys []string
+}
+
+func (d *binaryDecoder) parseMap() ([]*Node, error) {
+	var children []*Node
+
+	for d.pos < len(d.data) {
+		typ := d.data[d.pos]
+		d.pos++
+		if typ == binaryEnd || typ == binaryEndAlt {
+			return children, nil
+		}
+
+		key, err := d.readKey()
+		if err != nil {
+			return nil, err
+		}
+		node := &Node{Key: key}
+
+		switch typ {
+		case binaryMap:
+			node.IsObject = true
+			if node.Children, err = d.parseMap(); err != nil {
+				return nil, err
+			}
+		case binaryString:
+			node.Value, err = d.readString()
+		case binaryInt32, binaryPointer, binaryColor:
+			var b []byte
+			if b, err = d.read(4); err == nil {
+				node.Value = strconv.FormatInt(int64(int32(binary.LittleEndian.Uint32(b))), 10)
+			}
+		case binaryFloat32:
+			var b []byte
+			if b, err = d.read(4); err == nil {
+				node.Value = strconv.FormatFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(b))), 'g', -1, 32)
+			}
+		case binaryUint64:
+			var b []byte
+			if b, err = d.read(8); err == nil {
+				node.Value = strconv.FormatUint(binary.LittleEndian.Uint64(b), 10)
+			}
+		case binaryInt64:
+			var b []byte
+			if b, err = d.read(8); err == nil {
+				node.Value = strconv.FormatInt(int64(binary.LittleEndian.Uint64(b)), 10)
+			}
+		default:
+			return nil, fmt.Errorf("offset %d: unsupported binary KeyValues type 0x%02x", d.pos-1, typ)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		children = append(children, node)
+	}
+
+	return children, nil
+}
+
+func (d *binaryDecoder) readKey() (string, error) {
+	if d.keys == nil {
+		return d.readString()
+	}
+
+	b, err := d.read(4)
+	if err != nil {
+		return "", err
+	}
+	idx := binary.LittleEndian.Uint32(b)
+	if int(idx) >= len(d.keys) {
+		return "", fmt.Errorf("offset %d: key index %d out of range", d.pos-4, idx)
+	}
+	return d.keys[idx], nil
+}
+
+func (d *binaryDecoder) readString() (string, error) {
+	end := bytes.IndexByte(d.data[d.pos:], 0)
+	if end == -1 {
+		return "", fmt.Errorf("offset %d: unterminated string", d.pos)
+	}
+	s := string(d.data[d.pos : d.pos+end])
+	d.pos += end + 1
+	return s, nil
+}
+
+func (d *binaryDecoder) read(n int) ([]byte, error) {
+	if d.pos+n > len(d.data) {
+		return nil, fmt.Errorf("offset %d: unexpected end of data", d.pos)
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// AppendBinary appends node's children to buf in binary KeyValues format, followed by an end marker
+// Values are written as strings. If keys is non-nil, key names are written as indexes
+// into *keys, appending names that aren't in it yet
+func AppendBinary(buf []byte, node *Node, keys *[]string) []byte {
+	for _, child := range node.Children {
+		if child.IsObject {
+			buf = append(buf, binaryMap)
+		} else {
+			buf = append(buf, binaryString)
+		}
+
+		if keys == nil {
+			buf = append(append(buf, child.Key...), 0)
+		} else {
+			idx := -1
+			for i, key := range *keys {
+				if key == child.Key {
+					idx = i
+					break
+				}
+			}
+			if idx == -1 {
+				idx = len(*keys)
+				*keys = append(*keys, child.Key)
+			}
+			buf = binary.LittleEndian.AppendUint32(buf, uint32(idx))
+		}
+
+		if child.IsObject {
+			buf = AppendBinary(buf, child, keys)
+		} else {
+			buf = append(append(buf, child.Value...), 0)
+		}
+	}
+
+	return append(buf, binaryEnd)
+}
@@ -1,7 +1,8 @@
+// Package vdf reads and writes Valve's text KeyValues format (VDF) and its binary form.
+// Keys are matched case-insensitively, as Steam does, but keep their original case when written
 package vdf
 
 import (
-	"bufio"
 	"fmt"
 	"io"
 	"strings"
@@ -13,169 +14,265 @@ type Node struct {
 	Value    string
 	Children []*Node
 	IsObject bool
+
+	// Line is the 1-based line of the node's key in the parsed input (0 for nodes created in memory)
+	Line int
+	// EndLine is the line of an object's closing brace (0 if unknown)
+	EndLine int
+	// ValueStart and ValueEnd are the byte range of a value in the parsed input, quotes
+	// included, so it can be replaced in place (both 0 if unknown)
+	ValueStart int
+	ValueEnd   int
+	// EndOffset is the byte offset of an object's closing brace (0 if unknown)
+	EndOffset int
 }
 
-// Parser parses VDF format
+// Parser parses VDF format. Besides Steam's own layout, where an object's key and its "{"
+// are on lines of their own, it accepts the variants other tools write: "key" { on one line,
+// a value on the line after its key, blank lines or comments between a key and its "{", and
+// quoted values spanning lines
 type Parser struct {
-	scanner *bufio.Scanner
-	line    int
+	*tokenizer
+
+	// target limits parsing to the nodes along a path (nil parses everything)
+	target []string
+	// done is set once the target subtree has been read
+	done bool
 }
 
 // NewParser creates a new VDF parser
 func NewParser(r io.Reader) *Parser {
-	return &Parser{
-		scanner: bufio.NewScanner(r),
-		line:    0,
+	return &Parser{tokenizer: newTokenizer(r)}
+}
+
+// NewPathParser creates a parser that only builds the subtree at path (e.g. "Root/Software/apps")
+// and the nodes leading to it. Everything else is skipped without being built, and
+// parsing stops as soon as the subtree has been read
+func NewPathParser(r io.Reader, path string) *Parser {
+	p := NewParser(r)
+	p.target = strings.Split(path, "/")
+	return p
+}
+
+// wanted reports whether a node at depth should be kept when parsing a path
+func (p *Parser) wanted(depth int, key string) bool {
+	return p.target == nil || depth >= len(p.target) || KeyEqual(p.target[depth], key)
+}
+
+// markDone records that the target node itself has been read
+func (p *Parser) markDone(depth int, key string) {
+	if p.target != nil && depth == len(p.target)-1 && KeyEqual(p.target[depth], key) {
+		p.done = true
+	}
+}
+
+// skipObject consumes tokens up to the brace closing an object whose "{" was just read
+func (p *Parser) skipObject() error {
+	depth := 1
+	for depth > 0 {
+		tok, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch tok.kind {
+		case tokenEOF:
+			return nil
+		case tokenOpen:
+			depth++
+		case tokenClose:
+			depth--
+		}
 	}
+	return nil
 }
 
 // Parse parses the VDF content
 func (p *Parser) Parse() (*Node, error) {
-	root := &Node{IsObject: true}
+	root, err := p.ParsePartial()
+	if err != nil {
+		return nil, err
+	}
+	return root, nil
+}
 
-	for p.scanner.Scan() {
-		p.line++
-		line := strings.TrimSpace(p.scanner.Text())
+// ParsePartial is Parse, but on an error it also returns the nodes read before it, for
+// salvaging damaged files. Objects the error cut short keep the children read so far
+func (p *Parser) ParsePartial() (*Node, error) {
+	children, err := p.parseObject(0)
+	return &Node{IsObject: true, Children: children}, err
+}
 
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "//") {
-			continue
-		}
+// parseObject reads the nodes of an object up to its closing brace (the end of the input at
+// the top level). On an error it returns the nodes read so far with it
+func (p *Parser) parseObject(depth int) ([]*Node, error) {
+	var children []*Node
 
-		if line == "{" {
-			continue
+	for !p.done {
+		tok, err := p.next()
+		if err != nil {
+			return children, err
 		}
-
-		if line == "}" {
+		if tok.kind == tokenEOF || tok.kind == tokenClose {
 			break
 		}
-
-		// Parse key-value or object
-		parts := p.parseQuotedParts(line)
-		if len(parts) == 0 {
+		if tok.kind == tokenOpen {
 			continue
 		}
 
-		key := parts[0]
-
-		// Check if next line is '{'
-		node := &Node{Key: key}
+		key := tok.text
+		node := &Node{Key: key, Line: tok.line}
 
-		if len(parts) == 1 {
-			// This is an object
-			if !p.scanner.Scan() {
-				break
+		next, err := p.peek(0)
+		if err != nil {
+			return children, err
+		}
+		switch next.kind {
+		case tokenOpen:
+			// The "{" may follow on the same line or after blank lines and comments
+			_, _ = p.next()
+			if !p.wanted(depth, key) {
+				if err := p.skipObject(); err != nil {
+					return children, err
+				}
+				continue
 			}
-			p.line++
-			nextLine := strings.TrimSpace(p.scanner.Text())
-
-			if nextLine == "{" {
-				node.IsObject = true
-				children, err := p.parseObject()
-				if err != nil {
-					return nil, err
+			node.IsObject = true
+			if node.Children, err = p.parseObject(depth + 1); err != nil {
+				if p.wanted(depth, key) {
+					children = append(children, node)
 				}
-				node.Children = children
+				return children, err
+			}
+			node.EndLine, node.EndOffset = p.lastLine, p.lastStart
+		case tokenString:
+			isValue, err := p.isValue(tok, next)
+			if err != nil {
+				return children, err
+			}
+			if isValue {
+				_, _ = p.next()
+				node.Value = next.text
+				node.ValueStart, node.ValueEnd = next.start, next.end
 			}
-		} else if len(parts) == 2 {
-			// Key-value pair
-			node.Value = parts[1]
-			node.IsObject = false
 		}
 
-		root.Children = append(root.Children, node)
+		if !p.wanted(depth, key) {
+			continue
+		}
+		p.markDone(depth, key)
+
+		children = append(children, node)
 	}
 
-	return root, p.scanner.Err()
+	return children, nil
 }
 
-func (p *Parser) parseObject() ([]*Node, error) {
-	var children []*Node
-
-	for p.scanner.Scan() {
-		p.line++
-		line := strings.TrimSpace(p.scanner.Text())
-
-		if line == "" || strings.HasPrefix(line, "//") {
-			continue
-		}
+// isValue reports whether the string after key is its value: it is on the key's line, or
+// alone on the next one. Otherwise key has no value and the string starts the next node
+func (p *Parser) isValue(key, next token) (bool, error) {
+	if next.line == key.endLine {
+		return true, nil
+	}
+	after, err := p.peek(1)
+	if err != nil {
+		return false, err
+	}
+	return after.kind == tokenEOF || after.kind == tokenClose || after.line > next.endLine, nil
+}
 
-		if line == "}" {
-			break
-		}
+// SyntaxError describes where a VDF document is malformed
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
 
-		if line == "{" {
-			continue
-		}
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
 
-		parts := p.parseQuotedParts(line)
-		if len(parts) == 0 {
-			continue
+// Validate checks that a VDF document has balanced braces and terminated quotes
+// Returns a *SyntaxError pointing at the damaged region, or nil if the document is well-formed
+func Validate(r io.Reader) error {
+	tokens := newTokenizer(r)
+	var openLines []int
+	lastLine := 0
+
+	for {
+		tok, err := tokens.next()
+		if err != nil {
+			return err
 		}
 
-		key := parts[0]
-		node := &Node{Key: key}
-
-		if len(parts) == 1 {
-			// Check if next line is '{'
-			if !p.scanner.Scan() {
-				break
+		switch tok.kind {
+		case tokenOpen:
+			openLines = append(openLines, tok.line)
+		case tokenClose:
+			if len(openLines) == 0 {
+				return &SyntaxError{Line: tok.line, Msg: "unexpected closing brace"}
 			}
-			p.line++
-			nextLine := strings.TrimSpace(p.scanner.Text())
-
-			if nextLine == "{" {
-				node.IsObject = true
-				nestedChildren, err := p.parseObject()
-				if err != nil {
-					return nil, err
+			openLines = openLines[:len(openLines)-1]
+		case tokenEOF:
+			if len(openLines) > 0 {
+				return &SyntaxError{
+					Line: openLines[len(openLines)-1],
+					Msg:  fmt.Sprintf("object opened here is never closed (file truncated after line %d?)", lastLine),
 				}
-				node.Children = nestedChildren
 			}
-		} else if len(parts) == 2 {
-			node.Value = parts[1]
-			node.IsObject = false
+			return nil
 		}
-
-		children = append(children, node)
+		lastLine = tok.endLine
 	}
+}
 
-	return children, nil
+// KeyEqual reports whether two keys are the same key. Steam ignores case in keys
+// ("LaunchOptions" and "launchoptions"), and so does every lookup in this package
+func KeyEqual(a, b string) bool {
+	return strings.EqualFold(a, b)
 }
 
-func (p *Parser) parseQuotedParts(line string) []string {
-	var parts []string
-	var current strings.Builder
-	inQuotes := false
-
-	for i := 0; i < len(line); i++ {
-		ch := line[i]
-
-		if ch == '"' {
-			if inQuotes {
-				parts = append(parts, current.String())
-				current.Reset()
-				inQuotes = false
-			} else {
-				inQuotes = true
-			}
-		} else if inQuotes {
-			current.WriteByte(ch)
+// Path returns the keys of a slash-separated path followed by keys taken literally, so keys
+// that may contain slashes can be appended to a fixed prefix: Path("Software/Valve/Steam/apps", appID)
+func Path(path string, keys ...string) []string {
+	return append(strings.Split(path, "/"), keys...)
+}
+
+// ReplaceNode replaces the node at path with node, creating parent objects as needed
+func ReplaceNode(root *Node, path string, node *Node) {
+	ReplaceNodePath(root, strings.Split(path, "/"), node)
+}
+
+// ReplaceNodePath is ReplaceNode with the path given as keys, which may contain slashes
+func ReplaceNodePath(root *Node, keys []string, node *Node) {
+	if len(keys) == 0 {
+		return
+	}
+	current := childObject(root, keys[:len(keys)-1])
+
+	finalKey := keys[len(keys)-1]
+	node.Key = finalKey
+	for i, child := range current.Children {
+		if KeyEqual(child.Key, finalKey) {
+			node.Key = child.Key
+			current.Children[i] = node
+			return
 		}
 	}
-
-	return parts
+	current.Children = append(current.Children, node)
 }
 
 // FindNode finds a node by path (e.g., "Software/Valve/Steam")
 func FindNode(root *Node, path string) *Node {
-	parts := strings.Split(path, "/")
+	return FindNodePath(root, strings.Split(path, "/"))
+}
+
+// FindNodePath is FindNode with the path given as keys, which may contain slashes
+func FindNodePath(root *Node, keys []string) *Node {
 	current := root
 
-	for _, part := range parts {
+	for _, key := range keys {
 		found := false
 		for _, child := range current.Children {
-			if child.Key == part {
+			if KeyEqual(child.Key, key) {
 				current = child
 				found = true
 				break
@@ -189,37 +286,59 @@ func FindNode(root *Node, path string) *Node {
 	return current
 }
 
-// SetValue sets a value in the VDF tree, creating the path if necessary
-func SetValue(root *Node, path string, value string) error {
-	parts := strings.Split(path, "/")
-	current := root
+// Match is a node found by FindNodes
+type Match struct {
+	Node *Node
+	// Indexes are the positions of the nodes along the path in their parents' Children,
+	// e.g. [0, 2] for root.Children[0].Children[2]
+	Indexes []int
+}
 
-	for i, part := range parts[:len(parts)-1] {
-		found := false
-		for _, child := range current.Children {
-			if child.Key == part {
-				current = child
-				found = true
-				break
+// FindNodes finds every node at path, following all children with a matching key at each
+// step instead of only the first (files can repeat a key), in file order
+func FindNodes(root *Node, path string) []Match {
+	return FindNodesPath(root, strings.Split(path, "/"))
+}
+
+// FindNodesPath is FindNodes with the path given as keys, which may contain slashes
+func FindNodesPath(root *Node, keys []string) []Match {
+	matches := []Match{{Node: root}}
+
+	for _, key := range keys {
+		var next []Match
+		for _, match := range matches {
+			for i, child := range match.Node.Children {
+				if KeyEqual(child.Key, key) {
+					indexes := append(append([]int(nil), match.Indexes...), i)
+					next = append(next, Match{Node: child, Indexes: indexes})
+				}
 			}
 		}
-		if !found {
-			// Create the missing node
-			newNode := &Node{Key: part, IsObject: true}
-			current.Children = append(current.Children, newNode)
-			current = newNode
+		if len(next) == 0 {
+			return nil
 		}
+		matches = next
+	}
 
-		if i == len(parts)-2 {
-			// We're at the parent of the final key
-			break
-		}
+	return matches
+}
+
+// SetValue sets a value in the VDF tree, creating the path if necessary
+func SetValue(root *Node, path string, value string) error {
+	return SetValuePath(root, strings.Split(path, "/"), value)
+}
+
+// SetValuePath is SetValue with the path given as keys, which may contain slashes
+func SetValuePath(root *Node, keys []string, value string) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("empty path")
 	}
+	current := childObject(root, keys[:len(keys)-1])
 
 	// Set or update the final key
-	finalKey := parts[len(parts)-1]
+	finalKey := keys[len(keys)-1]
 	for _, child := range current.Children {
-		if child.Key == finalKey {
+		if KeyEqual(child.Key, finalKey) {
 			child.Value = value
 			return nil
 		}
@@ -234,13 +353,35 @@ func SetValue(root *Node, path string, value string) error {
 	return nil
 }
 
+// childObject returns the node at keys below root, creating missing objects along the way
+func childObject(root *Node, keys []string) *Node {
+	current := root
+
+	for _, key := range keys {
+		var next *Node
+		for _, child := range current.Children {
+			if KeyEqual(child.Key, key) {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			next = &Node{Key: key, IsObject: true}
+			current.Children = append(current.Children, next)
+		}
+		current = next
+	}
+
+	return current
+}
+
 // Write writes the VDF tree to a writer
 func Write(w io.Writer, node *Node, indent int) error {
 	indentStr := strings.Repeat("\t", indent)
 
 	for _, child := range node.Children {
 		if child.IsObject {
-			_, err := fmt.Fprintf(w, "%s\"%s\"\n%s{\n", indentStr, child.Key, indentStr)
+			_, err := fmt.Fprintf(w, "%s\"%s\"\n%s{\n", indentStr, Escape(child.Key), indentStr)
 			if err != nil {
 				return err
 			}
@@ -254,7 +395,7 @@ func Write(w io.Writer, node *Node, indent int) error {
 				return err
 			}
 		} else {
-			_, err := fmt.Fprintf(w, "%s\"%s\"\t\t\"%s\"\n", indentStr, child.Key, child.Value)
+			_, err := fmt.Fprintf(w, "%s\"%s\"\t\t\"%s\"\n", indentStr, Escape(child.Key), Escape(child.Value))
 			if err != nil {
 				return err
 			}
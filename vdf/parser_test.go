@@ -1,6 +1,7 @@
 package vdf
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -64,6 +65,193 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParseLayoutVariants(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name: "brace on the key's line",
+			input: `"libraryfolders" {
+	"0" {
+		"path"		"/home/deck/.local/share/Steam"
+		"apps" {
+			"570"		"35000000"
+		}
+	}
+}`,
+			want: []string{"libraryfolders/0/path=/home/deck/.local/share/Steam", "libraryfolders/0/apps/570=35000000"},
+		},
+		{
+			name: "value on the line after its key",
+			input: `"AppState"
+{
+	"appid"
+	"570"
+	"name"		"Dota 2"
+}`,
+			want: []string{"AppState/appid=570", "AppState/name=Dota 2"},
+		},
+		{
+			name: "blank lines and comments before the brace",
+			input: `"root"
+
+{
+	"apps"
+	// per-app settings
+
+	{
+		"570"		"1"
+	}
+	"after"		"kept"
+}`,
+			want: []string{"root/apps/570=1", "root/after=kept"},
+		},
+		{
+			name: "empty object on one line",
+			input: `"root"
+{
+	"apps" {}
+	"after"		"kept"
+}`,
+			want: []string{"root/apps/", "root/after=kept"},
+		},
+		{
+			name: "key without a value",
+			input: `"root"
+{
+	"orphan"
+	"next"		"kept"
+	"last"
+}`,
+			want: []string{"root/orphan=", "root/next=kept", "root/last="},
+		},
+		{
+			name: "bare keys and values",
+			input: `a 1
+b { c 2 }
+d/e x//y`,
+			want: []string{"a=1", "b/c=2", "d/e=x//y"},
+		},
+		{
+			name: "platform conditionals",
+			input: `"root"
+{
+	"a"		"1"		[$WIN32]
+	"b"		"2" [!$OSX]
+	"c" [$LINUX]
+	{
+		"d"		"3"
+	}
+}`,
+			want: []string{"root/a=1", "root/b=2", "root/c/d=3"},
+		},
+		{
+			name: "comment after a value",
+			input: `"root"
+{
+	"a"		"1"	// the first
+	// "b"		"2"
+	"c"		"3"
+}`,
+			want: []string{"root/a=1", "root/c=3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := NewParser(strings.NewReader(tt.input)).Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			var got []string
+			var walk func(prefix string, nodes []*Node)
+			walk = func(prefix string, nodes []*Node) {
+				for _, node := range nodes {
+					switch {
+					case node.IsObject && len(node.Children) == 0:
+						got = append(got, prefix+node.Key+"/")
+					case node.IsObject:
+						walk(prefix+node.Key+"/", node.Children)
+					default:
+						got = append(got, prefix+node.Key+"="+node.Value)
+					}
+				}
+			}
+			walk("", root.Children)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %q, want %q", got, tt.want)
+			}
+			if err := Validate(strings.NewReader(tt.input)); err != nil {
+				t.Errorf("Validate() error = %v, want nil", err)
+			}
+		})
+	}
+
+	// Objects skipped by a path parser may use the same layouts
+	input := `"root" {
+	"friends" {
+		"123" { "name" "x" }
+		"456" {
+		}
+	}
+	"apps"
+	{
+		"570" {
+			"LaunchOptions"		"-novid"
+		}
+	}
+}`
+	root, err := NewPathParser(strings.NewReader(input), "root/apps").Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if node := FindNode(root, "root/apps/570/LaunchOptions"); node == nil || node.Value != "-novid" {
+		t.Errorf("FindNode() after skipped objects = %v, want -novid", node)
+	}
+}
+
+func TestParseMultilineValues(t *testing.T) {
+	long := strings.Repeat("-fps_max 300 ", 10000)
+	input := "\"root\"\n{\n" +
+		"\t\"escaped\"\t\t\"line one\\nline two\"\n" +
+		"\t\"quoted\"\t\t\"-name \\\"My { Server\\\" %command%\" // comment\n" +
+		"\t\"spanning\"\t\t\"first\nsecond\"\n" +
+		"\t\"long\"\t\t\"" + long + "\"\n" +
+		"\t\"after\"\t\t\"kept\"\n" +
+		"}\n"
+
+	if err := Validate(strings.NewReader(input)); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+	root, err := NewParser(strings.NewReader(input)).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		key      string
+		want     string
+		wantLine int
+	}{
+		{key: "escaped", want: "line one\nline two", wantLine: 3},
+		{key: "quoted", want: `-name "My { Server" %command%`, wantLine: 4},
+		{key: "spanning", want: "first\nsecond", wantLine: 5},
+		{key: "long", want: long, wantLine: 7},
+		{key: "after", want: "kept", wantLine: 8},
+	}
+	for _, tt := range tests {
+		node := FindNode(root, "root/"+tt.key)
+		if node == nil || node.Value != tt.want || node.Line != tt.wantLine {
+			t.Errorf("FindNode(%q) = %+v, want %.40q on line %d", tt.key, node, tt.want, tt.wantLine)
+		}
+	}
+	if node := FindNode(root, "root"); node == nil || node.EndLine != 9 {
+		t.Errorf("root EndLine = %v, want 9", node)
+	}
+}
+
 func TestFindNode(t *testing.T) {
 	input := `"root"
 {
@@ -190,6 +378,145 @@ func TestSetValue(t *testing.T) {
 	}
 }
 
+func TestPathAPI(t *testing.T) {
+	input := `"root"
+{
+	"shortcuts"
+	{
+		"C:/Games/app.exe"
+		{
+			"LaunchOptions"		"-windowed"
+		}
+	}
+}`
+
+	root, err := NewParser(strings.NewReader(input)).Parse()
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	path := Path("root/shortcuts", "C:/Games/app.exe", "LaunchOptions")
+	if want := []string{"root", "shortcuts", "C:/Games/app.exe", "LaunchOptions"}; !reflect.DeepEqual(path, want) {
+		t.Errorf("Path() = %q, want %q", path, want)
+	}
+
+	if node := FindNodePath(root, path); node == nil || node.Value != "-windowed" {
+		t.Errorf("FindNodePath() = %+v, want -windowed", node)
+	}
+	// The string form splits the key and can't reach it
+	if node := FindNode(root, "root/shortcuts/C:/Games/app.exe/LaunchOptions"); node != nil {
+		t.Errorf("FindNode() = %+v, want nil", node)
+	}
+
+	if err := SetValuePath(root, path, "-fullscreen"); err != nil {
+		t.Fatalf("SetValuePath() error = %v", err)
+	}
+	if err := SetValuePath(root, Path("root/shortcuts", "D:/new.exe", "LaunchOptions"), "-new"); err != nil {
+		t.Fatalf("SetValuePath() error = %v", err)
+	}
+	shortcuts := FindNode(root, "root/shortcuts")
+	if len(shortcuts.Children) != 2 || shortcuts.Children[0].Children[0].Value != "-fullscreen" || shortcuts.Children[1].Key != "D:/new.exe" {
+		t.Errorf("SetValuePath() left shortcuts = %+v", shortcuts.Children)
+	}
+
+	ReplaceNodePath(root, Path("root/shortcuts", "D:/new.exe"), &Node{IsObject: true})
+	if node := FindNodePath(root, Path("root/shortcuts", "D:/new.exe")); node == nil || len(node.Children) != 0 {
+		t.Errorf("ReplaceNodePath() node = %+v, want an empty object", node)
+	}
+
+	if err := SetValuePath(root, nil, "x"); err == nil {
+		t.Error("SetValuePath() with an empty path error = nil, want error")
+	}
+}
+
+func TestFindNodes(t *testing.T) {
+	input := `"root"
+{
+	"apps"
+	{
+		"570"
+		{
+			"LaunchOptions"		"first"
+		}
+		"730"
+		{
+		}
+		"570"
+		{
+			"LaunchOptions"		"second"
+			"LaunchOptions"		"third"
+		}
+	}
+}`
+
+	root, err := NewParser(strings.NewReader(input)).Parse()
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	matches := FindNodes(root, "root/apps/570/LaunchOptions")
+	var values []string
+	var indexes [][]int
+	for _, match := range matches {
+		values = append(values, match.Node.Value)
+		indexes = append(indexes, match.Indexes)
+	}
+	if want := []string{"first", "second", "third"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("FindNodes() values = %v, want %v", values, want)
+	}
+	if want := [][]int{{0, 0, 0, 0}, {0, 0, 2, 0}, {0, 0, 2, 1}}; !reflect.DeepEqual(indexes, want) {
+		t.Errorf("FindNodes() indexes = %v, want %v", indexes, want)
+	}
+
+	if matches := FindNodes(root, "root/apps/730/LaunchOptions"); matches != nil {
+		t.Errorf("FindNodes() of a missing key = %v, want nil", matches)
+	}
+}
+
+func TestKeysIgnoreCase(t *testing.T) {
+	input := `"UserLocalConfigStore"
+{
+	"apps"
+	{
+		"570"
+		{
+			"launchoptions"		"-novid"
+		}
+	}
+}
+`
+
+	root, err := NewParser(strings.NewReader(input)).Parse()
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	if node := FindNode(root, "UserLocalConfigStore/Apps/570/LaunchOptions"); node == nil || node.Value != "-novid" {
+		t.Errorf("FindNode() with different case = %+v, want -novid", node)
+	}
+
+	// Setting a value keeps the key as it was in the file
+	if err := SetValue(root, "UserLocalConfigStore/apps/570/LaunchOptions", "-high"); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	var buf strings.Builder
+	if err := Write(&buf, root, 0); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if want := strings.Replace(input, "-novid", "-high", 1); buf.String() != want {
+		t.Errorf("Write() after SetValue() =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	// Path parsers match the target the same way
+	partial, err := NewPathParser(strings.NewReader(input), "userlocalconfigstore/APPS").Parse()
+	if err != nil {
+		t.Fatalf("Parse() with path failed: %v", err)
+	}
+	if node := FindNode(partial, "UserLocalConfigStore/apps/570/LaunchOptions"); node == nil {
+		t.Error("NewPathParser() with different case skipped the target")
+	}
+}
+
 func TestWrite(t *testing.T) {
 	input := `"root"
 {
@@ -287,3 +614,286 @@ func TestRoundTrip(t *testing.T) {
 		t.Errorf("Round-trip value = %v, want %v", node.Value, "modified value")
 	}
 }
+
+func TestRoundTripEscapes(t *testing.T) {
+	serverLine := `"LaunchOptions"		"PROTON_LOG=1 %command% -name \"My Server\""`
+	pathLine := `"LaunchOptions"		"\"C:\\Tools\\fps.exe\" %command%"`
+	input := "\"UserLocalConfigStore\"\n{\n\t\"apps\"\n\t{\n" +
+		"\t\t\"570\"\n\t\t{\n\t\t\t" + serverLine + "\n\t\t\t\"LastPlayed\"\t\t\"1700000000\"\n\t\t}\n" +
+		"\t\t\"730\"\n\t\t{\n\t\t\t" + pathLine + "\n\t\t}\n" +
+		"\t}\n}\n"
+
+	root, err := NewParser(strings.NewReader(input)).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	wants := map[string]string{
+		"UserLocalConfigStore/apps/570/LaunchOptions": `PROTON_LOG=1 %command% -name "My Server"`,
+		"UserLocalConfigStore/apps/730/LaunchOptions": `"C:\Tools\fps.exe" %command%`,
+	}
+	for path, want := range wants {
+		if node := FindNode(root, path); node == nil || node.Value != want {
+			t.Errorf("FindNode(%q) = %+v, want %q", path, node, want)
+		}
+	}
+
+	// Changing a sibling rewrites the whole file, which must keep the escaped values intact
+	if err := SetValue(root, "UserLocalConfigStore/apps/570/LastPlayed", "1800000000"); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	quoted := `-w 1920 -name "Other \ Server"`
+	if err := SetValue(root, "UserLocalConfigStore/apps/440/LaunchOptions", quoted); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	wants["UserLocalConfigStore/apps/440/LaunchOptions"] = quoted
+
+	var output strings.Builder
+	if err := Write(&output, root, 0); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	for _, line := range []string{serverLine, pathLine} {
+		if !strings.Contains(output.String(), line) {
+			t.Errorf("Write() output lost %s:\n%s", line, output.String())
+		}
+	}
+
+	root2, err := NewParser(strings.NewReader(output.String())).Parse()
+	if err != nil {
+		t.Fatalf("Parse() of written output error = %v\n%s", err, output.String())
+	}
+	for path, want := range wants {
+		if node := FindNode(root2, path); node == nil || node.Value != want {
+			t.Errorf("after round-trip FindNode(%q) = %+v, want %q", path, node, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantLine int // 0 means valid
+	}{
+		{
+			name: "valid",
+			input: `"root"
+{
+	"key"		"value"
+	"nested"
+	{
+		"child"		"\"quoted\""
+	}
+}`,
+		},
+		{
+			name: "truncated",
+			input: `"root"
+{
+	"apps"
+	{
+		"570"
+		{
+			"LaunchOptions"		"-novid"`,
+			wantLine: 6,
+		},
+		{
+			name: "brace on the key's line",
+			input: `"root" {
+	"apps" {}
+}
+}`,
+			wantLine: 4,
+		},
+		{
+			name: "unterminated value spanning lines",
+			input: `"root"
+{
+	"key"		"val
+	"other"		"value"
+}`,
+			wantLine: 4,
+		},
+		{
+			name: "extra closing brace",
+			input: `"root"
+{
+}
+}`,
+			wantLine: 4,
+		},
+		{
+			name: "unterminated string",
+			input: `"root"
+{
+	"key"		"val
+}`,
+			wantLine: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(strings.NewReader(tt.input))
+
+			if tt.wantLine == 0 {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			syntaxErr, ok := err.(*SyntaxError)
+			if !ok {
+				t.Fatalf("Validate() error = %v, want *SyntaxError", err)
+			}
+			if syntaxErr.Line != tt.wantLine {
+				t.Errorf("Validate() line = %d, want %d", syntaxErr.Line, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestParsePartial(t *testing.T) {
+	input := `"root"
+{
+	"apps"
+	{
+		"570"
+		{
+			"LaunchOptions"		"-novid"
+		}
+		"730"
+		{
+			"LaunchOptions"		"-hi`
+
+	if root, err := NewParser(strings.NewReader(input)).Parse(); root != nil || err == nil {
+		t.Fatalf("Parse() = %v, %v; want an error and no tree", root, err)
+	}
+
+	root, err := NewParser(strings.NewReader(input)).ParsePartial()
+	if syntaxErr, ok := err.(*SyntaxError); !ok || syntaxErr.Line != 11 {
+		t.Fatalf("ParsePartial() error = %v, want a *SyntaxError on line 11", err)
+	}
+	if node := FindNode(root, "root/apps/570/LaunchOptions"); node == nil || node.Value != "-novid" {
+		t.Errorf("ParsePartial() 570 = %v, want -novid", node)
+	}
+	if node := FindNode(root, "root/apps/730"); node == nil || len(node.Children) != 0 {
+		t.Errorf("ParsePartial() 730 = %v, want the object without its cut-off value", node)
+	}
+}
+
+func TestNewPathParser(t *testing.T) {
+	input := `"UserLocalConfigStore"
+{
+	"friends"
+	{
+		"123"
+		{
+			"name"		"someone"
+		}
+	}
+	"Software"
+	{
+		"Valve"
+		{
+			"Steam"
+			{
+				"news"		"skipped"
+				"apps"
+				{
+					"570"
+					{
+						"LaunchOptions"		"-novid"
+					}
+				}
+			}
+		}
+	}
+	"after"
+	{
+		"never"		"read"
+`
+
+	parser := NewPathParser(strings.NewReader(input), "UserLocalConfigStore/Software/Valve/Steam/apps")
+	root, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if node := FindNode(root, "UserLocalConfigStore/Software/Valve/Steam/apps/570/LaunchOptions"); node == nil || node.Value != "-novid" {
+		t.Errorf("FindNode() in target subtree = %v, want -novid", node)
+	}
+
+	for _, path := range []string{
+		"UserLocalConfigStore/friends",
+		"UserLocalConfigStore/Software/Valve/Steam/news",
+		"UserLocalConfigStore/after",
+	} {
+		if node := FindNode(root, path); node != nil {
+			t.Errorf("FindNode(%q) = %v, want skipped", path, node)
+		}
+	}
+
+	// Parsing stops once the apps object closes, before the unterminated trailing object
+	if parser.line >= strings.Count(input, "\n") {
+		t.Errorf("Parse() read %d lines, want it to stop after the target subtree", parser.line)
+	}
+}
+
+func TestParseBinary(t *testing.T) {
+	tree := &Node{IsObject: true, Children: []*Node{
+		{Key: "appinfo", IsObject: true, Children: []*Node{
+			{Key: "appid", Value: "570"},
+			{Key: "common", IsObject: true, Children: []*Node{
+				{Key: "name", Value: "Dota 2"},
+				{Key: "name_localized", IsObject: true},
+			}},
+		}},
+	}}
+
+	var keys []string
+	for _, tt := range []struct {
+		name string
+		keys *[]string
+	}{
+		{name: "inline keys", keys: nil},
+		{name: "string table", keys: &keys},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			data := AppendBinary(nil, tree, tt.keys)
+
+			var table []string
+			if tt.keys != nil {
+				table = *tt.keys
+			}
+			got, err := ParseBinary(data, table)
+			if err != nil {
+				t.Fatalf("ParseBinary() error = %v", err)
+			}
+
+			var want, gotText strings.Builder
+			_ = Write(&want, tree, 0)
+			_ = Write(&gotText, got, 0)
+			if gotText.String() != want.String() {
+				t.Errorf("ParseBinary() =\n%s\nwant\n%s", gotText.String(), want.String())
+			}
+		})
+	}
+
+	// Numbers are decoded to strings
+	data := []byte{binaryInt32, 'n', 0, 0xff, 0xff, 0xff, 0xff, binaryUint64, 'u', 0, 1, 0, 0, 0, 0, 0, 0, 0, binaryEnd}
+	got, err := ParseBinary(data, nil)
+	if err != nil {
+		t.Fatalf("ParseBinary() error = %v", err)
+	}
+	if n := FindNode(got, "n"); n == nil || n.Value != "-1" {
+		t.Errorf("int32 = %v, want -1", n)
+	}
+	if u := FindNode(got, "u"); u == nil || u.Value != "1" {
+		t.Errorf("uint64 = %v, want 1", u)
+	}
+
+	if _, err := ParseBinary([]byte{binaryString, 'k', 0, 'v'}, nil); err == nil {
+		t.Error("ParseBinary() on truncated data error = nil, want error")
+	}
+}
@@ -0,0 +1,207 @@
+package vdf
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// escapes maps the character after a backslash in a quoted string to the one the sequence
+// stands for
+var escapes = map[byte]byte{'"': '"', '\\': '\\', 'n': '\n', 't': '\t'}
+
+// escaper writes those characters back as escape sequences
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+
+// Escape returns s as it's written between quotes in a VDF file, with quotes, backslashes,
+// newlines and tabs escaped as Steam writes them. The parser decodes these sequences, so
+// values read from Steam's files are written back unchanged
+func Escape(s string) string {
+	return escaper.Replace(s)
+}
+
+type tokenKind int
+
+const (
+	tokenString tokenKind = iota
+	tokenOpen
+	tokenClose
+	tokenEOF
+)
+
+// token is a quoted or bare string, or a brace
+type token struct {
+	kind tokenKind
+	text string
+	// line is where the token starts and endLine where it ends; they differ for strings
+	// spanning lines
+	line    int
+	endLine int
+	// start and end are the byte range of the token in the input, quotes included
+	start int
+	end   int
+}
+
+// tokenizer splits VDF text into strings and braces, reading it as a stream of characters so
+// layout doesn't matter: a quoted string is one token however many lines it spans, and
+// however long it is. Escape sequences in quoted strings are decoded; see Escape
+type tokenizer struct {
+	reader *bufio.Reader
+	// line is the line the reader is on
+	line int
+	// lastLine is the line of the last token returned by next, and lastStart its offset
+	lastLine  int
+	lastStart int
+	// pos is the byte offset of the reader
+	pos    int
+	peeked []token
+}
+
+func newTokenizer(r io.Reader) *tokenizer {
+	return &tokenizer{reader: bufio.NewReader(r), line: 1}
+}
+
+// next returns the next token, or a tokenEOF token at the end of the input
+func (t *tokenizer) next() (token, error) {
+	tok, err := t.peek(0)
+	if err != nil {
+		return token{}, err
+	}
+	t.peeked = t.peeked[1:]
+	t.lastLine, t.lastStart = tok.endLine, tok.start
+	return tok, nil
+}
+
+// peek returns the token n places ahead without consuming it
+func (t *tokenizer) peek(n int) (token, error) {
+	for len(t.peeked) <= n {
+		tok, err := t.read()
+		if err != nil {
+			return token{}, err
+		}
+		t.peeked = append(t.peeked, tok)
+	}
+	return t.peeked[n], nil
+}
+
+// readByte reads the next byte, keeping track of the offset
+func (t *tokenizer) readByte() (byte, error) {
+	c, err := t.reader.ReadByte()
+	if err == nil {
+		t.pos++
+	}
+	return c, err
+}
+
+// unreadByte puts back the byte just read
+func (t *tokenizer) unreadByte() {
+	if t.reader.UnreadByte() == nil {
+		t.pos--
+	}
+}
+
+func (t *tokenizer) read() (token, error) {
+	for {
+		c, err := t.readByte()
+		if err == io.EOF {
+			return token{kind: tokenEOF, line: t.line, endLine: t.line, start: t.pos, end: t.pos}, nil
+		}
+		if err != nil {
+			return token{}, err
+		}
+
+		switch c {
+		case '\n':
+			t.line++
+		case ' ', '\t', '\r':
+		case '{':
+			return token{kind: tokenOpen, line: t.line, endLine: t.line, start: t.pos - 1, end: t.pos}, nil
+		case '}':
+			return token{kind: tokenClose, line: t.line, endLine: t.line, start: t.pos - 1, end: t.pos}, nil
+		case '"':
+			return t.quoted()
+		default:
+			// Put the byte back before peeking, as the reader can't unread after a peek
+			t.unreadByte()
+			if ahead, _ := t.reader.Peek(2); string(ahead) == "//" {
+				t.skipComment()
+				continue
+			}
+			start := t.pos
+			text, err := t.bare()
+			if err != nil {
+				return token{}, err
+			}
+			// Platform conditions like [$WIN32] aren't used by Steam's own files
+			if strings.HasPrefix(text, "[") {
+				continue
+			}
+			return token{kind: tokenString, text: text, line: t.line, endLine: t.line, start: start, end: t.pos}, nil
+		}
+	}
+}
+
+// quoted reads a quoted string whose opening quote was just read
+func (t *tokenizer) quoted() (token, error) {
+	start, offset := t.line, t.pos-1
+	var text strings.Builder
+	for {
+		c, err := t.readByte()
+		if err == io.EOF {
+			return token{}, &SyntaxError{Line: start, Msg: "unterminated quoted string"}
+		}
+		if err != nil {
+			return token{}, err
+		}
+
+		switch c {
+		case '"':
+			return token{kind: tokenString, text: text.String(), line: start, endLine: t.line, start: offset, end: t.pos}, nil
+		case '\\':
+			// The escaped character never ends the string
+			if c, err = t.readByte(); err != nil {
+				return token{}, &SyntaxError{Line: start, Msg: "unterminated quoted string"}
+			}
+			if decoded, ok := escapes[c]; ok {
+				text.WriteByte(decoded)
+				continue
+			}
+			// Unknown sequences are kept as written
+			text.WriteByte('\\')
+		}
+		if c == '\n' {
+			t.line++
+		}
+		text.WriteByte(c)
+	}
+}
+
+// bare reads an unquoted string, up to whitespace, a brace, or a quote
+func (t *tokenizer) bare() (string, error) {
+	var text strings.Builder
+	for {
+		c, err := t.readByte()
+		if err == io.EOF {
+			return text.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if strings.IndexByte(" \t\r\n{}\"", c) >= 0 {
+			t.unreadByte()
+			return text.String(), nil
+		}
+		text.WriteByte(c)
+	}
+}
+
+// skipComment skips the rest of a // comment, leaving the newline to be counted
+func (t *tokenizer) skipComment() {
+	for {
+		next, err := t.reader.Peek(1)
+		if err != nil || next[0] == '\n' {
+			return
+		}
+		_, _ = t.readByte()
+	}
+}
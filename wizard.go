@@ -0,0 +1,303 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zerkz/gsca/config"
+	"github.com/zerkz/gsca/i18n"
+	"github.com/zerkz/gsca/preset"
+	"github.com/zerkz/gsca/steam"
+)
+
+// defaultArgAlias is the arg alias the wizard saves its launch options under
+const defaultArgAlias = "default"
+
+var wizardCmd = &cobra.Command{
+	Use:   "wizard",
+	Short: "Set up gsca step by step",
+	Long: `Walk through first-time setup: find Steam, pick the account to manage, choose default
+launch options, and pick the games they are for.
+
+The answers are saved in the config file (user_id and the "default" arg alias) and in an
+allow list next to it, so afterwards a single command applies them:
+
+  gsca update --args @default --allow <allow list>
+
+Run the wizard again to change any answer; the current values are the defaults.`,
+	Args: cobra.NoArgs,
+	RunE: runWizard,
+}
+
+func init() {
+	rootCmd.AddCommand(wizardCmd)
+}
+
+// wizardStarters are the launch options the wizard offers besides imported presets
+var wizardStarters = []preset.Preset{
+	{Name: "gamemode", Description: "Feral GameMode performance tweaks while the game runs", Args: "gamemoderun %command%", When: preset.WhenLinux},
+	{Name: "mangohud", Description: "MangoHud overlay with FPS and frame times", Args: "mangohud %command%", When: preset.WhenLinux},
+	{Name: "gamemode-mangohud", Description: "Both of the above", Args: "gamemoderun mangohud %command%", When: preset.WhenLinux},
+	{Name: "nvapi", Description: "DLSS and Reflex on NVIDIA GPUs (Proton games)", Args: "PROTON_ENABLE_NVAPI=1 %command%", When: preset.WhenLinux},
+	{Name: "skip-intro", Description: "Skip intro videos (Source engine games)", Args: "-novid"},
+}
+
+func runWizard(cmd *cobra.Command, args []string) error {
+	if !canPrompt() {
+		return errNoTerminal("The wizard", "set gsca up with the config file and flags instead (see gsca --help)")
+	}
+
+	cfgPath, err := config.DefaultPath()
+	if err != nil {
+		return fmt.Errorf("failed to locate the config file: %w", err)
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Step 1 of 4: Steam install")
+	if steamPath == "" {
+		if steamPath, err = detectSteamPath(); err != nil {
+			return fmt.Errorf("failed to detect Steam path: %w", err)
+		}
+	}
+	fmt.Printf("Using %s\n", steamPath)
+
+	fmt.Println("\nStep 2 of 4: Steam account")
+	current := userID
+	if current == "" {
+		current = cfg.UserID
+	}
+	account, err := wizardPickUser(current)
+	if err != nil {
+		return err
+	}
+	userID = account
+
+	fmt.Println("\nStep 3 of 4: Default launch options")
+	launchOptions, err := wizardPickArgs(cfg.ArgAliases[defaultArgAlias])
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("\nStep 4 of 4: Games")
+	listPath := filepath.Join(filepath.Dir(cfgPath), "selected-games.txt")
+	games, err := wizardPickGames()
+	if err != nil {
+		return err
+	}
+	if len(games) > 0 {
+		fmt.Printf("Save the allow list to (default: %s): ", listPath)
+		if input := readLine(); input != "" {
+			listPath = input
+		}
+		if _, err := os.Stat(listPath); err == nil && !confirm(fmt.Sprintf("%s exists. Replace it? (Y/n): ", listPath)) {
+			games = nil
+		}
+	}
+
+	fmt.Println("\nSummary:")
+	fmt.Printf("  Steam install:   %s\n", steamPath)
+	fmt.Printf("  Account:         %s\n", userID)
+	if launchOptions != "" {
+		fmt.Printf("  Launch options:  %s (saved as @%s)\n", launchOptions, defaultArgAlias)
+	}
+	if len(games) > 0 {
+		fmt.Printf("  Allow list:      %s (%d game(s))\n", listPath, len(games))
+	}
+	fmt.Printf("  Config file:     %s\n", cfgPath)
+	if !confirm("\nSave these settings? (Y/n): ") {
+		fmt.Println(i18n.T(i18n.Cancelled))
+		return nil
+	}
+
+	cfg.UserID = userID
+	if launchOptions != "" {
+		if cfg.ArgAliases == nil {
+			cfg.ArgAliases = make(map[string]string)
+		}
+		cfg.ArgAliases[defaultArgAlias] = launchOptions
+	}
+	if err := cfg.Save(cfgPath); err != nil {
+		return err
+	}
+	fmt.Printf("\nSaved %s\n", cfgPath)
+	if len(games) > 0 {
+		if err := writeWizardList(listPath, games); err != nil {
+			return err
+		}
+		fmt.Printf("Saved %d game(s) to %s\n", len(games), listPath)
+	}
+
+	if _, hasDefault := cfg.ArgAliases[defaultArgAlias]; !hasDefault {
+		fmt.Println("\nNo default launch options yet; run gsca wizard again to choose some.")
+		return nil
+	}
+	if _, err := os.Stat(listPath); err != nil {
+		fmt.Println("\nNo allow list yet; pick games with gsca query, then apply the defaults with:")
+		fmt.Printf("   gsca update --args @%s --allow selected-games.txt\n", defaultArgAlias)
+		return nil
+	}
+	fmt.Println("\nPreview the changes, then apply them (with Steam closed) by running:")
+	fmt.Printf("   gsca update --args @%s --allow \"%s\" --dry-run\n", defaultArgAlias, listPath)
+	fmt.Printf("   gsca update --args @%s --allow \"%s\"\n", defaultArgAlias, listPath)
+	return nil
+}
+
+// wizardPickUser asks which account to manage when there are several, suggesting current
+// (if it has userdata here) or the one that signed in last
+func wizardPickUser(current string) (string, error) {
+	users, err := steam.ListUsers(steamPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to list Steam accounts: %w", err)
+	}
+	if len(users) == 1 {
+		fmt.Printf("Using the only account, %s\n", users[0].Label())
+		return users[0].ID, nil
+	}
+
+	def := 0
+	fmt.Println("Several accounts have signed in to this Steam install:")
+	for i, user := range users {
+		note := ""
+		if user.ID == current {
+			def = i
+			note = " (current)"
+		} else if user.MostRecent {
+			note = " (signed in last)"
+		}
+		fmt.Printf("  %d. %s%s\n", i+1, user.Label(), note)
+	}
+	fmt.Printf("Which one should gsca manage? [1-%d] (default %d): ", len(users), def+1)
+	input := readLine()
+	if input == "" {
+		return users[def].ID, nil
+	}
+	choice, err := strconv.Atoi(input)
+	if err != nil || choice < 1 || choice > len(users) {
+		return "", fmt.Errorf("invalid choice %q", input)
+	}
+	return users[choice-1].ID, nil
+}
+
+// wizardPickArgs offers the starter launch options and imported presets that work on this
+// platform, or launch options of the user's own. It returns "" to keep current
+func wizardPickArgs(current string) (string, error) {
+	var choices []preset.Preset
+	for _, p := range wizardStarters {
+		if p.SupportsOS(preset.OSName(runtime.GOOS)) {
+			choices = append(choices, p)
+		}
+	}
+	if store, err := presetStore(); err == nil {
+		if imported, err := store.List(); err == nil {
+			for _, p := range imported {
+				if p.SupportsOS(preset.OSName(runtime.GOOS)) {
+					choices = append(choices, p)
+				}
+			}
+		}
+	}
+
+	fmt.Println("Which launch options should your games use by default?")
+	for i, p := range choices {
+		fmt.Printf("  %d. %-40s %s\n", i+1, p.Args, p.Description)
+	}
+	fmt.Println("  c. Enter your own")
+	if current != "" {
+		fmt.Printf("Current default: %s\n", current)
+		fmt.Printf("Choice [1-%d, c] (default: keep the current one): ", len(choices))
+	} else {
+		fmt.Printf("Choice [1-%d, c] (default: none for now): ", len(choices))
+	}
+
+	var launchOptions string
+	switch input := strings.ToLower(readLine()); input {
+	case "":
+		return "", nil
+	case "c":
+		fmt.Print("Launch options: ")
+		launchOptions = readLine()
+		if launchOptions == "" {
+			return "", nil
+		}
+	default:
+		choice, err := strconv.Atoi(input)
+		if err != nil || choice < 1 || choice > len(choices) {
+			return "", fmt.Errorf("invalid choice %q", input)
+		}
+		launchOptions = choices[choice-1].Args
+	}
+
+	warnings, err := steam.CheckLaunchOptions(launchOptions)
+	if err != nil {
+		return "", err
+	}
+	for _, warning := range warnings {
+		fmt.Printf("WARNING: %s\n", warning)
+	}
+	return launchOptions, nil
+}
+
+// wizardPickGames lists the installed games of the chosen account by name and returns the
+// ones selected, using the same selection syntax as query
+func wizardPickGames() ([]steam.GameInfo, error) {
+	allGames, err := steam.GetAllGames(steamPath, steam.GetLocalConfigPath(steamPath, userID), printProgress("Loading game library..."))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get game library: %w", err)
+	}
+
+	var installed []steam.GameInfo
+	for _, game := range allGames {
+		if game.Installed && (includeTools || game.AppType != steam.AppTypeTool) && (includeHidden || !game.Hidden) {
+			installed = append(installed, game)
+		}
+	}
+	if len(installed) == 0 {
+		fmt.Println("No installed games found; make an allow list later with gsca query")
+		return nil, nil
+	}
+	sort.SliceStable(installed, func(i, j int) bool {
+		return steam.FoldName(installed[i].Name) < steam.FoldName(installed[j].Name)
+	})
+
+	fmt.Println("Which games should use the default launch options?")
+	input := selectMatches(installed, queryLimit)
+	if input == "" {
+		fmt.Println(i18n.T(i18n.NoGamesSelected))
+		return nil, nil
+	}
+	selected := parseSelection(input, len(installed))
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("invalid selection %q", input)
+	}
+
+	games := make([]steam.GameInfo, len(selected))
+	for i, idx := range selected {
+		games[i] = installed[idx]
+	}
+	return games, nil
+}
+
+// writeWizardList writes an allow list with each game's name as a comment above its ID
+func writeWizardList(path string, games []steam.GameInfo) error {
+	var b strings.Builder
+	b.WriteString("# Created by gsca wizard\n")
+	for _, game := range games {
+		fmt.Fprintf(&b, "# %s\n%s\n", game.Name, game.AppID)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}